@@ -0,0 +1,159 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var ErrNotAuthenticated = errors.New("coinpe: client is not authenticated, call Authenticate and Verify first")
+
+func expiresAt(seconds int32) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(seconds) * time.Second)
+}
+
+func (c *Client) storePartialToken(resp *api.AuthenticateResponse) {
+	if resp.AccessToken == "" {
+		return
+	}
+	c.setTokens(func(t *Tokens) {
+		t.PartialAccessToken = resp.AccessToken
+	})
+}
+
+func (c *Client) storeFullTokens(resp *api.AuthenticateResponse) {
+	c.setTokens(func(t *Tokens) {
+		t.PartialAccessToken = ""
+		t.AccessToken = resp.AccessToken
+		t.AccessTokenExpiresAt = expiresAt(resp.AccessTokenExpiryInSeconds)
+		if resp.RefreshToken != "" {
+			t.RefreshToken = resp.RefreshToken
+			t.RefreshTokenExpiresAt = expiresAt(resp.RefreshTokenExpiryInSeconds)
+		}
+	})
+}
+
+// CreateAccount registers a new account (or finds the existing one) and
+// stores the partial auth token needed by Verify.
+func (c *Client) CreateAccount(ctx context.Context, req *api.CreateAccountRequest) (*api.AuthenticateResponse, error) {
+	resp := &api.AuthenticateResponse{}
+	err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   "/v1/accounts",
+		body:   req,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.storePartialToken(resp)
+	return resp, nil
+}
+
+// Authenticate starts a login and stores the partial auth token needed by
+// Verify. When no account exists the response has GoTo set to
+// api.GotToCreateAccount and the error is nil.
+func (c *Client) Authenticate(ctx context.Context, req *api.AuthenticateRequest) (*api.AuthenticateResponse, error) {
+	resp := &api.AuthenticateResponse{}
+	err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   "/v1/authenticate",
+		body:   req,
+	}, resp)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		notFound := &api.AuthenticateResponse{}
+		if json.Unmarshal(apiErr.body, notFound) == nil && notFound.GoTo == api.GotToCreateAccount {
+			return notFound, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.storePartialToken(resp)
+	return resp, nil
+}
+
+// Verify completes the login with the OTP sent to the account and switches
+// the client to the full auth scoped access and refresh tokens.
+func (c *Client) Verify(ctx context.Context, otp string) (*api.AuthenticateResponse, error) {
+	partialToken := c.Tokens().PartialAccessToken
+	if partialToken == "" {
+		return nil, ErrNotAuthenticated
+	}
+
+	resp := &api.AuthenticateResponse{}
+	err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   "/v1/verify",
+		body: &api.VerifyAuthRequest{
+			Otp:         otp,
+			AccessToken: partialToken,
+		},
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.storeFullTokens(resp)
+	return resp, nil
+}
+
+// Refresh exchanges the refresh token for new tokens right away. Requests
+// already do this on their own when the access token is about to expire.
+func (c *Client) Refresh(ctx context.Context) error {
+	return c.refresh(ctx, c.Tokens().AccessToken)
+}
+
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	tokens := c.Tokens()
+	if tokens.AccessToken == "" {
+		return "", ErrNotAuthenticated
+	}
+
+	if tokens.RefreshToken != "" && !tokens.AccessTokenExpiresAt.IsZero() &&
+		time.Until(tokens.AccessTokenExpiresAt) < refreshLeeway {
+		err := c.refresh(ctx, tokens.AccessToken)
+		if err != nil {
+			return "", err
+		}
+		tokens = c.Tokens()
+	}
+
+	return tokens.AccessToken, nil
+}
+
+// refresh replaces staleToken, unless a concurrent request already did.
+func (c *Client) refresh(ctx context.Context, staleToken string) error {
+	c.refreshing.Lock()
+	defer c.refreshing.Unlock()
+
+	tokens := c.Tokens()
+	if tokens.AccessToken != staleToken {
+		return nil
+	}
+	if tokens.RefreshToken == "" {
+		return ErrNotAuthenticated
+	}
+
+	resp := &api.AuthenticateResponse{}
+	err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   "/v1/refresh",
+		body:   &api.RefreshTokenRequest{RefreshToken: tokens.RefreshToken},
+	}, resp)
+	if err != nil {
+		return err
+	}
+
+	c.storeFullTokens(resp)
+	return nil
+}
@@ -0,0 +1,273 @@
+// Package client is a Go SDK for the CoinPe /v1 HTTP API.
+//
+//	c := client.New("https://api.coinpe.example")
+//	_, err := c.Authenticate(ctx, &api.AuthenticateRequest{Username: "+919999999999", Role: "CUSTOMER"})
+//	_, err = c.Verify(ctx, "123123")
+//	wallet, err := c.GetMyWallet(ctx)
+//
+// Once verified the client attaches the full access token to every request
+// and refreshes it before it expires.
+package client
+
+import (
+	"bytes"
+	"coinpe/pkg/constants"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 200 * time.Millisecond
+	maxRetryBackoff     = 5 * time.Second
+
+	// access tokens are refreshed this long before they expire
+	refreshLeeway = 30 * time.Second
+)
+
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+
+	mu         sync.Mutex
+	tokens     Tokens
+	onRefresh  func(Tokens)
+	refreshing sync.Mutex
+}
+
+// Tokens is the authentication state of a Client. Persist it (see
+// WithTokenRefreshHook) and pass it back with WithTokens to resume a session.
+type Tokens struct {
+	PartialAccessToken    string
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+func New(baseURL string, options ...func(*Client)) *Client {
+	c := &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		httpClient:   &http.Client{Timeout: defaultTimeout},
+		maxRetries:   defaultMaxRetries,
+		retryBackoff: defaultRetryBackoff,
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+func WithHTTPClient(httpClient *http.Client) func(*Client) {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how often retryable requests are retried and the initial
+// backoff, which doubles on every attempt.
+func WithRetries(maxRetries int, backoff time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+func WithTokens(tokens Tokens) func(*Client) {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// WithTokenRefreshHook is called whenever the client obtains new tokens.
+func WithTokenRefreshHook(hook func(Tokens)) func(*Client) {
+	return func(c *Client) {
+		c.onRefresh = hook
+	}
+}
+
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+func (c *Client) setTokens(update func(t *Tokens)) {
+	c.mu.Lock()
+	update(&c.tokens)
+	tokens := c.tokens
+	c.mu.Unlock()
+
+	if c.onRefresh != nil {
+		c.onRefresh(tokens)
+	}
+}
+
+type request struct {
+	method         string
+	path           string
+	body           interface{}
+	authenticated  bool
+	idempotencyKey string
+	// rawBody is sent as is with contentType instead of body as JSON
+	rawBody     []byte
+	contentType string
+}
+
+// retryable requests are safe to send more than once
+func (r *request) retryable() bool {
+	return r.method == http.MethodGet || r.idempotencyKey != ""
+}
+
+func (c *Client) do(ctx context.Context, req *request, out interface{}) error {
+	var (
+		body []byte
+		err  error
+	)
+
+	if req.body != nil {
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return err
+		}
+	}
+	if req.rawBody != nil {
+		body = req.rawBody
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		accessToken := ""
+		if req.authenticated {
+			accessToken, err = c.accessToken(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		retryAfter, err := c.send(ctx, req, body, accessToken, out)
+		if err == nil {
+			return nil
+		}
+
+		// a token rejected before its expiry (e.g. secret rotation) gets one refresh
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized && req.authenticated && !refreshed {
+			refreshed = true
+			if refreshErr := c.refresh(ctx, accessToken); refreshErr == nil {
+				attempt--
+				continue
+			}
+			return err
+		}
+
+		if !req.retryable() || attempt >= c.maxRetries || !isTemporary(err) {
+			return err
+		}
+
+		wait := c.backoff(attempt)
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req *request, body []byte, accessToken string, out interface{}) (time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, reader)
+	if err != nil {
+		return 0, err
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	} else if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if accessToken != "" {
+		httpReq.Header.Set(constants.AuthorizationHeaderName, "Bearer "+accessToken)
+	}
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set(constants.IdempotencyKeyHeaderName, req.idempotencyKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if d, ok := out.(*download); ok && resp.StatusCode < http.StatusBadRequest {
+		return 0, d.copy(resp)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &Error{StatusCode: resp.StatusCode, body: respBody}
+		json.Unmarshal(respBody, apiErr)
+		if apiErr.Code == 0 {
+			apiErr.Code = codeForStatus(resp.StatusCode)
+		}
+		return parseRetryAfter(resp.Header.Get("Retry-After")), apiErr
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return 0, nil
+	}
+	return 0, json.Unmarshal(respBody, out)
+}
+
+func isTemporary(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	if errors.Is(err, errDownloadInterrupted) {
+		// part of the body was already written out
+		return false
+	}
+	// transport errors (connection refused, reset, timeouts) are worth retrying
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.retryBackoff << attempt
+	if wait <= 0 || wait > maxRetryBackoff {
+		wait = maxRetryBackoff
+	}
+	// full jitter keeps many clients from retrying in lockstep
+	return time.Duration(rand.Int64N(int64(wait)) + 1)
+}
+
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// statement exports carry their checksum in this header, or trailer for CSV
+const statementChecksumHeader = "Statement-Checksum"
+
+var errDownloadInterrupted = errors.New("download interrupted")
+
+// download streams a successful response body to w instead of decoding it as
+// JSON.
+type download struct {
+	w      io.Writer
+	header http.Header
+}
+
+func (d *download) copy(resp *http.Response) error {
+	_, err := io.Copy(d.w, resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errDownloadInterrupted, err)
+	}
+
+	// trailers are only populated once the body is read
+	d.header = resp.Header.Clone()
+	for k, v := range resp.Trailer {
+		d.header[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	errorConst "coinpe/pkg/error"
+	"fmt"
+	"net/http"
+)
+
+// Error is returned for every non 2xx response. Compare it against the
+// sentinel errors below with errors.Is, e.g.
+//
+//	if errors.Is(err, client.ErrInsufficientFunds) { ... }
+type Error struct {
+	StatusCode      int                    `json:"-"`
+	Code            int                    `json:"code,omitempty"`
+	CodeDescription string                 `json:"code_description,omitempty"`
+	Message         string                 `json:"message,omitempty"`
+	MessageID       string                 `json:"message_id,omitempty"`
+	AdditionalInfo  map[string]interface{} `json:"additional_info,omitempty"`
+	Retryable       bool                   `json:"retryable,omitempty"`
+	Errors          []errorConst.Error     `json:"errors,omitempty"`
+
+	body []byte
+}
+
+var (
+	ErrBadRequest        = &Error{Code: errorConst.ErrorBadRequest}
+	ErrBindingRequest    = &Error{Code: errorConst.ErrorBindingRequest}
+	ErrValidation        = &Error{Code: errorConst.ErrorValidation}
+	ErrInsufficientFunds = &Error{Code: errorConst.ErrorInsufficientFunds}
+	ErrUnauthorized      = &Error{Code: errorConst.ErrorUnauthorized}
+	ErrForbidden         = &Error{Code: errorConst.ErrorForbidden}
+	ErrNotFound          = &Error{Code: errorConst.ErrorNoRecordsFound}
+	ErrConflict          = &Error{Code: errorConst.ErrorConflict}
+	ErrUnprocessable     = &Error{Code: errorConst.ErrorUnprocessable}
+	ErrInternal          = &Error{Code: errorConst.ErrorInternalError}
+	ErrUnavailable       = &Error{Code: errorConst.ErrorServiceUnavailable}
+)
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("coinpe: %d %s: %s", e.Code, e.CodeDescription, e.Message)
+	}
+	return fmt.Sprintf("coinpe: %d %s", e.Code, e.CodeDescription)
+}
+
+// Is matches errors with the same pkg/error code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// Temporary reports whether retrying the same request may succeed, either
+// because the server flagged the error as retryable or from its status. A
+// CoinPe error body is only retried when flagged, other 5xx responses come
+// from a proxy in front of the server and are retried for the gateway ones.
+func (e *Error) Temporary() bool {
+	if e.Retryable || e.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if e.CodeDescription != "" {
+		return false
+	}
+	switch e.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// codeForStatus is used when the response body carries no pkg/error code,
+// e.g. the router's NoRoute handler.
+func codeForStatus(statusCode int) int {
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return errorConst.ErrorUnauthorized
+	case statusCode == http.StatusForbidden:
+		return errorConst.ErrorForbidden
+	case statusCode == http.StatusNotFound:
+		return errorConst.ErrorNoRecordsFound
+	case statusCode == http.StatusConflict:
+		return errorConst.ErrorConflict
+	case statusCode == http.StatusUnprocessableEntity:
+		return errorConst.ErrorUnprocessable
+	case statusCode == http.StatusServiceUnavailable:
+		return errorConst.ErrorServiceUnavailable
+	case statusCode >= http.StatusInternalServerError:
+		return errorConst.ErrorInternalError
+	default:
+		return errorConst.ErrorBadRequest
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// QuoteFee returns the fee a transfer from the authenticated account's
+// wallet would be charged if it were posted now.
+func (c *Client) QuoteFee(ctx context.Context, req *api.FeeQuoteRequest) (*api.FeeQuote, error) {
+	resp := &api.FeeQuote{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/fees/quote",
+		body:          req,
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateFeeSchedule adds a fee schedule. Internal roles only.
+func (c *Client) CreateFeeSchedule(ctx context.Context, req *api.CreateFeeScheduleRequest) (*api.FeeSchedule, error) {
+	resp := &api.FeeSchedule{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/fees/schedules",
+		body:          req,
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListFeeSchedules returns the fee schedules in force, newest first.
+func (c *Client) ListFeeSchedules(ctx context.Context) (*api.ListFeeSchedulesResponse, error) {
+	resp := &api.ListFeeSchedulesResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/fees/schedules",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) GetFeeSchedule(ctx context.Context, feeScheduleUUID string) (*api.FeeSchedule, error) {
+	resp := &api.FeeSchedule{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/fees/schedules/" + url.PathEscape(feeScheduleUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteFeeSchedule stops a fee schedule applying to new transfers.
+func (c *Client) DeleteFeeSchedule(ctx context.Context, feeScheduleUUID string) error {
+	return c.do(ctx, &request{
+		method:        http.MethodDelete,
+		path:          "/v1/fees/schedules/" + url.PathEscape(feeScheduleUUID),
+		authenticated: true,
+	}, nil)
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func (c *Client) GetMyHandle(ctx context.Context) (*api.HandleResponse, error) {
+	resp := &api.HandleResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/wallets/me/handle",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SetMyHandle gives the authenticated account's wallet handle, releasing
+// the one it had.
+func (c *Client) SetMyHandle(ctx context.Context, handle string) (*api.HandleResponse, error) {
+	resp := &api.HandleResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPut,
+		path:          "/v1/wallets/me/handle",
+		body:          &api.SetHandleRequest{Handle: handle},
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) DeleteMyHandle(ctx context.Context) error {
+	return c.do(ctx, &request{
+		method:        http.MethodDelete,
+		path:          "/v1/wallets/me/handle",
+		authenticated: true,
+	}, nil)
+}
+
+// ResolveHandle returns the wallet and display name a payment to handle
+// reaches.
+func (c *Client) ResolveHandle(ctx context.Context, handle string) (*api.ResolvedHandle, error) {
+	resp := &api.ResolvedHandle{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/handles/" + url.PathEscape(handle),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func paymentQRQuery(req *api.CreatePaymentQRRequest) string {
+	query := url.Values{}
+	if req.AmountInCents > 0 {
+		query.Set("amount_in_cents", strconv.Itoa(req.AmountInCents))
+	}
+	if req.ExpiresAt != nil {
+		query.Set("expires_at", req.ExpiresAt.Format(time.RFC3339))
+	}
+	if req.Size > 0 {
+		query.Set("size", strconv.Itoa(req.Size))
+	}
+	return query.Encode()
+}
+
+// GetMyPaymentQR returns a signed payment QR payload to the authenticated
+// account's handle.
+func (c *Client) GetMyPaymentQR(ctx context.Context, req *api.CreatePaymentQRRequest) (*api.PaymentQR, error) {
+	resp := &api.PaymentQR{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/wallets/me/qr?" + paymentQRQuery(req),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetMyPaymentQRPNG writes the payment QR code of GetMyPaymentQR to w as a
+// PNG.
+func (c *Client) GetMyPaymentQRPNG(ctx context.Context, req *api.CreatePaymentQRRequest, w io.Writer) error {
+	return c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/wallets/me/qr.png?" + paymentQRQuery(req),
+		authenticated: true,
+	}, &download{w: w})
+}
+
+// ParsePaymentQR checks a scanned payment QR payload and returns what it
+// asks for.
+func (c *Client) ParsePaymentQR(ctx context.Context, payload string) (*api.ParsedPaymentQR, error) {
+	resp := &api.ParsedPaymentQR{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/qr/parse",
+		body:          &api.ParsePaymentQRRequest{Payload: payload},
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
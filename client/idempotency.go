@@ -0,0 +1,14 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewIdempotencyKey returns a random key for the Idempotency-Key header.
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read never returns an error
+	rand.Read(b)
+	return "idem_" + hex.EncodeToString(b)
+}
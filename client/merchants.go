@@ -0,0 +1,220 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateMerchant makes the merchant profile of the authenticated MERCHANT
+// account.
+func (c *Client) CreateMerchant(ctx context.Context, req *api.CreateMerchantRequest) (*api.Merchant, error) {
+	resp := &api.Merchant{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/merchants",
+		body:          req,
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) GetMyMerchant(ctx context.Context) (*api.Merchant, error) {
+	resp := &api.Merchant{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/merchants/me",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateMyMerchant replaces the name and webhook URL of the authenticated
+// account's merchant profile.
+func (c *Client) UpdateMyMerchant(ctx context.Context, req *api.UpdateMerchantRequest) (*api.Merchant, error) {
+	resp := &api.Merchant{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPut,
+		path:          "/v1/merchants/me",
+		body:          req,
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RotateMyMerchantWebhookSecret replaces the secret signing the
+// authenticated merchant's webhooks.
+func (c *Client) RotateMyMerchantWebhookSecret(ctx context.Context) (*api.Merchant, error) {
+	resp := &api.Merchant{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/merchants/me/webhook-secret",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateCheckoutSession asks a customer to pay the authenticated merchant
+// for an order.
+func (c *Client) CreateCheckoutSession(ctx context.Context, req *api.CreateCheckoutSessionRequest, idempotencyKey string) (*api.CheckoutSession, error) {
+	resp := &api.CheckoutSession{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/checkout/sessions",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListCheckoutSessions returns a page of the authenticated merchant's
+// checkout sessions, newest first.
+func (c *Client) ListCheckoutSessions(ctx context.Context, req *api.ListCheckoutSessionsRequest) (*api.ListCheckoutSessionsResponse, error) {
+	query := url.Values{}
+	if req.Status != "" {
+		query.Set("status", req.Status)
+	}
+	if req.Before != "" {
+		query.Set("before", req.Before)
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+
+	resp := &api.ListCheckoutSessionsResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/checkout/sessions?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) GetCheckoutSession(ctx context.Context, checkoutSessionUUID string) (*api.CheckoutSession, error) {
+	resp := &api.CheckoutSession{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/checkout/sessions/" + url.PathEscape(checkoutSessionUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PayCheckoutSession pays a checkout session from the authenticated
+// account's wallet.
+func (c *Client) PayCheckoutSession(ctx context.Context, checkoutSessionUUID string, idempotencyKey string) (*api.PayCheckoutSessionResponse, error) {
+	resp := &api.PayCheckoutSessionResponse{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/checkout/sessions/" + url.PathEscape(checkoutSessionUUID) + "/pay",
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) CancelCheckoutSession(ctx context.Context, checkoutSessionUUID string) (*api.CheckoutSession, error) {
+	resp := &api.CheckoutSession{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/checkout/sessions/" + url.PathEscape(checkoutSessionUUID) + "/cancel",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RefundCheckoutSession refunds part of a paid checkout session, or all
+// that is left of it when req has no amount.
+func (c *Client) RefundCheckoutSession(ctx context.Context, checkoutSessionUUID string, req *api.CreateRefundRequest, idempotencyKey string) (*api.CreateRefundResponse, error) {
+	resp := &api.CreateRefundResponse{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/checkout/sessions/" + url.PathEscape(checkoutSessionUUID) + "/refunds",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListMySettlementBatches returns a page of the authenticated merchant's
+// settlement batches, newest first.
+func (c *Client) ListMySettlementBatches(ctx context.Context, req *api.ListSettlementBatchesRequest) (*api.ListSettlementBatchesResponse, error) {
+	query := url.Values{}
+	if req.Before != "" {
+		query.Set("before", req.Before)
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+
+	resp := &api.ListSettlementBatchesResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/merchants/me/settlements?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetMySettlementReport returns a settlement batch of the authenticated
+// merchant with what each of its checkout sessions contributed.
+func (c *Client) GetMySettlementReport(ctx context.Context, settlementBatchUUID string) (*api.SettlementReport, error) {
+	resp := &api.SettlementReport{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/merchants/me/settlements/" + url.PathEscape(settlementBatchUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DownloadMySettlementReport streams the CSV report of a settlement batch
+// to w.
+func (c *Client) DownloadMySettlementReport(ctx context.Context, settlementBatchUUID string, w io.Writer) error {
+	return c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/merchants/me/settlements/" + url.PathEscape(settlementBatchUUID) + "/report.csv",
+		authenticated: true,
+	}, &download{w: w})
+}
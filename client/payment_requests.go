@@ -0,0 +1,145 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreatePaymentRequest asks the owner of a wallet to pay the authenticated
+// account's wallet.
+func (c *Client) CreatePaymentRequest(ctx context.Context, req *api.CreatePaymentRequestRequest, idempotencyKey string) (*api.PaymentRequest, error) {
+	resp := &api.PaymentRequest{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/payment-requests",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListPaymentRequests returns a page of the payment requests the
+// authenticated account has to pay, or has made, newest first.
+func (c *Client) ListPaymentRequests(ctx context.Context, req *api.ListPaymentRequestsRequest) (*api.ListPaymentRequestsResponse, error) {
+	query := url.Values{}
+	if req.Direction != "" {
+		query.Set("direction", req.Direction)
+	}
+	if req.Status != "" {
+		query.Set("status", req.Status)
+	}
+	if req.Before != "" {
+		query.Set("before", req.Before)
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+
+	resp := &api.ListPaymentRequestsResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/payment-requests?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) GetPaymentRequest(ctx context.Context, paymentRequestUUID string) (*api.PaymentRequest, error) {
+	resp := &api.PaymentRequest{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/payment-requests/" + url.PathEscape(paymentRequestUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ApprovePaymentRequest pays a payment request from the authenticated
+// account's wallet.
+func (c *Client) ApprovePaymentRequest(ctx context.Context, paymentRequestUUID string, idempotencyKey string) (*api.ApprovePaymentRequestResponse, error) {
+	resp := &api.ApprovePaymentRequestResponse{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/payment-requests/" + url.PathEscape(paymentRequestUUID) + "/approve",
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeclinePaymentRequest declines a payment request, reason may be empty.
+func (c *Client) DeclinePaymentRequest(ctx context.Context, paymentRequestUUID string, req *api.DeclinePaymentRequestRequest) (*api.PaymentRequest, error) {
+	resp := &api.PaymentRequest{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/payment-requests/" + url.PathEscape(paymentRequestUUID) + "/decline",
+		body:          req,
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CancelPaymentRequest withdraws a payment request the authenticated
+// account made.
+func (c *Client) CancelPaymentRequest(ctx context.Context, paymentRequestUUID string) (*api.PaymentRequest, error) {
+	resp := &api.PaymentRequest{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/payment-requests/" + url.PathEscape(paymentRequestUUID) + "/cancel",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateSplitBill sends every payer of a bill a payment request for their
+// share.
+func (c *Client) CreateSplitBill(ctx context.Context, req *api.CreateSplitBillRequest, idempotencyKey string) (*api.SplitBill, error) {
+	resp := &api.SplitBill{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/split-bills",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetSplitBill returns a split bill with who has paid.
+func (c *Client) GetSplitBill(ctx context.Context, splitBillUUID string) (*api.SplitBill, error) {
+	resp := &api.SplitBill{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/split-bills/" + url.PathEscape(splitBillUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreatePayout credits the wallets of req from the treasury. The payout is
+// returned PROCESSING once every item is valid, queue workers post it.
+// Internal roles only.
+func (c *Client) CreatePayout(ctx context.Context, req *api.CreatePayoutRequest, idempotencyKey string) (*api.Payout, error) {
+	resp := &api.Payout{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/payouts",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ImportPayoutFile uploads a CSV payout file, see CreatePayout.
+func (c *Client) ImportPayoutFile(ctx context.Context, req *api.ImportPayoutFileRequest, file io.Reader, idempotencyKey string) (*api.Payout, error) {
+	// read up front so a retry can send the file again
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	if req.PurposeCode != "" {
+		query.Set("purpose_code", req.PurposeCode)
+	}
+	if req.Description != "" {
+		query.Set("description", req.Description)
+	}
+	if req.Filename != "" {
+		query.Set("filename", req.Filename)
+	}
+
+	resp := &api.Payout{}
+	err = c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/payouts/csv?" + query.Encode(),
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+		rawBody:        body,
+		contentType:    "text/csv",
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetPayout returns the progress of a payout.
+func (c *Client) GetPayout(ctx context.Context, payoutUUID string) (*api.Payout, error) {
+	resp := &api.Payout{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/payouts/" + url.PathEscape(payoutUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListPayoutItems returns a page of the items of a payout in file order.
+func (c *Client) ListPayoutItems(ctx context.Context, payoutUUID string, req *api.ListPayoutItemsRequest) (*api.ListPayoutItemsResponse, error) {
+	query := url.Values{}
+	if req.Status != "" {
+		query.Set("status", req.Status)
+	}
+	if req.AfterRow > 0 {
+		query.Set("after_row", strconv.Itoa(req.AfterRow))
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+
+	resp := &api.ListPayoutItemsResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/payouts/" + url.PathEscape(payoutUUID) + "/items?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DownloadPayoutResult streams the CSV outcome of every item of a payout
+// to w.
+func (c *Client) DownloadPayoutResult(ctx context.Context, payoutUUID string, w io.Writer) error {
+	return c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/payouts/" + url.PathEscape(payoutUUID) + "/result.csv",
+		authenticated: true,
+	}, &download{w: w})
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ImportSettlementFile uploads a CSV settlement file and returns how its
+// records reconciled. Internal roles only.
+func (c *Client) ImportSettlementFile(ctx context.Context, req *api.ImportSettlementFileRequest, file io.Reader) (*api.SettlementImportResponse, error) {
+	// read up front so a retry can send the file again
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"source": {req.Source}}
+	if req.Filename != "" {
+		query.Set("filename", req.Filename)
+	}
+	if req.Timezone != "" {
+		query.Set("timezone", req.Timezone)
+	}
+
+	resp := &api.SettlementImportResponse{}
+	err = c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/reconciliation/imports?" + query.Encode(),
+		authenticated: true,
+		rawBody:       body,
+		contentType:   "text/csv",
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListSettlementImports returns a page of settlement imports, newest first.
+func (c *Client) ListSettlementImports(ctx context.Context, req *api.ListSettlementImportsRequest) (*api.ListSettlementImportsResponse, error) {
+	query := url.Values{}
+	if req.Before != "" {
+		query.Set("before", req.Before)
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+
+	resp := &api.ListSettlementImportsResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/reconciliation/imports?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetReconciliationReport returns the reconciliation report of a settlement
+// import, its records limited to status when set.
+func (c *Client) GetReconciliationReport(ctx context.Context, importUUID string, status string) (*api.ReconciliationReport, error) {
+	path := "/v1/reconciliation/imports/" + url.PathEscape(importUUID)
+	if status != "" {
+		path += "?" + url.Values{"status": {status}}.Encode()
+	}
+
+	resp := &api.ReconciliationReport{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          path,
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReconcileSettlementImport runs the matching rules again over the
+// unmatched and mismatched records of a settlement import.
+func (c *Client) ReconcileSettlementImport(ctx context.Context, importUUID string) (*api.SettlementImportResponse, error) {
+	resp := &api.SettlementImportResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/reconciliation/imports/" + url.PathEscape(importUUID) + "/reconcile",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ResolveSettlementRecord matches, accepts, ignores or reopens a settlement
+// record.
+func (c *Client) ResolveSettlementRecord(ctx context.Context, recordUUID string, req *api.ResolveSettlementRecordRequest) (*api.SettlementRecord, error) {
+	resp := &api.SettlementRecord{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/reconciliation/records/" + url.PathEscape(recordUUID) + "/resolve",
+		body:          req,
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateScheduledTransfer schedules transfers from the authenticated
+// account's wallet.
+func (c *Client) CreateScheduledTransfer(ctx context.Context, req *api.CreateScheduledTransferRequest, idempotencyKey string) (*api.ScheduledTransfer, error) {
+	resp := &api.ScheduledTransfer{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/scheduled-transfers",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListScheduledTransfers returns a page of the authenticated account's
+// scheduled transfers, newest first.
+func (c *Client) ListScheduledTransfers(ctx context.Context, req *api.ListScheduledTransfersRequest) (*api.ListScheduledTransfersResponse, error) {
+	query := url.Values{}
+	if req.Status != "" {
+		query.Set("status", req.Status)
+	}
+	if req.Before != "" {
+		query.Set("before", req.Before)
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+
+	resp := &api.ListScheduledTransfersResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/scheduled-transfers?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetScheduledTransfer returns a scheduled transfer with its latest runs.
+func (c *Client) GetScheduledTransfer(ctx context.Context, scheduledTransferUUID string) (*api.ScheduledTransfer, error) {
+	resp := &api.ScheduledTransfer{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/scheduled-transfers/" + url.PathEscape(scheduledTransferUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PauseScheduledTransfer pauses an active scheduled transfer.
+func (c *Client) PauseScheduledTransfer(ctx context.Context, scheduledTransferUUID string) (*api.ScheduledTransfer, error) {
+	return c.changeScheduledTransfer(ctx, scheduledTransferUUID, "pause")
+}
+
+// ResumeScheduledTransfer resumes a paused scheduled transfer.
+func (c *Client) ResumeScheduledTransfer(ctx context.Context, scheduledTransferUUID string) (*api.ScheduledTransfer, error) {
+	return c.changeScheduledTransfer(ctx, scheduledTransferUUID, "resume")
+}
+
+// CancelScheduledTransfer cancels a scheduled transfer.
+func (c *Client) CancelScheduledTransfer(ctx context.Context, scheduledTransferUUID string) (*api.ScheduledTransfer, error) {
+	return c.changeScheduledTransfer(ctx, scheduledTransferUUID, "cancel")
+}
+
+func (c *Client) changeScheduledTransfer(ctx context.Context, scheduledTransferUUID string, action string) (*api.ScheduledTransfer, error) {
+	resp := &api.ScheduledTransfer{}
+	err := c.do(ctx, &request{
+		method:        http.MethodPost,
+		path:          "/v1/scheduled-transfers/" + url.PathEscape(scheduledTransferUUID) + "/" + action,
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
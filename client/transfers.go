@@ -0,0 +1,133 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CreateTransfer moves coins from the authenticated account's wallet. An
+// empty idempotencyKey is replaced with a generated one; pass your own to
+// make the transfer safe to repeat across process restarts.
+func (c *Client) CreateTransfer(ctx context.Context, req *api.CreateTransferRequest, idempotencyKey string) (*api.TransferResponse, error) {
+	if idempotencyKey == "" {
+		idempotencyKey = NewIdempotencyKey()
+	}
+
+	resp := &api.TransferResponse{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/transfers",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SubmitTransfer queues a transfer from the authenticated account's wallet.
+// Poll it with GetPendingTransaction or WaitForPendingTransaction, or set a
+// CallbackURL. idempotencyKey works as for CreateTransfer.
+func (c *Client) SubmitTransfer(ctx context.Context, req *api.SubmitTransferRequest, idempotencyKey string) (*api.PendingTransaction, error) {
+	if idempotencyKey == "" {
+		idempotencyKey = NewIdempotencyKey()
+	}
+
+	resp := &api.PendingTransaction{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/transfers/async",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) GetPendingTransaction(ctx context.Context, pendingTransactionUUID string) (*api.PendingTransaction, error) {
+	resp := &api.PendingTransaction{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/transfers/async/" + url.PathEscape(pendingTransactionUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WaitForPendingTransaction polls a queued transfer every interval until it
+// SUCCEEDED or FAILED, or ctx is done.
+func (c *Client) WaitForPendingTransaction(ctx context.Context, pendingTransactionUUID string, interval time.Duration) (*api.PendingTransaction, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pending, err := c.GetPendingTransaction(ctx, pendingTransactionUUID)
+		if err != nil {
+			return nil, err
+		}
+		if pending.Status != "QUEUED" {
+			return pending, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return pending, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SubmitTransferBatch queues up to 1000 transfers at once.
+func (c *Client) SubmitTransferBatch(ctx context.Context, req *api.SubmitTransferBatchRequest, idempotencyKey string) (*api.TransferBatch, error) {
+	if idempotencyKey == "" {
+		idempotencyKey = NewIdempotencyKey()
+	}
+
+	resp := &api.TransferBatch{}
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/v1/transfers/batches",
+		body:           req,
+		authenticated:  true,
+		idempotencyKey: idempotencyKey,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) GetTransferBatch(ctx context.Context, batchUUID string) (*api.TransferBatch, error) {
+	resp := &api.TransferBatch{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/transfers/batches/" + url.PathEscape(batchUUID),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VerifyWebhook reports whether signature, the Coinpe-Signature header of
+// a webhook, signs body with secret.
+func VerifyWebhook(secret string, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal([]byte("sha256="+hex.EncodeToString(mac.Sum(nil))), []byte(signature))
+}
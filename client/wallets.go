@@ -0,0 +1,113 @@
+package client
+
+import (
+	"coinpe/pkg/api"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// GetMyWallet returns the balance of the authenticated account's wallet.
+func (c *Client) GetMyWallet(ctx context.Context) (*api.WalletBalanceResponse, error) {
+	resp := &api.WalletBalanceResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/wallets/me",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetMyWalletChain verifies the transaction hash chain of the authenticated
+// account's wallet.
+func (c *Client) GetMyWalletChain(ctx context.Context) (*api.WalletChainResponse, error) {
+	resp := &api.WalletChainResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/wallets/me/chain",
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetMyBalanceAt returns the balance of the authenticated account's wallet
+// after every transaction created at or before at.
+func (c *Client) GetMyBalanceAt(ctx context.Context, at time.Time) (*api.BalanceAtResponse, error) {
+	query := url.Values{"at": {at.Format(time.RFC3339Nano)}}
+
+	resp := &api.BalanceAtResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/wallets/me/balance?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetMyStatement returns the statement of the authenticated account's wallet
+// for the transactions created in [from, to).
+func (c *Client) GetMyStatement(ctx context.Context, from time.Time, to time.Time) (*api.StatementResponse, error) {
+	query := url.Values{
+		"from": {from.Format(time.RFC3339Nano)},
+		"to":   {to.Format(time.RFC3339Nano)},
+	}
+
+	resp := &api.StatementResponse{}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          "/v1/wallets/me/statement?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ExportMyStatementCSV streams the CSV statement of the authenticated
+// account's wallet for [from, to) to w and returns its checksum. An export
+// cut short fails instead of returning a checksum.
+func (c *Client) ExportMyStatementCSV(ctx context.Context, from time.Time, to time.Time, w io.Writer) (string, error) {
+	return c.exportMyStatement(ctx, "/v1/wallets/me/statement.csv", from, to, w)
+}
+
+// ExportMyStatementPDF writes the PDF statement of the authenticated
+// account's wallet for [from, to) to w and returns the checksum printed on
+// it.
+func (c *Client) ExportMyStatementPDF(ctx context.Context, from time.Time, to time.Time, w io.Writer) (string, error) {
+	return c.exportMyStatement(ctx, "/v1/wallets/me/statement.pdf", from, to, w)
+}
+
+func (c *Client) exportMyStatement(ctx context.Context, path string, from time.Time, to time.Time, w io.Writer) (string, error) {
+	query := url.Values{
+		"from": {from.Format(time.RFC3339Nano)},
+		"to":   {to.Format(time.RFC3339Nano)},
+	}
+
+	resp := &download{w: w}
+	err := c.do(ctx, &request{
+		method:        http.MethodGet,
+		path:          path + "?" + query.Encode(),
+		authenticated: true,
+	}, resp)
+	if err != nil {
+		return "", err
+	}
+
+	checksum := resp.header.Get(statementChecksumHeader)
+	if checksum == "" {
+		return "", errDownloadInterrupted
+	}
+	return checksum, nil
+}
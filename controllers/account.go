@@ -11,24 +11,17 @@ import (
 	"gorm.io/gorm"
 )
 
-func (b *BaseController) CreateAccount(c *gin.Context) {
+func (b *BaseController) CreateAccount(c *gin.Context) error {
 	var (
 		roleID      uint64
 		request     = CreateAccountRequest{}
-		errResponse = errorConst.ErrorResponse{}
-		accountRepo = models.InitAccountRepo(b.DB)
-		walletRepo  = models.InitWalletRepo(b.DB)
+		accountRepo = models.InitAccountRepo(b.requestDB(c))
+		walletRepo  = models.InitWalletRepo(b.requestDB(c))
 	)
 
-	err := c.ShouldBindJSON(&request)
+	err := b.bindAndValidate(c, &request)
 	if err != nil {
-		logger.Error("unable to bind request | err: ", err)
-		c.JSON(http.StatusBadRequest,
-			errResponse.Generate(
-				errorConst.ErrorBindingRequest,
-				errorConst.ErrorText(errorConst.ErrorBindingRequest),
-				errorConst.EmptyInterface))
-		return
+		return err
 	}
 
 	switch request.Role {
@@ -41,29 +34,22 @@ func (b *BaseController) CreateAccount(c *gin.Context) {
 	case string(models.RoleTypeCustomer):
 		roleID = uint64(models.RoleCustomer)
 
+	case string(models.RoleTypeMerchant):
+		roleID = uint64(models.RoleMerchant)
+
 	default:
 		logger.Error("invalid role")
-		c.JSON(http.StatusBadRequest, errResponse.Generate(
-			errorConst.ErrorBadRequest,
-			"invalid role",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidRole)
 	}
 
-	tx := b.DB.Begin()
+	tx := b.requestDB(c).Begin()
 
 	// check account exists or not
 	existingAccount, err := accountRepo.FindOne(tx, request.Email, request.PhoneNumber, "")
 	if err != nil && err != gorm.ErrRecordNotFound {
 		logger.Error("error in getting account | err: ", err)
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in getting account",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingAccount)
 	}
 
 	accessTokenExpiryTime := int32(b.Config.JWTConfiguration.PartialAuthAccessTokenExpiryInSeconds)
@@ -86,12 +72,7 @@ func (b *BaseController) CreateAccount(c *gin.Context) {
 		if err != nil {
 			logger.Error("unable to create access token ", err)
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, errResponse.Generate(
-				errorConst.ErrorInternalError,
-				"error in creating access token",
-				errorConst.EmptyInterface,
-			))
-			return
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingAccessToken)
 		}
 
 		c.JSON(http.StatusOK, AuthenticateResponse{
@@ -101,7 +82,7 @@ func (b *BaseController) CreateAccount(c *gin.Context) {
 			Handle:                     *existingAccount.PhoneNumber,
 			GoTo:                       GoToVerifyAccount,
 		})
-		return
+		return nil
 	}
 
 	account := models.Account{
@@ -116,12 +97,7 @@ func (b *BaseController) CreateAccount(c *gin.Context) {
 	if err != nil {
 		logger.Error("error in creating account | err: ", err)
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in creating account",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingAccount)
 	}
 
 	// create wallet
@@ -134,12 +110,7 @@ func (b *BaseController) CreateAccount(c *gin.Context) {
 	if err != nil {
 		logger.Error("error in creating wallet | err: ", err)
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in creating wallet",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingWallet)
 	}
 
 	customClaims := &jwtauth.CustomClaims{
@@ -159,23 +130,13 @@ func (b *BaseController) CreateAccount(c *gin.Context) {
 	if err != nil {
 		logger.Error("unable to create access token ", err)
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in creating access token",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingAccessToken)
 	}
 
 	err = tx.Commit().Error
 	if err != nil {
 		logger.Error("error in commiting | err: ", err)
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in getting account",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingAccount)
 	}
 
 	c.JSON(http.StatusOK, AuthenticateResponse{
@@ -185,5 +146,5 @@ func (b *BaseController) CreateAccount(c *gin.Context) {
 		Handle:                     *account.PhoneNumber,
 		GoTo:                       GoToVerifyAccount,
 	})
-
+	return nil
 }
@@ -14,25 +14,18 @@ import (
 	"gorm.io/gorm"
 )
 
-func (b *BaseController) Authenticate(c *gin.Context) {
+func (b *BaseController) Authenticate(c *gin.Context) error {
 	var (
 		email        string
 		phone        string
 		refreshToken string
 		request      = AuthenticateRequest{}
-		errResponse  = errorConst.ErrorResponse{}
-		accountRepo  = models.InitAccountRepo(b.DB)
+		accountRepo  = models.InitAccountRepo(b.requestDB(c))
 	)
 
-	err := c.ShouldBindJSON(&request)
+	err := b.bindAndValidate(c, &request)
 	if err != nil {
-		logger.Error("unable to bind request | err: ", err)
-		c.JSON(http.StatusBadRequest,
-			errResponse.Generate(
-				errorConst.ErrorBindingRequest,
-				errorConst.ErrorText(errorConst.ErrorBindingRequest),
-				errorConst.EmptyInterface))
-		return
+		return err
 	}
 
 	if strings.Contains(request.Username, "@") {
@@ -41,28 +34,18 @@ func (b *BaseController) Authenticate(c *gin.Context) {
 		phone = request.Username
 	}
 
-	if !slices.Contains([]string{string(models.RoleTypeSuperAdmin), string(models.RoleTypeAdmin), string(models.RoleTypeCustomer)}, request.Role) {
+	if !slices.Contains([]string{string(models.RoleTypeSuperAdmin), string(models.RoleTypeAdmin), string(models.RoleTypeCustomer), string(models.RoleTypeMerchant)}, request.Role) {
 		logger.Error("invalid role")
-		c.JSON(http.StatusBadRequest, errResponse.Generate(
-			errorConst.ErrorBadRequest,
-			"invalid role",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidRole)
 	}
 
-	tx := b.DB.Begin()
+	tx := b.requestDB(c).Begin()
 
 	// check account exists or not
 	account, err := accountRepo.FindOne(tx, email, phone, "")
 	if err != nil && err != gorm.ErrRecordNotFound {
 		logger.Error("error in getting account | err: ", err)
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in getting account",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingAccount)
 	}
 
 	if err == gorm.ErrRecordNotFound || account.ID == 0 {
@@ -70,7 +53,7 @@ func (b *BaseController) Authenticate(c *gin.Context) {
 		c.JSON(http.StatusNotFound, AuthenticateResponse{
 			GoTo: GotToCreateAccount,
 		})
-		return
+		return nil
 	}
 
 	accessTokenExpiryTime := int32(b.Config.JWTConfiguration.PartialAuthAccessTokenExpiryInSeconds)
@@ -91,24 +74,14 @@ func (b *BaseController) Authenticate(c *gin.Context) {
 	if err != nil {
 		logger.Error("unable to create access token ", err)
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in creating access token",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingAccessToken)
 	}
 
 	err = tx.Commit().Error
 	if err != nil {
 		logger.Error("unable to commit | err: ", err)
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in commiting",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCommitting)
 	}
 
 	c.JSON(http.StatusOK, AuthenticateResponse{
@@ -119,38 +92,26 @@ func (b *BaseController) Authenticate(c *gin.Context) {
 		Handle:                     request.Username,
 		GoTo:                       GoToVerifyAccount,
 	})
-
+	return nil
 }
 
-func (b *BaseController) VerifyAuthenticate(c *gin.Context) {
+func (b *BaseController) VerifyAuthenticate(c *gin.Context) error {
 	var (
 		request         = VerifyAuthRequest{}
-		errResponse     = errorConst.ErrorResponse{}
-		accountRepo     = models.InitAccountRepo(b.DB)
-		credentialsRepo = models.InitCredentialRepo(b.DB)
+		accountRepo     = models.InitAccountRepo(b.requestDB(c))
+		credentialsRepo = models.InitCredentialRepo(b.requestDB(c))
 	)
 
-	err := c.ShouldBindJSON(&request)
+	err := b.bindAndValidate(c, &request)
 	if err != nil {
-		logger.Error("error in binding request | err: ", err)
-		c.JSON(http.StatusBadRequest,
-			errResponse.Generate(
-				errorConst.ErrorBindingRequest,
-				errorConst.ErrorText(errorConst.ErrorBindingRequest),
-				errorConst.EmptyInterface))
-		return
+		return err
 	}
 
 	// Parse token
 	parsedTokenClaims, err := jwtauth.ParseToken(request.AccessToken, []byte(b.Config.JWTConfiguration.SecretKey))
 	if err != nil {
 		logger.Error("error in getting parsed token | err: ", err)
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in getting parsed token",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorUnauthorized, errorConst.MsgInvalidAccessToken)
 	}
 
 	account, err := accountRepo.Get(&models.Account{
@@ -158,12 +119,10 @@ func (b *BaseController) VerifyAuthenticate(c *gin.Context) {
 	})
 	if err != nil {
 		logger.Error("unable to get account | err: ", err)
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in getting account",
-			errorConst.EmptyInterface,
-		))
-		return
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorUnauthorized, errorConst.MsgInvalidAccessToken)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingAccount)
 	}
 
 	// Get credential
@@ -173,12 +132,7 @@ func (b *BaseController) VerifyAuthenticate(c *gin.Context) {
 	})
 	if err != nil {
 		logger.Error("error in creating otp secret | err: ", err)
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"error in creating otp secret",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingOTPSecret)
 	}
 
 	secretKey := credentials.Password
@@ -187,22 +141,12 @@ func (b *BaseController) VerifyAuthenticate(c *gin.Context) {
 	isOTPValid, err := otphelpers.ValidateOTP(secretKey, request.Otp, b.Config.ShouldMock())
 	if err != nil {
 		logger.Error("unable to validate user otp | err: ", err)
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"unable to validate user otp",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgUnableToValidateUserOTP)
 	}
 
 	if !isOTPValid {
 		logger.Info("invalid otp provided")
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"invalid otp provided",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.New(errorConst.ErrorUnauthorized, errorConst.MsgInvalidOTPProvided)
 	}
 
 	accessTokenRequest := CreateTokenRequest{
@@ -222,17 +166,91 @@ func (b *BaseController) VerifyAuthenticate(c *gin.Context) {
 	accessTokenResponse, err := b.createToken(&accessTokenRequest)
 	if err != nil {
 		logger.Error("unable to create access token | err: ", err)
-		c.JSON(http.StatusInternalServerError, errResponse.Generate(
-			errorConst.ErrorInternalError,
-			"unable to create access token",
-			errorConst.EmptyInterface,
-		))
-		return
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgUnableToCreateAccessToken)
+	}
+
+	refreshTokenResponse, err := b.createToken(&CreateTokenRequest{
+		TokenType: TokenTypeRefresh,
+		CustomClaims: &jwtauth.CustomClaims{
+			Role:        string(parsedTokenClaims.Role),
+			AccountUUID: accountUUID,
+		},
+	})
+	if err != nil {
+		logger.Error("unable to create refresh token | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgUnableToCreateRefreshToken)
+	}
+
+	c.JSON(http.StatusOK, AuthenticateResponse{
+		AccessToken:                 accessTokenResponse.Token,
+		AccessTokenExpiryInSeconds:  int32(accessTokenResponse.ExpiryInSeconds),
+		RefreshToken:                refreshTokenResponse.Token,
+		RefreshTokenExpiryInSeconds: int32(refreshTokenResponse.ExpiryInSeconds),
+		GoTo:                        GoToContinue,
+	})
+	return nil
+}
+
+// RefreshToken exchanges a refresh token for a new full auth scoped access
+// token. The refresh token is rotated on every use.
+func (b *BaseController) RefreshToken(c *gin.Context) error {
+	var (
+		request     = RefreshTokenRequest{}
+		accountRepo = models.InitAccountRepo(b.requestDB(c))
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	parsedTokenClaims, err := jwtauth.ParseToken(request.RefreshToken, []byte(b.Config.JWTConfiguration.SecretKey))
+	if err != nil || parsedTokenClaims.TokenType != jwtauth.TokenTypeRefresh {
+		logger.Error("invalid refresh token | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorUnauthorized, errorConst.MsgInvalidRefreshToken)
+	}
+
+	account, err := accountRepo.Get(&models.Account{
+		UUID: parsedTokenClaims.AccountUUID,
+	})
+	if err != nil {
+		logger.Error("unable to get account | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorUnauthorized, errorConst.MsgInvalidRefreshToken)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingAccount)
+	}
+
+	accessTokenResponse, err := b.createToken(&CreateTokenRequest{
+		TokenType: TokenTypeAccess,
+		CustomClaims: &jwtauth.CustomClaims{
+			Role:        parsedTokenClaims.Role,
+			AccountUUID: account.UUID,
+		},
+	})
+	if err != nil {
+		logger.Error("unable to create access token | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgUnableToCreateAccessToken)
+	}
+
+	refreshTokenResponse, err := b.createToken(&CreateTokenRequest{
+		TokenType: TokenTypeRefresh,
+		CustomClaims: &jwtauth.CustomClaims{
+			Role:        parsedTokenClaims.Role,
+			AccountUUID: account.UUID,
+		},
+	})
+	if err != nil {
+		logger.Error("unable to create refresh token | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgUnableToCreateRefreshToken)
 	}
 
 	c.JSON(http.StatusOK, AuthenticateResponse{
-		AccessToken:                accessTokenResponse.Token,
-		AccessTokenExpiryInSeconds: int32(accessTokenResponse.ExpiryInSeconds),
-		GoTo:                       GoToContinue,
+		AccessToken:                 accessTokenResponse.Token,
+		AccessTokenExpiryInSeconds:  int32(accessTokenResponse.ExpiryInSeconds),
+		RefreshToken:                refreshTokenResponse.Token,
+		RefreshTokenExpiryInSeconds: int32(refreshTokenResponse.ExpiryInSeconds),
+		GoTo:                        GoToContinue,
 	})
+	return nil
 }
@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"coinpe/models"
+	"coinpe/pkg/constants"
 	"coinpe/pkg/jwtauth"
 	"coinpe/pkg/logger"
 	"coinpe/pkg/utils"
+	"slices"
 	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 // createToken: Creates the respective token and persists in redis
@@ -29,6 +34,8 @@ func (b *BaseController) createToken(request *CreateTokenRequest) (*CreateTokenR
 
 	}
 
+	request.CustomClaims.TokenType = string(request.TokenType)
+
 	token, err := jwtauth.NewTokenWithClaims([]byte(b.Config.JWTConfiguration.SecretKey),
 		*request.CustomClaims, expiryTime)
 	if err != nil {
@@ -42,3 +49,9 @@ func (b *BaseController) createToken(request *CreateTokenRequest) (*CreateTokenR
 	}, nil
 
 }
+
+// isInternalRole reports whether the authenticated account has an internal
+// (admin) role.
+func isInternalRole(c *gin.Context) bool {
+	return slices.Contains([]string{string(models.RoleTypeSuperAdmin), string(models.RoleTypeAdmin)}, c.GetString(constants.AuthorizedAccountRoleContextKey))
+}
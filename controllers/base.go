@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"coinpe/pkg/config"
+	"coinpe/pkg/events"
 
+	"github.com/gin-gonic/gin"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
@@ -12,5 +14,12 @@ type BaseController struct {
 	DB         *gorm.DB
 	Config     config.Config
 	Validator  *validator.Validate
-	Translator *ut.Translator
+	Translator *ut.UniversalTranslator
+	Events     *events.Broker
+}
+
+// requestDB is b.DB bound to the request context, so replica reads made
+// after a write in the same request still see that write.
+func (b *BaseController) requestDB(c *gin.Context) *gorm.DB {
+	return b.DB.WithContext(c.Request.Context())
 }
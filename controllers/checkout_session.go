@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultCheckoutSessionsLimit = 20
+
+func toAPIRefund(r *models.Refund) api.Refund {
+	refund := api.Refund{
+		UUID:                r.UUID,
+		CheckoutSessionUUID: r.CheckoutSessionUUID,
+		AmountInCents:       r.AmountInCents,
+		Reason:              r.Reason,
+		ReferenceID:         r.ReferenceID,
+	}
+	if r.CreatedAt != nil {
+		refund.CreatedAt = *r.CreatedAt
+	}
+	return refund
+}
+
+// toAPICheckoutSession maps s, with refunds when they are not nil.
+func toAPICheckoutSession(s *models.CheckoutSession, merchantName string, refunds []models.Refund) api.CheckoutSession {
+	session := api.CheckoutSession{
+		UUID:                s.UUID,
+		MerchantUUID:        s.MerchantUUID,
+		MerchantName:        merchantName,
+		MerchantWalletUUID:  s.MerchantWalletUUID,
+		OrderID:             s.OrderID,
+		AmountInCents:       s.AmountInCents,
+		Currency:            s.Currency,
+		Description:         s.Description,
+		SuccessURL:          s.SuccessURL,
+		Status:              string(s.CurrentStatus()),
+		ExpiresAt:           s.ExpiresAt,
+		CustomerWalletUUID:  s.CustomerWalletUUID,
+		ReferenceID:         s.ReferenceID,
+		PaidAt:              s.PaidAt,
+		RefundedInCents:     s.RefundedInCents,
+		MerchantFeeInCents:  s.MerchantFeeInCents,
+		SettlementStatus:    string(s.SettlementStatus),
+		SettlementBatchUUID: s.SettlementBatchUUID,
+	}
+	if s.CreatedAt != nil {
+		session.CreatedAt = *s.CreatedAt
+	}
+	for i := range refunds {
+		session.Refunds = append(session.Refunds, toAPIRefund(&refunds[i]))
+	}
+	return session
+}
+
+// hideMerchantTerms clears what only the merchant of s sees: the fee it
+// pays the platform and when it is paid out.
+func hideMerchantTerms(s *models.CheckoutSession) {
+	s.MerchantFeeInCents = 0
+	s.SettlementStatus = ""
+	s.SettlementBatchUUID = ""
+}
+
+func checkoutError(err error, id errorConst.MessageID) error {
+	logger.Error(errorConst.Text(id)+" | err: ", err)
+	var domainErr *errorConst.DomainError
+	if errors.As(err, &domainErr) {
+		return err
+	}
+	return errorConst.Wrap(err, errorConst.ErrorInternalError, id)
+}
+
+func (b *BaseController) checkoutSessionExpiry(expiresAt *time.Time) (time.Time, error) {
+	now := time.Now()
+	if expiresAt == nil {
+		return now.Add(b.Config.Checkout.SessionExpiry), nil
+	}
+
+	if !expiresAt.After(now) || expiresAt.After(now.Add(b.Config.Checkout.MaxSessionExpiry)) {
+		return time.Time{}, errorConst.New(errorConst.ErrorValidation, errorConst.MsgInvalidExpiry).WithFields([]errorConst.Error{{
+			Field:       "expires_at",
+			Description: fmt.Sprintf("must be in the future and at most %s away", b.Config.Checkout.MaxSessionExpiry),
+		}})
+	}
+	return expiresAt.UTC(), nil
+}
+
+// CreateCheckoutSession asks a customer to pay the authenticated merchant
+// for an order. It is called by the merchant's server.
+func (b *BaseController) CreateCheckoutSession(c *gin.Context) error {
+	var (
+		request             = CreateCheckoutSessionRequest{}
+		checkoutSessionRepo = models.InitCheckoutSessionRepo(b.requestDB(c))
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	expiresAt, err := b.checkoutSessionExpiry(request.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	m, err := b.myMerchant(c)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := models.InitWalletRepo(b.requestDB(c)).Get(&models.Wallet{ID: m.WalletID})
+	if err != nil {
+		logger.Error("error in getting merchant wallet | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgMerchantWalletNotFound)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingMerchantWallet)
+	}
+
+	s := &models.CheckoutSession{
+		MerchantID:         m.ID,
+		MerchantUUID:       m.UUID,
+		MerchantWalletID:   wallet.ID,
+		MerchantWalletUUID: wallet.UUID,
+		OrderID:            request.OrderID,
+		AmountInCents:      request.AmountInCents,
+		Currency:           wallet.Currency,
+		Description:        request.Description,
+		SuccessURL:         request.SuccessURL,
+		ExpiresAt:          expiresAt,
+	}
+	err = checkoutSessionRepo.Create(s)
+	if err != nil {
+		return checkoutError(err, errorConst.MsgErrorInCreatingCheckoutSession)
+	}
+
+	c.JSON(http.StatusCreated, toAPICheckoutSession(s, m.Name, nil))
+	return nil
+}
+
+// ListCheckoutSessions lists the authenticated merchant's checkout
+// sessions, newest first.
+func (b *BaseController) ListCheckoutSessions(c *gin.Context) error {
+	var (
+		request             = ListCheckoutSessionsRequest{}
+		checkoutSessionRepo = models.InitCheckoutSessionRepo(b.requestDB(c))
+		beforeID            uint64
+	)
+
+	err := b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultCheckoutSessionsLimit
+	}
+
+	m, err := b.myMerchant(c)
+	if err != nil {
+		return err
+	}
+
+	if request.Before != "" {
+		before, err := checkoutSessionRepo.Get(&models.CheckoutSession{UUID: request.Before, MerchantID: m.ID})
+		if err != nil {
+			logger.Error("error in getting checkout session | err: ", err)
+			if err == gorm.ErrRecordNotFound {
+				return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgCheckoutSessionNotFound)
+			}
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingCheckoutSession)
+		}
+		beforeID = before.ID
+	}
+
+	sessions, err := checkoutSessionRepo.List(m.ID, models.CheckoutSessionStatus(request.Status), beforeID, request.Limit)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInListingCheckoutSessions)
+	}
+
+	response := ListCheckoutSessionsResponse{CheckoutSessions: make([]api.CheckoutSession, 0, len(sessions))}
+	for i := range sessions {
+		response.CheckoutSessions = append(response.CheckoutSessions, toAPICheckoutSession(&sessions[i], m.Name, nil))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// checkoutSession returns the checkout session of the request with its
+// merchant and whether the authenticated account is that merchant.
+// Customers only see open sessions and the ones they paid.
+func (b *BaseController) checkoutSession(c *gin.Context, checkoutSessionRepo models.ICheckoutSession) (*models.CheckoutSession, *models.Merchant, bool, error) {
+	s, err := checkoutSessionRepo.Get(&models.CheckoutSession{UUID: c.Param("checkout_session_uuid")})
+	if err != nil {
+		logger.Error("error in getting checkout session | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, false, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgCheckoutSessionNotFound)
+		}
+		return nil, nil, false, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingCheckoutSession)
+	}
+
+	m, err := models.InitMerchantRepo(b.requestDB(c)).Get(&models.Merchant{ID: s.MerchantID})
+	if err != nil {
+		logger.Error("error in getting merchant | err: ", err)
+		return nil, nil, false, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingCheckoutSession)
+	}
+
+	accountUUID := c.GetString(constants.AuthorizedAccountUUIDContextKey)
+	isMerchant := m.AccountUUID == accountUUID
+	if !isMerchant && !isInternalRole(c) && s.CurrentStatus() != models.CheckoutSessionOpen && s.CustomerAccountUUID != accountUUID {
+		return nil, nil, false, errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgCheckoutSessionNotFound)
+	}
+	return s, m, isMerchant, nil
+}
+
+// GetCheckoutSession returns a checkout session, with its refunds, the
+// merchant's fee and its settlement to its merchant and internal roles.
+func (b *BaseController) GetCheckoutSession(c *gin.Context) error {
+	checkoutSessionRepo := models.InitCheckoutSessionRepo(b.requestDB(c))
+
+	s, m, isMerchant, err := b.checkoutSession(c, checkoutSessionRepo)
+	if err != nil {
+		return err
+	}
+
+	var refunds []models.Refund
+	if isMerchant || isInternalRole(c) {
+		refunds, err = checkoutSessionRepo.ListRefunds(s.ID)
+		if err != nil {
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingCheckoutSession)
+		}
+	} else {
+		hideMerchantTerms(s)
+	}
+
+	c.JSON(http.StatusOK, toAPICheckoutSession(s, m.Name, refunds))
+	return nil
+}
+
+// PayCheckoutSession pays an open checkout session from the authenticated
+// account's wallet, the merchant is sent a checkout_session.completed
+// webhook.
+func (b *BaseController) PayCheckoutSession(c *gin.Context) error {
+	checkoutSessionRepo := models.InitCheckoutSessionRepo(b.requestDB(c))
+
+	s, m, _, err := b.checkoutSession(c, checkoutSessionRepo)
+	if err != nil {
+		return err
+	}
+
+	customer, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	s, posting, err := checkoutSessionRepo.Pay(s.ID, customer)
+	if err != nil {
+		return checkoutError(err, errorConst.MsgErrorInPayingCheckoutSession)
+	}
+
+	hideMerchantTerms(s)
+	c.JSON(http.StatusOK, PayCheckoutSessionResponse{
+		CheckoutSession: toAPICheckoutSession(s, m.Name, nil),
+		Transactions:    toAPIPostingTransactions(posting),
+	})
+	return nil
+}
+
+// CancelCheckoutSession closes an open checkout session of the
+// authenticated merchant.
+func (b *BaseController) CancelCheckoutSession(c *gin.Context) error {
+	checkoutSessionRepo := models.InitCheckoutSessionRepo(b.requestDB(c))
+
+	err := requireMerchantRole(c)
+	if err != nil {
+		return err
+	}
+
+	s, m, isMerchant, err := b.checkoutSession(c, checkoutSessionRepo)
+	if err != nil {
+		return err
+	}
+	if !isMerchant {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgCheckoutSessionMerchantOnly)
+	}
+
+	s, err = checkoutSessionRepo.Cancel(s.ID)
+	if err != nil {
+		return checkoutError(err, errorConst.MsgErrorInCancellingCheckoutSession)
+	}
+
+	c.JSON(http.StatusOK, toAPICheckoutSession(s, m.Name, nil))
+	return nil
+}
+
+// RefundCheckoutSession returns some or all of a paid checkout session of
+// the authenticated merchant to the customer, the merchant is sent a
+// refund.succeeded webhook.
+func (b *BaseController) RefundCheckoutSession(c *gin.Context) error {
+	var (
+		request             = CreateRefundRequest{}
+		checkoutSessionRepo = models.InitCheckoutSessionRepo(b.requestDB(c))
+	)
+
+	// the body is optional, without it everything left is refunded
+	if c.Request.ContentLength != 0 {
+		err := b.bindAndValidate(c, &request)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := requireMerchantRole(c)
+	if err != nil {
+		return err
+	}
+
+	s, m, isMerchant, err := b.checkoutSession(c, checkoutSessionRepo)
+	if err != nil {
+		return err
+	}
+	if !isMerchant {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgCheckoutSessionMerchantOnly)
+	}
+
+	s, refund, err := checkoutSessionRepo.Refund(s.ID, request.AmountInCents, request.Reason)
+	if err != nil {
+		return checkoutError(err, errorConst.MsgErrorInRefundingCheckoutSession)
+	}
+
+	c.JSON(http.StatusCreated, CreateRefundResponse{
+		Refund:          toAPIRefund(refund),
+		CheckoutSession: toAPICheckoutSession(s, m.Name, nil),
+	})
+	return nil
+}
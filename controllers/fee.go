@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func toAPIFeeSchedule(s *models.FeeSchedule) api.FeeSchedule {
+	schedule := api.FeeSchedule{
+		UUID:         s.UUID,
+		CreatedAt:    s.CreatedAt,
+		Name:         s.Name,
+		PurposeCode:  string(s.PurposeCode),
+		Role:         string(s.Role),
+		MerchantUUID: s.MerchantUUID,
+		Payer:        string(s.Payer),
+		Type:         string(s.Type),
+		FlatInCents:  s.FlatInCents,
+		BasisPoints:  s.BasisPoints,
+		MinInCents:   s.MinInCents,
+		MaxInCents:   s.MaxInCents,
+	}
+	for _, t := range s.Tiers {
+		schedule.Tiers = append(schedule.Tiers, api.FeeTier(t))
+	}
+	return schedule
+}
+
+// requireFeeRole restricts changing fee schedules to internal roles.
+func requireFeeRole(c *gin.Context) error {
+	if !isInternalRole(c) {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgFeeSchedulesInternalOnly)
+	}
+	return nil
+}
+
+// newFeeSchedule checks what the validator cannot: the purpose code, the
+// merchant, the order of the tiers and the caps.
+func (b *BaseController) newFeeSchedule(c *gin.Context, request *CreateFeeScheduleRequest) (*models.FeeSchedule, error) {
+	s := &models.FeeSchedule{
+		Name:        request.Name,
+		PurposeCode: purposecodes.TransactionPurposeCode(request.PurposeCode),
+		Role:        models.RoleType(request.Role),
+		Payer:       models.FeePayerSender,
+		Type:        models.FeeType(request.Type),
+		FlatInCents: request.FlatInCents,
+		BasisPoints: request.BasisPoints,
+		MinInCents:  request.MinInCents,
+		MaxInCents:  request.MaxInCents,
+	}
+	if request.Payer != "" {
+		s.Payer = models.FeePayer(request.Payer)
+	}
+
+	if s.PurposeCode != "" {
+		if !purposecodes.IsValid(s.PurposeCode) {
+			return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidPurposeCode)
+		}
+		// fee legs, settlements and moves between shards are never charged
+		if slices.Contains([]purposecodes.TransactionPurposeCode{purposecodes.PurposeCodeFee, purposecodes.PurposeCodeRebalance, purposecodes.PurposeCodeSettlement}, s.PurposeCode) {
+			return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgPurposeCodeNotAllowedForFeeSchedules)
+		}
+	}
+
+	if s.MaxInCents > 0 && s.MinInCents > s.MaxInCents {
+		return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgMinimumFeeExceedsMaximumFee)
+	}
+
+	if s.Type == models.FeeTiered {
+		for i, t := range request.Tiers {
+			last := i == len(request.Tiers)-1
+			if t.UpToInCents == 0 && !last {
+				return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgOnlyTheLastFeeTierCanBeUnbounded)
+			}
+			if i > 0 && t.UpToInCents != 0 && t.UpToInCents <= request.Tiers[i-1].UpToInCents {
+				return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgFeeTiersMustBeSortedByAmount)
+			}
+			s.Tiers = append(s.Tiers, models.FeeTier(t))
+		}
+	}
+
+	if request.MerchantUUID != "" {
+		m, err := models.InitMerchantRepo(b.requestDB(c)).Get(&models.Merchant{UUID: request.MerchantUUID})
+		if err != nil {
+			logger.Error("error in getting merchant | err: ", err)
+			if err == gorm.ErrRecordNotFound {
+				return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgMerchantNotFound)
+			}
+			return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingMerchant)
+		}
+		s.MerchantID = &m.ID
+		s.MerchantUUID = m.UUID
+	}
+	return s, nil
+}
+
+func (b *BaseController) feeSchedule(c *gin.Context, feeRepo models.IFee) (*models.FeeSchedule, error) {
+	s, err := feeRepo.Get(&models.FeeSchedule{UUID: c.Param("fee_schedule_uuid")})
+	if err != nil {
+		logger.Error("error in getting fee schedule | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgFeeScheduleNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingFeeSchedule)
+	}
+	return s, nil
+}
+
+// CreateFeeSchedule adds a fee schedule, applied to transfers posted from
+// now on.
+func (b *BaseController) CreateFeeSchedule(c *gin.Context) error {
+	var (
+		request = CreateFeeScheduleRequest{}
+		feeRepo = models.InitFeeRepo(b.requestDB(c))
+	)
+
+	err := requireFeeRole(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	s, err := b.newFeeSchedule(c, &request)
+	if err != nil {
+		return err
+	}
+
+	err = feeRepo.Create(s)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingFeeSchedule)
+	}
+
+	c.JSON(http.StatusCreated, toAPIFeeSchedule(s))
+	return nil
+}
+
+// ListFeeSchedules lists the fee schedules in force, newest first.
+func (b *BaseController) ListFeeSchedules(c *gin.Context) error {
+	err := requireFeeRole(c)
+	if err != nil {
+		return err
+	}
+
+	schedules, err := models.InitFeeRepo(b.requestDB(c)).List()
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInListingFeeSchedules)
+	}
+
+	response := ListFeeSchedulesResponse{FeeSchedules: make([]api.FeeSchedule, 0, len(schedules))}
+	for i := range schedules {
+		response.FeeSchedules = append(response.FeeSchedules, toAPIFeeSchedule(&schedules[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+func (b *BaseController) GetFeeSchedule(c *gin.Context) error {
+	err := requireFeeRole(c)
+	if err != nil {
+		return err
+	}
+
+	s, err := b.feeSchedule(c, models.InitFeeRepo(b.requestDB(c)))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, toAPIFeeSchedule(s))
+	return nil
+}
+
+// DeleteFeeSchedule stops a fee schedule applying to new transfers, the
+// fees it already charged stay.
+func (b *BaseController) DeleteFeeSchedule(c *gin.Context) error {
+	feeRepo := models.InitFeeRepo(b.requestDB(c))
+
+	err := requireFeeRole(c)
+	if err != nil {
+		return err
+	}
+
+	s, err := b.feeSchedule(c, feeRepo)
+	if err != nil {
+		return err
+	}
+
+	err = feeRepo.Delete(s.ID)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInDeletingFeeSchedule)
+	}
+
+	c.Status(http.StatusNoContent)
+	return nil
+}
+
+// QuoteFee returns the fee a transfer from the authenticated account's
+// wallet would be charged if it were posted now.
+func (b *BaseController) QuoteFee(c *gin.Context) error {
+	var (
+		request     = FeeQuoteRequest{}
+		walletRepo  = models.InitWalletRepo(b.requestDB(c))
+		purposeCode = purposecodes.PurposeCodeTransfer
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	if request.PurposeCode != "" {
+		purposeCode = purposecodes.TransactionPurposeCode(request.PurposeCode)
+		if !purposecodes.IsValid(purposeCode) {
+			return errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidPurposeCode)
+		}
+	}
+
+	toWalletUUID, err := b.walletUUIDFor(c, request.ToWalletUUID, request.ToHandle)
+	if err != nil {
+		return err
+	}
+
+	from, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	to, err := walletRepo.Get(&models.Wallet{UUID: toWalletUUID})
+	if err != nil {
+		logger.Error("error in getting destination wallet | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgDestinationWalletNotFound)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingDestinationWallet)
+	}
+
+	quote, err := models.InitFeeRepo(b.requestDB(c)).Quote(from, to, request.AmountInCents, purposeCode)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInQuotingFee)
+	}
+
+	response := FeeQuote{
+		ToWalletUUID:    to.UUID,
+		AmountInCents:   request.AmountInCents,
+		PurposeCode:     string(purposeCode),
+		FeeInCents:      quote.FeeInCents,
+		FeePayer:        string(quote.Payer),
+		DebitedInCents:  request.AmountInCents,
+		CreditedInCents: request.AmountInCents,
+	}
+	if quote.Schedule != nil {
+		response.FeeScheduleUUID = quote.Schedule.UUID
+	}
+	if quote.Payer == models.FeePayerRecipient {
+		response.CreditedInCents -= quote.FeeInCents
+	} else {
+		response.DebitedInCents += quote.FeeInCents
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
@@ -0,0 +1,11 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type (
+	CreateFeeScheduleRequest = api.CreateFeeScheduleRequest
+	FeeSchedule              = api.FeeSchedule
+	ListFeeSchedulesResponse = api.ListFeeSchedulesResponse
+	FeeQuoteRequest          = api.FeeQuoteRequest
+	FeeQuote                 = api.FeeQuote
+)
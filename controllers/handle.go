@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"coinpe/models"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/paymentqr"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultPaymentQRSize = 512
+
+func toHandleResponse(h *models.Handle) HandleResponse {
+	return HandleResponse{
+		Handle:     h.Handle,
+		WalletUUID: h.WalletUUID,
+	}
+}
+
+// getHandle returns the handle, normalized, and ErrorNoRecordsFound when
+// there is no such handle.
+func (b *BaseController) getHandle(c *gin.Context, handle string) (*models.Handle, error) {
+	handle, err := models.NormalizeHandle(handle)
+	if err != nil {
+		return nil, errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgHandleNotFound)
+	}
+
+	h, err := models.InitHandleRepo(b.requestDB(c)).Get(&models.Handle{Handle: handle})
+	if err != nil {
+		logger.Error("error in getting handle | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgHandleNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingHandle)
+	}
+	return h, nil
+}
+
+// walletUUIDFor returns the UUID of the wallet handle points at, or
+// walletUUID when there is no handle, for requests addressing a wallet
+// either way.
+func (b *BaseController) walletUUIDFor(c *gin.Context, walletUUID string, handle string) (string, error) {
+	if handle == "" {
+		return walletUUID, nil
+	}
+
+	h, err := b.getHandle(c, handle)
+	if err != nil {
+		return "", err
+	}
+	return h.WalletUUID, nil
+}
+
+// resolveHandle returns whom a payment to handle reaches.
+func (b *BaseController) resolveHandle(c *gin.Context, handle string) (*ResolvedHandle, error) {
+	h, err := b.getHandle(c, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := models.InitWalletRepo(b.requestDB(c)).Get(&models.Wallet{ID: h.WalletID})
+	if err != nil {
+		logger.Error("error in getting handle wallet | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgHandleNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingHandle)
+	}
+
+	account, err := models.InitAccountRepo(b.requestDB(c)).Get(&models.Account{UUID: h.AccountUUID})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgHandleNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingHandle)
+	}
+
+	return &ResolvedHandle{
+		Handle:      h.Handle,
+		WalletUUID:  wallet.UUID,
+		DisplayName: account.DisplayName(h.Handle),
+		Currency:    wallet.Currency,
+	}, nil
+}
+
+// GetMyHandle returns the handle of the authenticated account's wallet.
+func (b *BaseController) GetMyHandle(c *gin.Context) error {
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	h, err := models.InitHandleRepo(b.requestDB(c)).Get(&models.Handle{WalletID: wallet.ID})
+	if err != nil {
+		logger.Error("error in getting handle | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgHandleNotFound)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingHandle)
+	}
+
+	c.JSON(http.StatusOK, toHandleResponse(h))
+	return nil
+}
+
+// SetMyHandle gives the authenticated account's wallet a handle. The one it
+// had is released.
+func (b *BaseController) SetMyHandle(c *gin.Context) error {
+	var (
+		request    = SetHandleRequest{}
+		handleRepo = models.InitHandleRepo(b.requestDB(c))
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	h, err := handleRepo.Set(wallet, request.Handle)
+	if err != nil {
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInSettingHandle)
+	}
+
+	c.JSON(http.StatusOK, toHandleResponse(h))
+	return nil
+}
+
+// DeleteMyHandle releases the handle of the authenticated account's wallet.
+func (b *BaseController) DeleteMyHandle(c *gin.Context) error {
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	err = models.InitHandleRepo(b.requestDB(c)).Delete(wallet.ID)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInDeletingHandle)
+	}
+
+	c.Status(http.StatusNoContent)
+	return nil
+}
+
+// ResolveHandle returns the wallet and display name behind a handle, for
+// payers to check whom they pay.
+func (b *BaseController) ResolveHandle(c *gin.Context) error {
+	resolved, err := b.resolveHandle(c, c.Param("handle"))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, resolved)
+	return nil
+}
+
+// paymentQRKey returns the key signing payment QR payloads.
+func (b *BaseController) paymentQRKey() (ed25519.PrivateKey, error) {
+	if b.Config.Handles.QRSigningKey == "" {
+		return nil, errorConst.New(errorConst.ErrorServiceUnavailable, errorConst.MsgPaymentQRCodesAreNotEnabled)
+	}
+
+	key, err := paymentqr.ParseSigningKey(b.Config.Handles.QRSigningKey)
+	if err != nil {
+		logger.Error("invalid payment qr signing key | err: ", err)
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgPaymentQRCodesAreNotEnabled)
+	}
+	return key, nil
+}
+
+// myPaymentQR signs the payment QR payload the query of c asks for, to the
+// authenticated account's handle.
+func (b *BaseController) myPaymentQR(c *gin.Context) (*PaymentQR, *CreatePaymentQRRequest, error) {
+	request := CreatePaymentQRRequest{}
+	err := b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(b.Config.Handles.QRDefaultExpiry)
+	if request.ExpiresAt != nil {
+		if !request.ExpiresAt.After(now) || request.ExpiresAt.After(now.Add(b.Config.Handles.QRMaxExpiry)) {
+			return nil, nil, errorConst.New(errorConst.ErrorValidation, errorConst.MsgInvalidExpiry).WithFields([]errorConst.Error{{
+				Field:       "expires_at",
+				Description: fmt.Sprintf("must be in the future and at most %s away", b.Config.Handles.QRMaxExpiry),
+			}})
+		}
+		expiresAt = *request.ExpiresAt
+	}
+
+	key, err := b.paymentQRKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h, err := models.InitHandleRepo(b.requestDB(c)).Get(&models.Handle{WalletID: wallet.ID})
+	if err != nil {
+		logger.Error("error in getting handle | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, errorConst.Wrap(err, errorConst.ErrorUnprocessable, errorConst.MsgPaymentQRNeedsHandle)
+		}
+		return nil, nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingHandle)
+	}
+
+	payload := &paymentqr.Payload{
+		Handle:        h.Handle,
+		WalletUUID:    wallet.UUID,
+		Currency:      wallet.Currency,
+		AmountInCents: request.AmountInCents,
+		// payloads carry whole seconds
+		ExpiresAt: expiresAt.UTC().Truncate(time.Second),
+	}
+	return &PaymentQR{
+		Payload:       payload.Encode(key),
+		Handle:        payload.Handle,
+		WalletUUID:    payload.WalletUUID,
+		Currency:      payload.Currency,
+		AmountInCents: payload.AmountInCents,
+		ExpiresAt:     payload.ExpiresAt,
+	}, &request, nil
+}
+
+// GetMyPaymentQR returns a signed payment QR payload to the authenticated
+// account's handle, optionally for an amount.
+func (b *BaseController) GetMyPaymentQR(c *gin.Context) error {
+	qr, _, err := b.myPaymentQR(c)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, qr)
+	return nil
+}
+
+// GetMyPaymentQRPNG returns the payment QR code of GetMyPaymentQR as a PNG.
+func (b *BaseController) GetMyPaymentQRPNG(c *gin.Context) error {
+	qr, request, err := b.myPaymentQR(c)
+	if err != nil {
+		return err
+	}
+
+	size := request.Size
+	if size == 0 {
+		size = defaultPaymentQRSize
+	}
+
+	image, err := paymentqr.PNG(qr.Payload, size)
+	if err != nil {
+		logger.Error("error in rendering payment qr code | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInRenderingPaymentQRCode)
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+qr.Handle+`.png"`)
+	c.Data(http.StatusOK, "image/png", image)
+	return nil
+}
+
+// ParsePaymentQR checks a scanned payment QR payload and returns what it
+// asks for with the name of whom it pays.
+func (b *BaseController) ParsePaymentQR(c *gin.Context) error {
+	request := ParsePaymentQRRequest{}
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	key, err := b.paymentQRKey()
+	if err != nil {
+		return err
+	}
+
+	payload, err := paymentqr.Parse(request.Payload, key.Public().(ed25519.PublicKey), time.Now())
+	if err != nil {
+		logger.Info("invalid payment qr payload | err: ", err)
+		switch err {
+		case paymentqr.ErrExpired:
+			return errorConst.Wrap(err, errorConst.ErrorUnprocessable, errorConst.MsgPaymentQRCodeHasExpired)
+		default:
+			return errorConst.Wrap(err, errorConst.ErrorBadRequest, errorConst.MsgInvalidPaymentQRCode)
+		}
+	}
+
+	resolved, err := b.resolveHandle(c, payload.Handle)
+	if err != nil {
+		return err
+	}
+	// the handle was given up since, whoever holds it now is not the payee
+	if resolved.WalletUUID != payload.WalletUUID {
+		return errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgPaymentQRCodeIsNoLongerValid)
+	}
+
+	c.JSON(http.StatusOK, ParsedPaymentQR{
+		Handle:        resolved.Handle,
+		WalletUUID:    resolved.WalletUUID,
+		DisplayName:   resolved.DisplayName,
+		Currency:      payload.Currency,
+		AmountInCents: payload.AmountInCents,
+		ExpiresAt:     payload.ExpiresAt,
+	})
+	return nil
+}
@@ -0,0 +1,13 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type (
+	SetHandleRequest       = api.SetHandleRequest
+	HandleResponse         = api.HandleResponse
+	ResolvedHandle         = api.ResolvedHandle
+	CreatePaymentQRRequest = api.CreatePaymentQRRequest
+	PaymentQR              = api.PaymentQR
+	ParsePaymentQRRequest  = api.ParsePaymentQRRequest
+	ParsedPaymentQR        = api.ParsedPaymentQR
+)
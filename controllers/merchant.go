@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func toAPIMerchant(m *models.Merchant) api.Merchant {
+	return api.Merchant{
+		UUID:              m.UUID,
+		CreatedAt:         m.CreatedAt,
+		Name:              m.Name,
+		WalletUUID:        m.WalletUUID,
+		PendingWalletUUID: m.PendingWalletUUID,
+		WebhookURL:        m.WebhookURL,
+		WebhookSecret:     m.WebhookSecret,
+	}
+}
+
+func requireMerchantRole(c *gin.Context) error {
+	if c.GetString(constants.AuthorizedAccountRoleContextKey) != string(models.RoleTypeMerchant) {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgOnlyMerchantsCanDoThis)
+	}
+	return nil
+}
+
+// myMerchant returns the merchant profile of the authenticated MERCHANT
+// account.
+func (b *BaseController) myMerchant(c *gin.Context) (*models.Merchant, error) {
+	err := requireMerchantRole(c)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := models.InitMerchantRepo(b.requestDB(c)).Get(&models.Merchant{
+		AccountUUID: c.GetString(constants.AuthorizedAccountUUIDContextKey),
+	})
+	if err != nil {
+		logger.Error("error in getting merchant | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgMerchantProfileNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingMerchant)
+	}
+	return m, nil
+}
+
+// CreateMerchant makes the merchant profile of the authenticated MERCHANT
+// account, paid into its wallet.
+func (b *BaseController) CreateMerchant(c *gin.Context) error {
+	var (
+		request      = CreateMerchantRequest{}
+		merchantRepo = models.InitMerchantRepo(b.requestDB(c))
+	)
+
+	err := requireMerchantRole(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	m := &models.Merchant{
+		AccountUUID: wallet.UserUUID,
+		WalletID:    wallet.ID,
+		WalletUUID:  wallet.UUID,
+		Name:        request.Name,
+		WebhookURL:  request.WebhookURL,
+	}
+	err = merchantRepo.Create(m)
+	if err != nil {
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingMerchant)
+	}
+
+	c.JSON(http.StatusCreated, toAPIMerchant(m))
+	return nil
+}
+
+func (b *BaseController) GetMyMerchant(c *gin.Context) error {
+	m, err := b.myMerchant(c)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, toAPIMerchant(m))
+	return nil
+}
+
+// UpdateMyMerchant replaces the name and webhook URL of the authenticated
+// account's merchant profile.
+func (b *BaseController) UpdateMyMerchant(c *gin.Context) error {
+	var (
+		request      = UpdateMerchantRequest{}
+		merchantRepo = models.InitMerchantRepo(b.requestDB(c))
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	m, err := b.myMerchant(c)
+	if err != nil {
+		return err
+	}
+
+	m, err = merchantRepo.Update(m.ID, request.Name, request.WebhookURL)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInUpdatingMerchant)
+	}
+
+	c.JSON(http.StatusOK, toAPIMerchant(m))
+	return nil
+}
+
+// RotateMyMerchantWebhookSecret replaces the secret signing the
+// authenticated merchant's webhooks, the old one stops working at once.
+func (b *BaseController) RotateMyMerchantWebhookSecret(c *gin.Context) error {
+	m, err := b.myMerchant(c)
+	if err != nil {
+		return err
+	}
+
+	m, err = models.InitMerchantRepo(b.requestDB(c)).RotateWebhookSecret(m.ID)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInUpdatingMerchant)
+	}
+
+	c.JSON(http.StatusOK, toAPIMerchant(m))
+	return nil
+}
@@ -0,0 +1,21 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type (
+	CreateMerchantRequest        = api.CreateMerchantRequest
+	UpdateMerchantRequest        = api.UpdateMerchantRequest
+	Merchant                     = api.Merchant
+	CreateCheckoutSessionRequest = api.CreateCheckoutSessionRequest
+	CheckoutSession              = api.CheckoutSession
+	ListCheckoutSessionsRequest  = api.ListCheckoutSessionsRequest
+	ListCheckoutSessionsResponse = api.ListCheckoutSessionsResponse
+	PayCheckoutSessionResponse   = api.PayCheckoutSessionResponse
+	CreateRefundRequest          = api.CreateRefundRequest
+	CreateRefundResponse         = api.CreateRefundResponse
+
+	ListSettlementBatchesRequest  = api.ListSettlementBatchesRequest
+	ListSettlementBatchesResponse = api.ListSettlementBatchesResponse
+	SettlementBatch               = api.SettlementBatch
+	SettlementReport              = api.SettlementReport
+)
@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/utils"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultSettlementBatchesLimit = 20
+
+func toAPISettlementBatch(b *models.SettlementBatch) api.SettlementBatch {
+	batch := api.SettlementBatch{
+		UUID:              b.UUID,
+		MerchantUUID:      b.MerchantUUID,
+		PendingWalletUUID: b.PendingWalletUUID,
+		WalletUUID:        b.WalletUUID,
+		Currency:          b.Currency,
+		CutoffAt:          b.CutoffAt,
+		SessionCount:      b.SessionCount,
+		GrossInCents:      b.GrossInCents,
+		RefundsInCents:    b.RefundsInCents,
+		FeesInCents:       b.FeesInCents,
+		NetInCents:        b.NetInCents,
+		ReferenceID:       b.ReferenceID,
+	}
+	if b.CreatedAt != nil {
+		batch.CreatedAt = *b.CreatedAt
+	}
+	return batch
+}
+
+func toAPISettlementLine(line *models.SettlementLine) api.SettlementLine {
+	response := api.SettlementLine{
+		CheckoutSessionUUID: line.Session.UUID,
+		OrderID:             line.Session.OrderID,
+		ReferenceID:         line.Session.ReferenceID,
+		AmountInCents:       line.Session.AmountInCents,
+		RefundsInCents:      line.RefundsInCents,
+		FeesInCents:         line.FeesInCents,
+		NetInCents:          line.Session.ReceivableInCents,
+	}
+	if line.Session.PaidAt != nil {
+		response.PaidAt = *line.Session.PaidAt
+	}
+	return response
+}
+
+// mySettlementBatch returns the settlement batch of the request, of the
+// authenticated merchant.
+func (b *BaseController) mySettlementBatch(c *gin.Context, settlementBatchRepo models.ISettlementBatch) (*models.SettlementBatch, error) {
+	m, err := b.myMerchant(c)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := settlementBatchRepo.Get(&models.SettlementBatch{UUID: c.Param("settlement_batch_uuid"), MerchantID: m.ID})
+	if err != nil {
+		logger.Error("error in getting settlement batch | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgSettlementBatchNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingSettlementBatch)
+	}
+	return batch, nil
+}
+
+// ListMySettlementBatches lists the authenticated merchant's settlement
+// batches, newest first.
+func (b *BaseController) ListMySettlementBatches(c *gin.Context) error {
+	var (
+		request             = ListSettlementBatchesRequest{}
+		settlementBatchRepo = models.InitSettlementBatchRepo(b.requestDB(c))
+		beforeID            uint64
+	)
+
+	err := b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultSettlementBatchesLimit
+	}
+
+	m, err := b.myMerchant(c)
+	if err != nil {
+		return err
+	}
+
+	if request.Before != "" {
+		before, err := settlementBatchRepo.Get(&models.SettlementBatch{UUID: request.Before, MerchantID: m.ID})
+		if err != nil {
+			logger.Error("error in getting settlement batch | err: ", err)
+			if err == gorm.ErrRecordNotFound {
+				return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgSettlementBatchNotFound)
+			}
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingSettlementBatch)
+		}
+		beforeID = before.ID
+	}
+
+	batches, err := settlementBatchRepo.List(m.ID, beforeID, request.Limit)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInListingSettlementBatches)
+	}
+
+	response := ListSettlementBatchesResponse{SettlementBatches: make([]api.SettlementBatch, 0, len(batches))}
+	for i := range batches {
+		response.SettlementBatches = append(response.SettlementBatches, toAPISettlementBatch(&batches[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// GetMySettlementReport returns a settlement batch of the authenticated
+// merchant with what each of its checkout sessions contributed.
+func (b *BaseController) GetMySettlementReport(c *gin.Context) error {
+	settlementBatchRepo := models.InitSettlementBatchRepo(b.requestDB(c))
+
+	batch, err := b.mySettlementBatch(c, settlementBatchRepo)
+	if err != nil {
+		return err
+	}
+
+	lines, err := settlementBatchRepo.Lines(batch)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingSettlementReport)
+	}
+
+	response := SettlementReport{
+		SettlementBatch: toAPISettlementBatch(batch),
+		Lines:           make([]api.SettlementLine, 0, len(lines)),
+	}
+	for i := range lines {
+		response.Lines = append(response.Lines, toAPISettlementLine(&lines[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// DownloadMySettlementReport returns the lines of a settlement batch of the
+// authenticated merchant as CSV, amounts in the batch's currency, with a
+// last row of totals.
+func (b *BaseController) DownloadMySettlementReport(c *gin.Context) error {
+	settlementBatchRepo := models.InitSettlementBatchRepo(b.requestDB(c))
+
+	batch, err := b.mySettlementBatch(c, settlementBatchRepo)
+	if err != nil {
+		return err
+	}
+
+	lines, err := settlementBatchRepo.Lines(batch)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingSettlementReport)
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="settlement_`+batch.UUID+`.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	rows := [][]string{{"checkout_session_uuid", "order_id", "paid_at", "reference_id", "amount", "refunds", "fees", "net"}}
+	for i := range lines {
+		line := toAPISettlementLine(&lines[i])
+		rows = append(rows, []string{
+			line.CheckoutSessionUUID,
+			line.OrderID,
+			line.PaidAt.UTC().Format(time.RFC3339),
+			line.ReferenceID,
+			utils.DecimalCents(line.AmountInCents),
+			utils.DecimalCents(line.RefundsInCents),
+			utils.DecimalCents(line.FeesInCents),
+			utils.DecimalCents(line.NetInCents),
+		})
+	}
+	rows = append(rows, []string{
+		"total",
+		strconv.Itoa(batch.SessionCount) + " sessions",
+		batch.CutoffAt.UTC().Format(time.RFC3339),
+		batch.ReferenceID,
+		utils.DecimalCents(batch.GrossInCents),
+		utils.DecimalCents(batch.RefundsInCents),
+		utils.DecimalCents(batch.FeesInCents),
+		utils.DecimalCents(batch.NetInCents),
+	})
+
+	err = w.WriteAll(rows)
+	if err != nil {
+		// the response has started, so the error can only be logged
+		logger.Error("settlement report export closed | err: ", err)
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultPaymentRequestsLimit = 20
+
+func toAPIPaymentRequest(p *models.PaymentRequest) api.PaymentRequest {
+	response := api.PaymentRequest{
+		UUID:                p.UUID,
+		SplitBillUUID:       p.SplitBillUUID,
+		RequesterWalletUUID: p.RequesterWalletUUID,
+		PayerWalletUUID:     p.PayerWalletUUID,
+		AmountInCents:       p.AmountInCents,
+		Note:                p.Note,
+		Status:              string(p.CurrentStatus()),
+		ExpiresAt:           p.ExpiresAt,
+		RespondedAt:         p.RespondedAt,
+		DeclineReason:       p.DeclineReason,
+		ReferenceID:         p.ReferenceID,
+	}
+	if p.CreatedAt != nil {
+		response.CreatedAt = *p.CreatedAt
+	}
+	return response
+}
+
+func toAPISplitBill(split *models.SplitBill, requests []models.PaymentRequest) api.SplitBill {
+	response := api.SplitBill{
+		UUID:                split.UUID,
+		RequesterWalletUUID: split.RequesterWalletUUID,
+		TotalInCents:        split.TotalInCents,
+		Note:                split.Note,
+		ExpiresAt:           split.ExpiresAt,
+		PaymentRequests:     make([]api.PaymentRequest, 0, len(requests)),
+	}
+	if split.CreatedAt != nil {
+		response.CreatedAt = *split.CreatedAt
+	}
+
+	for i := range requests {
+		request := toAPIPaymentRequest(&requests[i])
+		response.RequestedInCents += request.AmountInCents
+		switch models.PaymentRequestStatus(request.Status) {
+		case models.PaymentRequestPaid:
+			response.PaidCount++
+			response.PaidInCents += request.AmountInCents
+		case models.PaymentRequestPending:
+			response.PendingCount++
+		}
+		response.PaymentRequests = append(response.PaymentRequests, request)
+	}
+	return response
+}
+
+// paymentRequestExpiry returns when a payment request made now expires,
+// after the configured default unless expiresAt is set.
+func (b *BaseController) paymentRequestExpiry(expiresAt *time.Time) (time.Time, error) {
+	now := time.Now()
+	if expiresAt == nil {
+		return now.Add(b.Config.PaymentRequests.DefaultExpiry), nil
+	}
+
+	if !expiresAt.After(now) || expiresAt.After(now.Add(b.Config.PaymentRequests.MaxExpiry)) {
+		return time.Time{}, errorConst.New(errorConst.ErrorValidation, errorConst.MsgInvalidExpiry).WithFields([]errorConst.Error{{
+			Field:       "expires_at",
+			Description: fmt.Sprintf("must be in the future and at most %s away", b.Config.PaymentRequests.MaxExpiry),
+		}})
+	}
+	return expiresAt.UTC(), nil
+}
+
+// newPaymentRequest checks that the owner of payerWalletUUID can be asked
+// to pay requester and returns the request, ready to store.
+func (b *BaseController) newPaymentRequest(c *gin.Context, requester *models.Wallet, payerWalletUUID string, amountInCents int, note string, expiresAt time.Time) (*models.PaymentRequest, error) {
+	payer, err := models.InitWalletRepo(b.requestDB(c)).Get(&models.Wallet{UUID: payerWalletUUID})
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logger.Error("error in getting payer wallet | err: ", err)
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingPayerWallet)
+	}
+	// shards belong to no account
+	if err == gorm.ErrRecordNotFound || payer.ParentWalletID != nil {
+		return nil, errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgPayerWalletNotFound)
+	}
+
+	if payer.UserUUID == requester.UserUUID {
+		return nil, models.ErrPaymentRequestSelf
+	}
+	if payer.Currency != requester.Currency {
+		return nil, models.ErrCurrencyMismatch
+	}
+
+	return &models.PaymentRequest{
+		RequesterAccountUUID: requester.UserUUID,
+		RequesterWalletID:    requester.ID,
+		RequesterWalletUUID:  requester.UUID,
+		PayerAccountUUID:     payer.UserUUID,
+		PayerWalletID:        payer.ID,
+		PayerWalletUUID:      payer.UUID,
+		AmountInCents:        amountInCents,
+		Note:                 note,
+		ExpiresAt:            expiresAt,
+	}, nil
+}
+
+// CreatePaymentRequest asks the owner of a wallet to pay the authenticated
+// account's wallet.
+func (b *BaseController) CreatePaymentRequest(c *gin.Context) error {
+	var (
+		request            = CreatePaymentRequestRequest{}
+		paymentRequestRepo = models.InitPaymentRequestRepo(b.requestDB(c))
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	expiresAt, err := b.paymentRequestExpiry(request.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	requester, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	payerWalletUUID, err := b.walletUUIDFor(c, request.PayerWalletUUID, request.PayerHandle)
+	if err != nil {
+		return err
+	}
+
+	p, err := b.newPaymentRequest(c, requester, payerWalletUUID, request.AmountInCents, request.Note, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	requests := []models.PaymentRequest{*p}
+	err = paymentRequestRepo.Create(requests, nil)
+	if err != nil {
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingPaymentRequest)
+	}
+
+	c.JSON(http.StatusCreated, toAPIPaymentRequest(&requests[0]))
+	return nil
+}
+
+// ListPaymentRequests lists the payment requests the authenticated account
+// has to pay, or has made, newest first.
+func (b *BaseController) ListPaymentRequests(c *gin.Context) error {
+	var (
+		request            = ListPaymentRequestsRequest{}
+		paymentRequestRepo = models.InitPaymentRequestRepo(b.requestDB(c))
+		accountUUID        = c.GetString(constants.AuthorizedAccountUUIDContextKey)
+		beforeID           uint64
+	)
+
+	err := b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultPaymentRequestsLimit
+	}
+
+	if request.Before != "" {
+		before, err := paymentRequestRepo.Get(&models.PaymentRequest{UUID: request.Before})
+		if err != nil {
+			logger.Error("error in getting payment request | err: ", err)
+			if err == gorm.ErrRecordNotFound {
+				return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgPaymentRequestNotFound)
+			}
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingPaymentRequest)
+		}
+		beforeID = before.ID
+	}
+
+	requests, err := paymentRequestRepo.List(accountUUID, request.Direction == "outgoing", models.PaymentRequestStatus(request.Status), beforeID, request.Limit)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInListingPaymentRequests)
+	}
+
+	response := ListPaymentRequestsResponse{PaymentRequests: make([]api.PaymentRequest, 0, len(requests))}
+	for i := range requests {
+		response.PaymentRequests = append(response.PaymentRequests, toAPIPaymentRequest(&requests[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// paymentRequest returns the payment request of the path, to its requester,
+// its payer and internal roles only.
+func (b *BaseController) paymentRequest(c *gin.Context, paymentRequestRepo models.IPaymentRequest) (*models.PaymentRequest, error) {
+	p, err := paymentRequestRepo.Get(&models.PaymentRequest{UUID: c.Param("payment_request_uuid")})
+	if err != nil {
+		logger.Error("error in getting payment request | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgPaymentRequestNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingPaymentRequest)
+	}
+
+	accountUUID := c.GetString(constants.AuthorizedAccountUUIDContextKey)
+	if p.RequesterAccountUUID != accountUUID && p.PayerAccountUUID != accountUUID && !isInternalRole(c) {
+		return nil, errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgPaymentRequestNotFound)
+	}
+	return p, nil
+}
+
+// respondError passes domain errors of responding to a payment request
+// through.
+func respondError(err error) error {
+	logger.Error("error in responding to payment request | err: ", err)
+	var domainErr *errorConst.DomainError
+	if errors.As(err, &domainErr) {
+		return err
+	}
+	return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInRespondingToPaymentRequest)
+}
+
+func (b *BaseController) GetPaymentRequest(c *gin.Context) error {
+	p, err := b.paymentRequest(c, models.InitPaymentRequestRepo(b.requestDB(c)))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, toAPIPaymentRequest(p))
+	return nil
+}
+
+// ApprovePaymentRequest pays a pending payment request from the
+// authenticated account's wallet, which must be its payer.
+func (b *BaseController) ApprovePaymentRequest(c *gin.Context) error {
+	paymentRequestRepo := models.InitPaymentRequestRepo(b.requestDB(c))
+
+	p, err := b.paymentRequest(c, paymentRequestRepo)
+	if err != nil {
+		return err
+	}
+	if p.PayerAccountUUID != c.GetString(constants.AuthorizedAccountUUIDContextKey) {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgPaymentRequestPayerOnly)
+	}
+
+	p, posting, err := paymentRequestRepo.Approve(p.ID)
+	if err != nil {
+		return respondError(err)
+	}
+
+	c.JSON(http.StatusOK, ApprovePaymentRequestResponse{
+		PaymentRequest: toAPIPaymentRequest(p),
+		Transactions:   toAPIPostingTransactions(posting),
+	})
+	return nil
+}
+
+// DeclinePaymentRequest declines a pending payment request of which the
+// authenticated account is the payer.
+func (b *BaseController) DeclinePaymentRequest(c *gin.Context) error {
+	var (
+		request            = DeclinePaymentRequestRequest{}
+		paymentRequestRepo = models.InitPaymentRequestRepo(b.requestDB(c))
+	)
+
+	// the body, with a reason, is optional
+	if c.Request.ContentLength != 0 {
+		err := b.bindAndValidate(c, &request)
+		if err != nil {
+			return err
+		}
+	}
+
+	p, err := b.paymentRequest(c, paymentRequestRepo)
+	if err != nil {
+		return err
+	}
+	if p.PayerAccountUUID != c.GetString(constants.AuthorizedAccountUUIDContextKey) {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgPaymentRequestPayerOnly)
+	}
+
+	p, err = paymentRequestRepo.Decline(p.ID, request.Reason)
+	if err != nil {
+		return respondError(err)
+	}
+
+	c.JSON(http.StatusOK, toAPIPaymentRequest(p))
+	return nil
+}
+
+// CancelPaymentRequest withdraws a pending payment request the
+// authenticated account made.
+func (b *BaseController) CancelPaymentRequest(c *gin.Context) error {
+	paymentRequestRepo := models.InitPaymentRequestRepo(b.requestDB(c))
+
+	p, err := b.paymentRequest(c, paymentRequestRepo)
+	if err != nil {
+		return err
+	}
+	if p.RequesterAccountUUID != c.GetString(constants.AuthorizedAccountUUIDContextKey) {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgPaymentRequestRequesterOnly)
+	}
+
+	p, err = paymentRequestRepo.Cancel(p.ID)
+	if err != nil {
+		return respondError(err)
+	}
+
+	c.JSON(http.StatusOK, toAPIPaymentRequest(p))
+	return nil
+}
+
+// splitShares returns what each payer of request owes: their own amounts,
+// at most the total between them, or equal shares with the left over cents
+// on the first payers.
+func splitShares(request *CreateSplitBillRequest) ([]int, error) {
+	var (
+		shares = make([]int, len(request.Payers))
+		given  int
+		sum    int
+	)
+	for i, payer := range request.Payers {
+		shares[i] = payer.AmountInCents
+		sum += payer.AmountInCents
+		if payer.AmountInCents > 0 {
+			given++
+		}
+	}
+
+	switch given {
+	case len(shares):
+		if sum > request.TotalInCents {
+			return nil, errorConst.New(errorConst.ErrorValidation, errorConst.MsgSplitSharesExceedTheTotal)
+		}
+		return shares, nil
+	case 0:
+	default:
+		return nil, errorConst.New(errorConst.ErrorValidation, errorConst.MsgSplitAmountsPartial)
+	}
+
+	parts := len(shares)
+	if !request.ExcludeRequester {
+		parts++
+	}
+	share := request.TotalInCents / parts
+	if share == 0 {
+		return nil, errorConst.New(errorConst.ErrorValidation, errorConst.MsgTotalIsTooSmallToSplit)
+	}
+
+	left := request.TotalInCents - share*parts
+	for i := range shares {
+		shares[i] = share
+		if i < left {
+			shares[i]++
+		}
+	}
+	return shares, nil
+}
+
+// CreateSplitBill sends every payer of a bill a payment request for their
+// share, to the authenticated account's wallet.
+func (b *BaseController) CreateSplitBill(c *gin.Context) error {
+	var (
+		request            = CreateSplitBillRequest{}
+		paymentRequestRepo = models.InitPaymentRequestRepo(b.requestDB(c))
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	shares, err := splitShares(&request)
+	if err != nil {
+		return err
+	}
+
+	expiresAt, err := b.paymentRequestExpiry(request.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	requester, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	var (
+		requests = make([]models.PaymentRequest, 0, len(request.Payers))
+		seen     = map[string]bool{}
+	)
+	for i, payer := range request.Payers {
+		field := fmt.Sprintf("payers[%d]", i)
+		payerWalletUUID, err := b.walletUUIDFor(c, payer.WalletUUID, payer.Handle)
+		if err != nil {
+			return errorConst.Wrap(err, errorConst.ErrorValidation, errorConst.MsgInvalidPayerInSplit).WithFields([]errorConst.Error{{
+				Field:       field,
+				Description: err.Error(),
+			}})
+		}
+		if seen[payerWalletUUID] {
+			return errorConst.New(errorConst.ErrorValidation, errorConst.MsgPayerAppearsTwiceInTheSplit).WithFields([]errorConst.Error{{
+				Field:       field,
+				Description: payerWalletUUID,
+			}})
+		}
+		seen[payerWalletUUID] = true
+
+		p, err := b.newPaymentRequest(c, requester, payerWalletUUID, shares[i], request.Note, expiresAt)
+		if err != nil {
+			return errorConst.Wrap(err, errorConst.ErrorValidation, errorConst.MsgInvalidPayerInSplit).WithFields([]errorConst.Error{{
+				Field:       field,
+				Description: err.Error(),
+			}})
+		}
+		requests = append(requests, *p)
+	}
+
+	split := &models.SplitBill{
+		RequesterAccountUUID: requester.UserUUID,
+		RequesterWalletUUID:  requester.UUID,
+		TotalInCents:         request.TotalInCents,
+		Note:                 request.Note,
+		ExpiresAt:            expiresAt,
+	}
+	err = paymentRequestRepo.Create(requests, split)
+	if err != nil {
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingSplitBill)
+	}
+
+	c.JSON(http.StatusCreated, toAPISplitBill(split, requests))
+	return nil
+}
+
+// GetSplitBill returns a split bill with who has paid, to its requester,
+// its payers and internal roles only.
+func (b *BaseController) GetSplitBill(c *gin.Context) error {
+	var (
+		paymentRequestRepo = models.InitPaymentRequestRepo(b.requestDB(c))
+		accountUUID        = c.GetString(constants.AuthorizedAccountUUIDContextKey)
+	)
+
+	split, requests, err := paymentRequestRepo.GetSplit(&models.SplitBill{UUID: c.Param("split_bill_uuid")})
+	if err != nil {
+		logger.Error("error in getting split bill | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgSplitBillNotFound)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingSplitBill)
+	}
+
+	visible := split.RequesterAccountUUID == accountUUID || isInternalRole(c)
+	for i := range requests {
+		visible = visible || requests[i].PayerAccountUUID == accountUUID
+	}
+	if !visible {
+		return errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgSplitBillNotFound)
+	}
+
+	c.JSON(http.StatusOK, toAPISplitBill(split, requests))
+	return nil
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type (
+	CreatePaymentRequestRequest   = api.CreatePaymentRequestRequest
+	ListPaymentRequestsRequest    = api.ListPaymentRequestsRequest
+	ListPaymentRequestsResponse   = api.ListPaymentRequestsResponse
+	DeclinePaymentRequestRequest  = api.DeclinePaymentRequestRequest
+	ApprovePaymentRequestResponse = api.ApprovePaymentRequestResponse
+	PaymentRequest                = api.PaymentRequest
+	CreateSplitBillRequest        = api.CreateSplitBillRequest
+	SplitBill                     = api.SplitBill
+)
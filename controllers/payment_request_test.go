@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"coinpe/pkg/api"
+	errorConst "coinpe/pkg/error"
+	"fmt"
+	"testing"
+)
+
+func TestSplitShares(t *testing.T) {
+	payers := func(amounts ...int) []api.SplitPayer {
+		p := make([]api.SplitPayer, len(amounts))
+		for i, amount := range amounts {
+			p[i] = api.SplitPayer{WalletUUID: fmt.Sprintf("wa_payer%d", i), AmountInCents: amount}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		request CreateSplitBillRequest
+		want    []int
+		msg     errorConst.MessageID
+	}{
+		{
+			name:    "equal shares with the requester",
+			request: CreateSplitBillRequest{TotalInCents: 1000, Payers: payers(0, 0, 0)},
+			want:    []int{250, 250, 250},
+		},
+		{
+			name:    "left over cents on the first payers",
+			request: CreateSplitBillRequest{TotalInCents: 1000, Payers: payers(0, 0, 0), ExcludeRequester: true},
+			want:    []int{334, 333, 333},
+		},
+		{
+			name:    "own amounts",
+			request: CreateSplitBillRequest{TotalInCents: 1000, Payers: payers(600, 400)},
+			want:    []int{600, 400},
+		},
+		{
+			name:    "own amounts past the total",
+			request: CreateSplitBillRequest{TotalInCents: 1000, Payers: payers(600, 401)},
+			msg:     errorConst.MsgSplitSharesExceedTheTotal,
+		},
+		{
+			name:    "some amounts given",
+			request: CreateSplitBillRequest{TotalInCents: 1000, Payers: payers(600, 0)},
+			msg:     errorConst.MsgSplitAmountsPartial,
+		},
+		{
+			name:    "too small to split",
+			request: CreateSplitBillRequest{TotalInCents: 2, Payers: payers(0, 0)},
+			msg:     errorConst.MsgTotalIsTooSmallToSplit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitShares(&tt.request)
+			if tt.msg != "" {
+				if domainErr := errorConst.FromError(err); err == nil || domainErr.MessageID != tt.msg {
+					t.Fatalf("got %v and %v, want %s", got, err, tt.msg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("shares %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
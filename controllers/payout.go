@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxPayoutFileBytes = 20 << 20
+
+	defaultPayoutItemsLimit = 100
+)
+
+func toAPIPayout(p *models.Payout) api.Payout {
+	response := api.Payout{
+		UUID:             p.UUID,
+		CreatedBy:        p.CreatedBy,
+		Filename:         p.Filename,
+		PurposeCode:      string(p.PurposeCode),
+		Description:      p.Description,
+		Status:           string(p.Status),
+		ItemCount:        p.ItemCount,
+		TotalInCents:     p.TotalInCents,
+		SucceededCount:   p.SucceededCount,
+		SucceededInCents: p.SucceededInCents,
+		FailedCount:      p.FailedCount,
+		CompletedAt:      p.CompletedAt,
+	}
+	if p.CreatedAt != nil {
+		response.CreatedAt = *p.CreatedAt
+	}
+	return response
+}
+
+func toAPIPayoutItem(item *models.PayoutItem) api.PayoutItem {
+	return api.PayoutItem{
+		UUID:          item.UUID,
+		RowNumber:     item.RowNumber,
+		WalletUUID:    item.WalletUUID,
+		AmountInCents: item.AmountInCents,
+		Description:   item.Description,
+		Status:        string(item.Status),
+		ReferenceID:   item.ReferenceID,
+		Error:         item.Error,
+		ProcessedAt:   item.ProcessedAt,
+	}
+}
+
+// requirePayoutRole restricts payouts, which credit wallets from the
+// treasury, to internal roles.
+func requirePayoutRole(c *gin.Context) error {
+	if !isInternalRole(c) {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgPayoutsInternalOnly)
+	}
+	return nil
+}
+
+// newPayout checks the purpose code of a payout, REWARD unless set, and
+// never a plain transfer.
+func newPayout(c *gin.Context, purposeCode string, description string) (*models.Payout, error) {
+	payout := &models.Payout{
+		CreatedBy:   c.GetString(constants.AuthorizedAccountUUIDContextKey),
+		PurposeCode: purposecodes.PurposeCodeReward,
+		Description: description,
+	}
+	if purposeCode != "" {
+		payout.PurposeCode = purposecodes.TransactionPurposeCode(purposeCode)
+		if !purposecodes.IsValid(payout.PurposeCode) {
+			return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidPurposeCode)
+		}
+	}
+	if slices.Contains([]purposecodes.TransactionPurposeCode{purposecodes.PurposeCodeTransfer, purposecodes.PurposeCodeRebalance, purposecodes.PurposeCodePayment, purposecodes.PurposeCodeFee, purposecodes.PurposeCodeSettlement}, payout.PurposeCode) {
+		return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgPurposeCodeNotAllowedForPayouts)
+	}
+	return payout, nil
+}
+
+// createPayout stores payout and its items and answers 202, the queue
+// workers post it.
+func (b *BaseController) createPayout(c *gin.Context, payout *models.Payout, items []models.PayoutItem) error {
+	err := models.InitPayoutRepo(b.requestDB(c)).Create(payout, items)
+	if err != nil {
+		logger.Error("error in creating payout | err: ", err)
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgTreasuryWalletNotFound)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingPayout)
+	}
+
+	c.JSON(http.StatusAccepted, toAPIPayout(payout))
+	return nil
+}
+
+// CreatePayout validates every item of the payout in the request body and
+// queues it, all of it or nothing.
+func (b *BaseController) CreatePayout(c *gin.Context) error {
+	request := CreatePayoutRequest{}
+
+	err := requirePayoutRole(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	payout, err := newPayout(c, request.PurposeCode, request.Description)
+	if err != nil {
+		return err
+	}
+
+	items := make([]models.PayoutItem, 0, len(request.Items))
+	for i, item := range request.Items {
+		items = append(items, models.PayoutItem{
+			// numbered from 1 like the items of the request
+			RowNumber:     i + 1,
+			WalletUUID:    item.WalletUUID,
+			AmountInCents: item.AmountInCents,
+			Description:   item.Description,
+		})
+	}
+	return b.createPayout(c, payout, items)
+}
+
+// ImportPayoutFile validates every row of the CSV payout file in the
+// request body and queues it, all of it or nothing.
+func (b *BaseController) ImportPayoutFile(c *gin.Context) error {
+	request := ImportPayoutFileRequest{}
+
+	err := requirePayoutRole(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	payout, err := newPayout(c, request.PurposeCode, request.Description)
+	if err != nil {
+		return err
+	}
+	payout.Filename = request.Filename
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxPayoutFileBytes)
+	items, err := models.ParsePayoutCSV(body)
+	if err != nil {
+		logger.Error("error in parsing payout file | err: ", err)
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorBadRequest, errorConst.MsgUnableToReadPayoutFile)
+	}
+	return b.createPayout(c, payout, items)
+}
+
+func (b *BaseController) payout(c *gin.Context, payoutRepo models.IPayout) (*models.Payout, error) {
+	err := requirePayoutRole(c)
+	if err != nil {
+		return nil, err
+	}
+
+	payout, err := payoutRepo.Get(&models.Payout{UUID: c.Param("payout_uuid")})
+	if err != nil {
+		logger.Error("error in getting payout | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgPayoutNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingPayout)
+	}
+	return payout, nil
+}
+
+// GetPayout returns a payout and the progress of its items.
+func (b *BaseController) GetPayout(c *gin.Context) error {
+	payout, err := b.payout(c, models.InitPayoutRepo(b.requestDB(c)))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, toAPIPayout(payout))
+	return nil
+}
+
+// ListPayoutItems pages through the items of a payout in file order.
+func (b *BaseController) ListPayoutItems(c *gin.Context) error {
+	var (
+		request    = ListPayoutItemsRequest{}
+		payoutRepo = models.InitPayoutRepo(b.requestDB(c))
+	)
+
+	payout, err := b.payout(c, payoutRepo)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultPayoutItemsLimit
+	}
+
+	items, err := payoutRepo.ListItems(payout.ID, models.PayoutItemStatus(request.Status), request.AfterRow, request.Limit)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInListingPayoutItems)
+	}
+
+	response := ListPayoutItemsResponse{Items: make([]api.PayoutItem, 0, len(items))}
+	for i := range items {
+		response.Items = append(response.Items, toAPIPayoutItem(&items[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// DownloadPayoutResult streams the outcome of every item of a payout as
+// CSV, in file order. Items of a PROCESSING payout may still be PENDING.
+func (b *BaseController) DownloadPayoutResult(c *gin.Context) error {
+	payoutRepo := models.InitPayoutRepo(b.requestDB(c))
+
+	payout, err := b.payout(c, payoutRepo)
+	if err != nil {
+		return err
+	}
+
+	var (
+		w       = csv.NewWriter(c.Writer)
+		started bool
+		rows    int
+	)
+	err = payoutRepo.StreamItems(payout.ID, func(item *models.PayoutItem) error {
+		if !started {
+			started = true
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			c.Header("Content-Disposition", `attachment; filename="payout_`+payout.UUID+`_result.csv"`)
+			c.Status(http.StatusOK)
+
+			err := w.Write([]string{"row_number", "wallet_uuid", "amount", "description", "status", "reference_id", "error"})
+			if err != nil {
+				return err
+			}
+		}
+
+		err := w.Write([]string{
+			strconv.Itoa(item.RowNumber),
+			item.WalletUUID,
+			utils.DecimalCents(item.AmountInCents),
+			item.Description,
+			string(item.Status),
+			item.ReferenceID,
+			item.Error,
+		})
+		if err != nil {
+			return err
+		}
+
+		rows++
+		if rows%500 == 0 {
+			w.Flush()
+			c.Writer.Flush()
+			return w.Error()
+		}
+		return nil
+	})
+	if err != nil && !started {
+		logger.Error("error in exporting payout result | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInExportingPayoutResult)
+	}
+	if err != nil {
+		// the response has started, so the error can only be logged
+		logger.Error("payout result export closed | err: ", err)
+	}
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,11 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type (
+	CreatePayoutRequest     = api.CreatePayoutRequest
+	ImportPayoutFileRequest = api.ImportPayoutFileRequest
+	ListPayoutItemsRequest  = api.ListPayoutItemsRequest
+	ListPayoutItemsResponse = api.ListPayoutItemsResponse
+	Payout                  = api.Payout
+)
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func toAPIPendingTransaction(p *models.PendingTransaction) api.PendingTransaction {
+	response := api.PendingTransaction{
+		UUID:           p.UUID,
+		BatchUUID:      p.BatchUUID,
+		Status:         string(p.Status),
+		FromWalletUUID: p.FromWalletUUID,
+		ToWalletUUID:   p.ToWalletUUID,
+		AmountInCents:  p.AmountInCents,
+		PurposeCode:    string(p.PurposeCode),
+		Description:    p.Description,
+		Attempts:       p.Attempts,
+		ReferenceID:    p.ReferenceID,
+		Error:          p.Error,
+		ProcessedAt:    p.ProcessedAt,
+		CallbackURL:    p.WebhookURL,
+		WebhookStatus:  string(p.WebhookStatus),
+	}
+	if p.CreatedAt != nil {
+		response.CreatedAt = *p.CreatedAt
+	}
+	return response
+}
+
+// pendingTransfer checks request like CreateTransfer does, except for funds
+// which are checked when the transfer is posted, and returns it ready to
+// queue.
+func (b *BaseController) pendingTransfer(c *gin.Context, from *models.Wallet, request *SubmitTransferRequest, wallets map[string]*models.Wallet) (*models.PendingTransaction, error) {
+	purposeCode := purposecodes.PurposeCodeTransfer
+	if request.PurposeCode != "" {
+		purposeCode = purposecodes.TransactionPurposeCode(request.PurposeCode)
+		if !purposecodes.IsValid(purposeCode) {
+			return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidPurposeCode)
+		}
+	}
+
+	// only internal roles may move coins for anything other than a plain transfer
+	if purposeCode != purposecodes.PurposeCodeTransfer && !isInternalRole(c) {
+		return nil, errorConst.New(errorConst.ErrorForbidden, errorConst.MsgPurposeCodeNotAllowedForRole)
+	}
+
+	toWalletUUID, err := b.walletUUIDFor(c, request.ToWalletUUID, request.ToHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	to, ok := wallets[toWalletUUID]
+	if !ok {
+		to, err = models.InitWalletRepo(b.requestDB(c)).Get(&models.Wallet{UUID: toWalletUUID})
+		if err != nil {
+			logger.Error("error in getting destination wallet | err: ", err)
+			if err == gorm.ErrRecordNotFound {
+				return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgDestinationWalletNotFound)
+			}
+			return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingDestinationWallet)
+		}
+		wallets[toWalletUUID] = to
+	}
+
+	if from.UUID == to.UUID {
+		return nil, models.ErrSameWalletTransfer
+	}
+	if from.Currency != to.Currency {
+		return nil, models.ErrCurrencyMismatch
+	}
+
+	return &models.PendingTransaction{
+		SubmittedBy:    c.GetString(constants.AuthorizedAccountUUIDContextKey),
+		FromWalletID:   from.ID,
+		FromWalletUUID: from.UUID,
+		ToWalletID:     to.ID,
+		ToWalletUUID:   to.UUID,
+		AmountInCents:  request.AmountInCents,
+		PurposeCode:    purposeCode,
+		Description:    request.Description,
+		WebhookURL:     request.CallbackURL,
+	}, nil
+}
+
+// SubmitTransfer queues a transfer from the authenticated account's wallet
+// and returns it QUEUED, a queue worker posts it.
+func (b *BaseController) SubmitTransfer(c *gin.Context) error {
+	var (
+		request                = SubmitTransferRequest{}
+		pendingTransactionRepo = models.InitPendingTransactionRepo(b.requestDB(c))
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	from, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	pending, err := b.pendingTransfer(c, from, &request, map[string]*models.Wallet{})
+	if err != nil {
+		return err
+	}
+
+	queued := []models.PendingTransaction{*pending}
+	err = pendingTransactionRepo.Submit(queued)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInQueueingTransfer)
+	}
+
+	c.JSON(http.StatusAccepted, toAPIPendingTransaction(&queued[0]))
+	return nil
+}
+
+// SubmitTransferBatch queues up to 1000 transfers from the authenticated
+// account's wallet in one batch, all of them or none.
+func (b *BaseController) SubmitTransferBatch(c *gin.Context) error {
+	var (
+		request                = SubmitTransferBatchRequest{}
+		pendingTransactionRepo = models.InitPendingTransactionRepo(b.requestDB(c))
+		wallets                = map[string]*models.Wallet{}
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	from, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	batchUUID, err := utils.GenerateNanoID(20, models.EntityTransferBatch)
+	if err != nil {
+		logger.Error("unable to generate nano id | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInQueueingTransfer)
+	}
+
+	queued := make([]models.PendingTransaction, 0, len(request.Transfers))
+	for i := range request.Transfers {
+		transfer := &request.Transfers[i]
+		if transfer.CallbackURL == "" {
+			transfer.CallbackURL = request.CallbackURL
+		}
+
+		pending, err := b.pendingTransfer(c, from, transfer, wallets)
+		if err != nil {
+			return errorConst.Wrap(err, errorConst.ErrorValidation, errorConst.MsgInvalidTransferInBatch).WithFields([]errorConst.Error{{
+				Field:       fmt.Sprintf("transfers[%d]", i),
+				Description: err.Error(),
+			}})
+		}
+		pending.BatchUUID = batchUUID
+		queued = append(queued, *pending)
+	}
+
+	err = pendingTransactionRepo.Submit(queued)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInQueueingTransfer)
+	}
+
+	response := TransferBatch{
+		BatchUUID:           batchUUID,
+		PendingTransactions: make([]api.PendingTransaction, 0, len(queued)),
+	}
+	for i := range queued {
+		response.PendingTransactions = append(response.PendingTransactions, toAPIPendingTransaction(&queued[i]))
+	}
+	c.JSON(http.StatusAccepted, response)
+	return nil
+}
+
+// GetPendingTransaction returns a queued transfer, with its transactions
+// once it SUCCEEDED. Only its submitter and internal roles can see it.
+func (b *BaseController) GetPendingTransaction(c *gin.Context) error {
+	var (
+		pendingTransactionRepo = models.InitPendingTransactionRepo(b.requestDB(c))
+		transactionRepo        = models.InitTransactionRepo(b.requestDB(c))
+	)
+
+	pending, err := pendingTransactionRepo.Get(&models.PendingTransaction{UUID: c.Param("pending_transaction_uuid")})
+	if err != nil {
+		logger.Error("error in getting pending transaction | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgPendingTransactionNotFound)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingPendingTransaction)
+	}
+
+	if pending.SubmittedBy != c.GetString(constants.AuthorizedAccountUUIDContextKey) && !isInternalRole(c) {
+		return errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgPendingTransactionNotFound)
+	}
+
+	response := toAPIPendingTransaction(pending)
+	if pending.ReferenceID != "" {
+		legs, err := transactionRepo.FindByReference(pending.ReferenceID)
+		if err != nil {
+			logger.Error("error in getting transfer | err: ", err)
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingPendingTransaction)
+		}
+		for i := range legs {
+			response.Transactions = append(response.Transactions, toAPITransaction(&legs[i]))
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// GetTransferBatch returns the queued transfers of a batch. Only its
+// submitter and internal roles can see it.
+func (b *BaseController) GetTransferBatch(c *gin.Context) error {
+	pendingTransactionRepo := models.InitPendingTransactionRepo(b.requestDB(c))
+
+	pending, err := pendingTransactionRepo.ListBatch(c.Param("batch_uuid"))
+	if err != nil {
+		logger.Error("error in getting transfer batch | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingTransferBatch)
+	}
+
+	if len(pending) == 0 ||
+		(pending[0].SubmittedBy != c.GetString(constants.AuthorizedAccountUUIDContextKey) && !isInternalRole(c)) {
+		return errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgTransferBatchNotFound)
+	}
+
+	response := TransferBatch{
+		BatchUUID:           c.Param("batch_uuid"),
+		PendingTransactions: make([]api.PendingTransaction, 0, len(pending)),
+	}
+	for i := range pending {
+		response.PendingTransactions = append(response.PendingTransactions, toAPIPendingTransaction(&pending[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
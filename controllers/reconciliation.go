@@ -0,0 +1,353 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSettlementFileBytes = 20 << 20
+
+	defaultSettlementImportsLimit = 50
+
+	// unmatched transactions listed on a report
+	maxReportUnmatchedTransactions = 1000
+)
+
+func toAPISettlementImport(imp *models.SettlementImport) api.SettlementImport {
+	response := api.SettlementImport{
+		UUID:        imp.UUID,
+		Source:      imp.Source,
+		Filename:    imp.Filename,
+		Checksum:    imp.Checksum,
+		ImportedBy:  imp.ImportedBy,
+		RecordCount: imp.RecordCount,
+		SettledFrom: imp.SettledFrom,
+		SettledTo:   imp.SettledTo,
+	}
+	if imp.CreatedAt != nil {
+		response.CreatedAt = *imp.CreatedAt
+	}
+	return response
+}
+
+func toAPISettlementRecord(record *models.SettlementRecord) api.SettlementRecord {
+	response := api.SettlementRecord{
+		UUID:           record.UUID,
+		RowNumber:      record.RowNumber,
+		Reference:      record.Reference,
+		ExternalID:     record.ExternalID,
+		Direction:      string(record.Direction),
+		AmountInCents:  record.AmountInCents,
+		Currency:       record.Currency,
+		SettledAt:      record.SettledAt,
+		Description:    record.Description,
+		Status:         string(record.Status),
+		MatchRule:      string(record.MatchRule),
+		Detail:         record.Detail,
+		Resolution:     string(record.Resolution),
+		ResolutionNote: record.ResolutionNote,
+		ResolvedBy:     record.ResolvedBy,
+		ResolvedAt:     record.ResolvedAt,
+	}
+	if record.MatchedTransaction != nil {
+		t := toAPITransaction(record.MatchedTransaction)
+		response.MatchedTransaction = &t
+	}
+	return response
+}
+
+func toReconciliationCounts(counts map[models.SettlementRecordStatus]int) api.ReconciliationCounts {
+	return api.ReconciliationCounts{
+		Matched:    counts[models.SettlementRecordMatched],
+		Mismatched: counts[models.SettlementRecordMismatched],
+		Unmatched:  counts[models.SettlementRecordUnmatched],
+		Resolved:   counts[models.SettlementRecordResolved],
+	}
+}
+
+func (b *BaseController) reconciliationWindow() time.Duration {
+	if b.Config.Reconciliation.DateWindow > 0 {
+		return b.Config.Reconciliation.DateWindow
+	}
+	return models.DefaultReconciliationDateWindow
+}
+
+func requireInternalRole(c *gin.Context) error {
+	if !isInternalRole(c) {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgReconciliationInternalOnly)
+	}
+	return nil
+}
+
+func (b *BaseController) settlementImport(c *gin.Context, settlementRepo models.ISettlement) (*models.SettlementImport, error) {
+	imp, err := settlementRepo.GetImport(&models.SettlementImport{UUID: c.Param("import_uuid")})
+	if err != nil {
+		logger.Error("error in getting settlement import | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgSettlementImportNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingSettlementImport)
+	}
+	return imp, nil
+}
+
+// settlementImportResponse reports the import with its records counted by
+// status.
+func (b *BaseController) settlementImportResponse(settlementRepo models.ISettlement, imp *models.SettlementImport) (*SettlementImportResponse, error) {
+	counts, err := settlementRepo.CountRecords(imp.ID)
+	if err != nil {
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingReconciliationReport)
+	}
+	return &SettlementImportResponse{
+		Import: toAPISettlementImport(imp),
+		Counts: toReconciliationCounts(counts),
+	}, nil
+}
+
+// ImportSettlementFile imports the CSV settlement file in the request body
+// and reconciles its records right away.
+func (b *BaseController) ImportSettlementFile(c *gin.Context) error {
+	var (
+		request        = ImportSettlementFileRequest{}
+		settlementRepo = models.InitSettlementRepo(b.requestDB(c))
+	)
+
+	err := requireInternalRole(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	location := time.UTC
+	if request.Timezone != "" {
+		// validated by the timezone tag
+		location, _ = time.LoadLocation(request.Timezone)
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSettlementFileBytes)
+	imp, records, err := models.ParseSettlementCSV(body, location)
+	if err != nil {
+		logger.Error("error in parsing settlement file | err: ", err)
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorBadRequest, errorConst.MsgUnableToReadSettlementFile)
+	}
+
+	imp.Source = request.Source
+	imp.Filename = request.Filename
+	imp.ImportedBy = c.GetString(constants.AuthorizedAccountUUIDContextKey)
+
+	err = settlementRepo.Import(imp, records)
+	if err != nil {
+		logger.Error("error in importing settlement file | err: ", err)
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInImportingSettlementFile)
+	}
+
+	_, err = settlementRepo.Reconcile(imp.ID, b.reconciliationWindow())
+	if err != nil {
+		// the file is stored, the reconcile job or endpoint picks it up again
+		logger.Error("error in reconciling settlement file | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgSettlementFileReconcileFailed)
+	}
+
+	response, err := b.settlementImportResponse(settlementRepo, imp)
+	if err != nil {
+		return err
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// ListSettlementImports lists settlement imports, newest first.
+func (b *BaseController) ListSettlementImports(c *gin.Context) error {
+	var (
+		request        = ListSettlementImportsRequest{}
+		settlementRepo = models.InitSettlementRepo(b.requestDB(c))
+		beforeID       uint64
+	)
+
+	err := requireInternalRole(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultSettlementImportsLimit
+	}
+
+	if request.Before != "" {
+		before, err := settlementRepo.GetImport(&models.SettlementImport{UUID: request.Before})
+		if err != nil {
+			logger.Error("error in getting settlement import | err: ", err)
+			if err == gorm.ErrRecordNotFound {
+				return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgSettlementImportNotFound)
+			}
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingSettlementImport)
+		}
+		beforeID = before.ID
+	}
+
+	imports, err := settlementRepo.ListImports(beforeID, request.Limit)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInListingSettlementImports)
+	}
+
+	response := ListSettlementImportsResponse{Imports: make([]api.SettlementImport, 0, len(imports))}
+	for i := range imports {
+		response.Imports = append(response.Imports, toAPISettlementImport(&imports[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// GetReconciliationReport reports the records of a settlement import and the
+// externally settled transactions within the date window of its settled
+// period that no record matches.
+func (b *BaseController) GetReconciliationReport(c *gin.Context) error {
+	var (
+		request        = ReconciliationReportRequest{}
+		settlementRepo = models.InitSettlementRepo(b.requestDB(c))
+		window         = b.reconciliationWindow()
+	)
+
+	err := requireInternalRole(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	imp, err := b.settlementImport(c, settlementRepo)
+	if err != nil {
+		return err
+	}
+
+	counts, err := settlementRepo.CountRecords(imp.ID)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingReconciliationReport)
+	}
+
+	records, err := settlementRepo.ListRecords(imp.ID, models.SettlementRecordStatus(request.Status))
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingReconciliationReport)
+	}
+
+	transactions, err := settlementRepo.UnmatchedTransactions(imp.SettledFrom.Add(-window), imp.SettledTo.Add(window), maxReportUnmatchedTransactions)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingReconciliationReport)
+	}
+
+	response := ReconciliationReport{
+		Import:                toAPISettlementImport(imp),
+		Counts:                toReconciliationCounts(counts),
+		Records:               make([]api.SettlementRecord, 0, len(records)),
+		UnmatchedTransactions: make([]api.Transaction, 0, len(transactions)),
+	}
+	for i := range records {
+		response.Records = append(response.Records, toAPISettlementRecord(&records[i]))
+	}
+	for i := range transactions {
+		response.UnmatchedTransactions = append(response.UnmatchedTransactions, toAPITransaction(&transactions[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// ReconcileSettlementImport runs the matching rules again over the unmatched
+// and mismatched records of a settlement import.
+func (b *BaseController) ReconcileSettlementImport(c *gin.Context) error {
+	settlementRepo := models.InitSettlementRepo(b.requestDB(c))
+
+	err := requireInternalRole(c)
+	if err != nil {
+		return err
+	}
+
+	imp, err := b.settlementImport(c, settlementRepo)
+	if err != nil {
+		return err
+	}
+
+	_, err = settlementRepo.Reconcile(imp.ID, b.reconciliationWindow())
+	if err != nil {
+		logger.Error("error in reconciling settlement file | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInReconcilingSettlementFile)
+	}
+
+	response, err := b.settlementImportResponse(settlementRepo, imp)
+	if err != nil {
+		return err
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// ResolveSettlementRecord records an operator's resolution of a settlement
+// record.
+func (b *BaseController) ResolveSettlementRecord(c *gin.Context) error {
+	var (
+		request        = ResolveSettlementRecordRequest{}
+		settlementRepo = models.InitSettlementRepo(b.requestDB(c))
+	)
+
+	err := requireInternalRole(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	record, err := settlementRepo.Resolve(
+		c.Param("record_uuid"),
+		models.SettlementResolution(request.Resolution),
+		request.TransactionUUID,
+		request.Note,
+		c.GetString(constants.AuthorizedAccountUUIDContextKey),
+		b.reconciliationWindow(),
+	)
+	if err != nil {
+		logger.Error("error in resolving settlement record | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgSettlementRecordOrTransactionNotFound)
+		}
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInResolvingSettlementRecord)
+	}
+
+	c.JSON(http.StatusOK, toAPISettlementRecord(record))
+	return nil
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type (
+	ImportSettlementFileRequest    = api.ImportSettlementFileRequest
+	ListSettlementImportsRequest   = api.ListSettlementImportsRequest
+	ReconciliationReportRequest    = api.ReconciliationReportRequest
+	ResolveSettlementRecordRequest = api.ResolveSettlementRecordRequest
+	SettlementImportResponse       = api.SettlementImportResponse
+	ListSettlementImportsResponse  = api.ListSettlementImportsResponse
+	ReconciliationReport           = api.ReconciliationReport
+	SettlementRecordResponse       = api.SettlementRecord
+)
@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/recurrence"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultScheduledTransfersLimit = 20
+
+	// runs returned with a scheduled transfer
+	scheduledTransferRunsLimit = 20
+)
+
+func toAPIScheduledTransfer(s *models.ScheduledTransfer) api.ScheduledTransfer {
+	response := api.ScheduledTransfer{
+		UUID:                 s.UUID,
+		FromWalletUUID:       s.FromWalletUUID,
+		ToWalletUUID:         s.ToWalletUUID,
+		AmountInCents:        s.AmountInCents,
+		Description:          s.Description,
+		StartAt:              s.StartAt,
+		EndAt:                s.EndAt,
+		Recurrence:           s.Recurrence,
+		Timezone:             s.Timezone,
+		MaxRetries:           s.MaxRetries,
+		RetryIntervalSeconds: s.RetryIntervalSeconds,
+		Status:               string(s.Status),
+		NextOccurrenceAt:     s.NextOccurrenceAt,
+		NextRunAt:            s.NextRunAt,
+		SucceededCount:       s.SucceededCount,
+		FailedCount:          s.FailedCount,
+		LastRunAt:            s.LastRunAt,
+	}
+	if s.CreatedAt != nil {
+		response.CreatedAt = *s.CreatedAt
+	}
+	return response
+}
+
+func toAPIScheduledTransferRun(run *models.ScheduledTransferRun) api.ScheduledTransferRun {
+	return api.ScheduledTransferRun{
+		UUID:         run.UUID,
+		OccurrenceAt: run.OccurrenceAt,
+		Status:       string(run.Status),
+		Attempts:     run.Attempts,
+		ReferenceID:  run.ReferenceID,
+		Error:        run.Error,
+	}
+}
+
+// CreateScheduledTransfer schedules transfers from the authenticated
+// account's wallet, posted by the run-scheduled-transfers job.
+func (b *BaseController) CreateScheduledTransfer(c *gin.Context) error {
+	var (
+		request               = CreateScheduledTransferRequest{}
+		walletRepo            = models.InitWalletRepo(b.requestDB(c))
+		scheduledTransferRepo = models.InitScheduledTransferRepo(b.requestDB(c))
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	from, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	toWalletUUID, err := b.walletUUIDFor(c, request.ToWalletUUID, request.ToHandle)
+	if err != nil {
+		return err
+	}
+
+	to, err := walletRepo.Get(&models.Wallet{UUID: toWalletUUID})
+	if err != nil {
+		logger.Error("error in getting destination wallet | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgDestinationWalletNotFound)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingDestinationWallet)
+	}
+	if from.UUID == to.UUID {
+		return models.ErrSameWalletTransfer
+	}
+	if from.Currency != to.Currency {
+		return models.ErrCurrencyMismatch
+	}
+
+	s := &models.ScheduledTransfer{
+		AccountUUID:          c.GetString(constants.AuthorizedAccountUUIDContextKey),
+		FromWalletID:         from.ID,
+		FromWalletUUID:       from.UUID,
+		ToWalletID:           to.ID,
+		ToWalletUUID:         to.UUID,
+		AmountInCents:        request.AmountInCents,
+		Description:          request.Description,
+		Recurrence:           request.Recurrence,
+		Timezone:             request.Timezone,
+		StartAt:              request.StartAt.UTC(),
+		EndAt:                request.EndAt,
+		MaxRetries:           b.Config.ScheduledTransfers.MaxRetries,
+		RetryIntervalSeconds: int(b.Config.ScheduledTransfers.RetryInterval / time.Second),
+	}
+	if s.Timezone == "" {
+		s.Timezone = time.UTC.String()
+	}
+	if request.MaxRetries != nil {
+		s.MaxRetries = *request.MaxRetries
+	}
+	if request.RetryIntervalSeconds > 0 {
+		s.RetryIntervalSeconds = request.RetryIntervalSeconds
+	}
+
+	err = scheduledTransferRepo.Create(s)
+	if err != nil {
+		logger.Error("error in creating scheduled transfer | err: ", err)
+		var domainErr *errorConst.DomainError
+		switch {
+		case errors.As(err, &domainErr):
+			return err
+		case errors.Is(err, recurrence.ErrInvalidRule):
+			return errorConst.Wrap(err, errorConst.ErrorValidation, errorConst.MsgInvalidRecurrence).WithFields([]errorConst.Error{{
+				Field:       "recurrence",
+				Description: err.Error(),
+			}})
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingScheduledTransfer)
+	}
+
+	c.JSON(http.StatusCreated, toAPIScheduledTransfer(s))
+	return nil
+}
+
+// ListScheduledTransfers lists the authenticated account's scheduled
+// transfers, newest first.
+func (b *BaseController) ListScheduledTransfers(c *gin.Context) error {
+	var (
+		request               = ListScheduledTransfersRequest{}
+		scheduledTransferRepo = models.InitScheduledTransferRepo(b.requestDB(c))
+		accountUUID           = c.GetString(constants.AuthorizedAccountUUIDContextKey)
+		beforeID              uint64
+	)
+
+	err := b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultScheduledTransfersLimit
+	}
+
+	if request.Before != "" {
+		before, err := scheduledTransferRepo.Get(&models.ScheduledTransfer{UUID: request.Before, AccountUUID: accountUUID})
+		if err != nil {
+			logger.Error("error in getting scheduled transfer | err: ", err)
+			if err == gorm.ErrRecordNotFound {
+				return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgScheduledTransferNotFound)
+			}
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingScheduledTransfer)
+		}
+		beforeID = before.ID
+	}
+
+	transfers, err := scheduledTransferRepo.List(accountUUID, models.ScheduledTransferStatus(request.Status), beforeID, request.Limit)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInListingScheduledTransfers)
+	}
+
+	response := ListScheduledTransfersResponse{ScheduledTransfers: make([]api.ScheduledTransfer, 0, len(transfers))}
+	for i := range transfers {
+		response.ScheduledTransfers = append(response.ScheduledTransfers, toAPIScheduledTransfer(&transfers[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// scheduledTransfer returns the scheduled transfer of the path, to its
+// account and internal roles only.
+func (b *BaseController) scheduledTransfer(c *gin.Context, scheduledTransferRepo models.IScheduledTransfer) (*models.ScheduledTransfer, error) {
+	s, err := scheduledTransferRepo.Get(&models.ScheduledTransfer{UUID: c.Param("scheduled_transfer_uuid")})
+	if err != nil {
+		logger.Error("error in getting scheduled transfer | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgScheduledTransferNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingScheduledTransfer)
+	}
+
+	if s.AccountUUID != c.GetString(constants.AuthorizedAccountUUIDContextKey) && !isInternalRole(c) {
+		return nil, errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgScheduledTransferNotFound)
+	}
+	return s, nil
+}
+
+// GetScheduledTransfer returns a scheduled transfer with its latest runs.
+func (b *BaseController) GetScheduledTransfer(c *gin.Context) error {
+	scheduledTransferRepo := models.InitScheduledTransferRepo(b.requestDB(c))
+
+	s, err := b.scheduledTransfer(c, scheduledTransferRepo)
+	if err != nil {
+		return err
+	}
+
+	runs, err := scheduledTransferRepo.ListRuns(s.ID, scheduledTransferRunsLimit)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingScheduledTransfer)
+	}
+
+	response := toAPIScheduledTransfer(s)
+	for i := range runs {
+		response.Runs = append(response.Runs, toAPIScheduledTransferRun(&runs[i]))
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
+
+// changeScheduledTransfer applies change, Pause, Resume or Cancel of the
+// repo, to the scheduled transfer of the path.
+func (b *BaseController) changeScheduledTransfer(c *gin.Context, change func(repo models.IScheduledTransfer, id uint64) (*models.ScheduledTransfer, error)) error {
+	scheduledTransferRepo := models.InitScheduledTransferRepo(b.requestDB(c))
+
+	s, err := b.scheduledTransfer(c, scheduledTransferRepo)
+	if err != nil {
+		return err
+	}
+
+	s, err = change(scheduledTransferRepo, s.ID)
+	if err != nil {
+		logger.Error("error in updating scheduled transfer | err: ", err)
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInUpdatingScheduledTransfer)
+	}
+
+	c.JSON(http.StatusOK, toAPIScheduledTransfer(s))
+	return nil
+}
+
+// PauseScheduledTransfer stops an ACTIVE scheduled transfer until it is
+// resumed.
+func (b *BaseController) PauseScheduledTransfer(c *gin.Context) error {
+	return b.changeScheduledTransfer(c, models.IScheduledTransfer.Pause)
+}
+
+// ResumeScheduledTransfer resumes a PAUSED scheduled transfer from its next
+// occurrence after now.
+func (b *BaseController) ResumeScheduledTransfer(c *gin.Context) error {
+	return b.changeScheduledTransfer(c, models.IScheduledTransfer.Resume)
+}
+
+// CancelScheduledTransfer ends a scheduled transfer for good.
+func (b *BaseController) CancelScheduledTransfer(c *gin.Context) error {
+	return b.changeScheduledTransfer(c, models.IScheduledTransfer.Cancel)
+}
@@ -0,0 +1,10 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type (
+	CreateScheduledTransferRequest = api.CreateScheduledTransferRequest
+	ListScheduledTransfersRequest  = api.ListScheduledTransfersRequest
+	ListScheduledTransfersResponse = api.ListScheduledTransfersResponse
+	ScheduledTransfer              = api.ScheduledTransfer
+)
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyBalanceAt returns the balance of the authenticated account's wallet
+// after every transaction created at or before the at query parameter.
+func (b *BaseController) GetMyBalanceAt(c *gin.Context) error {
+	var (
+		request    = BalanceAtRequest{}
+		ledgerRepo = models.InitLedgerRepo(b.requestDB(c))
+	)
+
+	err := b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	balance, err := ledgerRepo.BalanceAt(wallet.ID, request.At)
+	if err != nil {
+		logger.Error("error in getting balance | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingBalance)
+	}
+
+	c.JSON(http.StatusOK, BalanceAtResponse{
+		WalletUUID:     wallet.UUID,
+		Currency:       wallet.Currency,
+		At:             request.At,
+		BalanceInCents: balance,
+	})
+	return nil
+}
+
+// GetMyStatement returns the statement of the authenticated account's wallet
+// for the transactions created in [from, to).
+func (b *BaseController) GetMyStatement(c *gin.Context) error {
+	var (
+		request    = StatementRequest{}
+		ledgerRepo = models.InitLedgerRepo(b.requestDB(c))
+	)
+
+	err := b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	statement, err := ledgerRepo.Statement(wallet, request.From, request.To)
+	if err != nil {
+		logger.Error("error in getting statement | err: ", err)
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingStatement)
+	}
+
+	response := StatementResponse{
+		WalletUUID:            wallet.UUID,
+		Currency:              wallet.Currency,
+		From:                  statement.From,
+		To:                    statement.To,
+		OpeningBalanceInCents: statement.OpeningBalanceInCents,
+		ClosingBalanceInCents: statement.ClosingBalanceInCents,
+		TotalCreditsInCents:   statement.TotalCreditsInCents,
+		TotalDebitsInCents:    statement.TotalDebitsInCents,
+		Transactions:          make([]api.Transaction, 0, len(statement.Transactions)),
+		Checksum:              statement.Checksum,
+	}
+	for i := range statement.Transactions {
+		response.Transactions = append(response.Transactions, toAPITransaction(&statement.Transactions[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+	return nil
+}
@@ -0,0 +1,421 @@
+package controllers
+
+import (
+	"bytes"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/utils"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+	"gorm.io/gorm"
+)
+
+const (
+	// a PDF is laid out in memory before it is sent, CSV has no limit
+	maxStatementPDFTransactions = 20000
+
+	statementChecksumHeader = "Statement-Checksum"
+	statementTimeFormat     = "2006-01-02 15:04:05"
+	statementDayFormat      = "2006-01-02"
+)
+
+var errStatementTooLargeForPDF = errors.New("statement too large for a PDF")
+
+// statementExport is what both export formats print above the line items.
+type statementExport struct {
+	account *models.Account
+	wallet  *models.Wallet
+	from    time.Time
+	to      time.Time
+}
+
+func (e *statementExport) filename(extension string) string {
+	return fmt.Sprintf("statement_%s_%s_%s.%s", e.wallet.UUID, e.from.UTC().Format(statementDayFormat), e.to.UTC().Format(statementDayFormat), extension)
+}
+
+func (e *statementExport) holder() string {
+	return strings.TrimSpace(e.account.FirstName + " " + e.account.LastName)
+}
+
+// statementExportFor loads the authenticated account's wallet and account
+// details for an export of request's period.
+func (b *BaseController) statementExportFor(c *gin.Context) (*statementExport, error) {
+	request := StatementRequest{}
+	err := b.bindQueryAndValidate(c, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := models.InitAccountRepo(b.requestDB(c)).Get(&models.Account{UUID: wallet.UserUUID})
+	if err != nil {
+		logger.Error("error in getting account | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgAccountNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingAccount)
+	}
+
+	return &statementExport{account: account, wallet: wallet, from: request.From, to: request.To}, nil
+}
+
+// counterparty is the other wallet of a transfer, empty for funding and
+// adjustments.
+func counterparty(t *models.Transaction) string {
+	if t.Type == constants.TransactionTypeDebit {
+		return t.ToWalletUUID
+	}
+	return t.FromWalletUUID
+}
+
+// ExportMyStatementCSV streams the statement of the authenticated account's
+// wallet as CSV. Rows are written as they are read, so the period is not
+// limited. The checksum is the last row and the Statement-Checksum trailer; a
+// stream cut short has neither.
+func (b *BaseController) ExportMyStatementCSV(c *gin.Context) error {
+	export, err := b.statementExportFor(c)
+	if err != nil {
+		return err
+	}
+
+	var (
+		w        = csv.NewWriter(c.Writer)
+		currency = export.wallet.Currency
+		checksum *models.StatementChecksum
+		balance  int
+		credits  int
+		debits   int
+		rows     int
+	)
+
+	err = models.InitLedgerRepo(b.requestDB(c)).StreamStatement(export.wallet.ID, export.from, export.to,
+		func(opening int) error {
+			balance = opening
+			checksum = models.NewStatementChecksum(export.wallet.UUID, export.from, export.to, opening)
+
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			c.Header("Content-Disposition", `attachment; filename="`+export.filename("csv")+`"`)
+			c.Header("Trailer", statementChecksumHeader)
+			c.Status(http.StatusOK)
+
+			records := [][]string{
+				{"Account holder", export.holder()},
+				{"Account", export.account.UUID},
+				{"Phone number", utils.String(export.account.PhoneNumber)},
+				{"Email", export.account.Email},
+				{"Wallet", export.wallet.UUID},
+				{"Currency", currency},
+				{"From", export.from.UTC().Format(time.RFC3339Nano)},
+				{"To", export.to.UTC().Format(time.RFC3339Nano)},
+				{"Opening balance", utils.DecimalCents(opening)},
+				{},
+				{"Date (UTC)", "Transaction", "Type", "Purpose", "Description", "Reference", "Counterparty", "Debit", "Credit", "Balance"},
+			}
+			return w.WriteAll(records)
+		},
+		func(t *models.Transaction) error {
+			checksum.Add(t)
+
+			var debit, credit string
+			if t.Type == constants.TransactionTypeDebit {
+				balance -= t.AmountInCents
+				debits += t.AmountInCents
+				debit = utils.DecimalCents(t.AmountInCents)
+			} else {
+				balance += t.AmountInCents
+				credits += t.AmountInCents
+				credit = utils.DecimalCents(t.AmountInCents)
+			}
+
+			err := w.Write([]string{
+				t.CreatedAt.UTC().Format(statementTimeFormat),
+				t.UUID,
+				string(t.Type),
+				string(t.PurposeCode),
+				t.Description,
+				t.ReferenceID,
+				counterparty(t),
+				debit,
+				credit,
+				utils.DecimalCents(balance),
+			})
+			if err != nil {
+				return err
+			}
+
+			// flush every few hundred rows so large periods stream
+			rows++
+			if rows%500 == 0 {
+				w.Flush()
+				c.Writer.Flush()
+				return w.Error()
+			}
+			return nil
+		},
+	)
+	if err != nil && checksum == nil {
+		// nothing was written, the error can still be returned
+		logger.Error("error in exporting statement | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInExportingStatement)
+	}
+	if err != nil {
+		// the response has started, so the error can only be logged
+		logger.Error("statement export closed | err: ", err)
+		w.Flush()
+		return nil
+	}
+
+	sum := checksum.Sum(balance)
+	_ = w.WriteAll([][]string{
+		{},
+		{"Total debits", utils.DecimalCents(debits)},
+		{"Total credits", utils.DecimalCents(credits)},
+		{"Closing balance", utils.DecimalCents(balance)},
+		{"Checksum", sum},
+	})
+	c.Writer.Header().Set(statementChecksumHeader, sum)
+	return nil
+}
+
+// ExportMyStatementPDF renders the statement of the authenticated account's
+// wallet as a PDF, with the checksum printed under the totals. Transactions
+// are streamed into the document page by page.
+func (b *BaseController) ExportMyStatementPDF(c *gin.Context) error {
+	export, err := b.statementExportFor(c)
+	if err != nil {
+		return err
+	}
+
+	var (
+		pdf      = newStatementPDF(export)
+		checksum *models.StatementChecksum
+		opening  int
+		balance  int
+		credits  int
+		debits   int
+		rows     int
+	)
+
+	err = models.InitLedgerRepo(b.requestDB(c)).StreamStatement(export.wallet.ID, export.from, export.to,
+		func(openingBalance int) error {
+			opening, balance = openingBalance, openingBalance
+			checksum = models.NewStatementChecksum(export.wallet.UUID, export.from, export.to, opening)
+			pdf.summary(opening)
+			pdf.tableHeader()
+			return nil
+		},
+		func(t *models.Transaction) error {
+			rows++
+			if rows > maxStatementPDFTransactions {
+				return errStatementTooLargeForPDF
+			}
+
+			checksum.Add(t)
+			if t.Type == constants.TransactionTypeDebit {
+				balance -= t.AmountInCents
+				debits += t.AmountInCents
+			} else {
+				balance += t.AmountInCents
+				credits += t.AmountInCents
+			}
+			pdf.line(t, balance)
+			return pdf.Error()
+		},
+	)
+	if errors.Is(err, errStatementTooLargeForPDF) {
+		return errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgStatementTooLargeForPDF)
+	}
+	if err != nil {
+		logger.Error("error in exporting statement | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInExportingStatement)
+	}
+
+	sum := checksum.Sum(balance)
+	pdf.totals(debits, credits, balance, sum)
+
+	var body bytes.Buffer
+	err = pdf.Output(&body)
+	if err != nil {
+		logger.Error("error in rendering statement | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInExportingStatement)
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+export.filename("pdf")+`"`)
+	c.Header(statementChecksumHeader, sum)
+	c.Data(http.StatusOK, "application/pdf", body.Bytes())
+	return nil
+}
+
+// statementPDF lays out a statement on A4 pages with the core fonts.
+type statementPDF struct {
+	*fpdf.Fpdf
+	export    *statementExport
+	translate func(string) string
+}
+
+// column widths in mm, they add up to the 190mm between the margins
+var statementPDFColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"Date (UTC)", 30, "L"},
+	{"Description", 52, "L"},
+	{"Reference", 30, "L"},
+	{"Debit", 26, "R"},
+	{"Credit", 26, "R"},
+	{"Balance", 26, "R"},
+}
+
+func newStatementPDF(export *statementExport) *statementPDF {
+	pdf := &statementPDF{
+		Fpdf:   fpdf.New("P", "mm", "A4", ""),
+		export: export,
+	}
+	pdf.translate = pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetTitle("Statement "+export.wallet.UUID, true)
+	pdf.SetAuthor("Coinpe", true)
+	pdf.SetCreationDate(time.Now().UTC())
+	pdf.SetMargins(10, 12, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() > 1 {
+			pdf.tableHeader()
+		}
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(95, 5, pdf.translate(export.wallet.UUID+", "+export.period()), "", 0, "L", false, 0, "")
+		pdf.CellFormat(95, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 9, "Coinpe wallet statement", "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	return pdf
+}
+
+func (e *statementExport) period() string {
+	return e.from.UTC().Format(statementTimeFormat) + " to " + e.to.UTC().Format(statementTimeFormat) + " UTC"
+}
+
+func (pdf *statementPDF) money(cents int) string {
+	return utils.FormatMoney(cents, pdf.export.wallet.Currency)
+}
+
+func (pdf *statementPDF) detail(label string, value string) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(35, 5, label, "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 5, pdf.translate(value), "", 1, "L", false, 0, "")
+}
+
+func (pdf *statementPDF) summary(opening int) {
+	export := pdf.export
+	pdf.detail("Account holder", export.holder())
+	pdf.detail("Account", export.account.UUID)
+	if phone := utils.String(export.account.PhoneNumber); phone != "" {
+		pdf.detail("Phone number", phone)
+	}
+	if export.account.Email != "" {
+		pdf.detail("Email", export.account.Email)
+	}
+	pdf.detail("Wallet", export.wallet.UUID)
+	pdf.detail("Currency", export.wallet.Currency)
+	pdf.detail("Period", export.period())
+	pdf.detail("Opening balance", pdf.money(opening))
+	pdf.Ln(4)
+}
+
+func (pdf *statementPDF) tableHeader() {
+	pdf.SetFont("Helvetica", "B", 8)
+	pdf.SetFillColor(235, 235, 235)
+	for _, column := range statementPDFColumns {
+		pdf.CellFormat(column.width, 6, column.title, "B", 0, column.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 8)
+}
+
+// fit truncates s to width, since cells do not clip their text.
+func (pdf *statementPDF) fit(s string, width float64) string {
+	s = pdf.translate(s)
+	width -= 2 * pdf.GetCellMargin()
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	for len(s) > 0 && pdf.GetStringWidth(s+"...") > width {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
+
+func (pdf *statementPDF) line(t *models.Transaction, balance int) {
+	description := t.Description
+	if description == "" {
+		description = string(t.PurposeCode)
+	}
+	if other := counterparty(t); other != "" {
+		description += " (" + other + ")"
+	}
+
+	var debit, credit string
+	if t.Type == constants.TransactionTypeDebit {
+		debit = pdf.money(t.AmountInCents)
+	} else {
+		credit = pdf.money(t.AmountInCents)
+	}
+
+	values := []string{
+		t.CreatedAt.UTC().Format(statementTimeFormat),
+		description,
+		t.ReferenceID,
+		debit,
+		credit,
+		pdf.money(balance),
+	}
+	for i, column := range statementPDFColumns {
+		pdf.CellFormat(column.width, 5, pdf.fit(values[i], column.width), "", 0, column.align, false, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func (pdf *statementPDF) totals(debits int, credits int, closing int, checksum string) {
+	pdf.Ln(3)
+	pdf.detail("Total debits", pdf.money(debits))
+	pdf.detail("Total credits", pdf.money(credits))
+	pdf.detail("Closing balance", pdf.money(closing))
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 8)
+	pdf.CellFormat(0, 5, "Verification checksum (SHA-256)", "", 1, "L", false, 0, "")
+	pdf.SetFont("Courier", "", 8)
+	pdf.CellFormat(0, 5, checksum, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.MultiCell(0, 4, "The checksum covers the wallet, the period, the opening and closing balances and the hash of every "+
+		"transaction listed. Requesting the statement for the same period returns the same checksum as long as the ledger "+
+		"is unchanged.", "", "L", false)
+	pdf.Ln(1)
+	pdf.CellFormat(0, 4, "Generated "+time.Now().UTC().Format(statementTimeFormat)+" UTC, "+strconv.Itoa(pdf.PageNo())+" pages", "", 1, "L", false, 0, "")
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"bytes"
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/validator"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestExportMyStatement exports the same period as CSV and PDF and checks
+// both carry the checksum of the JSON statement.
+func TestExportMyStatement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var role models.Role
+	err = db.First(&role).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := &models.Account{FirstName: "Asha", LastName: "Rao", Email: "asha@example.com", RoleID: role.ID}
+	err = models.InitAccountRepo(db).Create(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletRepo := models.InitWalletRepo(db)
+	wallet := &models.Wallet{UserUUID: account.UUID, Currency: models.EntityINR}
+	err = walletRepo.Create(wallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = walletRepo.Credit(wallet, &models.Transaction{AmountInCents: 1000}, purposecodes.PurposeCodeAdjustment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = walletRepo.Debit(wallet, &models.Transaction{AmountInCents: 300}, purposecodes.PurposeCodeAdjustment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	statement, err := models.InitLedgerRepo(db).Statement(wallet, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate, uni, err := validator.InitValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &BaseController{DB: db, Validator: validate, Translator: uni}
+	export := func(handler func(*BaseController, *gin.Context) error) *httptest.ResponseRecorder {
+		t.Helper()
+		query := url.Values{"from": {from.Format(time.RFC3339Nano)}, "to": {to.Format(time.RFC3339Nano)}}
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+		c.Set(constants.AuthorizedAccountUUIDContextKey, account.UUID)
+
+		err := handler(b, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+
+	rec := export((*BaseController).ExportMyStatementCSV)
+	r := csv.NewReader(rec.Body)
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{}
+	lines := 0
+	for _, record := range records {
+		if len(record) == 2 {
+			values[record[0]] = record[1]
+		}
+		if len(record) == 10 && record[0] != "Date (UTC)" {
+			lines++
+		}
+	}
+	if values["Account holder"] != "Asha Rao" || values["Opening balance"] != "0.00" || values["Closing balance"] != "7.00" || lines != 2 {
+		t.Errorf("CSV %v with %d lines, want Asha Rao's statement closing at 7.00 with 2", values, lines)
+	}
+	if values["Checksum"] != statement.Checksum {
+		t.Errorf("CSV checksum %s, want %s", values["Checksum"], statement.Checksum)
+	}
+	if trailer := rec.Result().Trailer.Get(statementChecksumHeader); trailer != statement.Checksum {
+		t.Errorf("CSV checksum trailer %q, want %s", trailer, statement.Checksum)
+	}
+
+	rec = export((*BaseController).ExportMyStatementPDF)
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF")) {
+		t.Errorf("PDF export is %q, want a PDF", rec.Body.String()[:min(rec.Body.Len(), 16)])
+	}
+	if header := rec.Header().Get(statementChecksumHeader); header != statement.Checksum {
+		t.Errorf("PDF checksum %q, want %s", header, statement.Checksum)
+	}
+}
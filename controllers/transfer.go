@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func toAPITransaction(t *models.Transaction) api.Transaction {
+	return api.Transaction{
+		UUID:                  t.UUID,
+		CreatedAt:             t.CreatedAt,
+		Type:                  string(t.Type),
+		AmountInCents:         t.AmountInCents,
+		OpeningBalanceInCents: t.OpeningBalanceInCents,
+		ClosingBalanceInCents: t.ClosingBalanceInCents,
+		Status:                string(t.Status),
+		FromWalletUUID:        t.FromWalletUUID,
+		ToWalletUUID:          t.ToWalletUUID,
+		PurposeCode:           string(t.PurposeCode),
+		ReferenceID:           t.ReferenceID,
+		Description:           t.Description,
+		WalletSequence:        t.WalletSequence,
+		PrevHash:              t.PrevHash,
+		Hash:                  t.Hash,
+	}
+}
+
+// toAPIPostingTransactions returns the legs of posting the sender sees,
+// with the fee leg when the sender paid it.
+func toAPIPostingTransactions(p *models.Posting) []api.Transaction {
+	transactions := []api.Transaction{toAPITransaction(p.Debit), toAPITransaction(p.Credit)}
+	if p.Fee != nil && p.FeePayer == models.FeePayerSender {
+		transactions = append(transactions, toAPITransaction(p.Fee))
+	}
+	return transactions
+}
+
+func (b *BaseController) CreateTransfer(c *gin.Context) error {
+	var (
+		request     = CreateTransferRequest{}
+		walletRepo  = models.InitWalletRepo(b.requestDB(c))
+		purposeCode = purposecodes.PurposeCodeTransfer
+	)
+
+	err := b.bindAndValidate(c, &request)
+	if err != nil {
+		return err
+	}
+
+	if request.PurposeCode != "" {
+		purposeCode = purposecodes.TransactionPurposeCode(request.PurposeCode)
+		if !purposecodes.IsValid(purposeCode) {
+			return errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidPurposeCode)
+		}
+	}
+
+	// only internal roles may move coins for anything other than a plain transfer
+	if purposeCode != purposecodes.PurposeCodeTransfer && !isInternalRole(c) {
+		return errorConst.New(errorConst.ErrorForbidden, errorConst.MsgPurposeCodeNotAllowedForRole)
+	}
+
+	toWalletUUID, err := b.walletUUIDFor(c, request.ToWalletUUID, request.ToHandle)
+	if err != nil {
+		return err
+	}
+
+	var (
+		from, to *models.Wallet
+		posting  *models.Posting
+	)
+	// wallets are read again on every attempt, TransferWithFeeTx locks them
+	err = models.TransactWithRetry(b.requestDB(c), func(tx *gorm.DB) error {
+		from, err = walletRepo.GetWithTx(tx, &models.Wallet{
+			UserUUID: c.GetString(constants.AuthorizedAccountUUIDContextKey),
+		})
+		if err != nil {
+			logger.Error("error in getting wallet | err: ", err)
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingWallet)
+		}
+
+		to, err = walletRepo.GetWithTx(tx, &models.Wallet{UUID: toWalletUUID})
+		if err != nil {
+			logger.Error("error in getting destination wallet | err: ", err)
+			if err == gorm.ErrRecordNotFound {
+				return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgDestinationWalletNotFound)
+			}
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingDestinationWallet)
+		}
+
+		posting, err = walletRepo.TransferWithFeeTx(tx, from, to, request.AmountInCents, purposeCode, request.Description)
+		return err
+	})
+	if err != nil {
+		logger.Error("error in creating transfer | err: ", err)
+		var domainErr *errorConst.DomainError
+		if errors.As(err, &domainErr) {
+			return err
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingTransfer)
+	}
+
+	response := TransferResponse{
+		ReferenceID:    posting.Debit.ReferenceID,
+		FromWalletUUID: from.UUID,
+		ToWalletUUID:   to.UUID,
+		AmountInCents:  posting.Debit.AmountInCents,
+		PurposeCode:    string(posting.Debit.PurposeCode),
+		Description:    posting.Debit.Description,
+		Transactions:   toAPIPostingTransactions(posting),
+	}
+	if posting.Fee != nil {
+		response.FeeInCents = posting.Fee.AmountInCents
+		response.FeePayer = string(posting.FeePayer)
+	}
+	c.JSON(http.StatusOK, response)
+	return nil
+}
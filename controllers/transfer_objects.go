@@ -0,0 +1,13 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type (
+	CreateTransferRequest = api.CreateTransferRequest
+	TransferResponse      = api.TransferResponse
+
+	SubmitTransferRequest      = api.SubmitTransferRequest
+	SubmitTransferBatchRequest = api.SubmitTransferBatchRequest
+	PendingTransaction         = api.PendingTransaction
+	TransferBatch              = api.TransferBatch
+)
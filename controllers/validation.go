@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindAndValidate binds the JSON body into request and runs the validator on
+// it. Invalid fields are reported with messages in the language of the
+// Accept-Language header.
+func (b *BaseController) bindAndValidate(c *gin.Context, request interface{}) error {
+	err := c.ShouldBindJSON(request)
+	if err != nil {
+		logger.Error("unable to bind request | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorBindingRequest, errorConst.MsgUnreadableBody)
+	}
+
+	return b.validate(c, request)
+}
+
+// bindQueryAndValidate is bindAndValidate for query parameters.
+func (b *BaseController) bindQueryAndValidate(c *gin.Context, request interface{}) error {
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Error("unable to bind query | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorBindingRequest, errorConst.MsgUnreadableQuery)
+	}
+
+	return b.validate(c, request)
+}
+
+func (b *BaseController) validate(c *gin.Context, request interface{}) error {
+	err := b.Validator.Struct(request)
+	if err != nil {
+		trans := validator.TranslatorFor(b.Translator, c.GetHeader("Accept-Language"))
+		fieldErrors := validator.FieldErrors(err, trans)
+		if fieldErrors == nil {
+			logger.Error("unable to validate request | err: ", err)
+			return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgUnableToValidateRequest)
+		}
+
+		logger.Info("invalid request | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorValidation, errorConst.MsgRequestHasInvalidFields).WithFields(fieldErrors)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/validator"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindAndValidate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validate, uni, err := validator.InitValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &BaseController{Validator: validate, Translator: uni}
+
+	type request struct {
+		AmountInCents int    `json:"amount_in_cents" validate:"required,gt=0"`
+		ToWalletUUID  string `json:"to_wallet_uuid" validate:"required"`
+	}
+	bind := func(body string, acceptLanguage string) error {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Accept-Language", acceptLanguage)
+		return b.bindAndValidate(c, &request{})
+	}
+
+	if err := bind(`{"amount_in_cents":500,"to_wallet_uuid":"wa_to"}`, ""); err != nil {
+		t.Errorf("valid body got %v", err)
+	}
+
+	domainErr := errorConst.FromError(bind(`{"amount_in_cents":`, ""))
+	if domainErr.Code != errorConst.ErrorBindingRequest {
+		t.Errorf("unreadable body got code %d, want %d", domainErr.Code, errorConst.ErrorBindingRequest)
+	}
+
+	domainErr = errorConst.FromError(bind(`{"amount_in_cents":-5}`, "es-ES"))
+	if domainErr.Code != errorConst.ErrorValidation || len(domainErr.Fields) != 2 {
+		t.Fatalf("invalid body got code %d with fields %v, want %d with 2", domainErr.Code, domainErr.Fields, errorConst.ErrorValidation)
+	}
+	fields := map[string]string{}
+	for _, f := range domainErr.Fields {
+		fields[f.Field] = f.Description
+	}
+	if !strings.Contains(fields["amount_in_cents"], "amount_in_cents debe ser mayor") {
+		t.Errorf("amount_in_cents got %q, want the Spanish gt message", fields["amount_in_cents"])
+	}
+	if _, ok := fields["to_wallet_uuid"]; !ok {
+		t.Errorf("fields %v miss to_wallet_uuid", fields)
+	}
+}
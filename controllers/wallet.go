@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func toWalletBalanceResponse(wallet *models.Wallet) WalletBalanceResponse {
+	return WalletBalanceResponse{
+		WalletUUID:              wallet.UUID,
+		Currency:                wallet.Currency,
+		TotalBalanceInCents:     wallet.TotalBalanceInCents,
+		OverdraftLimitInCents:   wallet.OverdraftLimitInCents,
+		AvailableBalanceInCents: wallet.TotalBalanceInCents + int(wallet.OverdraftLimitInCents),
+	}
+}
+
+// myWallet is the wallet of the authenticated account.
+func (b *BaseController) myWallet(c *gin.Context) (*models.Wallet, error) {
+	wallet, err := models.InitWalletRepo(b.requestDB(c)).Get(&models.Wallet{
+		UserUUID: c.GetString(constants.AuthorizedAccountUUIDContextKey),
+	})
+	if err != nil {
+		logger.Error("error in getting wallet | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return nil, errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgWalletNotFound)
+		}
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingWallet)
+	}
+	return wallet, nil
+}
+
+func (b *BaseController) GetMyWallet(c *gin.Context) error {
+	var (
+		walletRepo = models.InitWalletRepo(b.requestDB(c))
+	)
+
+	wallet, err := walletRepo.Get(&models.Wallet{
+		UserUUID: c.GetString(constants.AuthorizedAccountUUIDContextKey),
+	})
+	if err != nil {
+		logger.Error("error in getting wallet | err: ", err)
+		if err == gorm.ErrRecordNotFound {
+			return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgWalletNotFound)
+		}
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingWallet)
+	}
+
+	c.JSON(http.StatusOK, toWalletBalanceResponse(wallet))
+	return nil
+}
+
+// GetMyWalletChain verifies the hash chain of the authenticated account's
+// wallet and returns its head with the latest signed checkpoint.
+func (b *BaseController) GetMyWalletChain(c *gin.Context) error {
+	var (
+		ledgerRepo = models.InitLedgerRepo(b.requestDB(c))
+	)
+
+	wallet, err := b.myWallet(c)
+	if err != nil {
+		return err
+	}
+
+	verification, err := ledgerRepo.VerifyWalletChain(wallet.ID)
+	if err != nil {
+		logger.Error("error in verifying wallet chain | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInVerifyingWalletChain)
+	}
+
+	checkpoint, err := ledgerRepo.LatestCheckpoint()
+	if err != nil {
+		logger.Error("error in getting ledger checkpoint | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingLedgerCheckpoint)
+	}
+
+	response := WalletChainResponse{
+		WalletUUID:       wallet.UUID,
+		Valid:            verification.OK(),
+		TransactionCount: verification.TransactionCount,
+		HeadSequence:     verification.HeadSequence,
+		HeadHash:         verification.HeadHash,
+		Issues:           []api.ChainIssue{},
+	}
+	for _, issue := range verification.Issues {
+		response.Issues = append(response.Issues, api.ChainIssue{
+			Kind:            string(issue.Kind),
+			TransactionUUID: issue.TransactionUUID,
+			Detail:          issue.Detail,
+		})
+	}
+	if checkpoint != nil {
+		response.LatestCheckpoint = &api.LedgerCheckpoint{
+			GlobalSequence: checkpoint.GlobalSequence,
+			GlobalHash:     checkpoint.GlobalHash,
+			KeyID:          checkpoint.KeyID,
+			Signature:      checkpoint.Signature,
+			CreatedAt:      checkpoint.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+	return nil
+}
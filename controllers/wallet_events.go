@@ -0,0 +1,393 @@
+package controllers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	walletEventsHeartbeatInterval = 20 * time.Second
+	walletEventsReplayPageSize    = 100
+	walletEventsWriteTimeout      = 10 * time.Second
+)
+
+// checkStreamOrigin allows WebSockets opened by pages of the server's own
+// origin or of allowedOrigins, and by clients that are not browsers, which
+// send no Origin.
+func checkStreamOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		return slices.ContainsFunc(allowedOrigins, func(allowed string) bool {
+			return strings.EqualFold(strings.TrimSuffix(strings.TrimSpace(allowed), "/"), origin)
+		})
+	}
+}
+
+// CreateStreamTicket issues a ticket opening one wallet event stream as the
+// authenticated account, for clients that cannot send the access token in a
+// header.
+func (b *BaseController) CreateStreamTicket(c *gin.Context) error {
+	ticket, expiresAt, err := models.InitStreamTicketRepo(b.requestDB(c)).Issue(
+		c.GetString(constants.AuthorizedAccountUUIDContextKey),
+		c.GetString(constants.AuthorizedAccountRoleContextKey),
+		b.Config.Streams.TicketTTL,
+	)
+	if err != nil {
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInCreatingStreamTicket)
+	}
+
+	c.JSON(http.StatusCreated, StreamTicket{Ticket: ticket, ExpiresAt: expiresAt})
+	return nil
+}
+
+// walletEventCursor is the wallet_sequence of the last transaction event
+// sent for each shard of a wallet, by shard index; an unsharded wallet has
+// one. Each shard's sequence follows commit order, so resuming from the
+// cursor replays every leg committed since, on any shard. Its String form,
+// the sequences joined by dots, is the ID of transaction events.
+type walletEventCursor []int64
+
+func parseWalletEventCursor(id string) (walletEventCursor, error) {
+	parts := strings.Split(id, ".")
+	if len(parts) > models.MaxWalletShards {
+		return nil, fmt.Errorf("event id has %d shards, at most %d", len(parts), models.MaxWalletShards)
+	}
+
+	cursor := make(walletEventCursor, len(parts))
+	for i, part := range parts {
+		sequence, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if sequence < 0 {
+			return nil, fmt.Errorf("event id has negative sequence %d", sequence)
+		}
+		cursor[i] = sequence
+	}
+	return cursor, nil
+}
+
+// at returns the sequence of shardIndex, 0 for a shard added after the
+// cursor was made.
+func (c walletEventCursor) at(shardIndex int) int64 {
+	if shardIndex < len(c) {
+		return c[shardIndex]
+	}
+	return 0
+}
+
+func (c *walletEventCursor) set(shardIndex int, sequence int64) {
+	for len(*c) <= shardIndex {
+		*c = append(*c, 0)
+	}
+	(*c)[shardIndex] = sequence
+}
+
+func (c walletEventCursor) String() string {
+	parts := make([]string, len(c))
+	for i, sequence := range c {
+		parts[i] = strconv.FormatInt(sequence, 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// latestWalletEventCursor returns the cursor of the last transactions
+// committed on the shards of wallet.
+func latestWalletEventCursor(db *gorm.DB, wallet *models.Wallet) (walletEventCursor, error) {
+	var (
+		walletRepo      = models.InitWalletRepo(db)
+		transactionRepo = models.InitTransactionRepo(db)
+	)
+
+	shards, err := walletRepo.ShardsWithTx(db, wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := walletEventCursor{0}
+	for _, shard := range shards {
+		sequence, err := transactionRepo.LatestSequenceForWallet(shard.ID)
+		if err != nil {
+			return nil, err
+		}
+		cursor.set(shard.ShardIndex, sequence)
+	}
+	return cursor, nil
+}
+
+// walletEventStream tracks what has been sent on one connection so each
+// change signal only emits what the client has not seen yet.
+type walletEventStream struct {
+	db          *gorm.DB
+	walletID    uint64
+	cursor      walletEventCursor
+	lastBalance *WalletBalanceResponse
+}
+
+func (s *walletEventStream) flush(send func(WalletEvent) error) error {
+	var (
+		walletRepo      = models.InitWalletRepo(s.db)
+		transactionRepo = models.InitTransactionRepo(s.db)
+	)
+
+	wallet, err := walletRepo.Get(&models.Wallet{ID: s.walletID})
+	if err != nil {
+		logger.Error("error in getting wallet | err: ", err)
+		return err
+	}
+
+	// shards can be added while the stream is open, list them every time
+	shards, err := walletRepo.ShardsWithTx(s.db, wallet)
+	if err != nil {
+		return err
+	}
+
+	for _, shard := range shards {
+		for {
+			transactions, err := transactionRepo.ListForWalletAfterSequence(shard.ID, s.cursor.at(shard.ShardIndex), walletEventsReplayPageSize)
+			if err != nil {
+				return err
+			}
+
+			for _, transaction := range transactions {
+				s.cursor.set(shard.ShardIndex, transaction.WalletSequence)
+				err = send(WalletEvent{
+					ID:    s.cursor.String(),
+					Event: WalletEventTransaction,
+					Data:  transaction,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(transactions) < walletEventsReplayPageSize {
+				break
+			}
+		}
+	}
+
+	balance := toWalletBalanceResponse(wallet)
+	if s.lastBalance != nil && *s.lastBalance == balance {
+		return nil
+	}
+
+	err = send(WalletEvent{
+		Event: WalletEventBalance,
+		Data:  balance,
+	})
+	if err != nil {
+		return err
+	}
+	s.lastBalance = &balance
+	return nil
+}
+
+// streamWalletEvents replays transactions after cursor and then pushes
+// every committed change to the wallet until ctx is done or the event broker
+// stops. A nil cursor starts from the current state.
+func (b *BaseController) streamWalletEvents(ctx context.Context, wallet *models.Wallet, cursor walletEventCursor, send func(WalletEvent) error, heartbeat func() error) error {
+	// subscribe before reading any state so no commit in between is missed
+	changed, unsubscribe := b.Events.Subscribe(wallet.UUID)
+	defer unsubscribe()
+
+	if cursor == nil {
+		latest, err := latestWalletEventCursor(b.DB, wallet)
+		if err != nil {
+			return err
+		}
+		cursor = latest
+	}
+
+	stream := walletEventStream{
+		db:       b.DB,
+		walletID: wallet.ID,
+		cursor:   cursor,
+	}
+
+	err := stream.flush(send)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(walletEventsHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case _, ok := <-changed:
+			if !ok {
+				return nil
+			}
+			err = stream.flush(send)
+			if err != nil {
+				return err
+			}
+
+		case <-ticker.C:
+			err = heartbeat()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// authorizedWallet returns the wallet in the path if it belongs to the caller.
+func (b *BaseController) authorizedWallet(c *gin.Context) (*models.Wallet, error) {
+	var (
+		walletRepo = models.InitWalletRepo(b.DB)
+	)
+
+	wallet, err := walletRepo.Get(&models.Wallet{UUID: c.Param("wallet_uuid")})
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logger.Error("error in getting wallet | err: ", err)
+		return nil, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingWallet)
+	}
+
+	if err == gorm.ErrRecordNotFound || wallet.UserUUID != c.GetString(constants.AuthorizedAccountUUIDContextKey) {
+		return nil, errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgWalletNotFound)
+	}
+
+	return wallet, nil
+}
+
+// parseLastEventID returns the cursor the client resumes from, nil when it
+// sent none.
+func parseLastEventID(c *gin.Context) (walletEventCursor, error) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	if lastEventID == "" {
+		return nil, nil
+	}
+	return parseWalletEventCursor(lastEventID)
+}
+
+// StreamWalletEvents streams wallet events as Server-Sent Events.
+func (b *BaseController) StreamWalletEvents(c *gin.Context) error {
+	cursor, err := parseLastEventID(c)
+	if err != nil {
+		logger.Error("invalid last event id | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorBadRequest, errorConst.MsgInvalidLastEventID)
+	}
+
+	wallet, err := b.authorizedWallet(c)
+	if err != nil {
+		return err
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	err = b.streamWalletEvents(c.Request.Context(), wallet, cursor,
+		func(event WalletEvent) error {
+			err := sse.Encode(c.Writer, sse.Event{
+				Id:    event.ID,
+				Event: string(event.Event),
+				Data:  event.Data,
+			})
+			c.Writer.Flush()
+			return err
+		},
+		func() error {
+			_, err := c.Writer.WriteString(": ping\n\n")
+			c.Writer.Flush()
+			return err
+		},
+	)
+	if err != nil {
+		// the response has started, so the error can only be logged
+		logger.Error("wallet event stream closed | err: ", err)
+	}
+	return nil
+}
+
+// StreamWalletEventsWebSocket streams wallet events as JSON WebSocket messages.
+func (b *BaseController) StreamWalletEventsWebSocket(c *gin.Context) error {
+	cursor, err := parseLastEventID(c)
+	if err != nil {
+		logger.Error("invalid last event id | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorBadRequest, errorConst.MsgInvalidLastEventID)
+	}
+
+	wallet, err := b.authorizedWallet(c)
+	if err != nil {
+		return err
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: checkStreamOrigin(b.Config.Streams.AllowedOrigins)}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader has already replied to the client
+		logger.Error("unable to upgrade to websocket | err: ", err)
+		return nil
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// the stream is push only; reading is just to notice when the client goes away
+	go func() {
+		defer cancel()
+		conn.SetReadLimit(512)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = b.streamWalletEvents(ctx, wallet, cursor,
+		func(event WalletEvent) error {
+			conn.SetWriteDeadline(time.Now().Add(walletEventsWriteTimeout))
+			return conn.WriteJSON(event)
+		},
+		func() error {
+			return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(walletEventsWriteTimeout))
+		},
+	)
+	if err != nil {
+		logger.Error("wallet event stream closed | err: ", err)
+	}
+
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(walletEventsWriteTimeout))
+	return nil
+}
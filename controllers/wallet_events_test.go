@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckStreamOrigin(t *testing.T) {
+	check := checkStreamOrigin([]string{"https://app.example.com/", " https://Admin.Example.com"})
+	cases := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"https://api.example.com", true},
+		{"https://app.example.com", true},
+		{"https://admin.example.com", true},
+		{"http://app.example.com", false},
+		{"https://evil.example.net", false},
+		{"://bad", false},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest("GET", "https://api.example.com/v1/wallets/wa_1/events", nil)
+		if tc.origin != "" {
+			r.Header.Set("Origin", tc.origin)
+		}
+		if got := check(r); got != tc.want {
+			t.Errorf("origin %q allowed = %v, want %v", tc.origin, got, tc.want)
+		}
+	}
+}
+
+func TestWalletEventCursor(t *testing.T) {
+	cursor, err := parseWalletEventCursor("12.0.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor.at(0) != 12 || cursor.at(2) != 7 || cursor.at(5) != 0 {
+		t.Errorf("parsed %v", cursor)
+	}
+
+	// a shard added after the cursor was made starts from its first leg
+	cursor.set(4, 3)
+	if got := cursor.String(); got != "12.0.7.0.3" {
+		t.Errorf("String() = %q, want 12.0.7.0.3", got)
+	}
+
+	for _, id := range []string{"", "a", "1..2", "-1", "1.-2", strings.Repeat("1.", 64) + "1"} {
+		if _, err := parseWalletEventCursor(id); err == nil {
+			t.Errorf("parseWalletEventCursor(%q) succeeded, want an error", id)
+		}
+	}
+}
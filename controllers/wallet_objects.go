@@ -0,0 +1,28 @@
+package controllers
+
+import "coinpe/pkg/api"
+
+type WalletBalanceResponse = api.WalletBalanceResponse
+type WalletChainResponse = api.WalletChainResponse
+type BalanceAtRequest = api.BalanceAtRequest
+type BalanceAtResponse = api.BalanceAtResponse
+type StatementRequest = api.StatementRequest
+type StatementResponse = api.StatementResponse
+type StreamTicket = api.StreamTicket
+
+type WalletEventType string
+
+const (
+	WalletEventBalance     WalletEventType = "balance"
+	WalletEventTransaction WalletEventType = "transaction"
+)
+
+// WalletEvent is sent on the wallet event streams. Transaction events carry
+// the wallet_sequence reached on each shard of the wallet, joined by dots, as
+// their ID, which clients send back as Last-Event-ID (or last_event_id) to
+// resume after a reconnect.
+type WalletEvent struct {
+	ID    string          `json:"id,omitempty"`
+	Event WalletEventType `json:"event"`
+	Data  interface{}     `json:"data"`
+}
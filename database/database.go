@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"log"
 
 	"gorm.io/driver/postgres"
@@ -12,7 +14,6 @@ import (
 
 func New(cfg DBConfiguration, options ...func(*DBConnOptions)) (*gorm.DB, error) {
 	conn := &DBConnOptions{
-		migrations: []interface{}{},
 		logConfig: DBLogConfig{
 			MigrationsLogLevel: logger.Error,
 			DefaultLogLevel:    logger.Error,
@@ -22,7 +23,7 @@ func New(cfg DBConfiguration, options ...func(*DBConnOptions)) (*gorm.DB, error)
 		o(conn)
 	}
 
-	gormDB, err := gorm.Open(postgres.Open(getDSN(cfg)), &gorm.Config{
+	gormDB, err := gorm.Open(postgres.Open(GetDSN(cfg)), &gorm.Config{
 		Logger: logger.Default.LogMode(conn.logConfig.DefaultLogLevel),
 	})
 	if err != nil {
@@ -31,16 +32,30 @@ func New(cfg DBConfiguration, options ...func(*DBConnOptions)) (*gorm.DB, error)
 	}
 
 	if conn.replicaConfig != nil {
-		gormDB.Use(dbresolver.Register(dbresolver.Config{
+		err = gormDB.Use(dbresolver.Register(dbresolver.Config{
 			Replicas: []gorm.Dialector{
-				postgres.Open(getDSN(*conn.replicaConfig)),
+				postgres.Open(GetDSN(*conn.replicaConfig)),
 			},
 			Policy: dbresolver.RandomPolicy{},
 		}))
+		if err != nil {
+			log.Fatal("replica connection error", err.Error())
+			return nil, err
+		}
+
+		err = registerReplicaCallbacks(gormDB)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if len(conn.migrations) > 0 {
-		err = GetSilentSession(gormDB).AutoMigrate(conn.migrations...)
+	if conn.migrations != nil {
+		migrator, err := NewMigrator(gormDB, conn.migrations)
+		if err != nil {
+			log.Fatalf("database migration error: %s", err)
+		}
+
+		_, err = migrator.Up(context.Background())
 		if err != nil {
 			log.Fatalf("database migration error: %s", err)
 		}
@@ -55,13 +70,14 @@ func WithLogConfig(cfg DBLogConfig) func(*DBConnOptions) {
 	}
 }
 
-func WithMigrations(migrations []interface{}) func(*DBConnOptions) {
+// WithMigrations applies the pending SQL migrations in fsys on connect, see Migrator.
+func WithMigrations(migrations fs.FS) func(*DBConnOptions) {
 	return func(db *DBConnOptions) {
 		db.migrations = migrations
 	}
 }
 
-func getDSN(cfg DBConfiguration) string {
+func GetDSN(cfg DBConfiguration) string {
 	return fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
 		cfg.Host, cfg.Username, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode,
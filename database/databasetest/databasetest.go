@@ -0,0 +1,90 @@
+// Package databasetest gives integration tests a migrated Postgres schema
+// of their own. Tests using it are skipped unless TEST_DB_HOST is set, the
+// rest of the connection comes from TEST_DB_NAME, TEST_DB_USER,
+// TEST_DB_PASSWORD, TEST_DB_PORT and TEST_DB_SSL_MODE, e.g.
+//
+//	TEST_DB_HOST=localhost TEST_DB_NAME=coinpe_test TEST_DB_USER=admin \
+//		TEST_DB_PASSWORD=adminpassword TEST_DB_PORT=5432 TEST_DB_SSL_MODE=disable go test ./...
+package databasetest
+
+import (
+	"coinpe/database"
+	"coinpe/migrations"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/sethvargo/go-envconfig"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// New returns a connection whose search_path is a new schema with every
+// migration applied. The schema is dropped when the test ends.
+func New(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	var cfg database.DBConfiguration
+	err := envconfig.ProcessWith(context.Background(), &envconfig.Config{
+		Target:   &cfg,
+		Lookuper: envconfig.PrefixLookuper("TEST_DB_", envconfig.OsLookuper()),
+	})
+	if err != nil {
+		t.Fatalf("test database config: %s", err)
+	}
+	if cfg.Host == "" {
+		t.Skip("TEST_DB_HOST is not set")
+	}
+
+	admin, err := open(database.GetDSN(cfg))
+	if err != nil {
+		t.Fatalf("test database connection: %s", err)
+	}
+
+	schema := newSchemaName()
+	err = admin.Exec(fmt.Sprintf("CREATE SCHEMA %s", schema)).Error
+	if err != nil {
+		t.Fatalf("create schema %s: %s", schema, err)
+	}
+
+	db, err := open(database.GetDSN(cfg) + " search_path=" + schema)
+	if err != nil {
+		t.Fatalf("test database connection: %s", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("load migrations: %s", err)
+	}
+	_, err = migrator.Up(context.Background())
+	if err != nil {
+		t.Fatalf("apply migrations: %s", err)
+	}
+
+	return db
+}
+
+func open(dsn string) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+}
+
+func newSchemaName() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "test_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,74 @@
+package database_test
+
+import (
+	"coinpe/database"
+	"coinpe/database/databasetest"
+	"coinpe/migrations"
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestMigrator rolls the latest migration back and forth on a migrated
+// schema, and refuses to migrate once an applied script was edited.
+func TestMigrator(t *testing.T) {
+	db := databasetest.New(t)
+	ctx := context.Background()
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil || s.Modified {
+			t.Errorf("migration %d_%s applied at %v, modified %v, want applied as embedded", s.Version, s.Name, s.AppliedAt, s.Modified)
+		}
+	}
+	latest := statuses[len(statuses)-1]
+
+	applied, err := migrator.Up(ctx)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("migrated schema applied %d migrations and got %v, want none", len(applied), err)
+	}
+
+	rolledBack, err := migrator.Down(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rolledBack) != 1 || rolledBack[0].Version != latest.Version {
+		t.Fatalf("rolled back %+v, want only %d_%s", rolledBack, latest.Version, latest.Name)
+	}
+
+	statuses, err = migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := statuses[len(statuses)-1]; s.AppliedAt != nil {
+		t.Errorf("migration %d_%s is still applied after the rollback", s.Version, s.Name)
+	}
+
+	applied, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0].Version != latest.Version {
+		t.Fatalf("applied %+v, want only %d_%s again", applied, latest.Version, latest.Name)
+	}
+
+	edited, err := database.NewMigrator(db, fstest.MapFS{
+		"0001_initial_schema.up.sql": {Data: []byte("SELECT 1")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = edited.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "0001_initial_schema") {
+		t.Errorf("edited migration got %v, want it refused", err)
+	}
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"coinpe/migrations"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_add_index.up.sql":      {Data: []byte("CREATE INDEX i ON t (c);")},
+		"0010_add_index.down.sql":    {Data: []byte("DROP INDEX i;")},
+		"0002_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c int);")},
+		"embed.go":                   {Data: []byte("package migrations")},
+		"0002_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+
+	got, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Version != 2 || got[1].Version != 10 {
+		t.Fatalf("loaded %+v, want versions 2 and 10 in order", got)
+	}
+	if got[1].Name != "add_index" || got[1].Down != "DROP INDEX i;" {
+		t.Errorf("loaded %+v, want add_index with its down script", got[1])
+	}
+	sum := sha256.Sum256([]byte("CREATE TABLE t (c int);"))
+	if got[0].Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("checksum %s, want the sha256 of the up script", got[0].Checksum)
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			name: "bad name",
+			fsys: fstest.MapFS{"1-create.sql": {}},
+			want: "is not named",
+		},
+		{
+			name: "version reused",
+			fsys: fstest.MapFS{
+				"0001_one.up.sql": {Data: []byte("SELECT 1")},
+				"0001_two.up.sql": {Data: []byte("SELECT 2")},
+			},
+			want: "is used by",
+		},
+		{
+			name: "down only",
+			fsys: fstest.MapFS{"0001_one.down.sql": {Data: []byte("SELECT 1")}},
+			want: "has no up script",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := loadMigrations(c.fsys)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("got %v, want an error containing %q", err, c.want)
+			}
+		})
+	}
+}
+
+// TestEmbeddedMigrations loads the migrations shipped with the binary, so a
+// misnamed or clashing file fails here rather than on boot.
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := loadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range got {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s, want version %d", m.Version, m.Name, i+1)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down script", m.Version, m.Name)
+		}
+	}
+}
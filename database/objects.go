@@ -1,6 +1,10 @@
 package database
 
-import "gorm.io/gorm/logger"
+import (
+	"io/fs"
+
+	"gorm.io/gorm/logger"
+)
 
 type DBConfiguration struct {
 	Name     string `env:"NAME"`
@@ -18,7 +22,7 @@ type DBLogConfig struct {
 }
 
 type DBConnOptions struct {
-	migrations    []interface{}
+	migrations    fs.FS
 	logConfig     DBLogConfig
 	replicaConfig *DBConfiguration
 }
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const replicaSettingKey = "coinpe:replica"
+
+type readYourWritesKey struct{}
+
+// WithReplica routes reads marked with Replica to the cfg database. It is a
+// no-op when cfg has no host, so an unset READER_DB_ config keeps every query
+// on the primary.
+func WithReplica(cfg DBConfiguration) func(*DBConnOptions) {
+	return func(db *DBConnOptions) {
+		if cfg.Host == "" {
+			return
+		}
+		db.replicaConfig = &cfg
+	}
+}
+
+// Replica marks the reads of db as safe to serve from a lagging replica, for
+// history and reporting queries. Unmarked reads stay on the primary.
+func Replica(db *gorm.DB) *gorm.DB {
+	return db.Set(replicaSettingKey, true)
+}
+
+// Primary pins db to the primary even if it was marked with Replica, for
+// reads that decide balances.
+func Primary(db *gorm.DB) *gorm.DB {
+	return db.Set(replicaSettingKey, false)
+}
+
+// WithReadYourWrites returns a context whose Replica reads move to the primary
+// once any write has been made with it, so a request always sees its own writes.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, &atomic.Bool{})
+}
+
+func hasWritten(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	written, ok := ctx.Value(readYourWritesKey{}).(*atomic.Bool)
+	return ok && written.Load()
+}
+
+func markWritten(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+	if written, ok := db.Statement.Context.Value(readYourWritesKey{}).(*atomic.Bool); ok {
+		written.Store(true)
+	}
+}
+
+// markRawWritten is markWritten for Exec, which may also be a plain select.
+func markRawWritten(db *gorm.DB) {
+	sql := strings.TrimSpace(db.Statement.SQL.String())
+	if len(sql) >= 6 && strings.EqualFold(sql[:6], "select") {
+		return
+	}
+	markWritten(db)
+}
+
+// routeRead runs before dbresolver picks a connection and sends the read to
+// a replica only when it was marked with Replica and nothing was written in
+// its context yet.
+func routeRead(db *gorm.DB) {
+	replica, _ := db.Get(replicaSettingKey)
+	if isReplica, _ := replica.(bool); isReplica && !hasWritten(db.Statement.Context) {
+		dbresolver.Read.ModifyStatement(db.Statement)
+		return
+	}
+	dbresolver.Write.ModifyStatement(db.Statement)
+}
+
+func registerReplicaCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	for _, err := range []error{
+		callbacks.Create().After("*").Register("coinpe:mark_written", markWritten),
+		callbacks.Update().After("*").Register("coinpe:mark_written", markWritten),
+		callbacks.Delete().After("*").Register("coinpe:mark_written", markWritten),
+		callbacks.Raw().After("*").Register("coinpe:mark_written", markRawWritten),
+		callbacks.Query().Before("gorm:db_resolver").Register("coinpe:route_read", routeRead),
+		callbacks.Row().Before("gorm:db_resolver").Register("coinpe:route_read", routeRead),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dryRunDB builds statements without a connection, enough to check which
+// side of the resolver a read is sent to.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestRouteRead(t *testing.T) {
+	db := dryRunDB(t)
+
+	written := WithReadYourWrites(context.Background())
+	markWritten(db.WithContext(written))
+
+	cases := []struct {
+		name  string
+		db    *gorm.DB
+		reads bool
+	}{
+		{"unmarked", db, false},
+		{"replica", Replica(db), true},
+		{"replica without a write", Replica(db.WithContext(WithReadYourWrites(context.Background()))), true},
+		{"replica after a write", Replica(db.WithContext(written)), false},
+		{"pinned to primary", Primary(Replica(db)), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stmt := c.db.Session(&gorm.Session{}).Statement
+			routeRead(stmt.DB)
+
+			_, reads := stmt.Settings.Load("gorm:db_resolver:read")
+			_, writes := stmt.Settings.Load("gorm:db_resolver:write")
+			if reads != c.reads || writes == c.reads {
+				t.Errorf("read from the replica is %v, want %v", reads, c.reads)
+			}
+		})
+	}
+}
+
+func TestMarkRawWritten(t *testing.T) {
+	db := dryRunDB(t)
+
+	cases := []struct {
+		sql     string
+		written bool
+	}{
+		{"SELECT pg_advisory_lock(1)", false},
+		{"  select 1", false},
+		{"UPDATE wallets SET version = version + 1", true},
+		{"INSERT INTO wallets DEFAULT VALUES", true},
+	}
+	for _, c := range cases {
+		ctx := WithReadYourWrites(context.Background())
+		tx := db.WithContext(ctx).Session(&gorm.Session{})
+		tx.Statement.SQL.WriteString(c.sql)
+
+		markRawWritten(tx)
+		if hasWritten(ctx) != c.written {
+			t.Errorf("%q marked the context written %v, want %v", c.sql, hasWritten(ctx), c.written)
+		}
+	}
+
+	if hasWritten(nil) || hasWritten(context.Background()) {
+		t.Error("a context without WithReadYourWrites was written")
+	}
+}
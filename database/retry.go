@@ -0,0 +1,62 @@
+package database
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	// postgres error codes of transactions that can succeed when run again
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+
+	defaultRetryAttempts = 4
+	retryBackoff         = 10 * time.Millisecond
+)
+
+// IsRetryable reports whether err is a serialization failure or deadlock,
+// which roll the transaction back and leave nothing behind.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+	}
+	return false
+}
+
+// IsUniqueViolation reports whether err violates the named unique index or
+// constraint, any of them when constraint is empty.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" && (constraint == "" || pgErr.ConstraintName == constraint)
+	}
+	return false
+}
+
+// TransactionWithRetry runs fn in a transaction of db and runs it again,
+// after a short jittered backoff, while it fails with an error retryable
+// reports as transient. fn must not keep state across attempts.
+func TransactionWithRetry(db *gorm.DB, retryable func(err error) bool, fn func(tx *gorm.DB) error) error {
+	var err error
+	for attempt := 0; attempt < defaultRetryAttempts; attempt++ {
+		if attempt > 0 {
+			wait := retryBackoff << attempt
+			select {
+			case <-db.Statement.Context.Done():
+				return db.Statement.Context.Err()
+			case <-time.After(time.Duration(rand.Int64N(int64(wait))) + 1):
+			}
+		}
+
+		err = db.Transaction(fn)
+		if err == nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,10 @@
+// Package fixtures embeds the YAML fixtures loaded by models.LoadFixture,
+// such as the demo accounts seeded in local and sandbox environments.
+// Integration tests keep their own fixtures in the same format and load them
+// with os.DirFS, see models/testdata.
+package fixtures
+
+import "embed"
+
+//go:embed *.yaml
+var FS embed.FS
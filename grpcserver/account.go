@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"coinpe/models"
+	coinpev1 "coinpe/pb/coinpe/v1"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/utils"
+	"context"
+)
+
+func (s *Server) GetAccount(ctx context.Context, _ *coinpev1.GetAccountRequest) (*coinpev1.Account, error) {
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := models.InitAccountRepo(s.DB).Get(&models.Account{
+		UUID: claims.AccountUUID,
+	})
+	if err != nil {
+		return nil, toError(err, errorConst.MsgErrorInGettingAccount)
+	}
+
+	return &coinpev1.Account{
+		Uuid:        account.UUID,
+		FirstName:   account.FirstName,
+		LastName:    account.LastName,
+		PhoneNumber: utils.String(account.PhoneNumber),
+		Email:       account.Email,
+		Role:        claims.Role,
+		CreatedAt:   timestamp(account.CreatedAt),
+	}, nil
+}
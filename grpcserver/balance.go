@@ -0,0 +1,21 @@
+package grpcserver
+
+import (
+	coinpev1 "coinpe/pb/coinpe/v1"
+	"context"
+)
+
+func (s *Server) GetBalance(ctx context.Context, _ *coinpev1.GetBalanceRequest) (*coinpev1.Balance, error) {
+	wallet, err := s.callerWallet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coinpev1.Balance{
+		WalletUuid:              wallet.UUID,
+		Currency:                wallet.Currency,
+		TotalBalanceInCents:     int64(wallet.TotalBalanceInCents),
+		OverdraftLimitInCents:   uint64(wallet.OverdraftLimitInCents),
+		AvailableBalanceInCents: int64(wallet.TotalBalanceInCents) + int64(wallet.OverdraftLimitInCents),
+	}, nil
+}
@@ -0,0 +1,58 @@
+package grpcserver
+
+import (
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func acceptLanguage(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get("accept-language")
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// toStatusError renders err the way middleware.ErrorHandler does for HTTP:
+// domain errors keep their code and message, grpc statuses pass through and
+// anything else becomes Internal.
+func toStatusError(ctx context.Context, fullMethod string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var domainErr *errorConst.DomainError
+	if !errors.As(err, &domainErr) {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		logger.Error(fullMethod, " failed | err: ", err)
+		domainErr = errorConst.FromError(err)
+	}
+
+	return domainErr.Status(acceptLanguage(ctx)).Err()
+}
+
+func UnaryErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, toStatusError(ctx, info.FullMethod, err)
+	}
+}
+
+func StreamErrorInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return toStatusError(ss.Context(), info.FullMethod, handler(srv, ss))
+	}
+}
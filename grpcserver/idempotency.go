@@ -0,0 +1,107 @@
+package grpcserver
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const maxIdempotencyKeyLength = 255
+
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(constants.IdempotencyKeyHeaderName)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// idempotent runs call once for every idempotency-key metadata value sent by
+// accountUUID to the method of ctx, like middleware.IdempotencyMiddleware:
+// retries with the same request get the first response, unmarshalled into
+// resp, for models.IdempotencyKeyRetention. A call that fails has changed
+// nothing, so its key is released and a retry runs it again. Calls without
+// the metadata are run as they come.
+func idempotent[T proto.Message](ctx context.Context, repo models.IIdempotencyKey, accountUUID string, req proto.Message, resp T, call func() (T, error)) (T, error) {
+	var zero T
+
+	key := idempotencyKey(ctx)
+	if key == "" {
+		return call()
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		return zero, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgIdempotencyKeyIsTooLong)
+	}
+
+	method, _ := grpc.Method(ctx)
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return zero, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInReservingIdempotencyKey)
+	}
+	hash := sha256.Sum256(body)
+	record := &models.IdempotencyKey{
+		Scope:       strings.Join([]string{accountUUID, "GRPC", method}, " "),
+		Key:         key,
+		RequestHash: hex.EncodeToString(hash[:]),
+	}
+
+	reserved, err := repo.Reserve(record)
+	if err != nil {
+		return zero, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInReservingIdempotencyKey)
+	}
+
+	if !reserved {
+		existing, err := repo.Get(record.Scope, record.Key)
+		if err != nil {
+			logger.Error("unable to get idempotency key | err: ", err)
+			return zero, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingIdempotencyKey)
+		}
+
+		if existing.RequestHash != record.RequestHash {
+			return zero, errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgIdempotencyKeyReused)
+		}
+
+		if existing.CompletedAt == nil {
+			return zero, errorConst.New(errorConst.ErrorConflict, errorConst.MsgIdempotencyKeyInProgress).WithRetryable(true)
+		}
+
+		err = proto.Unmarshal(existing.ResponseBody, resp)
+		if err != nil {
+			return zero, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingIdempotencyKey)
+		}
+		// outside a served call, e.g. in tests, there is no header to set
+		_ = grpc.SetHeader(ctx, metadata.Pairs(constants.IdempotentReplayedHeaderName, "true"))
+		return resp, nil
+	}
+
+	result, err := call()
+	if err != nil {
+		repo.Release(record)
+		return zero, err
+	}
+
+	body, err = proto.Marshal(result)
+	if err != nil {
+		// the transfer is posted, keep the key reserved rather than free it
+		logger.Error("unable to marshal idempotent response | err: ", err)
+		return result, nil
+	}
+	repo.Complete(record, http.StatusOK, body)
+	return result, nil
+}
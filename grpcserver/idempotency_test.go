@@ -0,0 +1,69 @@
+package grpcserver
+
+import (
+	"coinpe/models/modelstest"
+	coinpev1 "coinpe/pb/coinpe/v1"
+	errorConst "coinpe/pkg/error"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestIdempotent(t *testing.T) {
+	var (
+		keys  = modelstest.NewIdempotencyKeys()
+		calls int
+		fail  error
+	)
+	create := func(ctx context.Context, req *coinpev1.CreateTransferRequest) (*coinpev1.Transfer, error) {
+		return idempotent(ctx, keys, "acc_idempotent", req, &coinpev1.Transfer{}, func() (*coinpev1.Transfer, error) {
+			calls++
+			if fail != nil {
+				return nil, fail
+			}
+			return &coinpev1.Transfer{ReferenceId: fmt.Sprintf("tr_%d", calls), AmountInCents: req.GetAmountInCents()}, nil
+		})
+	}
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("idempotency-key", key))
+	}
+	req := &coinpev1.CreateTransferRequest{ToWalletUuid: "wa_to", AmountInCents: 500}
+
+	first, err := create(withKey("k1"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := create(withKey("k1"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || !proto.Equal(first, replayed) {
+		t.Errorf("retry ran the call again (%d calls) or got %v, want %v", calls, replayed, first)
+	}
+
+	_, err = create(withKey("k1"), &coinpev1.CreateTransferRequest{ToWalletUuid: "wa_to", AmountInCents: 600})
+	if !errors.Is(err, errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgIdempotencyKeyReused)) {
+		t.Errorf("another request under the key got %v, want the key reused error", err)
+	}
+
+	_, err = create(context.Background(), req)
+	if err != nil || calls != 2 {
+		t.Errorf("a call without a key got %v after %d calls, want it run", err, calls)
+	}
+
+	// a failed call posted nothing, its retry runs
+	fail = errorConst.New(errorConst.ErrorConflict, errorConst.MsgWalletIsBusyRetryTheRequest).WithRetryable(true)
+	_, err = create(withKey("k2"), req)
+	if !errors.Is(err, fail) {
+		t.Fatalf("got %v, want %v", err, fail)
+	}
+	fail = nil
+	_, err = create(withKey("k2"), req)
+	if err != nil || calls != 4 {
+		t.Errorf("retry after a failure got %v after %d calls, want it run", err, calls)
+	}
+}
@@ -0,0 +1,120 @@
+package grpcserver
+
+import (
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/jwtauth"
+	"coinpe/pkg/logger"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type contextKey string
+
+const claimsContextKey contextKey = "claims"
+
+// methods under these prefixes are served without an access token
+var publicMethodPrefixes = []string{
+	"/grpc.reflection.",
+}
+
+func isPublicMethod(fullMethod string) bool {
+	for _, prefix := range publicMethodPrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseBearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+
+	tokenString, found := strings.CutPrefix(values[0], "Bearer")
+	if found {
+		tokenString = strings.TrimSpace(tokenString)
+	}
+	return tokenString
+}
+
+// authenticate mirrors middleware.AccessTokenMiddleware for full auth scoped tokens.
+func authenticate(ctx context.Context, secretKey []byte) (context.Context, error) {
+	tokenString := parseBearerToken(ctx)
+	if tokenString == "" {
+		logger.Error("authorization metadata cannot be empty")
+		return nil, errorConst.New(errorConst.ErrorUnauthorized, errorConst.MsgMissingAccessToken)
+	}
+
+	token, err := jwtauth.ParseToken(tokenString, secretKey)
+	if err != nil {
+		logger.Error("error in parsing access token | err: ", err)
+		return nil, errorConst.New(errorConst.ErrorUnauthorized, errorConst.MsgInvalidAccessToken)
+	}
+
+	if token.TokenType == jwtauth.TokenTypeRefresh {
+		logger.Error("refresh token used as an access token on grpc")
+		return nil, errorConst.New(errorConst.ErrorUnauthorized, errorConst.MsgInvalidAccessToken)
+	}
+
+	if token.IsPartial {
+		logger.Error("partial auth scoped token used on grpc")
+		return nil, errorConst.New(errorConst.ErrorForbidden, errorConst.MsgFullAuthScopedTokenRequired)
+	}
+
+	return context.WithValue(ctx, claimsContextKey, token), nil
+}
+
+func UnaryAuthInterceptor(secretKey []byte) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		authCtx, err := authenticate(ctx, secretKey)
+		if err != nil {
+			return nil, err
+		}
+		return handler(authCtx, req)
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func StreamAuthInterceptor(secretKey []byte) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublicMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		authCtx, err := authenticate(ss.Context(), secretKey)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: authCtx})
+	}
+}
+
+func claimsFromContext(ctx context.Context) (*jwtauth.CustomClaims, error) {
+	claims, ok := ctx.Value(claimsContextKey).(*jwtauth.CustomClaims)
+	if !ok || claims == nil {
+		return nil, errorConst.New(errorConst.ErrorUnauthorized, errorConst.MsgMissingAccessToken)
+	}
+	return claims, nil
+}
@@ -0,0 +1,128 @@
+package grpcserver
+
+import (
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/jwtauth"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	secretKey := []byte("grpc-test-secret")
+	token := func(claims jwtauth.CustomClaims) string {
+		signed, err := jwtauth.NewTokenWithClaims(secretKey, claims, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + *signed
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		code          int
+		accountUUID   string
+	}{
+		{"missing token", "/coinpe.v1.WalletService/ListWallets", "", errorConst.ErrorUnauthorized, ""},
+		{"invalid token", "/coinpe.v1.WalletService/ListWallets", "Bearer not-a-jwt", errorConst.ErrorUnauthorized, ""},
+		{
+			name:          "refresh token",
+			method:        "/coinpe.v1.WalletService/ListWallets",
+			authorization: token(jwtauth.CustomClaims{AccountUUID: "acc_grpc", TokenType: jwtauth.TokenTypeRefresh}),
+			code:          errorConst.ErrorUnauthorized,
+		},
+		{
+			name:          "partial token",
+			method:        "/coinpe.v1.WalletService/ListWallets",
+			authorization: token(jwtauth.CustomClaims{AccountUUID: "acc_grpc", IsPartial: true}),
+			code:          errorConst.ErrorForbidden,
+		},
+		{
+			name:          "access token",
+			method:        "/coinpe.v1.WalletService/ListWallets",
+			authorization: token(jwtauth.CustomClaims{AccountUUID: "acc_grpc", TokenType: jwtauth.TokenTypeAccess}),
+			accountUUID:   "acc_grpc",
+		},
+		{"public method", "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", "", 0, ""},
+	}
+
+	interceptor := UnaryAuthInterceptor(secretKey)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+
+			var called bool
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req any) (any, error) {
+				called = true
+				if tt.accountUUID == "" {
+					return nil, nil
+				}
+				claims, err := claimsFromContext(ctx)
+				if err != nil {
+					return nil, err
+				}
+				if claims.AccountUUID != tt.accountUUID {
+					t.Errorf("handler got claims for %s, want %s", claims.AccountUUID, tt.accountUUID)
+				}
+				return nil, nil
+			})
+
+			if tt.code == 0 {
+				if err != nil || !called {
+					t.Fatalf("handler called %v with err %v, want it called", called, err)
+				}
+				return
+			}
+			var domainErr *errorConst.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Code != tt.code {
+				t.Fatalf("got %v, want code %d", err, tt.code)
+			}
+			if called {
+				t.Error("handler was called for a rejected token")
+			}
+		})
+	}
+}
+
+func TestUnaryErrorInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"domain error", errorConst.New(errorConst.ErrorForbidden, errorConst.MsgFullAuthScopedTokenRequired), codes.PermissionDenied},
+		{"grpc status", status.Error(codes.NotFound, "no such wallet"), codes.NotFound},
+		{"other error", errors.New("connection reset"), codes.Internal},
+	}
+
+	interceptor := UnaryErrorInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/coinpe.v1.WalletService/ListWallets"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+				return nil, tt.err
+			})
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("got code %s, want %s", got, tt.code)
+			}
+		})
+	}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	if err != nil {
+		t.Errorf("successful call returned %v", err)
+	}
+}
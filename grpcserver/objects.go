@@ -0,0 +1,87 @@
+package grpcserver
+
+import (
+	"coinpe/models"
+	coinpev1 "coinpe/pb/coinpe/v1"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"errors"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// toError converts repository errors into domain errors, which the error
+// interceptors turn into grpc statuses.
+func toError(err error, id errorConst.MessageID) error {
+	var domainErr *errorConst.DomainError
+	switch {
+	case errors.As(err, &domainErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, id)
+	default:
+		logger.Error(errorConst.Text(id), " | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorInternalError, id)
+	}
+}
+
+func toProtoWallet(w *models.Wallet) *coinpev1.Wallet {
+	return &coinpev1.Wallet{
+		Uuid:                  w.UUID,
+		UserUuid:              w.UserUUID,
+		Currency:              w.Currency,
+		TotalBalanceInCents:   int64(w.TotalBalanceInCents),
+		OverdraftLimitInCents: uint64(w.OverdraftLimitInCents),
+		CreatedAt:             timestamp(w.CreatedAt),
+	}
+}
+
+func toProtoTransaction(t *models.Transaction) *coinpev1.Transaction {
+	return &coinpev1.Transaction{
+		Uuid:                  t.UUID,
+		Type:                  string(t.Type),
+		AmountInCents:         int64(t.AmountInCents),
+		OpeningBalanceInCents: int64(t.OpeningBalanceInCents),
+		ClosingBalanceInCents: int64(t.ClosingBalanceInCents),
+		Status:                string(t.Status),
+		FromWalletUuid:        t.FromWalletUUID,
+		ToWalletUuid:          t.ToWalletUUID,
+		PurposeCode:           string(t.PurposeCode),
+		ReferenceId:           t.ReferenceID,
+		Description:           t.Description,
+		CreatedAt:             timestamp(t.CreatedAt),
+	}
+}
+
+func toProtoTransfer(legs []models.Transaction) *coinpev1.Transfer {
+	transfer := &coinpev1.Transfer{}
+	for i := range legs {
+		leg := &legs[i]
+		transfer.ReferenceId = leg.ReferenceID
+		transfer.AmountInCents = int64(leg.AmountInCents)
+		transfer.PurposeCode = string(leg.PurposeCode)
+		transfer.Description = leg.Description
+		if leg.FromWalletUUID != "" {
+			transfer.FromWalletUuid = leg.FromWalletUUID
+		}
+		if leg.ToWalletUUID != "" {
+			transfer.ToWalletUuid = leg.ToWalletUUID
+		}
+		transfer.Legs = append(transfer.Legs, toProtoTransaction(leg))
+	}
+	return transfer
+}
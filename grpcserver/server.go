@@ -0,0 +1,45 @@
+package grpcserver
+
+import (
+	coinpev1 "coinpe/pb/coinpe/v1"
+	"coinpe/pkg/config"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"gorm.io/gorm"
+)
+
+type Server struct {
+	coinpev1.UnimplementedAccountServiceServer
+	coinpev1.UnimplementedWalletServiceServer
+	coinpev1.UnimplementedBalanceServiceServer
+	coinpev1.UnimplementedTransferServiceServer
+
+	DB     *gorm.DB
+	Config config.Config
+}
+
+// New builds a grpc.Server with every CoinPe service, the error and JWT auth
+// interceptors and server reflection registered.
+func New(app config.App) *grpc.Server {
+	srv := &Server{
+		DB:     app.DB,
+		Config: app.Config,
+	}
+
+	secretKey := []byte(app.Config.JWTConfiguration.SecretKey)
+	grpcServer := grpc.NewServer(
+		// the error interceptors come first so auth failures are rendered too
+		grpc.ChainUnaryInterceptor(UnaryErrorInterceptor(), UnaryAuthInterceptor(secretKey)),
+		grpc.ChainStreamInterceptor(StreamErrorInterceptor(), StreamAuthInterceptor(secretKey)),
+	)
+
+	coinpev1.RegisterAccountServiceServer(grpcServer, srv)
+	coinpev1.RegisterWalletServiceServer(grpcServer, srv)
+	coinpev1.RegisterBalanceServiceServer(grpcServer, srv)
+	coinpev1.RegisterTransferServiceServer(grpcServer, srv)
+
+	reflection.Register(grpcServer)
+
+	return grpcServer
+}
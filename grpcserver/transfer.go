@@ -0,0 +1,100 @@
+package grpcserver
+
+import (
+	"coinpe/models"
+	coinpev1 "coinpe/pb/coinpe/v1"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/jwtauth"
+	"coinpe/pkg/purposecodes"
+	"context"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+func (s *Server) CreateTransfer(ctx context.Context, req *coinpev1.CreateTransferRequest) (*coinpev1.Transfer, error) {
+	purposeCode := purposecodes.PurposeCodeTransfer
+
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetToWalletUuid() == "" {
+		return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgToWalletUUIDIsARequiredField)
+	}
+
+	if req.GetAmountInCents() <= 0 {
+		return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgAmountInCentsMustBeGreaterThanZero)
+	}
+
+	if req.GetPurposeCode() != "" {
+		purposeCode = purposecodes.TransactionPurposeCode(req.GetPurposeCode())
+		if !purposecodes.IsValid(purposeCode) {
+			return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidPurposeCode)
+		}
+	}
+
+	// only internal roles may move coins for anything other than a plain transfer
+	if purposeCode != purposecodes.PurposeCodeTransfer &&
+		!slices.Contains([]string{string(models.RoleTypeSuperAdmin), string(models.RoleTypeAdmin)}, claims.Role) {
+		return nil, errorConst.New(errorConst.ErrorForbidden, errorConst.MsgPurposeCodeNotAllowedForRole)
+	}
+
+	return idempotent(ctx, models.InitIdempotencyKeyRepo(s.DB), claims.AccountUUID, req, &coinpev1.Transfer{}, func() (*coinpev1.Transfer, error) {
+		return s.createTransfer(claims, req, purposeCode)
+	})
+}
+
+func (s *Server) createTransfer(claims *jwtauth.CustomClaims, req *coinpev1.CreateTransferRequest, purposeCode purposecodes.TransactionPurposeCode) (*coinpev1.Transfer, error) {
+	var (
+		walletRepo = models.InitWalletRepo(s.DB)
+		posting    *models.Posting
+	)
+	err := models.TransactWithRetry(s.DB, func(tx *gorm.DB) error {
+		from, err := walletRepo.GetWithTx(tx, &models.Wallet{UserUUID: claims.AccountUUID})
+		if err != nil {
+			return toError(err, errorConst.MsgErrorInGettingWallet)
+		}
+
+		to, err := walletRepo.GetWithTx(tx, &models.Wallet{UUID: req.GetToWalletUuid()})
+		if err != nil {
+			return toError(err, errorConst.MsgErrorInGettingDestinationWallet)
+		}
+
+		posting, err = walletRepo.TransferWithFeeTx(tx, from, to, int(req.GetAmountInCents()), purposeCode, req.GetDescription())
+		return err
+	})
+	if err != nil {
+		return nil, toError(err, errorConst.MsgErrorInCreatingTransfer)
+	}
+
+	// a fee is a transfer of its own, under another reference ID
+	return toProtoTransfer([]models.Transaction{*posting.Debit, *posting.Credit}), nil
+}
+
+func (s *Server) GetTransfer(ctx context.Context, req *coinpev1.GetTransferRequest) (*coinpev1.Transfer, error) {
+	if req.GetReferenceId() == "" {
+		return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgReferenceIDIsARequiredField)
+	}
+
+	wallet, err := s.callerWallet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	legs, err := models.InitTransactionRepo(s.DB).FindByReference(req.GetReferenceId())
+	if err != nil {
+		return nil, toError(err, errorConst.MsgErrorInGettingTransfer)
+	}
+
+	// callers can only see transfers their own wallet took part in
+	isParticipant := slices.ContainsFunc(legs, func(t models.Transaction) bool {
+		return t.OfWallet(wallet.ID)
+	})
+	if !isParticipant {
+		return nil, errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgTransferNotFound)
+	}
+
+	return toProtoTransfer(legs), nil
+}
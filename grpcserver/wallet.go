@@ -0,0 +1,75 @@
+package grpcserver
+
+import (
+	"coinpe/models"
+	coinpev1 "coinpe/pb/coinpe/v1"
+	errorConst "coinpe/pkg/error"
+	"context"
+	"strconv"
+)
+
+// callerWallet returns the wallet owned by the authenticated account.
+func (s *Server) callerWallet(ctx context.Context) (*models.Wallet, error) {
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := models.InitWalletRepo(s.DB).Get(&models.Wallet{
+		UserUUID: claims.AccountUUID,
+	})
+	if err != nil {
+		return nil, toError(err, errorConst.MsgErrorInGettingWallet)
+	}
+	return wallet, nil
+}
+
+func (s *Server) GetWallet(ctx context.Context, _ *coinpev1.GetWalletRequest) (*coinpev1.Wallet, error) {
+	wallet, err := s.callerWallet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoWallet(wallet), nil
+}
+
+func (s *Server) ListTransactions(ctx context.Context, req *coinpev1.ListTransactionsRequest) (*coinpev1.ListTransactionsResponse, error) {
+	var (
+		beforeID uint64
+		pageSize = int(req.GetPageSize())
+	)
+
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	} else if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	if req.GetPageToken() != "" {
+		parsed, err := strconv.ParseUint(req.GetPageToken(), 10, 64)
+		if err != nil {
+			return nil, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidPageToken)
+		}
+		beforeID = parsed
+	}
+
+	wallet, err := s.callerWallet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := models.InitTransactionRepo(s.DB).ListForWallet(wallet.ID, beforeID, pageSize)
+	if err != nil {
+		return nil, toError(err, errorConst.MsgErrorInListingTransactions)
+	}
+
+	response := &coinpev1.ListTransactionsResponse{}
+	for i := range transactions {
+		response.Transactions = append(response.Transactions, toProtoTransaction(&transactions[i]))
+	}
+
+	if len(transactions) == pageSize {
+		response.NextPageToken = strconv.FormatUint(transactions[len(transactions)-1].ID, 10)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+)
+
+const sealBatchSize = 1000
+
+var (
+	ErrSigningKeyMissing = errors.New("LEDGER_CHECKPOINT_SIGNING_KEY is not set")
+	ErrChainBroken       = errors.New("transaction hash chain broken")
+)
+
+func checkpointSigningKey(app config.App) (ed25519.PrivateKey, error) {
+	if app.Config.Ledger.CheckpointSigningKey == "" {
+		return nil, ErrSigningKeyMissing
+	}
+	return models.ParseCheckpointSigningKey(app.Config.Ledger.CheckpointSigningKey)
+}
+
+// CheckpointLedger seals every committed transaction into the global chain
+// and signs a checkpoint of its head.
+func CheckpointLedger(ctx context.Context, app config.App) error {
+	key, err := checkpointSigningKey(app)
+	if err != nil {
+		return err
+	}
+
+	ledgerRepo := models.InitLedgerRepo(app.DB.WithContext(ctx))
+
+	var total int
+	for {
+		sealed, err := ledgerRepo.SealGlobalChain(sealBatchSize)
+		if err != nil {
+			return err
+		}
+		total += sealed
+		if sealed < sealBatchSize {
+			break
+		}
+	}
+
+	checkpoint, err := ledgerRepo.CreateCheckpoint(key)
+	if err != nil {
+		return err
+	}
+	if checkpoint == nil {
+		logger.Info("sealed ", total, " transactions, no new checkpoint")
+		return nil
+	}
+
+	logger.Infof("sealed %d transactions, checkpoint %d at sequence %d", total, checkpoint.ID, checkpoint.GlobalSequence)
+	return nil
+}
+
+// VerifyChain checks the chain of every wallet and the global chain against
+// the signed checkpoints.
+func VerifyChain(ctx context.Context, app config.App) error {
+	key, err := checkpointSigningKey(app)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ledgerRepo = models.InitLedgerRepo(app.DB.WithContext(ctx))
+		issues     int
+	)
+
+	err = ledgerRepo.VerifyWalletChains(func(walletUUID string, v *models.ChainVerification) error {
+		for _, issue := range v.Issues {
+			logger.Warnf("wallet %s: %s %s %s", walletUUID, issue.Kind, issue.TransactionUUID, issue.Detail)
+		}
+		issues += len(v.Issues)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	global, err := ledgerRepo.VerifyGlobalChain(key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return err
+	}
+	for _, issue := range global.Issues {
+		logger.Warnf("global chain: %s %s %s", issue.Kind, issue.TransactionUUID, issue.Detail)
+	}
+	issues += len(global.Issues)
+
+	logger.Infof("verified %d sealed transactions up to sequence %d, %d issues", global.TransactionCount, global.HeadSequence, issues)
+	if issues > 0 {
+		return fmt.Errorf("%w: %d issues", ErrChainBroken, issues)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+)
+
+// ExpireIdempotencyKeys deletes the idempotency keys past their retention
+// and the reservations nobody completed. Reserve ignores them either way,
+// the job keeps the table small.
+func ExpireIdempotencyKeys(ctx context.Context, app config.App) error {
+	deleted, err := models.InitIdempotencyKeyRepo(app.DB.WithContext(ctx)).DeleteExpired()
+	if err != nil {
+		return err
+	}
+	logger.Infof("deleted %d expired idempotency keys", deleted)
+	return nil
+}
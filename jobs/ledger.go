@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+var ErrLedgerDiscrepancies = errors.New("ledger discrepancies found")
+
+// VerifyLedger replays the transactions of every wallet and reports chain
+// breaks and drifted balances. With --repair and a --reason it posts
+// adjustment entries for drifted wallets whose chain is intact. It fails
+// while any discrepancy is left.
+func VerifyLedger(ctx context.Context, app config.App) error {
+	var (
+		repair     = viper.GetBool("repair")
+		reason     = viper.GetString("reason")
+		ledgerRepo = models.InitLedgerRepo(app.DB.WithContext(ctx))
+		checked    int
+		repaired   int
+		unresolved int
+	)
+
+	if repair && reason == "" {
+		return fmt.Errorf("--repair needs a --reason: %w", models.ErrAuditReasonEmpty)
+	}
+
+	err := ledgerRepo.Verify(func(v *models.WalletVerification) error {
+		checked++
+		if v.OK() {
+			return nil
+		}
+
+		for _, issue := range v.Issues {
+			logger.Warnf("wallet %s: %s", v.WalletUUID, issue)
+		}
+
+		if !repair || !v.ChainIntact() {
+			unresolved++
+			return nil
+		}
+
+		adjustment, err := ledgerRepo.Adjust(v.WalletID, reason)
+		if err != nil {
+			return err
+		}
+		if adjustment != nil {
+			logger.Infof("wallet %s: posted adjustment %s", v.WalletUUID, adjustment.UUID)
+		}
+		repaired++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("verified %d wallets, %d repaired, %d with discrepancies", checked, repaired, unresolved)
+	if unresolved > 0 {
+		return fmt.Errorf("%w in %d wallets", ErrLedgerDiscrepancies, unresolved)
+	}
+	return nil
+}
+
+// RebuildBalances resets the stored balance of every drifted wallet to the
+// sum of its transactions. Wallets whose chain is broken are left alone.
+func RebuildBalances(ctx context.Context, app config.App) error {
+	var (
+		ledgerRepo = models.InitLedgerRepo(app.DB.WithContext(ctx))
+		rebuilt    int
+		skipped    int
+	)
+
+	err := ledgerRepo.Verify(func(v *models.WalletVerification) error {
+		if v.OK() {
+			return nil
+		}
+
+		if !v.ChainIntact() {
+			logger.Warnf("wallet %s: transactions do not chain, not rebuilding", v.WalletUUID)
+			skipped++
+			return nil
+		}
+
+		_, err := ledgerRepo.RebuildBalance(v.WalletID)
+		if err != nil {
+			return err
+		}
+		rebuilt++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("rebuilt %d wallet balances, skipped %d with broken chains", rebuilt, skipped)
+	if skipped > 0 {
+		return fmt.Errorf("%w in %d wallets", ErrLedgerDiscrepancies, skipped)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+	"errors"
+	"time"
+)
+
+// SettleMerchants closes a settlement batch for every merchant with
+// checkout payments due under the MERCHANT_SETTLEMENT_DELAY_DAYS cycle.
+// Schedule it a little after midnight UTC. A merchant whose batch fails
+// is retried on the next run and does not hold the others back, the job
+// fails once every merchant was tried.
+func SettleMerchants(ctx context.Context, app config.App) error {
+	var (
+		settlementBatchRepo = models.InitSettlementBatchRepo(app.DB.WithContext(ctx))
+		cutoff              = models.SettlementCutoff(time.Now(), app.Config.MerchantSettlement.DelayDays)
+		batches, netInCents int
+		errs                []error
+	)
+
+	merchantIDs, err := settlementBatchRepo.DueMerchants(cutoff)
+	if err != nil {
+		return err
+	}
+
+	for _, merchantID := range merchantIDs {
+		for ctx.Err() == nil {
+			batch, err := settlementBatchRepo.Close(merchantID, cutoff)
+			if err != nil {
+				logger.Error("unable to settle merchant ", merchantID, " | err: ", err)
+				errs = append(errs, err)
+				break
+			}
+			if batch == nil {
+				break
+			}
+			batches++
+			netInCents += batch.NetInCents
+		}
+	}
+
+	logger.Infof("closed %d settlement batches for %d merchants before %s, %d cents settled",
+		batches, len(merchantIDs), cutoff.Format(time.RFC3339), netInCents)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return errors.Join(errs...)
+}
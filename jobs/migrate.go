@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"coinpe/database"
+	"coinpe/migrations"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Migrate applies every pending migration.
+func Migrate(ctx context.Context, app config.App) error {
+	migrator, err := database.NewMigrator(app.DB, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("applied ", len(applied), " migrations")
+	return nil
+}
+
+// MigrateRollback rolls back the latest --steps migrations.
+func MigrateRollback(ctx context.Context, app config.App) error {
+	steps := viper.GetInt("steps")
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+
+	migrator, err := database.NewMigrator(app.DB, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	rolledBack, err := migrator.Down(ctx, steps)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("rolled back ", len(rolledBack), " migrations")
+	return nil
+}
+
+// MigrateStatus prints every migration with when it was applied.
+func MigrateStatus(ctx context.Context, app config.App) error {
+	migrator, err := database.NewMigrator(app.DB, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		status, appliedAt := "pending", ""
+		if s.AppliedAt != nil {
+			status, appliedAt = "applied", s.AppliedAt.Format(time.RFC3339)
+		}
+		switch {
+		case s.Modified:
+			status = "modified"
+		case s.AppliedAt != nil && s.Up == "":
+			status = "unknown"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+	}
+	return w.Flush()
+}
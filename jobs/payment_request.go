@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+)
+
+// ExpirePaymentRequests marks pending payment requests past their expiry
+// EXPIRED. They cannot be approved either way, the job keeps listings by
+// status accurate.
+func ExpirePaymentRequests(ctx context.Context, app config.App) error {
+	expired, err := models.InitPaymentRequestRepo(app.DB.WithContext(ctx)).ExpireDue()
+	if err != nil {
+		return err
+	}
+	logger.Infof("expired %d payment requests", expired)
+	return nil
+}
@@ -0,0 +1,314 @@
+package jobs
+
+import (
+	"bytes"
+	"coinpe/models"
+	"coinpe/pkg/api"
+	"coinpe/pkg/config"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// QueueWorker posts queued transfers and payouts and delivers webhooks
+// inside the server, see models.PendingTransaction and models.Payout. Every
+// instance runs one, SKIP LOCKED spreads the queue over all of them.
+type QueueWorker struct {
+	cfg          config.QueueConfiguration
+	repo         models.IPendingTransaction
+	payoutRepo   models.IPayout
+	merchantRepo models.IMerchant
+	client       *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewQueueWorker(app config.App) *QueueWorker {
+	return newQueueWorker(context.Background(), app)
+}
+
+func newQueueWorker(ctx context.Context, app config.App) *QueueWorker {
+	w := &QueueWorker{
+		cfg:    app.Config.Queue,
+		client: utils.NewWebhookClient(app.Config.Queue.WebhookTimeout),
+		stop:   make(chan struct{}),
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	w.repo = models.InitPendingTransactionRepo(app.DB.WithContext(w.ctx))
+	w.payoutRepo = models.InitPayoutRepo(app.DB.WithContext(w.ctx))
+	w.merchantRepo = models.InitMerchantRepo(app.DB.WithContext(w.ctx))
+	return w
+}
+
+// Start runs the configured number of posting workers, one payout worker
+// and one worker for each kind of webhook.
+func (w *QueueWorker) Start() {
+	for range w.cfg.Workers {
+		w.wg.Add(1)
+		go w.loop(w.processNext)
+	}
+	w.wg.Add(3)
+	go w.loop(w.processPayoutChunk)
+	go w.loop(w.deliverNext)
+	go w.loop(w.deliverNextMerchantWebhook)
+	logger.Infof("started %d queue workers", w.cfg.Workers)
+}
+
+// Shutdown stops claiming queued transfers and waits for the ones being
+// posted until ctx is done, then cancels them. A cancelled posting rolls
+// back and stays queued for the next start.
+func (w *QueueWorker) Shutdown(ctx context.Context) error {
+	close(w.stop)
+	defer w.cancel()
+
+	drained := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		logger.Info("queue workers drained")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop calls next until it is stopped, waiting a poll interval whenever
+// next found nothing to do or failed.
+func (w *QueueWorker) loop(next func() (bool, error)) {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		busy, err := next()
+		if err == nil && busy {
+			continue
+		}
+
+		select {
+		case <-w.stop:
+			return
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+func (w *QueueWorker) processNext() (bool, error) {
+	p, err := w.repo.ProcessNext(w.cfg.WalletConcurrency, w.cfg.MaxAttempts, w.cfg.RetryBackoff)
+	if err != nil || p == nil {
+		return false, err
+	}
+	logger.Infof("pending transaction %s %s after %d attempts", p.UUID, p.Status, p.Attempts)
+	return true, nil
+}
+
+func (w *QueueWorker) processPayoutChunk() (bool, error) {
+	return w.payoutRepo.ProcessNextChunk(w.cfg.PayoutChunkSize, w.cfg.MaxAttempts)
+}
+
+func (w *QueueWorker) deliverNext() (bool, error) {
+	return w.repo.DeliverNextWebhook(w.cfg.WebhookMaxAttempts, w.cfg.WebhookBackoff, w.cfg.WebhookTimeout, w.deliver)
+}
+
+func (w *QueueWorker) deliverNextMerchantWebhook() (bool, error) {
+	return w.merchantRepo.DeliverNextWebhook(w.cfg.WebhookMaxAttempts, w.cfg.WebhookBackoff, w.cfg.WebhookTimeout, w.deliverMerchantWebhook)
+}
+
+// deliver POSTs the webhook of p.
+func (w *QueueWorker) deliver(p *models.PendingTransaction) error {
+	return w.post(p.WebhookURL, w.cfg.WebhookSecret, pendingTransactionWebhook(p))
+}
+
+// deliverMerchantWebhook POSTs wh to its merchant, signed with the
+// merchant's own secret.
+func (w *QueueWorker) deliverMerchantWebhook(wh *models.MerchantWebhook, m *models.Merchant, s *models.CheckoutSession, refund *models.Refund) error {
+	return w.post(wh.URL, m.WebhookSecret, merchantWebhook(wh, m, s, refund))
+}
+
+// post sends payload as JSON to url, signed with secret when set, any 2xx
+// response acknowledges it. URLs stored before they had to pass
+// utils.CheckWebhookURL are checked again, and w.client only dials public
+// addresses.
+func (w *QueueWorker) post(url string, secret string, payload interface{}) error {
+	err := utils.CheckWebhookURL(url)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(constants.WebhookSignatureHeaderName, utils.SignWebhook(secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+func pendingTransactionWebhook(p *models.PendingTransaction) api.PendingTransactionWebhook {
+	event := "pending_transaction.succeeded"
+	if p.Status == models.PendingTransactionFailed {
+		event = "pending_transaction.failed"
+	}
+
+	payload := api.PendingTransactionWebhook{
+		Event: event,
+		PendingTransaction: api.PendingTransaction{
+			UUID:           p.UUID,
+			BatchUUID:      p.BatchUUID,
+			Status:         string(p.Status),
+			FromWalletUUID: p.FromWalletUUID,
+			ToWalletUUID:   p.ToWalletUUID,
+			AmountInCents:  p.AmountInCents,
+			PurposeCode:    string(p.PurposeCode),
+			Description:    p.Description,
+			Attempts:       p.Attempts,
+			ReferenceID:    p.ReferenceID,
+			Error:          p.Error,
+			ProcessedAt:    p.ProcessedAt,
+			CallbackURL:    p.WebhookURL,
+		},
+	}
+	if p.CreatedAt != nil {
+		payload.PendingTransaction.CreatedAt = *p.CreatedAt
+	}
+	return payload
+}
+
+func merchantWebhook(wh *models.MerchantWebhook, m *models.Merchant, s *models.CheckoutSession, refund *models.Refund) api.MerchantWebhook {
+	payload := api.MerchantWebhook{
+		ID:    wh.UUID,
+		Event: string(wh.Event),
+		CheckoutSession: api.CheckoutSession{
+			UUID:                s.UUID,
+			MerchantUUID:        s.MerchantUUID,
+			MerchantName:        m.Name,
+			MerchantWalletUUID:  s.MerchantWalletUUID,
+			OrderID:             s.OrderID,
+			AmountInCents:       s.AmountInCents,
+			Currency:            s.Currency,
+			Description:         s.Description,
+			SuccessURL:          s.SuccessURL,
+			Status:              string(s.CurrentStatus()),
+			ExpiresAt:           s.ExpiresAt,
+			CustomerWalletUUID:  s.CustomerWalletUUID,
+			ReferenceID:         s.ReferenceID,
+			PaidAt:              s.PaidAt,
+			RefundedInCents:     s.RefundedInCents,
+			MerchantFeeInCents:  s.MerchantFeeInCents,
+			SettlementStatus:    string(s.SettlementStatus),
+			SettlementBatchUUID: s.SettlementBatchUUID,
+		},
+	}
+	if wh.CreatedAt != nil {
+		payload.CreatedAt = *wh.CreatedAt
+	}
+	if s.CreatedAt != nil {
+		payload.CheckoutSession.CreatedAt = *s.CreatedAt
+	}
+	if refund != nil {
+		payload.Refund = &api.Refund{
+			UUID:                refund.UUID,
+			CheckoutSessionUUID: refund.CheckoutSessionUUID,
+			AmountInCents:       refund.AmountInCents,
+			Reason:              refund.Reason,
+			ReferenceID:         refund.ReferenceID,
+		}
+		if refund.CreatedAt != nil {
+			payload.Refund.CreatedAt = *refund.CreatedAt
+		}
+	}
+	return payload
+}
+
+// ProcessQueue posts every due queued transfer and pending payout and
+// delivers due webhooks, then exits. It is for instances running without
+// queue workers.
+func ProcessQueue(ctx context.Context, app config.App) error {
+	var (
+		worker    = newQueueWorker(ctx, app)
+		processed int
+		delivered int
+	)
+	defer worker.cancel()
+
+	for {
+		busy, err := worker.processNext()
+		if err != nil {
+			return err
+		}
+		if !busy {
+			break
+		}
+		processed++
+	}
+
+	for {
+		due, err := worker.processPayoutChunk()
+		if err != nil {
+			return err
+		}
+		if !due {
+			break
+		}
+	}
+
+	for {
+		due, err := worker.deliverNext()
+		if err != nil {
+			return err
+		}
+		if !due {
+			break
+		}
+		delivered++
+	}
+
+	for {
+		due, err := worker.deliverNextMerchantWebhook()
+		if err != nil {
+			return err
+		}
+		if !due {
+			break
+		}
+		delivered++
+	}
+
+	logger.Infof("processed %d pending transactions, attempted %d webhooks", processed, delivered)
+	return nil
+}
@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+)
+
+// Reconcile runs the matching rules over the unmatched and mismatched
+// settlement records of every import, picking up transactions that arrived
+// after their settlement file. Records left unmatched wait for an operator,
+// they do not fail the job.
+func Reconcile(ctx context.Context, app config.App) error {
+	window := app.Config.Reconciliation.DateWindow
+	if window <= 0 {
+		window = models.DefaultReconciliationDateWindow
+	}
+
+	result, err := models.InitSettlementRepo(app.DB.WithContext(ctx)).Reconcile(0, window)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("reconciled settlement records: %d matched, %d mismatched, %d unmatched",
+		result[models.SettlementRecordMatched], result[models.SettlementRecordMismatched], result[models.SettlementRecordUnmatched])
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package jobs holds the one-off and scheduled jobs of CoinPe. A job runs
+// with the loaded config and database, either once through the --job flag
+// or on a cron schedule inside the server.
+package jobs
+
+import (
+	"coinpe/pkg/config"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	ExitOK = iota
+	ExitFailed
+	ExitUnknownJob
+)
+
+var ErrUnknownJob = errors.New("unknown job")
+
+type Job struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, app config.App) error
+}
+
+type Registry struct {
+	jobs map[string]Job
+}
+
+func NewRegistry() *Registry {
+	return &Registry{jobs: map[string]Job{}}
+}
+
+// Default returns a registry with every job CoinPe ships with. There is no
+// expire-holds or expire-coins job: wallets have no holds, funds are moved
+// in one transfer or queued as pending transactions, and coins never expire.
+// Jobs for them belong with the change that adds either concept.
+func Default() *Registry {
+	r := NewRegistry()
+	r.Register(Job{Name: "migrate", Description: "Apply pending migrations", Run: Migrate})
+	r.Register(Job{Name: "migrate:rollback", Description: "Roll back the latest --steps migrations", Run: MigrateRollback})
+	r.Register(Job{Name: "migrate:status", Description: "List migrations and when they were applied", Run: MigrateStatus})
+	r.Register(Job{Name: "seed", Description: "Insert the system data", Run: Seed})
+	r.Register(Job{Name: "verify-ledger", Description: "Check every wallet against its transactions, --repair posts adjustments", Run: VerifyLedger})
+	r.Register(Job{Name: "rebuild-balances", Description: "Reset drifted wallet balances to the sum of their transactions", Run: RebuildBalances})
+	r.Register(Job{Name: "checkpoint-ledger", Description: "Seal new transactions into the global hash chain and sign a checkpoint", Run: CheckpointLedger})
+	r.Register(Job{Name: "verify-chain", Description: "Detect edited or deleted transactions through the hash chains", Run: VerifyChain})
+	r.Register(Job{Name: "snapshot-balances", Description: "Snapshot end of day wallet balances for point-in-time queries", Run: SnapshotBalances})
+	r.Register(Job{Name: "reconcile", Description: "Match pending settlement records against new transactions", Run: Reconcile})
+	r.Register(Job{Name: "shard-wallets", Description: "Split the treasury and configured wallets into shards", Run: ShardWallets})
+	r.Register(Job{Name: "process-queue", Description: "Post due queued transfers and payouts and deliver webhooks, then exit", Run: ProcessQueue})
+	r.Register(Job{Name: "run-scheduled-transfers", Description: "Post the due occurrences of scheduled transfers", Run: RunScheduledTransfers})
+	r.Register(Job{Name: "expire-payment-requests", Description: "Mark pending payment requests past their expiry expired", Run: ExpirePaymentRequests})
+	r.Register(Job{Name: "expire-idempotency-keys", Description: "Delete idempotency keys past their retention and abandoned reservations", Run: ExpireIdempotencyKeys})
+	r.Register(Job{Name: "expire-stream-tickets", Description: "Delete stream tickets that expired without being redeemed", Run: ExpireStreamTickets})
+	r.Register(Job{Name: "settle-merchants", Description: "Close settlement batches moving due merchant receivables to merchant wallets", Run: SettleMerchants})
+	r.Register(Job{Name: "rebalance-shards", Description: "Even out the shards of sharded wallets and check their invariants", Run: RebalanceShards})
+	return r
+}
+
+// Register adds job, it panics when the name is already taken.
+func (r *Registry) Register(job Job) {
+	if _, ok := r.jobs[job.Name]; ok {
+		panic(fmt.Sprintf("job %s is already registered", job.Name))
+	}
+	r.jobs[job.Name] = job
+}
+
+func (r *Registry) Get(name string) (Job, bool) {
+	job, ok := r.jobs[name]
+	return job, ok
+}
+
+// Names returns the registered job names in alphabetical order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.jobs))
+	for name := range r.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) Run(ctx context.Context, app config.App, name string) error {
+	job, ok := r.Get(name)
+	if !ok {
+		return fmt.Errorf("%w %q, available jobs: %v", ErrUnknownJob, name, r.Names())
+	}
+	return job.Run(ctx, app)
+}
+
+// ExitCode maps the result of Run to the process exit status.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrUnknownJob):
+		return ExitUnknownJob
+	default:
+		return ExitFailed
+	}
+}
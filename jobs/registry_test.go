@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"coinpe/pkg/config"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegistryRun(t *testing.T) {
+	errJob := errors.New("job failed")
+
+	var ran []string
+	r := NewRegistry()
+	r.Register(Job{Name: "ok", Run: func(context.Context, config.App) error {
+		ran = append(ran, "ok")
+		return nil
+	}})
+	r.Register(Job{Name: "failing", Run: func(context.Context, config.App) error {
+		ran = append(ran, "failing")
+		return errJob
+	}})
+
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"ok", nil, ExitOK},
+		{"failing", errJob, ExitFailed},
+		{"missing", ErrUnknownJob, ExitUnknownJob},
+	}
+	for _, c := range cases {
+		err := r.Run(context.Background(), config.App{}, c.name)
+		if !errors.Is(err, c.err) {
+			t.Errorf("job %s returned %v, want %v", c.name, err, c.err)
+		}
+		if code := ExitCode(err); code != c.code {
+			t.Errorf("job %s exited with %d, want %d", c.name, code, c.code)
+		}
+	}
+	if !slices.Equal(ran, []string{"ok", "failing"}) {
+		t.Errorf("ran %v, want ok then failing", ran)
+	}
+	if names := r.Names(); !slices.Equal(names, []string{"failing", "ok"}) {
+		t.Errorf("names %v, want them sorted", names)
+	}
+}
+
+func TestRegisterTakenName(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Job{Name: "seed"})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering seed twice did not panic")
+		}
+	}()
+	r.Register(Job{Name: "seed"})
+}
+
+func TestDefault(t *testing.T) {
+	r := Default()
+	for _, name := range r.Names() {
+		job, _ := r.Get(name)
+		if job.Run == nil || job.Description == "" {
+			t.Errorf("job %s has no run function or description", name)
+		}
+	}
+}
+
+func TestNewSchedulerRejectsSchedules(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Job{Name: "seed", Run: func(context.Context, config.App) error { return nil }})
+
+	_, err := NewScheduler(r, config.App{}, map[string]string{"missing": "@hourly"})
+	if !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("unknown job got %v, want ErrUnknownJob", err)
+	}
+
+	_, err = NewScheduler(r, config.App{}, map[string]string{"seed": "every hour"})
+	if err == nil || !strings.Contains(err.Error(), "invalid schedule") {
+		t.Errorf("invalid cron got %v, want it rejected", err)
+	}
+
+	s, err := NewScheduler(r, config.App{}, map[string]string{"seed": "0 3 * * *"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := s.cron.Entries(); len(entries) != 1 {
+		t.Errorf("scheduled %d entries, want 1", len(entries))
+	}
+}
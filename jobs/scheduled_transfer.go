@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+)
+
+// RunScheduledTransfers posts every due occurrence of the scheduled
+// transfers, then exits. Schedule it every minute or so, occurrences are
+// posted no earlier than the job runs.
+func RunScheduledTransfers(ctx context.Context, app config.App) error {
+	var (
+		scheduledTransferRepo = models.InitScheduledTransferRepo(app.DB.WithContext(ctx))
+		counts                = map[models.ScheduledTransferRunStatus]int{}
+	)
+
+	for ctx.Err() == nil {
+		run, err := scheduledTransferRepo.RunNext()
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			break
+		}
+		counts[run.Status]++
+	}
+
+	logger.Infof("scheduled transfers: %d succeeded, %d retrying, %d failed",
+		counts[models.ScheduledTransferRunSucceeded], counts[models.ScheduledTransferRunRetrying], counts[models.ScheduledTransferRunFailed])
+	return ctx.Err()
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// every instance runs the scheduler, the per job advisory lock keeps a
+// scheduled run to one instance at a time
+const jobLockPrefix = "coinpe:job:"
+
+// Scheduler runs registered jobs on cron expressions inside the server.
+type Scheduler struct {
+	cron     *cron.Cron
+	registry *Registry
+	app      config.App
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewScheduler schedules each job of schedules, which maps job names to
+// standard five field cron expressions or descriptors such as @hourly.
+func NewScheduler(registry *Registry, app config.App, schedules map[string]string) (*Scheduler, error) {
+	cronLogger := cron.PrintfLogger(logger.GetLogger())
+	s := &Scheduler{
+		cron: cron.New(cron.WithChain(
+			cron.Recover(cronLogger),
+			cron.SkipIfStillRunning(cronLogger),
+		)),
+		registry: registry,
+		app:      app,
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	for name, spec := range schedules {
+		if _, ok := registry.Get(name); !ok {
+			return nil, fmt.Errorf("%w %q in schedule, available jobs: %v", ErrUnknownJob, name, registry.Names())
+		}
+
+		_, err := s.cron.AddFunc(spec, func() { s.run(name) })
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q for job %s: %w", spec, name, err)
+		}
+		logger.Infof("scheduled job %s at %q", name, spec)
+	}
+
+	return s, nil
+}
+
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Shutdown stops scheduling and waits for running jobs until ctx is done,
+// then cancels them.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	stopped := s.cron.Stop()
+	defer s.cancel()
+
+	select {
+	case <-stopped.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) run(name string) {
+	sqlDB, err := s.app.DB.DB()
+	if err != nil {
+		logger.Error("unable to get database for job ", name, " | err: ", err)
+		return
+	}
+
+	conn, err := sqlDB.Conn(s.ctx)
+	if err != nil {
+		logger.Error("unable to get connection for job ", name, " | err: ", err)
+		return
+	}
+	defer conn.Close()
+
+	var locked bool
+	err = conn.QueryRowContext(s.ctx, "SELECT pg_try_advisory_lock(hashtext($1))", jobLockPrefix+name).Scan(&locked)
+	if err != nil {
+		logger.Error("unable to lock job ", name, " | err: ", err)
+		return
+	}
+	if !locked {
+		logger.Debug("job ", name, " is running on another instance, skipping")
+		return
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", jobLockPrefix+name)
+
+	start := time.Now()
+	err = s.registry.Run(s.ctx, s.app, name)
+	if err != nil {
+		logger.Error("job ", name, " failed after ", time.Since(start), " | err: ", err)
+		return
+	}
+	logger.Info("job ", name, " finished in ", time.Since(start))
+}
@@ -0,0 +1,11 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"context"
+)
+
+func Seed(ctx context.Context, app config.App) error {
+	return models.AddSystemData(app.DB.WithContext(ctx), app.Config.Environment)
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ShardWallets grows the treasury wallet to SHARDING_TREASURY_SHARDS shards
+// and every wallet in SHARDING_WALLETS to its shard count.
+func ShardWallets(ctx context.Context, app config.App) error {
+	walletRepo := models.InitWalletRepo(app.DB.WithContext(ctx))
+
+	shardCounts := map[string]int{models.CoinpeWallet.UUID: app.Config.Sharding.TreasuryShards}
+	for walletUUID, shardCount := range app.Config.Sharding.Wallets {
+		shardCounts[walletUUID] = shardCount
+	}
+
+	for walletUUID, shardCount := range shardCounts {
+		wallet, err := walletRepo.Get(&models.Wallet{UUID: walletUUID})
+		if err != nil {
+			return fmt.Errorf("wallet %s: %w", walletUUID, err)
+		}
+
+		wallet, err = walletRepo.Shard(wallet.ID, shardCount)
+		if err != nil {
+			return fmt.Errorf("wallet %s: %w", walletUUID, err)
+		}
+		logger.Infof("wallet %s has %d shards", wallet.UUID, wallet.ShardCount)
+	}
+	return nil
+}
+
+// RebalanceShards evens out the shards of every sharded wallet. Wallets
+// whose shards break an invariant are reported and left alone, and fail the
+// job.
+func RebalanceShards(ctx context.Context, app config.App) error {
+	var (
+		walletRepo = models.InitWalletRepo(app.DB.WithContext(ctx))
+		violations int
+	)
+
+	wallets, err := walletRepo.ShardedWallets()
+	if err != nil {
+		return err
+	}
+
+	for _, wallet := range wallets {
+		result, err := walletRepo.RebalanceShards(wallet.ID)
+		if errors.Is(err, models.ErrShardInvariant) {
+			logger.Warnf("wallet %s: %s", wallet.UUID, err)
+			violations++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		logger.Infof("wallet %s: balance %d over %d shards, moved %d in %d transfers",
+			result.WalletUUID, result.BalanceInCents, result.Shards, result.MovedInCents, result.Moves)
+	}
+
+	if violations > 0 {
+		return fmt.Errorf("%w in %d wallets", models.ErrShardInvariant, violations)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+	"time"
+)
+
+// SnapshotBalances snapshots the end of day balances of every finished UTC
+// day that has not been snapshotted yet. Schedule it a little after
+// midnight UTC so the last transactions of the day have committed.
+func SnapshotBalances(ctx context.Context, app config.App) error {
+	ledgerRepo := models.InitLedgerRepo(app.DB.WithContext(ctx))
+
+	day, ok, err := ledgerRepo.NextSnapshotDay()
+	if err != nil || !ok {
+		return err
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for ; day.Before(today); day = day.AddDate(0, 0, 1) {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		written, err := ledgerRepo.SnapshotBalances(day)
+		if err != nil {
+			return err
+		}
+		logger.Infof("snapshotted %d wallet balances for %s", written, day.Format("2006-01-02"))
+	}
+	return nil
+}
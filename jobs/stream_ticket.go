@@ -0,0 +1,19 @@
+package jobs
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/config"
+	"coinpe/pkg/logger"
+	"context"
+)
+
+// ExpireStreamTickets deletes the stream tickets that expired without being
+// redeemed. Redeem ignores them either way, the job keeps the table small.
+func ExpireStreamTickets(ctx context.Context, app config.App) error {
+	deleted, err := models.InitStreamTicketRepo(app.DB.WithContext(ctx)).DeleteExpired()
+	if err != nil {
+		return err
+	}
+	logger.Infof("deleted %d expired stream tickets", deleted)
+	return nil
+}
@@ -3,17 +3,25 @@ package main
 import (
 	"coinpe/controllers"
 	"coinpe/database"
+	"coinpe/grpcserver"
+	"coinpe/jobs"
+	"coinpe/migrations"
 	"coinpe/models"
 	"coinpe/pkg/config"
+	"coinpe/pkg/events"
 	"coinpe/pkg/graceful"
 	"coinpe/pkg/logger"
 	"coinpe/pkg/validator"
 	"coinpe/routers"
+	"coinpe/routers/middleware"
+	"context"
 	"net/http"
-	"time"
+	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	gormlogger "gorm.io/gorm/logger"
 )
 
@@ -35,42 +43,67 @@ func main() {
 		MigrationsLogLevel: gormlogger.Silent,
 	}
 
-	// Get DB connection
-	db, err := database.New(
-		cfg.MainDatabase,
+	dbOptions := []func(*database.DBConnOptions){
 		database.WithLogConfig(dbLogConfig),
-		database.WithMigrations(models.GetMigrationModel()),
-	)
+		database.WithReplica(cfg.ReaderDB),
+	}
+
+	// migrate jobs manage the schema themselves, everything else migrates first
+	job := viper.GetString("job")
+	if !strings.HasPrefix(job, "migrate") {
+		dbOptions = append(dbOptions, database.WithMigrations(migrations.FS))
+	}
+
+	// Get DB connection
+	db, err := database.New(cfg.MainDatabase, dbOptions...)
 	if err != nil {
 		logger.Fatalf("unable to get database connection, error: %s", err)
 	}
 
-	models.AddSystemData(db, cfg.Environment)
-
 	app := config.App{
 		Config: *cfg,
 		DB:     db,
 	}
 
+	registry := jobs.Default()
+	if job != "" {
+		err = registry.Run(context.Background(), app, job)
+		if err != nil {
+			logger.Errorf("job %s failed, error: %s", job, err)
+		}
+		os.Exit(jobs.ExitCode(err))
+	}
+
+	err = models.AddSystemData(db, cfg.Environment)
+	if err != nil {
+		logger.Fatalf("unable to add system data, error: %s", err)
+	}
+
 	ctrl := controllers.BaseController{
 		DB:     app.DB,
 		Config: app.Config,
 	}
 
-	validate, trans, err := validator.InitValidator()
+	validate, uni, err := validator.InitValidator()
 	if err != nil {
 		logger.Fatal("Unable to init validator ", err)
 	}
 
+	// Fan out committed wallet changes to balance streams on this instance
+	eventBroker := events.NewBroker(database.GetDSN(cfg.MainDatabase))
+	eventsCtx, stopEvents := context.WithCancel(context.Background())
+	go eventBroker.Run(eventsCtx)
+
 	//adding remaining values to the controller
-	ctrl.Translator = &trans
+	ctrl.Translator = uni
 	ctrl.Validator = validate
+	ctrl.Events = eventBroker
 
 	router := gin.New()
 	if app.Config.VPCProxyCIDR != "" {
 		router.SetTrustedProxies([]string{app.Config.VPCProxyCIDR})
 	}
-	router.Use(gin.Logger())
+	router.Use(middleware.RequestLogger())
 	router.Use(gin.Recovery())
 
 	app.Router = router
@@ -84,11 +117,34 @@ func main() {
 		Addr:    listenAddr,
 		Handler: app.Router,
 	}
+	// end open wallet event streams so they do not hold up Shutdown
+	server.RegisterOnShutdown(stopEvents)
 
 	graceful := graceful.Graceful{
-		HTTPServer:      server,
-		ShutdownTimeout: time.Duration(5 * time.Second),
-		State:           &graceful.ServerState{},
+		HTTPServer:         server,
+		ShutdownTimeout:    app.Config.Server.ShutdownTimeout,
+		WorkerDrainTimeout: app.Config.Server.WorkerDrainTimeout,
+		State:              &graceful.ServerState{},
+	}
+
+	if app.Config.Server.GRPCPort != "" {
+		graceful.GRPCServer = grpcserver.New(app)
+		graceful.GRPCAddr = ":" + app.Config.Server.GRPCPort
+	}
+
+	if app.Config.Scheduler.Enabled {
+		scheduler, err := jobs.NewScheduler(registry, app, app.Config.Scheduler.Schedules)
+		if err != nil {
+			logger.Fatal("unable to start job scheduler ", err)
+		}
+		scheduler.Start()
+		graceful.Workers = append(graceful.Workers, scheduler)
+	}
+
+	if app.Config.Queue.Workers > 0 {
+		queueWorker := jobs.NewQueueWorker(app)
+		queueWorker.Start()
+		graceful.Workers = append(graceful.Workers, queueWorker)
 	}
 
 	// You can generate ASCI art here
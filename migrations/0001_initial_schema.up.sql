@@ -0,0 +1,119 @@
+-- Baseline of the schema previously created by GORM AutoMigrate. Every
+-- statement is guarded so databases that were auto migrated adopt it as is.
+
+CREATE TABLE IF NOT EXISTS roles (
+	id             bigserial PRIMARY KEY,
+	created_at     timestamptz,
+	updated_at     timestamptz,
+	deleted_at     timestamptz,
+	display_name   text,
+	name           text,
+	description    text,
+	system_defined boolean DEFAULT true,
+	is_internal    boolean,
+	is_active      boolean,
+	is_default     boolean
+);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS permissions (
+	id          bigserial PRIMARY KEY,
+	created_at  timestamptz,
+	updated_at  timestamptz,
+	deleted_at  timestamptz,
+	name        text,
+	description text
+);
+CREATE INDEX IF NOT EXISTS idx_permissions_deleted_at ON permissions (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_name ON permissions (name);
+
+CREATE TABLE IF NOT EXISTS roles_permissions (
+	role_id       bigint NOT NULL,
+	permission_id bigint NOT NULL,
+	PRIMARY KEY (role_id, permission_id),
+	CONSTRAINT fk_roles_permissions_role FOREIGN KEY (role_id) REFERENCES roles (id),
+	CONSTRAINT fk_roles_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions (id)
+);
+
+CREATE TABLE IF NOT EXISTS accounts (
+	id           bigserial PRIMARY KEY,
+	created_at   timestamptz,
+	updated_at   timestamptz,
+	deleted_at   timestamptz,
+	uuid         text,
+	first_name   text,
+	last_name    text,
+	phone_number text,
+	email        text,
+	role_id      bigint NOT NULL,
+	CONSTRAINT uni_accounts_uuid UNIQUE (uuid),
+	CONSTRAINT uni_accounts_phone_number UNIQUE (phone_number),
+	CONSTRAINT fk_accounts_role FOREIGN KEY (role_id) REFERENCES roles (id)
+);
+CREATE INDEX IF NOT EXISTS idx_accounts_deleted_at ON accounts (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_accounts_role_id ON accounts (role_id);
+
+CREATE TABLE IF NOT EXISTS credentials (
+	id         bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	password   text NOT NULL,
+	type       text,
+	account_id bigint,
+	CONSTRAINT fk_accounts_credentials FOREIGN KEY (account_id) REFERENCES accounts (id)
+);
+CREATE INDEX IF NOT EXISTS idx_credentials_deleted_at ON credentials (deleted_at);
+
+CREATE TABLE IF NOT EXISTS wallets (
+	id                       bigserial PRIMARY KEY,
+	created_at               timestamptz,
+	updated_at               timestamptz,
+	deleted_at               timestamptz,
+	user_uuid                text NOT NULL,
+	uuid                     text NOT NULL,
+	total_balance_in_cents   bigint NOT NULL DEFAULT 0,
+	currency                 text NOT NULL,
+	additional_info          jsonb,
+	overdraft_limit_in_cents bigint,
+	CONSTRAINT uni_wallets_uuid UNIQUE (uuid)
+);
+CREATE INDEX IF NOT EXISTS idx_wallets_deleted_at ON wallets (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_user_uuid ON wallets (user_uuid);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	id                       bigserial PRIMARY KEY,
+	created_at               timestamptz,
+	updated_at               timestamptz,
+	deleted_at               timestamptz,
+	uuid                     text NOT NULL,
+	wallet_id                bigint NOT NULL,
+	type                     text NOT NULL,
+	amount_in_cents          bigint NOT NULL,
+	opening_balance_in_cents bigint,
+	closing_balance_in_cents bigint,
+	status                   text NOT NULL,
+	from_wallet_uuid         text,
+	to_wallet_uuid           text,
+	purpose_code             text NOT NULL,
+	reference_id             text,
+	description              text,
+	metadata                 jsonb,
+	CONSTRAINT uni_transactions_uuid UNIQUE (uuid)
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_wallet_id ON transactions (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_reference_id ON transactions (reference_id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	id              bigserial PRIMARY KEY,
+	created_at      timestamptz,
+	updated_at      timestamptz,
+	scope           text NOT NULL,
+	key             text NOT NULL,
+	request_hash    text NOT NULL,
+	response_status bigint,
+	response_body   bytea,
+	completed_at    timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idempotency_keys_scope_key ON idempotency_keys (scope, key);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS wallets_notify_wallet_event ON wallets;
+DROP FUNCTION IF EXISTS notify_wallet_event();
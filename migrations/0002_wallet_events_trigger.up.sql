@@ -0,0 +1,17 @@
+-- Notifies the wallet's UUID on the wallet_events channel whenever a wallet
+-- row is written. Every ledger posting updates the wallet in the same
+-- transaction as the transaction row, and postgres only delivers NOTIFY on
+-- commit, so listeners never observe uncommitted balances.
+
+CREATE OR REPLACE FUNCTION notify_wallet_event() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_notify('wallet_events', NEW.uuid);
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS wallets_notify_wallet_event ON wallets;
+
+CREATE TRIGGER wallets_notify_wallet_event
+	AFTER INSERT OR UPDATE ON wallets
+	FOR EACH ROW EXECUTE FUNCTION notify_wallet_event();
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS ledger_checkpoints;
+
+DROP INDEX IF EXISTS idx_transactions_unsealed;
+DROP INDEX IF EXISTS idx_transactions_global_sequence;
+DROP INDEX IF EXISTS idx_transactions_wallet_sequence;
+
+ALTER TABLE transactions
+	DROP COLUMN IF EXISTS global_hash,
+	DROP COLUMN IF EXISTS global_sequence,
+	DROP COLUMN IF EXISTS hash,
+	DROP COLUMN IF EXISTS prev_hash,
+	DROP COLUMN IF EXISTS wallet_sequence;
@@ -0,0 +1,91 @@
+-- Hash chains over the transaction log. Every transaction carries the hash
+-- of its contents and of the previous transaction of its wallet
+-- (wallet_sequence, prev_hash, hash), written when it is inserted. The global
+-- chain (global_sequence, global_hash) is sealed afterwards by the
+-- checkpoint-ledger job, which also signs ledger_checkpoints. Each run seals,
+-- in id order, the transactions committed when it starts, so one committing
+-- later is sealed by a later run, after higher ids: it is not commit order.
+
+ALTER TABLE transactions
+	ADD COLUMN wallet_sequence bigint,
+	ADD COLUMN prev_hash       text,
+	ADD COLUMN hash            text,
+	ADD COLUMN global_sequence bigint,
+	ADD COLUMN global_hash     text;
+
+-- Must match Transaction.ContentHash: every field is written as
+-- <byte length>:<value>, timestamps as unix microseconds.
+CREATE FUNCTION pg_temp.chain_field(v text) RETURNS text AS $$
+	SELECT octet_length(coalesce(v, ''))::text || ':' || coalesce(v, '')
+$$ LANGUAGE sql IMMUTABLE;
+
+DO $$
+DECLARE
+	t           record;
+	prev        text := '';
+	seq         bigint := 0;
+	wallet      bigint := NULL;
+	created_at  bigint;
+	content     text;
+	content_sum text;
+BEGIN
+	FOR t IN SELECT * FROM transactions ORDER BY wallet_id, id LOOP
+		IF wallet IS DISTINCT FROM t.wallet_id THEN
+			wallet := t.wallet_id;
+			prev := '';
+			seq := 0;
+		END IF;
+		seq := seq + 1;
+
+		created_at := coalesce(
+			extract(epoch FROM date_trunc('second', t.created_at))::bigint * 1000000
+				+ extract(microseconds FROM t.created_at)::bigint % 1000000,
+			0);
+
+		-- parent_wallet_id (0018_transaction_parent_wallet) is hashed after
+		-- wallet_id, no transaction has one yet
+		content := pg_temp.chain_field(t.uuid)
+			|| pg_temp.chain_field(t.wallet_id::text)
+			|| pg_temp.chain_field(NULL)
+			|| pg_temp.chain_field(seq::text)
+			|| pg_temp.chain_field(t.type)
+			|| pg_temp.chain_field(t.amount_in_cents::text)
+			|| pg_temp.chain_field(coalesce(t.opening_balance_in_cents, 0)::text)
+			|| pg_temp.chain_field(coalesce(t.closing_balance_in_cents, 0)::text)
+			|| pg_temp.chain_field(t.status)
+			|| pg_temp.chain_field(t.from_wallet_uuid)
+			|| pg_temp.chain_field(t.to_wallet_uuid)
+			|| pg_temp.chain_field(t.purpose_code)
+			|| pg_temp.chain_field(t.reference_id)
+			|| pg_temp.chain_field(t.description)
+			|| pg_temp.chain_field(created_at::text)
+			|| pg_temp.chain_field(prev);
+		content_sum := encode(sha256(convert_to(content, 'UTF8')), 'hex');
+
+		UPDATE transactions
+		SET wallet_sequence = seq, prev_hash = prev, hash = content_sum
+		WHERE id = t.id;
+
+		prev := content_sum;
+	END LOOP;
+END $$;
+
+ALTER TABLE transactions
+	ALTER COLUMN wallet_sequence SET NOT NULL,
+	ALTER COLUMN prev_hash SET NOT NULL,
+	ALTER COLUMN prev_hash SET DEFAULT '',
+	ALTER COLUMN hash SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_transactions_wallet_sequence ON transactions (wallet_id, wallet_sequence);
+CREATE UNIQUE INDEX idx_transactions_global_sequence ON transactions (global_sequence);
+CREATE INDEX idx_transactions_unsealed ON transactions (id) WHERE global_sequence IS NULL;
+
+CREATE TABLE ledger_checkpoints (
+	id              bigserial PRIMARY KEY,
+	created_at      timestamptz,
+	global_sequence bigint NOT NULL,
+	global_hash     text NOT NULL,
+	key_id          text NOT NULL,
+	signature       text NOT NULL
+);
+CREATE INDEX idx_ledger_checkpoints_global_sequence ON ledger_checkpoints (global_sequence);
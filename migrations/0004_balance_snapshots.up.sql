@@ -0,0 +1,14 @@
+-- End of day (UTC) balances of wallets with transactions that day, written
+-- by the snapshot-balances job. A point-in-time balance is the latest
+-- snapshot before it plus the transactions since.
+
+CREATE TABLE balance_snapshots (
+	id               bigserial PRIMARY KEY,
+	created_at       timestamptz,
+	wallet_id        bigint NOT NULL,
+	day              date NOT NULL,
+	balance_in_cents bigint NOT NULL
+);
+CREATE UNIQUE INDEX idx_balance_snapshots_wallet_day ON balance_snapshots (wallet_id, day);
+
+CREATE INDEX idx_transactions_wallet_created_at ON transactions (wallet_id, created_at);
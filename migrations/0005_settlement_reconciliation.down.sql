@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS settlement_records;
+DROP TABLE IF EXISTS settlement_imports;
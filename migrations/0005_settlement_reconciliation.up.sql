@@ -0,0 +1,47 @@
+-- Settlement files imported from banks and payment gateways. Each record is
+-- reconciled against the transaction it settles, matched_transaction_id is
+-- unique so a transaction settles at most one record.
+
+CREATE TABLE settlement_imports (
+	id           bigserial PRIMARY KEY,
+	created_at   timestamptz,
+	uuid         text NOT NULL,
+	source       text NOT NULL,
+	filename     text,
+	checksum     text NOT NULL,
+	imported_by  text NOT NULL,
+	record_count bigint NOT NULL,
+	settled_from timestamptz NOT NULL,
+	settled_to   timestamptz NOT NULL,
+	CONSTRAINT uni_settlement_imports_uuid UNIQUE (uuid)
+);
+CREATE UNIQUE INDEX idx_settlement_imports_source_checksum ON settlement_imports (source, checksum);
+
+CREATE TABLE settlement_records (
+	id                     bigserial PRIMARY KEY,
+	created_at             timestamptz,
+	updated_at             timestamptz,
+	uuid                   text NOT NULL,
+	import_id              bigint NOT NULL,
+	row_number             bigint NOT NULL,
+	reference              text,
+	external_id            text,
+	direction              text NOT NULL,
+	amount_in_cents        bigint NOT NULL,
+	currency               text NOT NULL,
+	settled_at             timestamptz NOT NULL,
+	description            text,
+	status                 text NOT NULL,
+	match_rule             text,
+	matched_transaction_id bigint,
+	detail                 text,
+	resolution             text,
+	resolution_note        text,
+	resolved_by            text,
+	resolved_at            timestamptz,
+	CONSTRAINT uni_settlement_records_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_settlement_records_import_id ON settlement_records (import_id);
+CREATE INDEX idx_settlement_records_reference ON settlement_records (reference);
+CREATE INDEX idx_settlement_records_status ON settlement_records (status);
+CREATE UNIQUE INDEX idx_settlement_records_matched_transaction_id ON settlement_records (matched_transaction_id);
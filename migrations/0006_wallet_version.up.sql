@@ -0,0 +1,4 @@
+-- Optimistic version of a wallet's balance. Every balance update bumps it
+-- and only applies when the version read is still current.
+
+ALTER TABLE wallets ADD COLUMN version bigint NOT NULL DEFAULT 0;
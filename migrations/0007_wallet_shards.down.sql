@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_wallets_parent_shard;
+DROP INDEX IF EXISTS idx_wallets_parent_wallet_id;
+ALTER TABLE wallets
+	DROP COLUMN IF EXISTS shard_count,
+	DROP COLUMN IF EXISTS shard_index,
+	DROP COLUMN IF EXISTS parent_wallet_id;
@@ -0,0 +1,11 @@
+-- Sharded wallets. A wallet with shard_count > 1 spreads its balance over
+-- its own row, shard 0, and shard_count - 1 shard wallets whose
+-- parent_wallet_id points at it. Every shard keeps its own ledger.
+
+ALTER TABLE wallets
+	ADD COLUMN parent_wallet_id bigint,
+	ADD COLUMN shard_index      bigint NOT NULL DEFAULT 0,
+	ADD COLUMN shard_count      bigint NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_wallets_parent_wallet_id ON wallets (parent_wallet_id);
+CREATE UNIQUE INDEX idx_wallets_parent_shard ON wallets (parent_wallet_id, shard_index) WHERE parent_wallet_id IS NOT NULL;
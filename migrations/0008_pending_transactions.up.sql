@@ -0,0 +1,34 @@
+-- Transfers queued for the queue workers. Workers claim due rows with
+-- FOR UPDATE SKIP LOCKED and post them in the claiming transaction, so a
+-- row stays QUEUED until its transfer commits.
+
+CREATE TABLE pending_transactions (
+	id               bigserial PRIMARY KEY,
+	created_at       timestamptz,
+	updated_at       timestamptz,
+	uuid             text NOT NULL,
+	batch_uuid       text,
+	submitted_by     text NOT NULL,
+	from_wallet_id   bigint NOT NULL,
+	from_wallet_uuid text NOT NULL,
+	to_wallet_id     bigint NOT NULL,
+	to_wallet_uuid   text NOT NULL,
+	amount_in_cents  bigint NOT NULL,
+	purpose_code     text NOT NULL,
+	description      text,
+	status           text NOT NULL,
+	run_after        timestamptz NOT NULL,
+	attempts         bigint NOT NULL DEFAULT 0,
+	reference_id     text,
+	error            text,
+	processed_at     timestamptz,
+	webhook_url      text,
+	webhook_status   text,
+	webhook_attempts bigint NOT NULL DEFAULT 0,
+	webhook_after    timestamptz,
+	CONSTRAINT uni_pending_transactions_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_pending_transactions_batch_uuid ON pending_transactions (batch_uuid);
+CREATE INDEX idx_pending_transactions_submitted_by ON pending_transactions (submitted_by);
+CREATE INDEX idx_pending_transactions_due ON pending_transactions (status, run_after);
+CREATE INDEX idx_pending_transactions_webhook_due ON pending_transactions (webhook_status, webhook_after);
@@ -0,0 +1,45 @@
+-- Payouts credit many wallets from the treasury. Every row of the payout
+-- file is a payout item, posted in chunks by the queue workers; an item is
+-- PENDING until its transfer commits, or fails for good after attempts
+-- errors of its own.
+
+CREATE TABLE payouts (
+	id                 bigserial PRIMARY KEY,
+	created_at         timestamptz,
+	updated_at         timestamptz,
+	uuid               text NOT NULL,
+	created_by         text NOT NULL,
+	filename           text,
+	from_wallet_id     bigint NOT NULL,
+	purpose_code       text NOT NULL,
+	description        text,
+	status             text NOT NULL,
+	item_count         bigint NOT NULL,
+	total_in_cents     bigint NOT NULL,
+	succeeded_count    bigint NOT NULL DEFAULT 0,
+	succeeded_in_cents bigint NOT NULL DEFAULT 0,
+	failed_count       bigint NOT NULL DEFAULT 0,
+	completed_at       timestamptz,
+	CONSTRAINT uni_payouts_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_payouts_status ON payouts (status);
+
+CREATE TABLE payout_items (
+	id              bigserial PRIMARY KEY,
+	created_at      timestamptz,
+	updated_at      timestamptz,
+	uuid            text NOT NULL,
+	payout_id       bigint NOT NULL,
+	row_number      bigint NOT NULL,
+	wallet_id       bigint NOT NULL,
+	wallet_uuid     text NOT NULL,
+	amount_in_cents bigint NOT NULL,
+	description     text,
+	status          text NOT NULL,
+	reference_id    text,
+	error           text,
+	attempts        bigint NOT NULL DEFAULT 0,
+	processed_at    timestamptz,
+	CONSTRAINT uni_payout_items_uuid UNIQUE (uuid)
+);
+CREATE UNIQUE INDEX idx_payout_items_payout_row ON payout_items (payout_id, row_number);
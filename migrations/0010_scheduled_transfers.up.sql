@@ -0,0 +1,48 @@
+-- Scheduled transfers are standing instructions posted by the
+-- run-scheduled-transfers job. Each occurrence has at most one run, which
+-- keeps it from being posted twice.
+
+CREATE TABLE scheduled_transfers (
+	id                     bigserial PRIMARY KEY,
+	created_at             timestamptz,
+	updated_at             timestamptz,
+	uuid                   text NOT NULL,
+	account_uuid           text NOT NULL,
+	from_wallet_id         bigint NOT NULL,
+	from_wallet_uuid       text NOT NULL,
+	to_wallet_id           bigint NOT NULL,
+	to_wallet_uuid         text NOT NULL,
+	amount_in_cents        bigint NOT NULL,
+	description            text,
+	recurrence             text,
+	timezone               text NOT NULL,
+	start_at               timestamptz NOT NULL,
+	end_at                 timestamptz,
+	max_retries            bigint NOT NULL,
+	retry_interval_seconds bigint NOT NULL,
+	status                 text NOT NULL,
+	next_occurrence_at     timestamptz,
+	next_run_at            timestamptz,
+	retries                bigint NOT NULL DEFAULT 0,
+	succeeded_count        bigint NOT NULL DEFAULT 0,
+	failed_count           bigint NOT NULL DEFAULT 0,
+	last_run_at            timestamptz,
+	CONSTRAINT uni_scheduled_transfers_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_scheduled_transfers_account_uuid ON scheduled_transfers (account_uuid);
+CREATE INDEX idx_scheduled_transfers_due ON scheduled_transfers (status, next_run_at);
+
+CREATE TABLE scheduled_transfer_runs (
+	id                    bigserial PRIMARY KEY,
+	created_at            timestamptz,
+	updated_at            timestamptz,
+	uuid                  text NOT NULL,
+	scheduled_transfer_id bigint NOT NULL,
+	occurrence_at         timestamptz NOT NULL,
+	status                text NOT NULL,
+	attempts              bigint NOT NULL,
+	reference_id          text,
+	error                 text,
+	CONSTRAINT uni_scheduled_transfer_runs_uuid UNIQUE (uuid)
+);
+CREATE UNIQUE INDEX idx_scheduled_transfer_runs_occurrence ON scheduled_transfer_runs (scheduled_transfer_id, occurrence_at);
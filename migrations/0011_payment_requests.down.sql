@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payment_requests;
+DROP TABLE IF EXISTS split_bills;
@@ -0,0 +1,43 @@
+-- Payment requests ask a wallet's owner to pay the requester, split bills
+-- fan one bill out to several of them.
+
+CREATE TABLE split_bills (
+	id                     bigserial PRIMARY KEY,
+	created_at             timestamptz,
+	updated_at             timestamptz,
+	uuid                   text NOT NULL,
+	requester_account_uuid text NOT NULL,
+	requester_wallet_uuid  text NOT NULL,
+	total_in_cents         bigint NOT NULL,
+	note                   text,
+	expires_at             timestamptz NOT NULL,
+	CONSTRAINT uni_split_bills_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_split_bills_requester_account_uuid ON split_bills (requester_account_uuid);
+
+CREATE TABLE payment_requests (
+	id                     bigserial PRIMARY KEY,
+	created_at             timestamptz,
+	updated_at             timestamptz,
+	uuid                   text NOT NULL,
+	split_bill_id          bigint,
+	split_bill_uuid        text,
+	requester_account_uuid text NOT NULL,
+	requester_wallet_id    bigint NOT NULL,
+	requester_wallet_uuid  text NOT NULL,
+	payer_account_uuid     text NOT NULL,
+	payer_wallet_id        bigint NOT NULL,
+	payer_wallet_uuid      text NOT NULL,
+	amount_in_cents        bigint NOT NULL,
+	note                   text,
+	status                 text NOT NULL,
+	expires_at             timestamptz NOT NULL,
+	responded_at           timestamptz,
+	decline_reason         text,
+	reference_id           text,
+	CONSTRAINT uni_payment_requests_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_payment_requests_split_bill_id ON payment_requests (split_bill_id);
+CREATE INDEX idx_payment_requests_requester_account_uuid ON payment_requests (requester_account_uuid);
+CREATE INDEX idx_payment_requests_payer_account_uuid ON payment_requests (payer_account_uuid);
+CREATE INDEX idx_payment_requests_expiry ON payment_requests (status, expires_at);
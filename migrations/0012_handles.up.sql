@@ -0,0 +1,14 @@
+-- Handles name wallets for payers, like a VPA, one per wallet.
+
+CREATE TABLE handles (
+	id           bigserial PRIMARY KEY,
+	created_at   timestamptz,
+	updated_at   timestamptz,
+	handle       text NOT NULL,
+	account_uuid text NOT NULL,
+	wallet_id    bigint NOT NULL,
+	wallet_uuid  text NOT NULL,
+	CONSTRAINT uni_handles_handle UNIQUE (handle)
+);
+CREATE INDEX idx_handles_account_uuid ON handles (account_uuid);
+CREATE UNIQUE INDEX idx_handles_wallet_id ON handles (wallet_id);
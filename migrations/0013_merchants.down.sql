@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS merchant_webhooks;
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS checkout_sessions;
+DROP TABLE IF EXISTS merchants;
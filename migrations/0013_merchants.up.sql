@@ -0,0 +1,78 @@
+-- Merchants take payments through checkout sessions, refund them and are
+-- told about both by webhooks.
+
+CREATE TABLE merchants (
+	id             bigserial PRIMARY KEY,
+	created_at     timestamptz,
+	updated_at     timestamptz,
+	uuid           text NOT NULL,
+	account_uuid   text NOT NULL,
+	wallet_id      bigint NOT NULL,
+	wallet_uuid    text NOT NULL,
+	name           text NOT NULL,
+	webhook_url    text,
+	webhook_secret text NOT NULL,
+	CONSTRAINT uni_merchants_uuid UNIQUE (uuid),
+	CONSTRAINT uni_merchants_account_uuid UNIQUE (account_uuid)
+);
+
+CREATE TABLE checkout_sessions (
+	id                    bigserial PRIMARY KEY,
+	created_at            timestamptz,
+	updated_at            timestamptz,
+	uuid                  text NOT NULL,
+	merchant_id           bigint NOT NULL,
+	merchant_uuid         text NOT NULL,
+	merchant_wallet_id    bigint NOT NULL,
+	merchant_wallet_uuid  text NOT NULL,
+	order_id              text NOT NULL,
+	amount_in_cents       bigint NOT NULL,
+	currency              text NOT NULL,
+	description           text,
+	success_url           text,
+	status                text NOT NULL,
+	expires_at            timestamptz NOT NULL,
+	customer_account_uuid text,
+	customer_wallet_id    bigint,
+	customer_wallet_uuid  text,
+	reference_id          text,
+	paid_at               timestamptz,
+	refunded_in_cents     bigint NOT NULL DEFAULT 0,
+	CONSTRAINT uni_checkout_sessions_uuid UNIQUE (uuid)
+);
+CREATE UNIQUE INDEX idx_checkout_sessions_order ON checkout_sessions (merchant_id, order_id);
+CREATE INDEX idx_checkout_sessions_customer_account_uuid ON checkout_sessions (customer_account_uuid);
+
+CREATE TABLE refunds (
+	id                    bigserial PRIMARY KEY,
+	created_at            timestamptz,
+	uuid                  text NOT NULL,
+	checkout_session_id   bigint NOT NULL,
+	checkout_session_uuid text NOT NULL,
+	merchant_id           bigint NOT NULL,
+	amount_in_cents       bigint NOT NULL,
+	reason                text,
+	reference_id          text NOT NULL,
+	CONSTRAINT uni_refunds_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_refunds_checkout_session_id ON refunds (checkout_session_id);
+CREATE INDEX idx_refunds_merchant_id ON refunds (merchant_id);
+
+CREATE TABLE merchant_webhooks (
+	id                  bigserial PRIMARY KEY,
+	created_at          timestamptz,
+	updated_at          timestamptz,
+	uuid                text NOT NULL,
+	merchant_id         bigint NOT NULL,
+	event               text NOT NULL,
+	checkout_session_id bigint NOT NULL,
+	refund_id           bigint,
+	url                 text NOT NULL,
+	status              text NOT NULL,
+	attempts            bigint NOT NULL DEFAULT 0,
+	deliver_after       timestamptz NOT NULL,
+	last_error          text,
+	CONSTRAINT uni_merchant_webhooks_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_merchant_webhooks_merchant_id ON merchant_webhooks (merchant_id);
+CREATE INDEX idx_merchant_webhooks_due ON merchant_webhooks (status, deliver_after);
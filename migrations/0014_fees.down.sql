@@ -0,0 +1,2 @@
+ALTER TABLE checkout_sessions DROP COLUMN IF EXISTS merchant_fee_in_cents;
+DROP TABLE IF EXISTS fee_schedules;
@@ -0,0 +1,27 @@
+-- Fee schedules set the fees charged on transfers, posted as separate legs
+-- to the fee revenue wallet.
+
+CREATE TABLE fee_schedules (
+	id            bigserial PRIMARY KEY,
+	created_at    timestamptz,
+	updated_at    timestamptz,
+	deleted_at    timestamptz,
+	uuid          text NOT NULL,
+	name          text NOT NULL,
+	purpose_code  text,
+	role          text,
+	merchant_id   bigint,
+	merchant_uuid text,
+	payer         text NOT NULL,
+	type          text NOT NULL,
+	flat_in_cents bigint NOT NULL DEFAULT 0,
+	basis_points  bigint NOT NULL DEFAULT 0,
+	tiers         jsonb,
+	min_in_cents  bigint NOT NULL DEFAULT 0,
+	max_in_cents  bigint NOT NULL DEFAULT 0,
+	CONSTRAINT uni_fee_schedules_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_fee_schedules_deleted_at ON fee_schedules (deleted_at);
+CREATE INDEX idx_fee_schedules_merchant_id ON fee_schedules (merchant_id);
+
+ALTER TABLE checkout_sessions ADD COLUMN merchant_fee_in_cents bigint NOT NULL DEFAULT 0;
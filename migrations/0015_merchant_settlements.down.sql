@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS settlement_batches;
+ALTER TABLE refunds
+	DROP COLUMN IF EXISTS merchant_fee_in_cents,
+	DROP COLUMN IF EXISTS wallet_id;
+DROP INDEX IF EXISTS idx_checkout_sessions_settlement_due;
+DROP INDEX IF EXISTS idx_checkout_sessions_settlement_batch_id;
+ALTER TABLE checkout_sessions
+	DROP COLUMN IF EXISTS receivable_in_cents,
+	DROP COLUMN IF EXISTS settlement_batch_uuid,
+	DROP COLUMN IF EXISTS settlement_batch_id,
+	DROP COLUMN IF EXISTS settlement_status;
+ALTER TABLE merchants
+	DROP COLUMN IF EXISTS pending_wallet_uuid,
+	DROP COLUMN IF EXISTS pending_wallet_id;
@@ -0,0 +1,45 @@
+-- Checkout payments wait in a pending wallet of their merchant until a
+-- settlement batch nets their refunds and fees and moves the rest to the
+-- merchant's wallet.
+
+ALTER TABLE merchants
+	ADD COLUMN pending_wallet_id bigint,
+	ADD COLUMN pending_wallet_uuid text;
+
+ALTER TABLE checkout_sessions
+	ADD COLUMN settlement_status text,
+	ADD COLUMN settlement_batch_id bigint,
+	ADD COLUMN settlement_batch_uuid text,
+	ADD COLUMN receivable_in_cents bigint NOT NULL DEFAULT 0;
+CREATE INDEX idx_checkout_sessions_settlement_batch_id ON checkout_sessions (settlement_batch_id);
+CREATE INDEX idx_checkout_sessions_settlement_due ON checkout_sessions (paid_at) WHERE settlement_status = 'PENDING';
+
+-- refunds so far were paid from the merchant's wallet
+ALTER TABLE refunds
+	ADD COLUMN wallet_id bigint,
+	ADD COLUMN merchant_fee_in_cents bigint NOT NULL DEFAULT 0;
+UPDATE refunds SET wallet_id = checkout_sessions.merchant_wallet_id
+	FROM checkout_sessions WHERE checkout_sessions.id = refunds.checkout_session_id;
+ALTER TABLE refunds ALTER COLUMN wallet_id SET NOT NULL;
+
+CREATE TABLE settlement_batches (
+	id                  bigserial PRIMARY KEY,
+	created_at          timestamptz,
+	uuid                text NOT NULL,
+	merchant_id         bigint NOT NULL,
+	merchant_uuid       text NOT NULL,
+	pending_wallet_id   bigint NOT NULL,
+	pending_wallet_uuid text NOT NULL,
+	wallet_id           bigint NOT NULL,
+	wallet_uuid         text NOT NULL,
+	currency            text NOT NULL,
+	cutoff_at           timestamptz NOT NULL,
+	session_count       bigint NOT NULL,
+	gross_in_cents      bigint NOT NULL,
+	refunds_in_cents    bigint NOT NULL,
+	fees_in_cents       bigint NOT NULL,
+	net_in_cents        bigint NOT NULL,
+	reference_id        text,
+	CONSTRAINT uni_settlement_batches_uuid UNIQUE (uuid)
+);
+CREATE INDEX idx_settlement_batches_merchant_id ON settlement_batches (merchant_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_idempotency_keys_locked_until;
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+ALTER TABLE idempotency_keys
+	DROP COLUMN IF EXISTS expires_at,
+	DROP COLUMN IF EXISTS locked_until;
@@ -0,0 +1,15 @@
+-- Reservations of idempotency keys end at locked_until, so a key whose
+-- request died with its process can be taken over, and completed keys
+-- expire at expires_at.
+
+ALTER TABLE idempotency_keys
+	ADD COLUMN locked_until timestamptz,
+	ADD COLUMN expires_at timestamptz;
+
+UPDATE idempotency_keys SET locked_until = COALESCE(created_at, now()) + interval '5 minutes'
+	WHERE completed_at IS NULL;
+UPDATE idempotency_keys SET expires_at = completed_at + interval '24 hours'
+	WHERE completed_at IS NOT NULL;
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+CREATE INDEX idx_idempotency_keys_locked_until ON idempotency_keys (locked_until) WHERE completed_at IS NULL;
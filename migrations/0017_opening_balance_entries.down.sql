@@ -0,0 +1,7 @@
+-- Opening entries that were already sealed into the global chain stay, as
+-- deleting them would break it.
+DELETE FROM transactions
+WHERE uuid LIKE 'txn\_opening%'
+	AND wallet_sequence = 1
+	AND purpose_code = 'ADD_FUNDS'
+	AND global_sequence IS NULL;
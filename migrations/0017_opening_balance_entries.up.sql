@@ -0,0 +1,65 @@
+-- Wallets funded before the ledger was checked, such as the treasury seeded
+-- at 100000 by the first releases, hold a balance without a transaction
+-- behind it and fail verify-ledger. Each wallet with a positive balance and
+-- no transactions at all gets one opening ADD_FUNDS credit, dated when the
+-- wallet was created, so the ledger, point-in-time balances and statements
+-- account for it. Wallets with some history or a negative balance are left
+-- to verify-ledger --repair, which posts an audited adjustment.
+--
+-- The entry is the first of its wallet's chain, hashed like
+-- Transaction.ContentHash (see 0003_transaction_hash_chain). The global chain
+-- seals it on the next checkpoint-ledger run.
+
+CREATE FUNCTION pg_temp.chain_field(v text) RETURNS text AS $$
+	SELECT octet_length(coalesce(v, ''))::text || ':' || coalesce(v, '')
+$$ LANGUAGE sql IMMUTABLE;
+
+DO $$
+DECLARE
+	w           record;
+	tx_uuid     text;
+	tx_time     timestamptz;
+	created_at  bigint;
+	content     text;
+BEGIN
+	FOR w IN
+		SELECT * FROM wallets
+		WHERE total_balance_in_cents > 0
+			AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.wallet_id = wallets.id)
+		ORDER BY id
+	LOOP
+		tx_uuid := 'txn_opening' || lpad(w.id::text, 13, '0');
+		tx_time := coalesce(w.created_at, now());
+		created_at := extract(epoch FROM date_trunc('second', tx_time))::bigint * 1000000
+			+ extract(microseconds FROM tx_time)::bigint % 1000000;
+
+		content := pg_temp.chain_field(tx_uuid)
+			|| pg_temp.chain_field(w.id::text)
+			|| pg_temp.chain_field('')
+			|| pg_temp.chain_field('1')
+			|| pg_temp.chain_field('CREDIT')
+			|| pg_temp.chain_field(w.total_balance_in_cents::text)
+			|| pg_temp.chain_field('0')
+			|| pg_temp.chain_field(w.total_balance_in_cents::text)
+			|| pg_temp.chain_field('SUCCESS')
+			|| pg_temp.chain_field('')
+			|| pg_temp.chain_field(w.uuid)
+			|| pg_temp.chain_field('ADD_FUNDS')
+			|| pg_temp.chain_field('')
+			|| pg_temp.chain_field('opening balance')
+			|| pg_temp.chain_field(created_at::text)
+			|| pg_temp.chain_field('');
+
+		INSERT INTO transactions (
+			created_at, updated_at, uuid, wallet_id, type, amount_in_cents,
+			opening_balance_in_cents, closing_balance_in_cents, status,
+			from_wallet_uuid, to_wallet_uuid, purpose_code, reference_id, description,
+			wallet_sequence, prev_hash, hash
+		) VALUES (
+			tx_time, tx_time, tx_uuid, w.id, 'CREDIT', w.total_balance_in_cents,
+			0, w.total_balance_in_cents, 'SUCCESS',
+			'', w.uuid, 'ADD_FUNDS', '', 'opening balance',
+			1, '', encode(sha256(convert_to(content, 'UTF8')), 'hex')
+		);
+	END LOOP;
+END $$;
@@ -0,0 +1,9 @@
+CREATE OR REPLACE FUNCTION notify_wallet_event() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_notify('wallet_events', NEW.uuid);
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_transactions_parent_wallet_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS parent_wallet_id;
@@ -0,0 +1,24 @@
+-- Legs posted to a shard of a sharded wallet (see 0007_wallet_shards) carry
+-- the shard's wallet_id. parent_wallet_id records the wallet they belong to,
+-- so history, statements and event streams of the wallet include them. It is
+-- NULL for legs posted to an unsharded wallet or to shard 0, the wallet's own
+-- row. Transaction.ContentHash covers it, so a leg cannot be moved to another
+-- wallet without breaking its chain. Shards (0007_wallet_shards) are created
+-- by the server once every migration has run, so there are no legs to
+-- backfill.
+
+ALTER TABLE transactions ADD COLUMN parent_wallet_id bigint;
+
+CREATE INDEX idx_transactions_parent_wallet_id ON transactions (parent_wallet_id) WHERE parent_wallet_id IS NOT NULL;
+
+-- A change to a shard is a change to its wallet, notify the wallet's UUID.
+CREATE OR REPLACE FUNCTION notify_wallet_event() RETURNS trigger AS $$
+BEGIN
+	IF NEW.parent_wallet_id IS NULL THEN
+		PERFORM pg_notify('wallet_events', NEW.uuid);
+	ELSE
+		PERFORM pg_notify('wallet_events', uuid) FROM wallets WHERE id = NEW.parent_wallet_id;
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
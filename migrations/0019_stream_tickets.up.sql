@@ -0,0 +1,17 @@
+-- Browser EventSource and WebSocket clients cannot send an Authorization
+-- header. They open wallet event streams with a ticket instead: issued to an
+-- authenticated account, valid for a few seconds and redeemed once, so the
+-- query string logged by proxies holds nothing worth replaying. Only the
+-- SHA-256 of the ticket is stored.
+
+CREATE TABLE stream_tickets (
+	id           bigserial PRIMARY KEY,
+	created_at   timestamptz,
+	ticket_hash  text NOT NULL,
+	account_uuid text NOT NULL,
+	role         text NOT NULL,
+	expires_at   timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_stream_tickets_ticket_hash ON stream_tickets (ticket_hash);
+CREATE INDEX idx_stream_tickets_expires_at ON stream_tickets (expires_at);
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migrations of the CoinPe
+// schema. Files are named <version>_<name>.up.sql with a matching .down.sql,
+// versions are applied in order by database.Migrator and an applied file must
+// never be edited; add a new version instead.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -3,6 +3,7 @@ package models
 import (
 	"coinpe/pkg/logger"
 	"coinpe/pkg/utils"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -62,6 +63,16 @@ func (a *Account) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// DisplayName is the name shown to those paying a, its handle when it has
+// no name.
+func (a *Account) DisplayName(handle string) string {
+	name := strings.TrimSpace(a.FirstName + " " + a.LastName)
+	if name == "" {
+		return handle
+	}
+	return name
+}
+
 func (ar *accountRepo) Get(where *Account) (*Account, error) {
 	return ar.GetWithTx(ar.db, where)
 }
@@ -0,0 +1,420 @@
+package models
+
+import (
+	"coinpe/database"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntityCheckoutSession = "cs_"
+	EntityRefund          = "rf_"
+
+	checkoutOrderUniqueIndex   = "idx_checkout_sessions_order"
+	checkoutSessionDescription = "order "
+)
+
+type CheckoutSessionStatus string
+
+const (
+	CheckoutSessionOpen      CheckoutSessionStatus = "OPEN"
+	CheckoutSessionCompleted CheckoutSessionStatus = "COMPLETED"
+	// every coin paid has been refunded
+	CheckoutSessionRefunded  CheckoutSessionStatus = "REFUNDED"
+	CheckoutSessionCancelled CheckoutSessionStatus = "CANCELLED"
+	CheckoutSessionExpired   CheckoutSessionStatus = "EXPIRED"
+)
+
+var (
+	ErrCheckoutOrderExists    = errorConst.New(errorConst.ErrorConflict, errorConst.MsgOrderAlreadyHasACheckoutSession)
+	ErrCheckoutSessionNotOpen = errorConst.New(errorConst.ErrorConflict, errorConst.MsgCheckoutSessionIsNoLongerOpen)
+	ErrCheckoutSessionExpired = errorConst.New(errorConst.ErrorConflict, errorConst.MsgCheckoutSessionHasExpired)
+	ErrCheckoutSessionNotPaid = errorConst.New(errorConst.ErrorConflict, errorConst.MsgCheckoutSessionHasNotBeenPaid)
+	ErrCheckoutSelfPayment    = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgMerchantPaysOwnCheckoutSession)
+	ErrRefundExceedsPayment   = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgRefundExceedsPayment)
+	ErrCheckoutWalletNotFound = errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgMerchantWalletNotFound)
+)
+
+// CheckoutSession asks a customer to pay AmountInCents to a merchant for
+// its order OrderID. It is OPEN until a customer pays it (COMPLETED, with
+// the ReferenceID of the payment), the merchant cancels it or ExpiresAt
+// passes. The merchant can then refund it, in parts, up to what was paid.
+// The payment waits in the merchant's pending wallet, PENDING settlement,
+// until a settlement batch moves ReceivableInCents, what is left of it
+// after the fees and refunds paid from there, to MerchantWalletID.
+type CheckoutSession struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID               string `json:"uuid" gorm:"unique;not null"`
+	MerchantID         uint64 `json:"merchant_id" gorm:"not null;uniqueIndex:idx_checkout_sessions_order"`
+	MerchantUUID       string `json:"merchant_uuid" gorm:"not null"`
+	MerchantWalletID   uint64 `json:"merchant_wallet_id" gorm:"not null"`
+	MerchantWalletUUID string `json:"merchant_wallet_uuid" gorm:"not null"`
+	OrderID            string `json:"order_id" gorm:"not null;uniqueIndex:idx_checkout_sessions_order"`
+	AmountInCents      int    `json:"amount_in_cents" gorm:"not null"`
+	Currency           string `json:"currency" gorm:"not null"`
+	Description        string `json:"description,omitempty"`
+	SuccessURL         string `json:"success_url,omitempty"`
+
+	Status              CheckoutSessionStatus `json:"status" gorm:"not null"`
+	ExpiresAt           time.Time             `json:"expires_at" gorm:"not null"`
+	CustomerAccountUUID string                `json:"customer_account_uuid,omitempty" gorm:"index"`
+	CustomerWalletID    *uint64               `json:"customer_wallet_id,omitempty"`
+	CustomerWalletUUID  string                `json:"customer_wallet_uuid,omitempty"`
+	ReferenceID         string                `json:"reference_id,omitempty"`
+	PaidAt              *time.Time            `json:"paid_at,omitempty"`
+	RefundedInCents     int                   `json:"refunded_in_cents" gorm:"not null;default:0"`
+
+	// fee the merchant paid on the payment, see TransferWithFeeTx
+	MerchantFeeInCents int `json:"merchant_fee_in_cents" gorm:"not null;default:0"`
+
+	SettlementStatus    SettlementStatus `json:"settlement_status,omitempty"`
+	SettlementBatchID   *uint64          `json:"settlement_batch_id,omitempty" gorm:"index"`
+	SettlementBatchUUID string           `json:"settlement_batch_uuid,omitempty"`
+	ReceivableInCents   int              `json:"receivable_in_cents" gorm:"not null;default:0"`
+}
+
+// Refund returns AmountInCents of a paid checkout session to the customer,
+// from WalletID: the merchant's pending wallet while the payment there
+// covers it, its available wallet otherwise.
+type Refund struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	UUID                string `json:"uuid" gorm:"unique;not null"`
+	CheckoutSessionID   uint64 `json:"checkout_session_id" gorm:"not null;index"`
+	CheckoutSessionUUID string `json:"checkout_session_uuid" gorm:"not null"`
+	MerchantID          uint64 `json:"merchant_id" gorm:"not null;index"`
+	AmountInCents       int    `json:"amount_in_cents" gorm:"not null"`
+	Reason              string `json:"reason,omitempty"`
+	ReferenceID         string `json:"reference_id" gorm:"not null"`
+	WalletID            uint64 `json:"wallet_id" gorm:"not null"`
+
+	// fee the merchant paid on the refund, see TransferWithFeeTx
+	MerchantFeeInCents int `json:"merchant_fee_in_cents" gorm:"not null;default:0"`
+}
+
+type checkoutSessionRepo struct {
+	db *gorm.DB
+}
+
+func (s *CheckoutSession) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.UUID == "" {
+		s.UUID, err = utils.GenerateNanoID(20, EntityCheckoutSession)
+	}
+	return err
+}
+
+func (r *Refund) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.UUID == "" {
+		r.UUID, err = utils.GenerateNanoID(20, EntityRefund)
+	}
+	return err
+}
+
+// CurrentStatus is the status of s, EXPIRED once an OPEN session is past
+// ExpiresAt.
+func (s *CheckoutSession) CurrentStatus() CheckoutSessionStatus {
+	if s.Status == CheckoutSessionOpen && !time.Now().Before(s.ExpiresAt) {
+		return CheckoutSessionExpired
+	}
+	return s.Status
+}
+
+// Create implements ICheckoutSession. It stores s OPEN, or
+// ErrCheckoutOrderExists when its merchant has a session for the order.
+func (r *checkoutSessionRepo) Create(s *CheckoutSession) error {
+	s.Status = CheckoutSessionOpen
+	err := r.db.Create(s).Error
+	if database.IsUniqueViolation(err, checkoutOrderUniqueIndex) {
+		return ErrCheckoutOrderExists
+	}
+	if err != nil {
+		logger.Error("unable to create checkout session | err: ", err)
+		return err
+	}
+	return nil
+}
+
+// Get implements ICheckoutSession.
+func (r *checkoutSessionRepo) Get(where *CheckoutSession) (*CheckoutSession, error) {
+	var s CheckoutSession
+	err := r.db.Where(where).First(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List implements ICheckoutSession. It returns the checkout sessions of a
+// merchant newest first, only those in status when it is set.
+func (r *checkoutSessionRepo) List(merchantID uint64, status CheckoutSessionStatus, beforeID uint64, limit int) ([]CheckoutSession, error) {
+	sessions := []CheckoutSession{}
+	builder := database.Replica(r.db).Where(&CheckoutSession{MerchantID: merchantID})
+
+	// sessions past their expiry are EXPIRED whether marked or not
+	now := time.Now()
+	switch status {
+	case "":
+	case CheckoutSessionOpen:
+		builder = builder.Where("status = ? AND expires_at > ?", status, now)
+	case CheckoutSessionExpired:
+		builder = builder.Where("status = ? OR (status = ? AND expires_at <= ?)", status, CheckoutSessionOpen, now)
+	default:
+		builder = builder.Where("status = ?", status)
+	}
+	if beforeID > 0 {
+		builder = builder.Where("id < ?", beforeID)
+	}
+
+	err := builder.Order("id desc").
+		Limit(limit).
+		Find(&sessions).Error
+	if err != nil {
+		logger.Error("unable to list checkout sessions | err: ", err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ListRefunds implements ICheckoutSession.
+func (r *checkoutSessionRepo) ListRefunds(sessionID uint64) ([]Refund, error) {
+	refunds := []Refund{}
+	err := r.db.Where(&Refund{CheckoutSessionID: sessionID}).Order("id asc").Find(&refunds).Error
+	if err != nil {
+		logger.Error("unable to list refunds | err: ", err)
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// update locks the checkout session id, reads its merchant and applies
+// change to them in one database transaction, retried on balance
+// conflicts.
+func (r *checkoutSessionRepo) update(id uint64, change func(tx *gorm.DB, s *CheckoutSession, m *Merchant) error) (*CheckoutSession, error) {
+	var s CheckoutSession
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		s = CheckoutSession{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, id).Error
+		if err != nil {
+			return err
+		}
+
+		var m Merchant
+		err = tx.First(&m, s.MerchantID).Error
+		if err != nil {
+			return err
+		}
+		return change(tx, &s, &m)
+	})
+	if err != nil {
+		logger.Error("unable to update checkout session | err: ", err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+// merchantWalletWithTx returns the wallet the session settles into.
+func merchantWalletWithTx(tx *gorm.DB, s *CheckoutSession) (*Wallet, error) {
+	wallet, err := InitWalletRepo(tx).GetWithTx(tx, &Wallet{ID: s.MerchantWalletID})
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrCheckoutWalletNotFound
+	}
+	return wallet, err
+}
+
+// Pay implements ICheckoutSession. It transfers the amount of an open
+// session from customer to the merchant's pending wallet, completes the
+// session and queues its webhook, or changes nothing. It returns the
+// session and the legs of the payment.
+func (r *checkoutSessionRepo) Pay(id uint64, customer *Wallet) (*CheckoutSession, *Posting, error) {
+	var posting *Posting
+	s, err := r.update(id, func(tx *gorm.DB, s *CheckoutSession, m *Merchant) error {
+		switch s.CurrentStatus() {
+		case CheckoutSessionOpen:
+		case CheckoutSessionExpired:
+			return ErrCheckoutSessionExpired
+		default:
+			return ErrCheckoutSessionNotOpen
+		}
+		if customer.UserUUID == m.AccountUUID {
+			return ErrCheckoutSelfPayment
+		}
+
+		walletRepo := InitWalletRepo(tx)
+		from, err := walletRepo.GetWithTx(tx, &Wallet{ID: customer.ID})
+		if err != nil {
+			return err
+		}
+		to, err := pendingWalletWithTx(tx, m)
+		if err != nil {
+			return err
+		}
+
+		posting, err = walletRepo.TransferWithFeeTx(tx, from, to, s.AmountInCents, purposecodes.PurposeCodePayment, checkoutSessionDescription+s.OrderID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		s.Status = CheckoutSessionCompleted
+		s.CustomerAccountUUID = from.UserUUID
+		s.CustomerWalletID = &from.ID
+		s.CustomerWalletUUID = from.UUID
+		s.ReferenceID = posting.Debit.ReferenceID
+		s.PaidAt = &now
+		if posting.Fee != nil && posting.FeePayer == FeePayerRecipient {
+			s.MerchantFeeInCents = posting.Fee.AmountInCents
+		}
+		s.SettlementStatus = SettlementPending
+		s.ReceivableInCents = s.AmountInCents - s.MerchantFeeInCents
+		err = tx.Model(&CheckoutSession{}).Where("id = ?", s.ID).Updates(map[string]interface{}{
+			"status":                s.Status,
+			"customer_account_uuid": s.CustomerAccountUUID,
+			"customer_wallet_id":    s.CustomerWalletID,
+			"customer_wallet_uuid":  s.CustomerWalletUUID,
+			"reference_id":          s.ReferenceID,
+			"paid_at":               s.PaidAt,
+			"merchant_fee_in_cents": s.MerchantFeeInCents,
+			"settlement_status":     s.SettlementStatus,
+			"receivable_in_cents":   s.ReceivableInCents,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return enqueueMerchantWebhookWithTx(tx, m, MerchantWebhookCheckoutCompleted, s, nil)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, posting, nil
+}
+
+// Cancel implements ICheckoutSession.
+func (r *checkoutSessionRepo) Cancel(id uint64) (*CheckoutSession, error) {
+	return r.update(id, func(tx *gorm.DB, s *CheckoutSession, m *Merchant) error {
+		switch s.CurrentStatus() {
+		case CheckoutSessionOpen:
+		case CheckoutSessionExpired:
+			return ErrCheckoutSessionExpired
+		default:
+			return ErrCheckoutSessionNotOpen
+		}
+
+		s.Status = CheckoutSessionCancelled
+		return tx.Model(&CheckoutSession{}).Where("id = ?", s.ID).Update("status", s.Status).Error
+	})
+}
+
+// Refund implements ICheckoutSession. It transfers amountInCents of a paid
+// session, what is left of the payment when 0, back to the customer's
+// wallet and queues its webhook, or changes nothing. An unsettled payment
+// is refunded from the pending wallet as long as what is left of it there
+// covers the refund and the merchant's fee on it, so a settlement batch
+// never nets a session below zero.
+func (r *checkoutSessionRepo) Refund(id uint64, amountInCents int, reason string) (*CheckoutSession, *Refund, error) {
+	var refund *Refund
+	s, err := r.update(id, func(tx *gorm.DB, s *CheckoutSession, m *Merchant) error {
+		switch s.Status {
+		case CheckoutSessionCompleted:
+		case CheckoutSessionRefunded:
+			return ErrRefundExceedsPayment
+		default:
+			return ErrCheckoutSessionNotPaid
+		}
+
+		left := s.AmountInCents - s.RefundedInCents
+		amount := amountInCents
+		if amount == 0 {
+			amount = left
+		}
+		if amount > left {
+			return ErrRefundExceedsPayment.WithFields([]errorConst.Error{{
+				Field:       "amount_in_cents",
+				Description: "at most " + utils.FormatMoney(left, s.Currency),
+			}})
+		}
+
+		walletRepo := InitWalletRepo(tx)
+		from, err := merchantWalletWithTx(tx, s)
+		if err != nil {
+			return err
+		}
+		to, err := walletRepo.GetWithTx(tx, &Wallet{ID: *s.CustomerWalletID})
+		if err != nil {
+			return err
+		}
+
+		var fromPending bool
+		if s.SettlementStatus == SettlementPending {
+			pending, err := pendingWalletWithTx(tx, m)
+			if err != nil {
+				return err
+			}
+			quote, err := quoteFeeWithTx(tx, pending, to, amount, purposecodes.PurposeCodeRefund)
+			if err != nil {
+				return err
+			}
+			cost := amount
+			if quote.Payer == FeePayerSender {
+				cost += quote.FeeInCents
+			}
+			if cost <= s.ReceivableInCents {
+				from, fromPending = pending, true
+			}
+		}
+
+		posting, err := walletRepo.TransferWithFeeTx(tx, from, to, amount, purposecodes.PurposeCodeRefund, "refund of "+checkoutSessionDescription+s.OrderID)
+		if err != nil {
+			return err
+		}
+
+		refund = &Refund{
+			CheckoutSessionID:   s.ID,
+			CheckoutSessionUUID: s.UUID,
+			MerchantID:          s.MerchantID,
+			AmountInCents:       amount,
+			Reason:              reason,
+			ReferenceID:         posting.Debit.ReferenceID,
+			WalletID:            from.ID,
+		}
+		if posting.Fee != nil && posting.FeePayer == FeePayerSender {
+			refund.MerchantFeeInCents = posting.Fee.AmountInCents
+		}
+		err = tx.Create(refund).Error
+		if err != nil {
+			return err
+		}
+
+		s.RefundedInCents += amount
+		if s.RefundedInCents == s.AmountInCents {
+			s.Status = CheckoutSessionRefunded
+		}
+		if fromPending {
+			s.ReceivableInCents -= amount + refund.MerchantFeeInCents
+		}
+		err = tx.Model(&CheckoutSession{}).Where("id = ?", s.ID).Updates(map[string]interface{}{
+			"status":              s.Status,
+			"refunded_in_cents":   s.RefundedInCents,
+			"receivable_in_cents": s.ReceivableInCents,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return enqueueMerchantWebhookWithTx(tx, m, MerchantWebhookRefundSucceeded, s, refund)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, refund, nil
+}
@@ -0,0 +1,157 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newMerchant creates a merchant with an empty available wallet, and
+// pending wallet, for accountUUID.
+func newMerchant(t *testing.T, db *gorm.DB, accountUUID string) (*models.Merchant, *models.Wallet) {
+	t.Helper()
+	wallet := &models.Wallet{UserUUID: accountUUID, Currency: models.EntityINR}
+	err := models.InitWalletRepo(db).Create(wallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &models.Merchant{
+		AccountUUID: accountUUID,
+		WalletID:    wallet.ID,
+		WalletUUID:  wallet.UUID,
+		Name:        "Chai Point",
+		WebhookURL:  "https://example.com/hooks",
+	}
+	err = models.InitMerchantRepo(db).Create(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, wallet
+}
+
+// newCheckoutSession opens a session of m for orderID.
+func newCheckoutSession(t *testing.T, db *gorm.DB, m *models.Merchant, orderID string, amountInCents int) *models.CheckoutSession {
+	t.Helper()
+	s := &models.CheckoutSession{
+		MerchantID:         m.ID,
+		MerchantUUID:       m.UUID,
+		MerchantWalletID:   m.WalletID,
+		MerchantWalletUUID: m.WalletUUID,
+		OrderID:            orderID,
+		AmountInCents:      amountInCents,
+		Currency:           models.EntityINR,
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}
+	err := models.InitCheckoutSessionRepo(db).Create(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestCheckoutSessionPayAndRefund pays a session into the merchant's
+// pending wallet and refunds it in two parts from there.
+func TestCheckoutSessionPayAndRefund(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo          = models.InitWalletRepo(db)
+		checkoutSessionRepo = models.InitCheckoutSessionRepo(db)
+	)
+
+	m, merchantWallet := newMerchant(t, db, "acc_checkoutmerchant")
+	customer := &models.Wallet{UserUUID: "acc_checkoutcustomer", Currency: models.EntityINR}
+	err = walletRepo.Create(customer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = walletRepo.Credit(customer, &models.Transaction{AmountInCents: 1000}, purposecodes.PurposeCodeAddFunds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newCheckoutSession(t, db, m, "order-1", 600)
+	err = checkoutSessionRepo.Create(&models.CheckoutSession{
+		MerchantID: m.ID, MerchantUUID: m.UUID, MerchantWalletID: m.WalletID, MerchantWalletUUID: m.WalletUUID,
+		OrderID: "order-1", AmountInCents: 600, Currency: models.EntityINR, ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if !errors.Is(err, models.ErrCheckoutOrderExists) {
+		t.Errorf("second session for the order got %v, want ErrCheckoutOrderExists", err)
+	}
+
+	_, _, err = checkoutSessionRepo.Pay(s.ID, merchantWallet)
+	if !errors.Is(err, models.ErrCheckoutSelfPayment) {
+		t.Errorf("merchant paying itself got %v, want ErrCheckoutSelfPayment", err)
+	}
+
+	paid, _, err := checkoutSessionRepo.Pay(s.ID, customer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paid.Status != models.CheckoutSessionCompleted || paid.SettlementStatus != models.SettlementPending || paid.ReceivableInCents != 600 {
+		t.Errorf("paid session %+v, want it COMPLETED with 600 pending settlement", paid)
+	}
+	_, _, err = checkoutSessionRepo.Pay(s.ID, customer)
+	if !errors.Is(err, models.ErrCheckoutSessionNotOpen) {
+		t.Errorf("paying twice got %v, want ErrCheckoutSessionNotOpen", err)
+	}
+
+	refunded, refund, err := checkoutSessionRepo.Refund(s.ID, 200, "one cup short")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.WalletID != *m.PendingWalletID || refunded.Status != models.CheckoutSessionCompleted || refunded.ReceivableInCents != 400 {
+		t.Errorf("refund %+v of session %+v, want 200 of it from the pending wallet", refund, refunded)
+	}
+	_, _, err = checkoutSessionRepo.Refund(s.ID, 500, "")
+	if !errors.Is(err, models.ErrRefundExceedsPayment) {
+		t.Errorf("refunding past the payment got %v, want ErrRefundExceedsPayment", err)
+	}
+	refunded, refund, err = checkoutSessionRepo.Refund(s.ID, 0, "order cancelled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.AmountInCents != 400 || refunded.Status != models.CheckoutSessionRefunded || refunded.ReceivableInCents != 0 {
+		t.Errorf("refund %+v of session %+v, want the 400 left and the session REFUNDED", refund, refunded)
+	}
+
+	for _, w := range []struct {
+		id   uint64
+		want int
+	}{{customer.ID, 1000}, {*m.PendingWalletID, 0}, {merchantWallet.ID, 0}} {
+		got, err := walletRepo.Get(&models.Wallet{ID: w.id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.TotalBalanceInCents != w.want {
+			t.Errorf("wallet %s holds %d, want %d", got.UUID, got.TotalBalanceInCents, w.want)
+		}
+	}
+
+	var webhooks int64
+	err = db.Model(&models.MerchantWebhook{}).Where("checkout_session_id = ?", s.ID).Count(&webhooks).Error
+	if err != nil || webhooks != 3 {
+		t.Errorf("queued %d webhooks and got %v, want one for the payment and each refund", webhooks, err)
+	}
+
+	expired := newCheckoutSession(t, db, m, "order-2", 100)
+	err = db.Model(&models.CheckoutSession{}).Where("id = ?", expired.ID).Update("expires_at", time.Now().Add(-time.Minute)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = checkoutSessionRepo.Pay(expired.ID, customer)
+	if !errors.Is(err, models.ErrCheckoutSessionExpired) {
+		t.Errorf("paying an expired session got %v, want ErrCheckoutSessionExpired", err)
+	}
+}
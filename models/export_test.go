@@ -0,0 +1,4 @@
+package models
+
+// exported for the tests of package models_test
+var QueueBackoff = queueBackoff
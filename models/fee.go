@@ -0,0 +1,395 @@
+package models
+
+import (
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	EntityFeeSchedule = "fee_"
+
+	feeDescription = "fee on "
+)
+
+type FeeType string
+
+const (
+	FeeFlat FeeType = "FLAT"
+	// BasisPoints of the amount, plus FlatInCents
+	FeePercentage FeeType = "PERCENTAGE"
+	// the FlatInCents and BasisPoints of the tier the amount falls in
+	FeeTiered FeeType = "TIERED"
+)
+
+type FeePayer string
+
+const (
+	FeePayerSender    FeePayer = "SENDER"
+	FeePayerRecipient FeePayer = "RECIPIENT"
+)
+
+// FeeTier is a band of a TIERED fee schedule for amounts up to
+// UpToInCents, 0 for no upper bound.
+type FeeTier struct {
+	UpToInCents int `json:"up_to_in_cents"`
+	FlatInCents int `json:"flat_in_cents"`
+	BasisPoints int `json:"basis_points"`
+}
+
+// FeeSchedule sets the fee charged on the transfers it matches. A schedule
+// matches transfers with its PurposeCode, to or from the wallet of its
+// merchant, whose charged account has its Role, every transfer when none of
+// them is set. The most specific schedule matching a transfer wins: one for
+// a merchant over one for a role over one for a purpose code, the newest
+// when they tie. The fee, after the MinInCents and MaxInCents caps when set,
+// is paid by Payer.
+type FeeSchedule struct {
+	ID        uint64         `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time     `json:"created_at,omitempty"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+
+	UUID         string                              `json:"uuid" gorm:"unique;not null"`
+	Name         string                              `json:"name" gorm:"not null"`
+	PurposeCode  purposecodes.TransactionPurposeCode `json:"purpose_code,omitempty"`
+	Role         RoleType                            `json:"role,omitempty"`
+	MerchantID   *uint64                             `json:"merchant_id,omitempty" gorm:"index"`
+	MerchantUUID string                              `json:"merchant_uuid,omitempty"`
+	Payer        FeePayer                            `json:"payer" gorm:"not null"`
+
+	Type        FeeType                      `json:"type" gorm:"not null"`
+	FlatInCents int                          `json:"flat_in_cents" gorm:"not null;default:0"`
+	BasisPoints int                          `json:"basis_points" gorm:"not null;default:0"`
+	Tiers       datatypes.JSONSlice[FeeTier] `json:"tiers,omitempty"`
+	MinInCents  int                          `json:"min_in_cents" gorm:"not null;default:0"`
+	MaxInCents  int                          `json:"max_in_cents" gorm:"not null;default:0"`
+}
+
+// FeeQuote is the fee a transfer is charged under the current fee
+// schedules, Schedule is nil when it is free.
+type FeeQuote struct {
+	Schedule   *FeeSchedule
+	FeeInCents int
+	Payer      FeePayer
+}
+
+// Posting is a transfer posted with its fee, see TransferWithFeeTx. Fee is
+// the debit of the fee leg, nil when the transfer was free.
+type Posting struct {
+	Debit    *Transaction
+	Credit   *Transaction
+	Fee      *Transaction
+	FeePayer FeePayer
+}
+
+type feeRepo struct {
+	db *gorm.DB
+}
+
+func (s *FeeSchedule) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.UUID == "" {
+		s.UUID, err = utils.GenerateNanoID(16, EntityFeeSchedule)
+	}
+	return err
+}
+
+// percentOf returns basisPoints of amountInCents, rounded half up.
+func percentOf(amountInCents int, basisPoints int) int {
+	return (amountInCents*basisPoints + 5000) / 10000
+}
+
+// tier returns the tier amountInCents falls in, the last one above every
+// bound. Tiers are sorted by UpToInCents with the unbounded one last.
+func (s *FeeSchedule) tier(amountInCents int) FeeTier {
+	for _, t := range s.Tiers {
+		if t.UpToInCents == 0 || amountInCents <= t.UpToInCents {
+			return t
+		}
+	}
+	return s.Tiers[len(s.Tiers)-1]
+}
+
+// Fee returns the fee s sets on a transfer of amountInCents.
+func (s *FeeSchedule) Fee(amountInCents int) int {
+	var fee int
+	switch s.Type {
+	case FeeFlat:
+		fee = s.FlatInCents
+	case FeePercentage:
+		fee = s.FlatInCents + percentOf(amountInCents, s.BasisPoints)
+	case FeeTiered:
+		if len(s.Tiers) > 0 {
+			t := s.tier(amountInCents)
+			fee = t.FlatInCents + percentOf(amountInCents, t.BasisPoints)
+		}
+	}
+
+	if s.MinInCents > 0 && fee < s.MinInCents {
+		fee = s.MinInCents
+	}
+	if s.MaxInCents > 0 && fee > s.MaxInCents {
+		fee = s.MaxInCents
+	}
+	return fee
+}
+
+// specificity ranks s among the schedules matching a transfer.
+func (s *FeeSchedule) specificity() int {
+	var rank int
+	if s.MerchantID != nil {
+		rank += 4
+	}
+	if s.Role != "" {
+		rank += 2
+	}
+	if s.PurposeCode != "" {
+		rank++
+	}
+	return rank
+}
+
+// Create implements IFee.
+func (r *feeRepo) Create(s *FeeSchedule) error {
+	err := r.db.Create(s).Error
+	if err != nil {
+		logger.Error("unable to create fee schedule | err: ", err)
+		return err
+	}
+	return nil
+}
+
+// Get implements IFee.
+func (r *feeRepo) Get(where *FeeSchedule) (*FeeSchedule, error) {
+	var s FeeSchedule
+	err := r.db.Where(where).First(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List implements IFee. It returns every fee schedule in force, newest
+// first.
+func (r *feeRepo) List() ([]FeeSchedule, error) {
+	schedules := []FeeSchedule{}
+	err := r.db.Order("id desc").Find(&schedules).Error
+	if err != nil {
+		logger.Error("unable to list fee schedules | err: ", err)
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// Delete implements IFee. The schedule stops applying to new transfers.
+func (r *feeRepo) Delete(id uint64) error {
+	err := r.db.Delete(&FeeSchedule{}, id).Error
+	if err != nil {
+		logger.Error("unable to delete fee schedule | err: ", err)
+		return err
+	}
+	return nil
+}
+
+// Quote implements IFee.
+func (r *feeRepo) Quote(from *Wallet, to *Wallet, amountInCents int, purposeCode purposecodes.TransactionPurposeCode) (*FeeQuote, error) {
+	return quoteFeeWithTx(r.db, from, to, amountInCents, purposeCode)
+}
+
+// quoteFeeWithTx returns the fee of a transfer of amountInCents from from to
+// to. Fees are charged in the currency of FeeRevenueWallet, transfers in
+// other currencies, fee legs, settlements and moves between shards are
+// free. A merchant's pending wallet is charged as the merchant's account.
+// A recipient is never charged more than it received.
+func quoteFeeWithTx(tx *gorm.DB, from *Wallet, to *Wallet, amountInCents int, purposeCode purposecodes.TransactionPurposeCode) (*FeeQuote, error) {
+	free := &FeeQuote{Payer: FeePayerSender}
+	switch {
+	case purposeCode == purposecodes.PurposeCodeFee, purposeCode == purposecodes.PurposeCodeRebalance,
+		purposeCode == purposecodes.PurposeCodeSettlement:
+		return free, nil
+	case from.UUID == FeeRevenueWallet.UUID, to.UUID == FeeRevenueWallet.UUID:
+		return free, nil
+	case from.Currency != FeeRevenueWallet.Currency:
+		return free, nil
+	}
+
+	var merchants []Merchant
+	walletIDs := []uint64{from.ID, to.ID}
+	err := tx.Where("wallet_id IN ? OR pending_wallet_id IN ?", walletIDs, walletIDs).Find(&merchants).Error
+	if err != nil {
+		logger.Error("unable to query merchants | err: ", err)
+		return nil, err
+	}
+
+	// pending wallets are owned by the merchant profile
+	owners := map[string]string{}
+	merchantIDs := make([]uint64, 0, len(merchants))
+	for _, m := range merchants {
+		merchantIDs = append(merchantIDs, m.ID)
+		owners[m.UUID] = m.AccountUUID
+	}
+	accountOf := func(w *Wallet) string {
+		if accountUUID, ok := owners[w.UserUUID]; ok {
+			return accountUUID
+		}
+		return w.UserUUID
+	}
+
+	builder := tx.Where("purpose_code = '' OR purpose_code IS NULL OR purpose_code = ?", purposeCode)
+	if len(merchantIDs) > 0 {
+		builder = builder.Where("merchant_id IS NULL OR merchant_id IN ?", merchantIDs)
+	} else {
+		builder = builder.Where("merchant_id IS NULL")
+	}
+	var schedules []FeeSchedule
+	err = builder.Find(&schedules).Error
+	if err != nil {
+		logger.Error("unable to query fee schedules | err: ", err)
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return free, nil
+	}
+
+	roles, err := accountRolesWithTx(tx, accountOf(from), accountOf(to))
+	if err != nil {
+		return nil, err
+	}
+
+	var best *FeeSchedule
+	for i := range schedules {
+		s := &schedules[i]
+		charged := from
+		if s.Payer == FeePayerRecipient {
+			charged = to
+		}
+		if s.Role != "" && s.Role != roles[accountOf(charged)] {
+			continue
+		}
+
+		if best == nil || s.specificity() > best.specificity() ||
+			(s.specificity() == best.specificity() && s.ID > best.ID) {
+			best = s
+		}
+	}
+	if best == nil {
+		return free, nil
+	}
+
+	fee := best.Fee(amountInCents)
+	if best.Payer == FeePayerRecipient && fee > amountInCents {
+		fee = amountInCents
+	}
+	return &FeeQuote{Schedule: best, FeeInCents: fee, Payer: best.Payer}, nil
+}
+
+// accountRolesWithTx maps the UUIDs of accounts to the names of their
+// roles, system wallets have no account and are left out.
+func accountRolesWithTx(tx *gorm.DB, accountUUIDs ...string) (map[string]RoleType, error) {
+	var rows []struct {
+		UUID string
+		Name RoleType
+	}
+	err := tx.Table("accounts").
+		Select("accounts.uuid, roles.name").
+		Joins("JOIN roles ON roles.id = accounts.role_id").
+		Where("accounts.uuid IN ? AND accounts.deleted_at IS NULL", accountUUIDs).
+		Scan(&rows).Error
+	if err != nil {
+		logger.Error("unable to query account roles | err: ", err)
+		return nil, err
+	}
+
+	roles := make(map[string]RoleType, len(rows))
+	for _, row := range rows {
+		roles[row.UUID] = row.Name
+	}
+	return roles, nil
+}
+
+// TransferWithFeeTx posts a transfer like TransferWithTx, then the fee the
+// fee schedules set on it as a transfer of its own, with purpose code FEE,
+// from the wallet paying it to FeeRevenueWallet. The fee leg's description
+// names the reference ID of the transfer.
+//
+// Every charged transfer credits FeeRevenueWallet, so it is locked with the
+// rows of from and to in id order rather than after them, which would
+// deadlock against a transfer locking them the other way round; so are the
+// shards of from when they fund the debit, see lockDebitWithTx. Shard it to
+// spread its credits over several rows.
+func (r *walletRepo) TransferWithFeeTx(tx *gorm.DB, from *Wallet, to *Wallet, amountInCents int, purposeCode purposecodes.TransactionPurposeCode, description string) (*Posting, error) {
+	if from.UUID == to.UUID {
+		return nil, ErrSameWalletTransfer
+	}
+
+	if from.Currency != to.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	quote, err := quoteFeeWithTx(tx, from, to, amountInCents, purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	if quote.FeeInCents <= 0 {
+		debit, credit, err := r.TransferWithTx(tx, from, to, amountInCents, purposeCode, description)
+		if err != nil {
+			return nil, err
+		}
+		return &Posting{Debit: debit, Credit: credit}, nil
+	}
+
+	revenue, err := r.GetWithTx(tx, &Wallet{UUID: FeeRevenueWallet.UUID})
+	if err != nil {
+		logger.Error("unable to get fee revenue wallet | err: ", err)
+		return nil, err
+	}
+
+	// a sender paying the fee is debited both from one row
+	debitInCents := amountInCents
+	if quote.Payer == FeePayerSender {
+		debitInCents += quote.FeeInCents
+	}
+
+	fromRow, err := r.pickShardWithTx(tx, from, debitInCents, true)
+	if err != nil {
+		return nil, err
+	}
+
+	toRow, err := r.pickShardWithTx(tx, to, amountInCents, false)
+	if err != nil {
+		return nil, err
+	}
+
+	revenueRow, err := r.pickShardWithTx(tx, revenue, quote.FeeInCents, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// the shards funding the sender's row are locked in the same pass
+	fromRow, err = r.lockDebitWithTx(tx, fromRow, debitInCents, toRow, revenueRow)
+	if err != nil {
+		return nil, err
+	}
+
+	debit, credit, err := r.transferRowsWithTx(tx, fromRow, toRow, from.UUID, to.UUID, amountInCents, purposeCode, description)
+	if err != nil {
+		return nil, err
+	}
+	posting := &Posting{Debit: debit, Credit: credit, FeePayer: quote.Payer}
+
+	// a recipient is never charged more than it received, its row covers the fee
+	payerRow, payerUUID := fromRow, from.UUID
+	if quote.Payer == FeePayerRecipient {
+		payerRow, payerUUID = toRow, to.UUID
+	}
+
+	posting.Fee, _, err = r.transferRowsWithTx(tx, payerRow, revenueRow, payerUUID, revenue.UUID, quote.FeeInCents, purposecodes.PurposeCodeFee, feeDescription+debit.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	return posting, nil
+}
@@ -0,0 +1,109 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"testing"
+)
+
+func TestFeeScheduleFee(t *testing.T) {
+	tiers := []models.FeeTier{
+		{UpToInCents: 10000, FlatInCents: 100},
+		{UpToInCents: 100000, BasisPoints: 150},
+		{FlatInCents: 500, BasisPoints: 100},
+	}
+
+	tests := []struct {
+		name     string
+		schedule models.FeeSchedule
+		amount   int
+		want     int
+	}{
+		{"flat", models.FeeSchedule{Type: models.FeeFlat, FlatInCents: 250}, 99999, 250},
+		{"percentage", models.FeeSchedule{Type: models.FeePercentage, BasisPoints: 250}, 10000, 250},
+		{"percentage rounds half up", models.FeeSchedule{Type: models.FeePercentage, BasisPoints: 150}, 1234, 19},
+		{"percentage rounds down", models.FeeSchedule{Type: models.FeePercentage, BasisPoints: 150}, 1233, 18},
+		{"percentage plus flat", models.FeeSchedule{Type: models.FeePercentage, FlatInCents: 30, BasisPoints: 290}, 10000, 320},
+		{"first tier", models.FeeSchedule{Type: models.FeeTiered, Tiers: tiers}, 10000, 100},
+		{"second tier", models.FeeSchedule{Type: models.FeeTiered, Tiers: tiers}, 10001, 150},
+		{"unbounded tier", models.FeeSchedule{Type: models.FeeTiered, Tiers: tiers}, 200000, 2500},
+		{"no tiers", models.FeeSchedule{Type: models.FeeTiered}, 10000, 0},
+		{"min", models.FeeSchedule{Type: models.FeePercentage, BasisPoints: 100, MinInCents: 50}, 1000, 50},
+		{"max", models.FeeSchedule{Type: models.FeePercentage, BasisPoints: 100, MaxInCents: 2000}, 1000000, 2000},
+		{"between the caps", models.FeeSchedule{Type: models.FeePercentage, BasisPoints: 100, MinInCents: 50, MaxInCents: 2000}, 10000, 100},
+	}
+	for _, tt := range tests {
+		if got := tt.schedule.Fee(tt.amount); got != tt.want {
+			t.Errorf("%s: fee on %d is %d, want %d", tt.name, tt.amount, got, tt.want)
+		}
+	}
+}
+
+// TestQuote checks the most specific schedule wins, a merchant's schedule
+// only applies to its own wallets, a recipient is never charged past what
+// it receives and settlements are free.
+func TestQuote(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo = models.InitWalletRepo(db)
+		feeRepo    = models.InitFeeRepo(db)
+	)
+
+	m, merchantWallet := newMerchant(t, db, "acc_feemerchant")
+	customer := &models.Wallet{UserUUID: "acc_feecustomer", Currency: models.EntityINR}
+	other := &models.Wallet{UserUUID: "acc_feeother", Currency: models.EntityINR}
+	for _, w := range []*models.Wallet{customer, other} {
+		err = walletRepo.Create(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	pending, err := walletRepo.Get(&models.Wallet{ID: *m.PendingWalletID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []models.FeeSchedule{
+		{Name: "everything", Payer: models.FeePayerSender, Type: models.FeeFlat, FlatInCents: 1},
+		{Name: "payments", PurposeCode: purposecodes.PurposeCodePayment, Payer: models.FeePayerSender, Type: models.FeeFlat, FlatInCents: 2},
+		{Name: "chai point", MerchantID: &m.ID, MerchantUUID: m.UUID, PurposeCode: purposecodes.PurposeCodePayment,
+			Payer: models.FeePayerRecipient, Type: models.FeePercentage, FlatInCents: 30, BasisPoints: 200},
+	} {
+		err = feeRepo.Create(&s)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		from    *models.Wallet
+		to      *models.Wallet
+		amount  int
+		purpose purposecodes.TransactionPurposeCode
+		fee     int
+		payer   models.FeePayer
+	}{
+		{"transfer", customer, merchantWallet, 1000, purposecodes.PurposeCodeTransfer, 1, models.FeePayerSender},
+		{"payment to another wallet", customer, other, 1000, purposecodes.PurposeCodePayment, 2, models.FeePayerSender},
+		{"payment to the merchant", customer, pending, 1000, purposecodes.PurposeCodePayment, 50, models.FeePayerRecipient},
+		{"capped at the payment", customer, pending, 20, purposecodes.PurposeCodePayment, 20, models.FeePayerRecipient},
+		{"settlement", pending, merchantWallet, 1000, purposecodes.PurposeCodeSettlement, 0, models.FeePayerSender},
+	}
+	for _, tt := range tests {
+		quote, err := feeRepo.Quote(tt.from, tt.to, tt.amount, tt.purpose)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if quote.FeeInCents != tt.fee || quote.Payer != tt.payer {
+			t.Errorf("%s: quoted %d paid by %s, want %d paid by %s", tt.name, quote.FeeInCents, quote.Payer, tt.fee, tt.payer)
+		}
+	}
+}
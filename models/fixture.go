@@ -0,0 +1,138 @@
+package models
+
+import (
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Fixture is the YAML format of fixtures. Accounts and wallets are matched
+// on their UUID, so loading a fixture again leaves existing rows untouched.
+type Fixture struct {
+	Accounts []AccountFixture `yaml:"accounts"`
+}
+
+type AccountFixture struct {
+	UUID        string         `yaml:"uuid"`
+	FirstName   string         `yaml:"first_name"`
+	LastName    string         `yaml:"last_name"`
+	PhoneNumber string         `yaml:"phone_number"`
+	Email       string         `yaml:"email"`
+	Role        RoleType       `yaml:"role"`
+	Wallet      *WalletFixture `yaml:"wallet"`
+}
+
+type WalletFixture struct {
+	UUID                  string `yaml:"uuid"`
+	Currency              string `yaml:"currency"`
+	BalanceInCents        int    `yaml:"balance_in_cents"`
+	OverdraftLimitInCents uint   `yaml:"overdraft_limit_in_cents"`
+}
+
+// LoadFixture creates the accounts and wallets of the named YAML file in
+// fsys. A new wallet is funded with an ADD_FUNDS credit, so its balance is
+// backed by the ledger.
+func LoadFixture(db *gorm.DB, fsys fs.FS, name string) error {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	var fixture Fixture
+	err = yaml.Unmarshal(content, &fixture)
+	if err != nil {
+		return fmt.Errorf("fixture %s: %w", name, err)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, a := range fixture.Accounts {
+			err := loadAccountFixture(tx, a)
+			if err != nil {
+				return fmt.Errorf("fixture %s, account %s: %w", name, a.UUID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func loadAccountFixture(tx *gorm.DB, a AccountFixture) error {
+	if a.UUID == "" {
+		return errors.New("uuid is required")
+	}
+
+	role, err := InitRoleRepo(tx).GetWithTx(tx, &Role{Name: a.Role})
+	if err != nil {
+		return fmt.Errorf("role %q: %w", a.Role, err)
+	}
+
+	var account Account
+	err = tx.Where(&Account{UUID: a.UUID}).Limit(1).Find(&account).Error
+	if err != nil {
+		return err
+	}
+
+	if account.ID == 0 {
+		account = Account{
+			UUID:      a.UUID,
+			FirstName: a.FirstName,
+			LastName:  a.LastName,
+			Email:     a.Email,
+			RoleID:    role.ID,
+		}
+		if a.PhoneNumber != "" {
+			account.PhoneNumber = &a.PhoneNumber
+		}
+
+		err = InitAccountRepo(tx).CreateWithTx(tx, &account)
+		if err != nil {
+			return err
+		}
+		if account.ID == 0 {
+			return errors.New("phone number is taken by another account")
+		}
+	}
+
+	if a.Wallet == nil {
+		return nil
+	}
+
+	var wallet Wallet
+	err = tx.Where(&Wallet{UserUUID: account.UUID}).Limit(1).Find(&wallet).Error
+	if err != nil || wallet.ID != 0 {
+		return err
+	}
+
+	currency := a.Wallet.Currency
+	if currency == "" {
+		currency = EntityINR
+	}
+
+	walletRepo := InitWalletRepo(tx)
+	wallet = Wallet{
+		UserUUID:              account.UUID,
+		UUID:                  a.Wallet.UUID,
+		Currency:              currency,
+		OverdraftLimitInCents: a.Wallet.OverdraftLimitInCents,
+	}
+	err = walletRepo.CreateWithTx(tx, &wallet)
+	if err != nil {
+		return err
+	}
+	if wallet.ID == 0 {
+		return errors.New("wallet uuid is taken by another account")
+	}
+
+	if a.Wallet.BalanceInCents <= 0 {
+		return nil
+	}
+
+	_, err = walletRepo.CreditWithTx(tx, &wallet, &Transaction{
+		AmountInCents: a.Wallet.BalanceInCents,
+		Description:   "fixture balance",
+	}, purposecodes.PurposeCodeAddFunds)
+	return err
+}
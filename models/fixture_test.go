@@ -0,0 +1,74 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"os"
+	"testing"
+)
+
+func TestLoadFixture(t *testing.T) {
+	db := databasetest.New(t)
+
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatalf("AddSystemData: %s", err)
+	}
+
+	// loading twice must not create or fund anything again
+	for i := 0; i < 2; i++ {
+		err = models.LoadFixture(db, os.DirFS("testdata"), "wallets.yaml")
+		if err != nil {
+			t.Fatalf("LoadFixture: %s", err)
+		}
+	}
+
+	walletRepo := models.InitWalletRepo(db)
+	ledgerRepo := models.InitLedgerRepo(db)
+
+	tests := []struct {
+		walletUUID string
+		userUUID   string
+		balance    int
+		overdraft  uint
+		credits    int64
+	}{
+		{"wa_fixturepayer000001", "acc_fixturepayer", 120000, 5000, 1},
+		{"wa_fixturepayee000001", "acc_fixturepayee", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		wallet, err := walletRepo.Get(&models.Wallet{UUID: tt.walletUUID})
+		if err != nil {
+			t.Fatalf("wallet %s: %s", tt.walletUUID, err)
+		}
+		if wallet.UserUUID != tt.userUUID || wallet.Currency != models.EntityINR {
+			t.Errorf("wallet %s belongs to %s in %s", tt.walletUUID, wallet.UserUUID, wallet.Currency)
+		}
+		if wallet.TotalBalanceInCents != tt.balance || wallet.OverdraftLimitInCents != tt.overdraft {
+			t.Errorf("wallet %s has balance %d and overdraft %d, want %d and %d",
+				tt.walletUUID, wallet.TotalBalanceInCents, wallet.OverdraftLimitInCents, tt.balance, tt.overdraft)
+		}
+
+		var credits int64
+		err = db.Model(&models.Transaction{}).
+			Where(&models.Transaction{WalletID: wallet.ID, PurposeCode: purposecodes.PurposeCodeAddFunds}).
+			Count(&credits).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		if credits != tt.credits {
+			t.Errorf("wallet %s has %d ADD_FUNDS transactions, want %d", tt.walletUUID, credits, tt.credits)
+		}
+
+		v, err := ledgerRepo.VerifyWallet(wallet.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v.OK() {
+			t.Errorf("wallet %s does not match its ledger: %v", tt.walletUUID, v.Issues)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"coinpe/database"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lowercase letters, digits, dots, dashes and underscores, starting with a
+// letter or a digit
+var handleRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,31}$`)
+
+var (
+	ErrInvalidHandle = errorConst.New(errorConst.ErrorValidation, errorConst.MsgInvalidHandle).WithFields([]errorConst.Error{{
+		Field:       "handle",
+		Description: "must be 3 to 32 lowercase letters, digits, dots, dashes or underscores, starting with a letter or a digit",
+	}})
+	ErrHandleTaken = errorConst.New(errorConst.ErrorConflict, errorConst.MsgHandleIsAlreadyTaken)
+)
+
+// Handle is a user chosen name, like a VPA, that others pay a wallet by
+// instead of its UUID. A wallet has at most one handle.
+type Handle struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	Handle      string `json:"handle" gorm:"unique;not null"`
+	AccountUUID string `json:"account_uuid" gorm:"not null;index"`
+	WalletID    uint64 `json:"wallet_id" gorm:"not null;uniqueIndex"`
+	WalletUUID  string `json:"wallet_uuid" gorm:"not null"`
+}
+
+type handleRepo struct {
+	db *gorm.DB
+}
+
+// NormalizeHandle returns handle lowercased and without a leading @, or
+// ErrInvalidHandle.
+func NormalizeHandle(handle string) (string, error) {
+	handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+	if !handleRegex.MatchString(handle) {
+		return "", ErrInvalidHandle
+	}
+	return handle, nil
+}
+
+// Get implements IHandle.
+func (r *handleRepo) Get(where *Handle) (*Handle, error) {
+	var h Handle
+	err := r.db.Where(where).First(&h).Error
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// Set implements IHandle. It gives wallet the handle, replacing the one it
+// had, which others can then take.
+func (r *handleRepo) Set(wallet *Wallet, handle string) (*Handle, error) {
+	handle, err := NormalizeHandle(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Handle{
+		Handle:      handle,
+		AccountUUID: wallet.UserUUID,
+		WalletID:    wallet.ID,
+		WalletUUID:  wallet.UUID,
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(&Handle{WalletID: wallet.ID}).Delete(&Handle{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(h).Error
+	})
+	if database.IsUniqueViolation(err, "uni_handles_handle") {
+		return nil, ErrHandleTaken
+	}
+	if err != nil {
+		logger.Error("unable to set handle | err: ", err)
+		return nil, err
+	}
+	return h, nil
+}
+
+// Delete implements IHandle. It releases the handle of the wallet, if any.
+func (r *handleRepo) Delete(walletID uint64) error {
+	err := r.db.Where(&Handle{WalletID: walletID}).Delete(&Handle{}).Error
+	if err != nil {
+		logger.Error("unable to delete handle | err: ", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeHandle(t *testing.T) {
+	valid := map[string]string{
+		"asha.rao":  "asha.rao",
+		" @Asha_R ": "asha_r",
+		"007":       "007",
+	}
+	for handle, want := range valid {
+		got, err := models.NormalizeHandle(handle)
+		if err != nil || got != want {
+			t.Errorf("NormalizeHandle(%q) = %q, %v, want %q", handle, got, err, want)
+		}
+	}
+
+	for _, handle := range []string{"", "ab", ".asha", "asha rao", "asha@bank", strings.Repeat("a", 33)} {
+		if _, err := models.NormalizeHandle(handle); !errors.Is(err, models.ErrInvalidHandle) {
+			t.Errorf("NormalizeHandle(%q) got %v, want ErrInvalidHandle", handle, err)
+		}
+	}
+}
+
+// TestSetHandle renames a wallet's handle, after which another wallet can
+// take the old one but not the new one.
+func TestSetHandle(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo = models.InitWalletRepo(db)
+		handleRepo = models.InitHandleRepo(db)
+	)
+
+	first := &models.Wallet{UserUUID: "acc_handlefirst", Currency: models.EntityINR}
+	second := &models.Wallet{UserUUID: "acc_handlesecond", Currency: models.EntityINR}
+	for _, w := range []*models.Wallet{first, second} {
+		err = walletRepo.Create(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = handleRepo.Set(first, "@Asha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = handleRepo.Set(first, "asha.rao")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = handleRepo.Set(second, "asha.rao")
+	if !errors.Is(err, models.ErrHandleTaken) {
+		t.Errorf("taking a used handle got %v, want ErrHandleTaken", err)
+	}
+	h, err := handleRepo.Set(second, "asha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.WalletUUID != second.UUID {
+		t.Errorf("released handle points at %s, want %s", h.WalletUUID, second.UUID)
+	}
+
+	got, err := handleRepo.Get(&models.Handle{Handle: "asha.rao"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.WalletID != first.ID {
+		t.Errorf("asha.rao points at wallet %d, want %d", got.WalletID, first.ID)
+	}
+}
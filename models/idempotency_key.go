@@ -0,0 +1,139 @@
+package models
+
+import (
+	"coinpe/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// IdempotencyKeyLockTTL is how long a reservation holds its key. A retry
+	// after that takes the key over, so a process that died mid-request does
+	// not leave it in progress forever. It outlives any request it guards.
+	IdempotencyKeyLockTTL = 5 * time.Minute
+	// IdempotencyKeyRetention is how long a completed key replays its
+	// response, after that the key can be used again.
+	IdempotencyKeyRetention = 24 * time.Hour
+)
+
+// IdempotencyKey records the outcome of a request sent with an
+// Idempotency-Key header so that retries replay the original response.
+type IdempotencyKey struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	Scope          string     `json:"scope" gorm:"not null;uniqueIndex:idx_idempotency_keys_scope_key"`
+	Key            string     `json:"key" gorm:"not null;uniqueIndex:idx_idempotency_keys_scope_key"`
+	RequestHash    string     `json:"request_hash" gorm:"not null"`
+	ResponseStatus int        `json:"response_status"`
+	ResponseBody   []byte     `json:"-"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+
+	// LockedUntil ends the reservation of a key in progress, it also tells
+	// the reservation that holds the key from one that lost it
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	// ExpiresAt ends the replays of a completed key
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type idempotencyKeyRepo struct {
+	db *gorm.DB
+}
+
+// Reserve implements IIdempotencyKey. It returns false when the scope and key
+// are in use: in progress under a live reservation, or completed and not
+// expired. A reservation past LockedUntil is taken over by a retry of the
+// same request, an expired key by any request.
+func (r *idempotencyKeyRepo) Reserve(k *IdempotencyKey) (bool, error) {
+	// postgres keeps microseconds, LockedUntil has to match the stored value
+	now := time.Now().Truncate(time.Microsecond)
+	lockedUntil := now.Add(IdempotencyKeyLockTTL)
+	k.LockedUntil = &lockedUntil
+
+	result := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"created_at":      gorm.Expr("EXCLUDED.created_at"),
+			"updated_at":      gorm.Expr("EXCLUDED.updated_at"),
+			"request_hash":    gorm.Expr("EXCLUDED.request_hash"),
+			"locked_until":    gorm.Expr("EXCLUDED.locked_until"),
+			"response_status": nil,
+			"response_body":   nil,
+			"completed_at":    nil,
+			"expires_at":      nil,
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{gorm.Expr(
+			"(idempotency_keys.completed_at IS NULL AND idempotency_keys.locked_until < ? AND idempotency_keys.request_hash = EXCLUDED.request_hash) OR idempotency_keys.expires_at < ?",
+			now, now,
+		)}},
+	}).
+		Model(&IdempotencyKey{}).
+		Create(k)
+	if result.Error != nil {
+		logger.Error("unable to reserve idempotency key | err: ", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Get implements IIdempotencyKey.
+func (r *idempotencyKeyRepo) Get(scope string, key string) (*IdempotencyKey, error) {
+	var k IdempotencyKey
+	err := r.db.Model(&IdempotencyKey{}).
+		Where(&IdempotencyKey{Scope: scope, Key: key}).
+		First(&k).Error
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Complete implements IIdempotencyKey. It records the response of k for
+// IdempotencyKeyRetention, unless k lost its reservation to a retry.
+func (r *idempotencyKeyRepo) Complete(k *IdempotencyKey, responseStatus int, responseBody []byte) error {
+	now := time.Now()
+	result := r.db.Model(&IdempotencyKey{}).
+		Where("id = ? AND locked_until = ? AND completed_at IS NULL", k.ID, k.LockedUntil).
+		Updates(map[string]interface{}{
+			"response_status": responseStatus,
+			"response_body":   responseBody,
+			"completed_at":    now,
+			"expires_at":      now.Add(IdempotencyKeyRetention),
+		})
+	if result.Error != nil {
+		logger.Error("unable to complete idempotency key | err: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		logger.Warnf("idempotency key %s was taken over before its request completed", k.Key)
+	}
+	return nil
+}
+
+// Release implements IIdempotencyKey. Released keys can be retried.
+func (r *idempotencyKeyRepo) Release(k *IdempotencyKey) error {
+	err := r.db.Where("id = ? AND locked_until = ? AND completed_at IS NULL", k.ID, k.LockedUntil).
+		Delete(&IdempotencyKey{}).Error
+	if err != nil {
+		logger.Error("unable to release idempotency key | err: ", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteExpired implements IIdempotencyKey. It deletes the completed keys
+// past their retention and the reservations past their lock, which a retry
+// would take over anyway, and returns how many there were.
+func (r *idempotencyKeyRepo) DeleteExpired() (int64, error) {
+	now := time.Now()
+	result := r.db.Where("expires_at < ? OR (completed_at IS NULL AND locked_until < ?)", now, now).
+		Delete(&IdempotencyKey{})
+	if result.Error != nil {
+		logger.Error("unable to delete expired idempotency keys | err: ", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"coinpe/pkg/purposecodes"
+	"crypto/ed25519"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -64,8 +68,149 @@ type IWallet interface {
 	Create(w *Wallet) error
 	CreateWithTx(tx *gorm.DB, w *Wallet) error
 	Delete(walletID string) error
-	UpdateBalance(tx *gorm.DB, walletID string, totalBalance int) error
+	LockWithTx(tx *gorm.DB, wallets ...*Wallet) error
+	UpdateBalance(tx *gorm.DB, wallet *Wallet, totalBalance int) error
 	CanDebit(w *Wallet, amountInCents int) bool
 	UpdateWithTx(tx *gorm.DB, where *Wallet, w *Wallet) error
 	Update(where *Wallet, w *Wallet) error
+	Credit(wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error)
+	CreditWithTx(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error)
+	Debit(wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error)
+	DebitWithTx(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error)
+	TransferWithTx(tx *gorm.DB, from *Wallet, to *Wallet, amountInCents int, purposeCode purposecodes.TransactionPurposeCode, description string) (*Transaction, *Transaction, error)
+	TransferWithFeeTx(tx *gorm.DB, from *Wallet, to *Wallet, amountInCents int, purposeCode purposecodes.TransactionPurposeCode, description string) (*Posting, error)
+	Shard(walletID uint64, shardCount int) (*Wallet, error)
+	ShardsWithTx(tx *gorm.DB, wallet *Wallet) ([]Wallet, error)
+	ShardedWallets() ([]Wallet, error)
+	RebalanceShards(walletID uint64) (*ShardRebalance, error)
+}
+
+type IPendingTransaction interface {
+	Submit(pending []PendingTransaction) error
+	Get(where *PendingTransaction) (*PendingTransaction, error)
+	ListBatch(batchUUID string) ([]PendingTransaction, error)
+	ProcessNext(walletConcurrency int, maxAttempts int, backoff time.Duration) (*PendingTransaction, error)
+	DeliverNextWebhook(maxAttempts int, backoff time.Duration, timeout time.Duration, deliver func(p *PendingTransaction) error) (bool, error)
+}
+
+type IPayout interface {
+	Create(payout *Payout, items []PayoutItem) error
+	Get(where *Payout) (*Payout, error)
+	ListItems(payoutID uint64, status PayoutItemStatus, afterRow int, limit int) ([]PayoutItem, error)
+	StreamItems(payoutID uint64, each func(item *PayoutItem) error) error
+	ProcessNextChunk(chunkSize int, maxAttempts int) (bool, error)
+}
+
+type IIdempotencyKey interface {
+	Reserve(k *IdempotencyKey) (bool, error)
+	Get(scope string, key string) (*IdempotencyKey, error)
+	Complete(k *IdempotencyKey, responseStatus int, responseBody []byte) error
+	Release(k *IdempotencyKey) error
+	DeleteExpired() (int64, error)
+}
+
+type ITransaction interface {
+	Create(t *Transaction) error
+	CreateWithTx(tx *gorm.DB, t *Transaction) error
+	Get(where *Transaction) (*Transaction, error)
+	GetWithTx(tx *gorm.DB, where *Transaction) (*Transaction, error)
+	FindByReference(referenceID string) ([]Transaction, error)
+	ListForWallet(walletID uint64, beforeID uint64, limit int) ([]Transaction, error)
+	ListForWalletAfterSequence(walletID uint64, afterSequence int64, limit int) ([]Transaction, error)
+	LatestSequenceForWallet(walletID uint64) (int64, error)
+}
+
+type ILedger interface {
+	VerifyWallet(walletID uint64) (*WalletVerification, error)
+	Verify(fn func(v *WalletVerification) error) error
+	RebuildBalance(walletID uint64) (*WalletVerification, error)
+	Adjust(walletID uint64, reason string) (*Transaction, error)
+	VerifyWalletChain(walletID uint64) (*ChainVerification, error)
+	VerifyWalletChains(fn func(walletUUID string, v *ChainVerification) error) error
+	SealGlobalChain(limit int) (int, error)
+	CreateCheckpoint(key ed25519.PrivateKey) (*LedgerCheckpoint, error)
+	LatestCheckpoint() (*LedgerCheckpoint, error)
+	VerifyGlobalChain(publicKey ed25519.PublicKey) (*ChainVerification, error)
+	BalanceAt(walletID uint64, at time.Time) (int, error)
+	Statement(wallet *Wallet, from time.Time, to time.Time) (*Statement, error)
+	StreamStatement(walletID uint64, from time.Time, to time.Time, fn func(openingBalanceInCents int) error, each func(t *Transaction) error) error
+	NextSnapshotDay() (day time.Time, ok bool, err error)
+	SnapshotBalances(day time.Time) (int64, error)
+}
+
+type ISettlement interface {
+	Import(imp *SettlementImport, records []SettlementRecord) error
+	GetImport(where *SettlementImport) (*SettlementImport, error)
+	ListImports(beforeID uint64, limit int) ([]SettlementImport, error)
+	ListRecords(importID uint64, status SettlementRecordStatus) ([]SettlementRecord, error)
+	CountRecords(importID uint64) (map[SettlementRecordStatus]int, error)
+	Reconcile(importID uint64, window time.Duration) (ReconciliationResult, error)
+	Resolve(recordUUID string, resolution SettlementResolution, transactionUUID string, note string, resolvedBy string, window time.Duration) (*SettlementRecord, error)
+	UnmatchedTransactions(from time.Time, to time.Time, limit int) ([]Transaction, error)
+}
+type IScheduledTransfer interface {
+	Create(s *ScheduledTransfer) error
+	Get(where *ScheduledTransfer) (*ScheduledTransfer, error)
+	List(accountUUID string, status ScheduledTransferStatus, beforeID uint64, limit int) ([]ScheduledTransfer, error)
+	ListRuns(scheduledTransferID uint64, limit int) ([]ScheduledTransferRun, error)
+	Pause(id uint64) (*ScheduledTransfer, error)
+	Resume(id uint64) (*ScheduledTransfer, error)
+	Cancel(id uint64) (*ScheduledTransfer, error)
+	RunNext() (*ScheduledTransferRun, error)
+}
+type IPaymentRequest interface {
+	Create(requests []PaymentRequest, split *SplitBill) error
+	Get(where *PaymentRequest) (*PaymentRequest, error)
+	List(accountUUID string, outgoing bool, status PaymentRequestStatus, beforeID uint64, limit int) ([]PaymentRequest, error)
+	GetSplit(where *SplitBill) (*SplitBill, []PaymentRequest, error)
+	Approve(id uint64) (*PaymentRequest, *Posting, error)
+	Decline(id uint64, reason string) (*PaymentRequest, error)
+	Cancel(id uint64) (*PaymentRequest, error)
+	ExpireDue() (int64, error)
+}
+
+type IHandle interface {
+	Get(where *Handle) (*Handle, error)
+	Set(wallet *Wallet, handle string) (*Handle, error)
+	Delete(walletID uint64) error
+}
+
+type IMerchant interface {
+	Create(m *Merchant) error
+	Get(where *Merchant) (*Merchant, error)
+	Update(id uint64, name string, webhookURL string) (*Merchant, error)
+	RotateWebhookSecret(id uint64) (*Merchant, error)
+	DeliverNextWebhook(maxAttempts int, backoff time.Duration, timeout time.Duration, deliver func(w *MerchantWebhook, m *Merchant, s *CheckoutSession, refund *Refund) error) (bool, error)
+}
+
+type ICheckoutSession interface {
+	Create(s *CheckoutSession) error
+	Get(where *CheckoutSession) (*CheckoutSession, error)
+	List(merchantID uint64, status CheckoutSessionStatus, beforeID uint64, limit int) ([]CheckoutSession, error)
+	ListRefunds(sessionID uint64) ([]Refund, error)
+	Pay(id uint64, customer *Wallet) (*CheckoutSession, *Posting, error)
+	Cancel(id uint64) (*CheckoutSession, error)
+	Refund(id uint64, amountInCents int, reason string) (*CheckoutSession, *Refund, error)
+}
+
+type ISettlementBatch interface {
+	DueMerchants(cutoff time.Time) ([]uint64, error)
+	Close(merchantID uint64, cutoff time.Time) (*SettlementBatch, error)
+	Get(where *SettlementBatch) (*SettlementBatch, error)
+	List(merchantID uint64, beforeID uint64, limit int) ([]SettlementBatch, error)
+	Lines(b *SettlementBatch) ([]SettlementLine, error)
+}
+
+type IFee interface {
+	Create(s *FeeSchedule) error
+	Get(where *FeeSchedule) (*FeeSchedule, error)
+	List() ([]FeeSchedule, error)
+	Delete(id uint64) error
+	Quote(from *Wallet, to *Wallet, amountInCents int, purposeCode purposecodes.TransactionPurposeCode) (*FeeQuote, error)
+}
+
+type IStreamTicket interface {
+	Issue(accountUUID string, role string, ttl time.Duration) (string, time.Time, error)
+	Redeem(ticket string) (*StreamTicket, error)
+	DeleteExpired() (int64, error)
 }
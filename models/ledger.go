@@ -0,0 +1,301 @@
+package models
+
+import (
+	"coinpe/pkg/constants"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerIssueKind string
+
+const (
+	// LedgerIssueChainBreak: the opening balance is not the closing balance
+	// of the previous transaction of the wallet.
+	LedgerIssueChainBreak LedgerIssueKind = "CHAIN_BREAK"
+	// LedgerIssueClosingMismatch: the closing balance is not the opening
+	// balance plus or minus the amount.
+	LedgerIssueClosingMismatch LedgerIssueKind = "CLOSING_MISMATCH"
+	// LedgerIssueBalanceDrift: the stored wallet balance is not the sum of
+	// its transactions.
+	LedgerIssueBalanceDrift LedgerIssueKind = "BALANCE_DRIFT"
+)
+
+var (
+	ErrLedgerChainBroken = errors.New("wallet transactions do not chain, repair needs a manual investigation")
+	ErrAuditReasonEmpty  = errors.New("an audit reason is required")
+)
+
+type LedgerIssue struct {
+	Kind            LedgerIssueKind
+	TransactionUUID string
+	ExpectedInCents int
+	ActualInCents   int
+}
+
+func (i LedgerIssue) String() string {
+	if i.TransactionUUID == "" {
+		return fmt.Sprintf("%s expected %d, found %d", i.Kind, i.ExpectedInCents, i.ActualInCents)
+	}
+	return fmt.Sprintf("%s at %s expected %d, found %d", i.Kind, i.TransactionUUID, i.ExpectedInCents, i.ActualInCents)
+}
+
+// WalletVerification is the result of replaying the transactions of a
+// wallet from a zero balance.
+type WalletVerification struct {
+	WalletID             uint64
+	WalletUUID           string
+	StoredBalanceInCents int
+	LedgerBalanceInCents int
+	TransactionCount     int
+	Issues               []LedgerIssue
+}
+
+func (v *WalletVerification) OK() bool {
+	return len(v.Issues) == 0
+}
+
+// ChainIntact reports whether every transaction follows from the previous
+// one, which is what makes the ledger balance trustworthy.
+func (v *WalletVerification) ChainIntact() bool {
+	for _, issue := range v.Issues {
+		if issue.Kind != LedgerIssueBalanceDrift {
+			return false
+		}
+	}
+	return true
+}
+
+type ledgerRepo struct {
+	db *gorm.DB
+}
+
+// VerifyWallet implements ILedger.
+func (r *ledgerRepo) VerifyWallet(walletID uint64) (*WalletVerification, error) {
+	var v *WalletVerification
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		v, err = r.verifyWithTx(tx, walletID)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		logger.Error("unable to verify wallet | err: ", err)
+		return nil, err
+	}
+	return v, nil
+}
+
+// Verify implements ILedger. Every wallet is verified in its own snapshot
+// and passed to fn, which can stop the run by returning an error.
+func (r *ledgerRepo) Verify(fn func(v *WalletVerification) error) error {
+	return r.eachWallet(func(wallet Wallet) error {
+		v, err := r.VerifyWallet(wallet.ID)
+		if err != nil {
+			return err
+		}
+		return fn(v)
+	})
+}
+
+// eachWallet calls fn with the ID and UUID of every wallet, in batches.
+func (r *ledgerRepo) eachWallet(fn func(wallet Wallet) error) error {
+	var (
+		afterID   uint64
+		batchSize = 500
+	)
+	for {
+		var wallets []Wallet
+		err := r.db.Model(&Wallet{}).
+			Select("id", "uuid").
+			Where("id > ?", afterID).
+			Order("id asc").
+			Limit(batchSize).
+			Find(&wallets).Error
+		if err != nil {
+			logger.Error("unable to list wallets | err: ", err)
+			return err
+		}
+
+		for _, wallet := range wallets {
+			err = fn(wallet)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(wallets) < batchSize {
+			return nil
+		}
+		afterID = wallets[len(wallets)-1].ID
+	}
+}
+
+func (r *ledgerRepo) verifyWithTx(tx *gorm.DB, walletID uint64) (*WalletVerification, error) {
+	var wallet Wallet
+	err := tx.Where(&Wallet{ID: walletID}).First(&wallet).Error
+	if err != nil {
+		return nil, err
+	}
+
+	v := &WalletVerification{
+		WalletID:             wallet.ID,
+		WalletUUID:           wallet.UUID,
+		StoredBalanceInCents: wallet.TotalBalanceInCents,
+	}
+
+	rows, err := tx.Model(&Transaction{}).
+		Where(&Transaction{WalletID: walletID, Status: constants.EntitySuccess}).
+		Order("id asc").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Transaction
+		err = tx.ScanRows(rows, &t)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.OpeningBalanceInCents != v.LedgerBalanceInCents {
+			v.Issues = append(v.Issues, LedgerIssue{
+				Kind:            LedgerIssueChainBreak,
+				TransactionUUID: t.UUID,
+				ExpectedInCents: v.LedgerBalanceInCents,
+				ActualInCents:   t.OpeningBalanceInCents,
+			})
+		}
+
+		amount := t.AmountInCents
+		if t.Type == constants.TransactionTypeDebit {
+			amount = -amount
+		}
+		if t.ClosingBalanceInCents != t.OpeningBalanceInCents+amount {
+			v.Issues = append(v.Issues, LedgerIssue{
+				Kind:            LedgerIssueClosingMismatch,
+				TransactionUUID: t.UUID,
+				ExpectedInCents: t.OpeningBalanceInCents + amount,
+				ActualInCents:   t.ClosingBalanceInCents,
+			})
+		}
+
+		v.LedgerBalanceInCents += amount
+		v.TransactionCount++
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if v.StoredBalanceInCents != v.LedgerBalanceInCents {
+		v.Issues = append(v.Issues, LedgerIssue{
+			Kind:            LedgerIssueBalanceDrift,
+			ExpectedInCents: v.LedgerBalanceInCents,
+			ActualInCents:   v.StoredBalanceInCents,
+		})
+	}
+
+	return v, nil
+}
+
+// repair locks the wallet, verifies it again and calls fix when its stored
+// balance drifted from an intact ledger.
+func (r *ledgerRepo) repair(walletID uint64, fix func(tx *gorm.DB, wallet *Wallet, v *WalletVerification) error) (*WalletVerification, error) {
+	var v *WalletVerification
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		var wallet Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(&Wallet{ID: walletID}).
+			First(&wallet).Error
+		if err != nil {
+			return err
+		}
+
+		v, err = r.verifyWithTx(tx, walletID)
+		if err != nil {
+			return err
+		}
+		if !v.ChainIntact() {
+			return ErrLedgerChainBroken
+		}
+		if v.OK() {
+			return nil
+		}
+
+		return fix(tx, &wallet, v)
+	})
+	if err != nil {
+		logger.Error("unable to repair wallet | err: ", err)
+		return v, err
+	}
+	return v, nil
+}
+
+// RebuildBalance implements ILedger. It trusts the ledger and resets the
+// stored balance to the sum of the wallet's transactions.
+func (r *ledgerRepo) RebuildBalance(walletID uint64) (*WalletVerification, error) {
+	return r.repair(walletID, func(tx *gorm.DB, wallet *Wallet, v *WalletVerification) error {
+		logger.Warnf("rebuilding balance of wallet %s from %d to %d", wallet.UUID, v.StoredBalanceInCents, v.LedgerBalanceInCents)
+		return InitWalletRepo(tx).UpdateBalance(tx, wallet, v.LedgerBalanceInCents)
+	})
+}
+
+// Adjust implements ILedger. It trusts the stored balance and posts an
+// ADJUSTMENT entry for the difference, carrying reason, so the ledger sums
+// to it again. The stored balance itself does not change.
+func (r *ledgerRepo) Adjust(walletID uint64, reason string) (*Transaction, error) {
+	if reason == "" {
+		return nil, ErrAuditReasonEmpty
+	}
+
+	var adjustment *Transaction
+	_, err := r.repair(walletID, func(tx *gorm.DB, wallet *Wallet, v *WalletVerification) error {
+		difference := v.StoredBalanceInCents - v.LedgerBalanceInCents
+		transactionType := constants.TransactionTypeCredit
+		if difference < 0 {
+			transactionType = constants.TransactionTypeDebit
+			difference = -difference
+		}
+
+		metadata, err := json.Marshal(map[string]interface{}{
+			"audit_reason":            reason,
+			"stored_balance_in_cents": v.StoredBalanceInCents,
+			"ledger_balance_in_cents": v.LedgerBalanceInCents,
+		})
+		if err != nil {
+			return err
+		}
+
+		adjustment = &Transaction{
+			WalletID:              wallet.ID,
+			Type:                  transactionType,
+			AmountInCents:         difference,
+			OpeningBalanceInCents: v.LedgerBalanceInCents,
+			ClosingBalanceInCents: v.StoredBalanceInCents,
+			Status:                constants.EntitySuccess,
+			PurposeCode:           purposecodes.PurposeCodeAdjustment,
+			Description:           reason,
+			Metadata:              datatypes.JSON(metadata),
+		}
+		if transactionType == constants.TransactionTypeCredit {
+			adjustment.ToWalletUUID = wallet.UUID
+		} else {
+			adjustment.FromWalletUUID = wallet.UUID
+		}
+
+		logger.Warnf("adjusting ledger of wallet %s by %s %d: %s", wallet.UUID, transactionType, difference, reason)
+		return InitTransactionRepo(tx).CreateWithTx(tx, adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
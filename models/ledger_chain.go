@@ -0,0 +1,318 @@
+package models
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type ChainIssueKind string
+
+const (
+	ChainIssueHashMismatch       ChainIssueKind = "HASH_MISMATCH"
+	ChainIssuePrevHashMismatch   ChainIssueKind = "PREV_HASH_MISMATCH"
+	ChainIssueSequenceGap        ChainIssueKind = "SEQUENCE_GAP"
+	ChainIssueDeleted            ChainIssueKind = "DELETED"
+	ChainIssueGlobalHashMismatch ChainIssueKind = "GLOBAL_HASH_MISMATCH"
+	ChainIssueCheckpointInvalid  ChainIssueKind = "CHECKPOINT_SIGNATURE_INVALID"
+	ChainIssueCheckpointMismatch ChainIssueKind = "CHECKPOINT_MISMATCH"
+	ChainIssueCheckpointMissing  ChainIssueKind = "CHECKPOINT_ROWS_MISSING"
+)
+
+// every instance may run checkpoint-ledger, sealing is serialised on this lock
+const globalChainLockName = "coinpe:global_chain"
+
+var ErrTransactionTampered = errors.New("unsealed transaction does not match its hash")
+
+type ChainIssue struct {
+	Kind            ChainIssueKind `json:"kind"`
+	TransactionUUID string         `json:"transaction_uuid,omitempty"`
+	Detail          string         `json:"detail"`
+}
+
+type ChainVerification struct {
+	TransactionCount int
+	HeadSequence     int64
+	HeadHash         string
+	Issues           []ChainIssue
+}
+
+func (v *ChainVerification) OK() bool {
+	return len(v.Issues) == 0
+}
+
+// VerifyWalletChain implements ILedger. It walks the chain of the wallet,
+// soft deleted transactions included.
+func (r *ledgerRepo) VerifyWalletChain(walletID uint64) (*ChainVerification, error) {
+	v := &ChainVerification{}
+
+	rows, err := r.db.Unscoped().Model(&Transaction{}).
+		Where(&Transaction{WalletID: walletID}).
+		Order("wallet_sequence asc").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Transaction
+		err = r.db.ScanRows(rows, &t)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.WalletSequence != v.HeadSequence+1 {
+			v.Issues = append(v.Issues, ChainIssue{
+				Kind:            ChainIssueSequenceGap,
+				TransactionUUID: t.UUID,
+				Detail:          fmt.Sprintf("expected wallet sequence %d, found %d", v.HeadSequence+1, t.WalletSequence),
+			})
+		}
+		if t.PrevHash != v.HeadHash {
+			v.Issues = append(v.Issues, ChainIssue{
+				Kind:            ChainIssuePrevHashMismatch,
+				TransactionUUID: t.UUID,
+				Detail:          "previous hash does not match the previous transaction",
+			})
+		}
+		if t.ContentHash() != t.Hash {
+			v.Issues = append(v.Issues, ChainIssue{
+				Kind:            ChainIssueHashMismatch,
+				TransactionUUID: t.UUID,
+				Detail:          "contents do not match the hash",
+			})
+		}
+		if t.DeletedAt.Valid {
+			v.Issues = append(v.Issues, ChainIssue{
+				Kind:            ChainIssueDeleted,
+				TransactionUUID: t.UUID,
+				Detail:          "transaction is soft deleted",
+			})
+		}
+
+		v.TransactionCount++
+		v.HeadSequence = t.WalletSequence
+		v.HeadHash = t.Hash
+	}
+	return v, rows.Err()
+}
+
+// VerifyWalletChains implements ILedger. The chain of every wallet is
+// verified and passed to fn, which can stop the run by returning an error.
+func (r *ledgerRepo) VerifyWalletChains(fn func(walletUUID string, v *ChainVerification) error) error {
+	return r.eachWallet(func(wallet Wallet) error {
+		v, err := r.VerifyWalletChain(wallet.ID)
+		if err != nil {
+			return err
+		}
+		return fn(wallet.UUID, v)
+	})
+}
+
+// SealGlobalChain implements ILedger. It appends up to limit committed,
+// unsealed transactions to the global chain in id order and returns how
+// many were sealed. A transaction with a lower id that commits afterwards is
+// appended by a later call, so global_sequence is not id or commit order.
+// It refuses to seal a transaction that no longer matches its hash.
+func (r *ledgerRepo) SealGlobalChain(limit int) (int, error) {
+	var sealed int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", globalChainLockName).Error
+		if err != nil {
+			return err
+		}
+
+		var head Transaction
+		err = tx.Unscoped().
+			Select("global_sequence", "global_hash").
+			Where("global_sequence IS NOT NULL").
+			Order("global_sequence desc").
+			Limit(1).
+			Find(&head).Error
+		if err != nil {
+			return err
+		}
+
+		var (
+			sequence   int64
+			globalHash = head.GlobalHash
+		)
+		if head.GlobalSequence != nil {
+			sequence = *head.GlobalSequence
+		}
+
+		var pending []Transaction
+		err = tx.Unscoped().
+			Where("global_sequence IS NULL").
+			Order("id asc").
+			Limit(limit).
+			Find(&pending).Error
+		if err != nil {
+			return err
+		}
+
+		for _, t := range pending {
+			if t.ContentHash() != t.Hash {
+				return fmt.Errorf("%w: %s", ErrTransactionTampered, t.UUID)
+			}
+
+			sequence++
+			globalHash = GlobalChainHash(sequence, t.Hash, globalHash)
+			err = tx.Unscoped().Model(&Transaction{}).
+				Where("id = ?", t.ID).
+				UpdateColumns(map[string]interface{}{
+					"global_sequence": sequence,
+					"global_hash":     globalHash,
+				}).Error
+			if err != nil {
+				return err
+			}
+			sealed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sealed, nil
+}
+
+// CreateCheckpoint implements ILedger. It signs the head of the global chain,
+// nil is returned when nothing was sealed since the latest checkpoint.
+func (r *ledgerRepo) CreateCheckpoint(key ed25519.PrivateKey) (*LedgerCheckpoint, error) {
+	var head Transaction
+	err := r.db.Unscoped().
+		Select("global_sequence", "global_hash").
+		Where("global_sequence IS NOT NULL").
+		Order("global_sequence desc").
+		Limit(1).
+		Find(&head).Error
+	if err != nil || head.GlobalSequence == nil {
+		return nil, err
+	}
+
+	latest, err := r.LatestCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.GlobalSequence >= *head.GlobalSequence {
+		return nil, nil
+	}
+
+	checkpoint := &LedgerCheckpoint{
+		GlobalSequence: *head.GlobalSequence,
+		GlobalHash:     head.GlobalHash,
+	}
+	checkpoint.sign(key)
+
+	err = r.db.Create(checkpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// LatestCheckpoint implements ILedger, nil when there is none yet.
+func (r *ledgerRepo) LatestCheckpoint() (*LedgerCheckpoint, error) {
+	var checkpoints []LedgerCheckpoint
+	err := r.db.Order("global_sequence desc, id desc").Limit(1).Find(&checkpoints).Error
+	if err != nil || len(checkpoints) == 0 {
+		return nil, err
+	}
+	return &checkpoints[0], nil
+}
+
+// VerifyGlobalChain implements ILedger. It walks the whole global chain,
+// recomputing every hash, and checks each checkpoint is signed by publicKey
+// and still reached by the chain.
+func (r *ledgerRepo) VerifyGlobalChain(publicKey ed25519.PublicKey) (*ChainVerification, error) {
+	var checkpoints []LedgerCheckpoint
+	err := r.db.Order("global_sequence asc").Find(&checkpoints).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		v             = &ChainVerification{}
+		checkpointsAt = map[int64][]LedgerCheckpoint{}
+	)
+	for _, c := range checkpoints {
+		if !c.VerifySignature(publicKey) {
+			v.Issues = append(v.Issues, ChainIssue{
+				Kind:   ChainIssueCheckpointInvalid,
+				Detail: fmt.Sprintf("checkpoint %d is not signed by the configured key", c.ID),
+			})
+			continue
+		}
+		checkpointsAt[c.GlobalSequence] = append(checkpointsAt[c.GlobalSequence], c)
+	}
+
+	rows, err := r.db.Unscoped().Model(&Transaction{}).
+		Where("global_sequence IS NOT NULL").
+		Order("global_sequence asc").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Transaction
+		err = r.db.ScanRows(rows, &t)
+		if err != nil {
+			return nil, err
+		}
+
+		if *t.GlobalSequence != v.HeadSequence+1 {
+			v.Issues = append(v.Issues, ChainIssue{
+				Kind:            ChainIssueSequenceGap,
+				TransactionUUID: t.UUID,
+				Detail:          fmt.Sprintf("expected global sequence %d, found %d", v.HeadSequence+1, *t.GlobalSequence),
+			})
+		}
+		if t.ContentHash() != t.Hash {
+			v.Issues = append(v.Issues, ChainIssue{
+				Kind:            ChainIssueHashMismatch,
+				TransactionUUID: t.UUID,
+				Detail:          "contents do not match the hash",
+			})
+		}
+
+		expected := GlobalChainHash(*t.GlobalSequence, t.Hash, v.HeadHash)
+		if t.GlobalHash != expected {
+			v.Issues = append(v.Issues, ChainIssue{
+				Kind:            ChainIssueGlobalHashMismatch,
+				TransactionUUID: t.UUID,
+				Detail:          "global hash does not follow from the previous transaction",
+			})
+		}
+
+		for _, c := range checkpointsAt[*t.GlobalSequence] {
+			if c.GlobalHash != expected {
+				v.Issues = append(v.Issues, ChainIssue{
+					Kind:            ChainIssueCheckpointMismatch,
+					TransactionUUID: t.UUID,
+					Detail:          fmt.Sprintf("chain no longer reaches checkpoint %d", c.ID),
+				})
+			}
+		}
+
+		v.TransactionCount++
+		v.HeadSequence = *t.GlobalSequence
+		v.HeadHash = t.GlobalHash
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if n := len(checkpoints); n > 0 && checkpoints[n-1].GlobalSequence > v.HeadSequence {
+		v.Issues = append(v.Issues, ChainIssue{
+			Kind:   ChainIssueCheckpointMissing,
+			Detail: fmt.Sprintf("checkpoint %d covers sequence %d, the chain ends at %d", checkpoints[n-1].ID, checkpoints[n-1].GlobalSequence, v.HeadSequence),
+		})
+	}
+	return v, nil
+}
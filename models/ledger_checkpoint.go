@@ -0,0 +1,65 @@
+package models
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// LedgerCheckpoint is a signed head of the global transaction chain. Rows
+// sealed up to GlobalSequence cannot be edited, removed or truncated without
+// the chain no longer reaching GlobalHash.
+type LedgerCheckpoint struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	GlobalSequence int64  `json:"global_sequence" gorm:"not null;index"`
+	GlobalHash     string `json:"global_hash" gorm:"not null"`
+	KeyID          string `json:"key_id" gorm:"not null"`
+	Signature      string `json:"signature" gorm:"not null"`
+}
+
+// ParseCheckpointSigningKey parses the base64 encoded 32 byte ed25519 seed of
+// LEDGER_CHECKPOINT_SIGNING_KEY.
+func ParseCheckpointSigningKey(encoded string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint signing key is not base64: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("checkpoint signing key must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// CheckpointKeyID identifies publicKey on checkpoints, so a rotated key is
+// told apart from a forged signature.
+func CheckpointKeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+func checkpointMessage(sequence int64, globalHash string) []byte {
+	return []byte("coinpe-ledger-checkpoint:" + strconv.FormatInt(sequence, 10) + ":" + globalHash)
+}
+
+func (c *LedgerCheckpoint) sign(key ed25519.PrivateKey) {
+	c.KeyID = CheckpointKeyID(key.Public().(ed25519.PublicKey))
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, checkpointMessage(c.GlobalSequence, c.GlobalHash)))
+}
+
+// VerifySignature reports whether c was signed by publicKey.
+func (c *LedgerCheckpoint) VerifySignature(publicKey ed25519.PublicKey) bool {
+	if c.KeyID != CheckpointKeyID(publicKey) {
+		return false
+	}
+	signature, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(publicKey, checkpointMessage(c.GlobalSequence, c.GlobalHash), signature)
+}
@@ -0,0 +1,118 @@
+package models_test
+
+import (
+	"coinpe/database"
+	"coinpe/database/databasetest"
+	"coinpe/migrations"
+	"coinpe/models"
+	"coinpe/pkg/purposecodes"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestOpeningBalanceMigration replays 0017_opening_balance_entries over a
+// treasury seeded the way the first releases did, at 100000 without ledger
+// rows, and checks the ledger and the hash chain accept the opening entry.
+func TestOpeningBalanceMigration(t *testing.T) {
+	db := databasetest.New(t)
+	ctx := context.Background()
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps int
+	for _, status := range statuses {
+		if status.Version >= 17 && status.AppliedAt != nil {
+			steps++
+		}
+	}
+	_, err = migrator.Down(ctx, steps)
+	if err != nil {
+		t.Fatalf("roll back the backfill: %s", err)
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	legacy := []models.Wallet{
+		{UserUUID: "acc_legacytreasury", UUID: "wa_legacytreasury0001", Currency: models.EntityINR, TotalBalanceInCents: 100000, CreatedAt: &createdAt},
+		{UserUUID: "acc_legacyempty", UUID: "wa_legacyempty0000001", Currency: models.EntityINR},
+	}
+	for i := range legacy {
+		err = db.Create(&legacy[i]).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a wallet with history keeps its drift for verify-ledger --repair
+	walletRepo := models.InitWalletRepo(db)
+	funded := models.Wallet{UserUUID: "acc_legacyfunded", UUID: "wa_legacyfunded000001", Currency: models.EntityINR}
+	err = walletRepo.Create(&funded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = walletRepo.Credit(&funded, &models.Transaction{AmountInCents: 500}, purposecodes.PurposeCodeAddFunds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Model(&models.Wallet{}).Where("id = ?", funded.ID).Update("total_balance_in_cents", 900).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("apply the backfill: %s", err)
+	}
+
+	ledgerRepo := models.InitLedgerRepo(db)
+	tests := []struct {
+		wallet       models.Wallet
+		transactions int64
+		ok           bool
+	}{
+		{legacy[0], 1, true},
+		{legacy[1], 0, true},
+		{funded, 1, false},
+	}
+
+	for _, tt := range tests {
+		var count int64
+		err = db.Model(&models.Transaction{}).Where("wallet_id = ?", tt.wallet.ID).Count(&count).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != tt.transactions {
+			t.Errorf("wallet %s has %d transactions, want %d", tt.wallet.UUID, count, tt.transactions)
+		}
+
+		v, err := ledgerRepo.VerifyWallet(tt.wallet.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.OK() != tt.ok {
+			t.Errorf("wallet %s verifies %t, want %t: %v", tt.wallet.UUID, v.OK(), tt.ok, v.Issues)
+		}
+
+		chain, err := ledgerRepo.VerifyWalletChain(tt.wallet.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !chain.OK() {
+			t.Errorf("wallet %s chain is broken: %v", tt.wallet.UUID, chain.Issues)
+		}
+	}
+
+	balance, err := ledgerRepo.BalanceAt(legacy[0].ID, createdAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 100000 {
+		t.Errorf("treasury balance at creation is %d, want 100000", balance)
+	}
+}
@@ -0,0 +1,312 @@
+package models
+
+import (
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntityMerchant        = "mer_"
+	EntityMerchantWebhook = "evt_"
+	merchantWebhookSecret = "whsec_"
+)
+
+type MerchantWebhookEvent string
+
+const (
+	MerchantWebhookCheckoutCompleted MerchantWebhookEvent = "checkout_session.completed"
+	MerchantWebhookRefundSucceeded   MerchantWebhookEvent = "refund.succeeded"
+)
+
+var ErrMerchantExists = errorConst.New(errorConst.ErrorConflict, errorConst.MsgMerchantProfileExists)
+
+// Merchant is the business profile of a MERCHANT account. Checkout
+// sessions pay its pending wallet, owned by the merchant profile itself,
+// and settlement batches move what it received to its account's wallet.
+// Events are POSTed to WebhookURL, signed with WebhookSecret.
+type Merchant struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID          string `json:"uuid" gorm:"unique;not null"`
+	AccountUUID   string `json:"account_uuid" gorm:"unique;not null"`
+	WalletID      uint64 `json:"wallet_id" gorm:"not null"`
+	WalletUUID    string `json:"wallet_uuid" gorm:"not null"`
+	Name          string `json:"name" gorm:"not null"`
+	WebhookURL    string `json:"webhook_url,omitempty"`
+	WebhookSecret string `json:"-" gorm:"not null"`
+
+	PendingWalletID   *uint64 `json:"pending_wallet_id,omitempty"`
+	PendingWalletUUID string  `json:"pending_wallet_uuid,omitempty"`
+}
+
+// MerchantWebhook is an event of a checkout session, and of one of its
+// refunds, waiting to be POSTed to its merchant (PENDING), sent
+// (DELIVERED) or given up on (FAILED). It is stored in the transaction
+// causing it, so no event is lost or sent for a change rolled back.
+type MerchantWebhook struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID              string               `json:"uuid" gorm:"unique;not null"`
+	MerchantID        uint64               `json:"merchant_id" gorm:"not null;index"`
+	Event             MerchantWebhookEvent `json:"event" gorm:"not null"`
+	CheckoutSessionID uint64               `json:"checkout_session_id" gorm:"not null"`
+	RefundID          *uint64              `json:"refund_id,omitempty"`
+	URL               string               `json:"url" gorm:"not null"`
+
+	Status       WebhookStatus `json:"status" gorm:"not null;index:idx_merchant_webhooks_due"`
+	Attempts     int           `json:"attempts" gorm:"not null;default:0"`
+	DeliverAfter time.Time     `json:"deliver_after" gorm:"not null;index:idx_merchant_webhooks_due"`
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+type merchantRepo struct {
+	db *gorm.DB
+}
+
+func (m *Merchant) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.UUID == "" {
+		m.UUID, err = utils.GenerateNanoID(16, EntityMerchant)
+		if err != nil {
+			return err
+		}
+	}
+	if m.WebhookSecret == "" {
+		m.WebhookSecret, err = utils.GenerateNanoID(32, merchantWebhookSecret)
+	}
+	return err
+}
+
+func (w *MerchantWebhook) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.UUID == "" {
+		w.UUID, err = utils.GenerateNanoID(20, EntityMerchantWebhook)
+	}
+	return err
+}
+
+// Create implements IMerchant.
+func (r *merchantRepo) Create(m *Merchant) error {
+	var existing []Merchant
+	err := r.db.Where(&Merchant{AccountUUID: m.AccountUUID}).Limit(1).Find(&existing).Error
+	if err != nil {
+		logger.Error("unable to query merchant | err: ", err)
+		return err
+	}
+	if len(existing) > 0 {
+		return ErrMerchantExists
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(m).Error
+		if err != nil {
+			return err
+		}
+
+		_, err = pendingWalletWithTx(tx, m)
+		return err
+	})
+	if err != nil {
+		logger.Error("unable to create merchant | err: ", err)
+		return err
+	}
+	return nil
+}
+
+// pendingWalletWithTx returns the wallet holding the receivables of m
+// until they settle, in the currency of its account's wallet. Merchants
+// made before settlement batches get theirs on their next payment.
+func pendingWalletWithTx(tx *gorm.DB, m *Merchant) (*Wallet, error) {
+	walletRepo := InitWalletRepo(tx)
+	if m.PendingWalletID != nil {
+		return walletRepo.GetWithTx(tx, &Wallet{ID: *m.PendingWalletID})
+	}
+
+	available, err := walletRepo.GetWithTx(tx, &Wallet{ID: m.WalletID})
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrCheckoutWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// a concurrent first payment may create it first, the insert is then
+	// skipped and the read below finds theirs
+	err = walletRepo.CreateWithTx(tx, &Wallet{UserUUID: m.UUID, Currency: available.Currency})
+	if err != nil {
+		return nil, err
+	}
+	pending, err := walletRepo.GetWithTx(tx, &Wallet{UserUUID: m.UUID})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Model(&Merchant{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+		"pending_wallet_id":   pending.ID,
+		"pending_wallet_uuid": pending.UUID,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	m.PendingWalletID = &pending.ID
+	m.PendingWalletUUID = pending.UUID
+	return pending, nil
+}
+
+// Get implements IMerchant.
+func (r *merchantRepo) Get(where *Merchant) (*Merchant, error) {
+	var m Merchant
+	err := r.db.Where(where).First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Update implements IMerchant. It sets the name and webhook URL of the
+// merchant id, an empty webhook URL turning webhooks off.
+func (r *merchantRepo) Update(id uint64, name string, webhookURL string) (*Merchant, error) {
+	err := r.db.Model(&Merchant{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"name":        name,
+		"webhook_url": webhookURL,
+	}).Error
+	if err != nil {
+		logger.Error("unable to update merchant | err: ", err)
+		return nil, err
+	}
+	return r.Get(&Merchant{ID: id})
+}
+
+// RotateWebhookSecret implements IMerchant.
+func (r *merchantRepo) RotateWebhookSecret(id uint64) (*Merchant, error) {
+	secret, err := utils.GenerateNanoID(32, merchantWebhookSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&Merchant{}).Where("id = ?", id).Update("webhook_secret", secret).Error
+	if err != nil {
+		logger.Error("unable to rotate merchant webhook secret | err: ", err)
+		return nil, err
+	}
+	return r.Get(&Merchant{ID: id})
+}
+
+// enqueueMerchantWebhookWithTx stores event of session, and refund when
+// set, for merchant, unless the merchant has no webhook URL.
+func enqueueMerchantWebhookWithTx(tx *gorm.DB, merchant *Merchant, event MerchantWebhookEvent, session *CheckoutSession, refund *Refund) error {
+	if merchant.WebhookURL == "" {
+		return nil
+	}
+
+	w := &MerchantWebhook{
+		MerchantID:        merchant.ID,
+		Event:             event,
+		CheckoutSessionID: session.ID,
+		URL:               merchant.WebhookURL,
+		Status:            WebhookPending,
+		DeliverAfter:      time.Now(),
+	}
+	if refund != nil {
+		w.RefundID = &refund.ID
+	}
+	return tx.Create(w).Error
+}
+
+// DeliverNextWebhook implements IMerchant. It claims the next due merchant
+// webhook and, once the claim is committed, calls deliver with it, its
+// merchant, checkout session and refund, then records the outcome. As for
+// pending transactions, see pendingTransactionRepo.DeliverNextWebhook, a
+// claim lasts for timeout and webhookClaimGrace and webhooks are delivered
+// at least once. A failed delivery is retried with backoff until
+// maxAttempts. It reports whether a webhook was due.
+func (r *merchantRepo) DeliverNextWebhook(maxAttempts int, backoff time.Duration, timeout time.Duration, deliver func(w *MerchantWebhook, m *Merchant, s *CheckoutSession, refund *Refund) error) (bool, error) {
+	var (
+		w        *MerchantWebhook
+		merchant Merchant
+		session  CheckoutSession
+		refund   *Refund
+	)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var webhooks []MerchantWebhook
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND deliver_after <= ?", WebhookPending, time.Now()).
+			Order("deliver_after asc").
+			Limit(1).
+			Find(&webhooks).Error
+		if err != nil || len(webhooks) == 0 {
+			return err
+		}
+		claimed := &webhooks[0]
+
+		err = tx.First(&merchant, claimed.MerchantID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.First(&session, claimed.CheckoutSessionID).Error
+		if err != nil {
+			return err
+		}
+		if claimed.RefundID != nil {
+			refund = &Refund{}
+			err = tx.First(refund, *claimed.RefundID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		claimed.Attempts++
+		err = tx.Model(&MerchantWebhook{}).Where("id = ?", claimed.ID).Updates(map[string]interface{}{
+			"attempts":      claimed.Attempts,
+			"deliver_after": time.Now().Add(timeout + webhookClaimGrace),
+		}).Error
+		if err != nil {
+			return err
+		}
+		w = claimed
+		return nil
+	})
+	if err != nil {
+		logger.Error("unable to claim merchant webhook | err: ", err)
+		return false, err
+	}
+	if w == nil {
+		return false, nil
+	}
+
+	updates := map[string]interface{}{}
+	err = deliver(w, &merchant, &session, refund)
+	switch {
+	case err == nil:
+		updates["status"] = WebhookDelivered
+		updates["last_error"] = ""
+	case w.Attempts >= maxAttempts:
+		logger.Error("giving up on merchant webhook ", w.UUID, " | err: ", err)
+		updates["status"] = WebhookFailed
+		updates["last_error"] = err.Error()
+	default:
+		logger.Warnf("merchant webhook %s attempt %d failed: %s", w.UUID, w.Attempts, err)
+		updates["deliver_after"] = time.Now().Add(queueBackoff(backoff, w.Attempts))
+		updates["last_error"] = err.Error()
+	}
+
+	// a worker that claimed the webhook again after the claim ran out owns it now
+	result := r.db.Model(&MerchantWebhook{}).
+		Where("id = ? AND status = ? AND attempts = ?", w.ID, WebhookPending, w.Attempts).
+		Updates(updates)
+	if result.Error != nil {
+		logger.Error("unable to record merchant webhook delivery | err: ", result.Error)
+		return true, result.Error
+	}
+	if result.RowsAffected == 0 {
+		logger.Warnf("merchant webhook %s was claimed again while attempt %d ran", w.UUID, w.Attempts)
+	}
+	return true, nil
+}
@@ -0,0 +1,108 @@
+// Package modelstest has in-memory stand-ins for model repositories, for
+// tests of their callers that need no database.
+package modelstest
+
+import (
+	"coinpe/models"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeys is a models.IIdempotencyKey keeping keys in memory, with
+// the reservation rules of the Postgres one.
+type IdempotencyKeys struct {
+	mu     sync.Mutex
+	nextID uint64
+	keys   map[[2]string]models.IdempotencyKey
+}
+
+// NewIdempotencyKeys returns an empty IdempotencyKeys.
+func NewIdempotencyKeys() *IdempotencyKeys {
+	return &IdempotencyKeys{keys: map[[2]string]models.IdempotencyKey{}}
+}
+
+// Reserve implements models.IIdempotencyKey.
+func (s *IdempotencyKeys) Reserve(k *models.IdempotencyKey) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	existing, ok := s.keys[[2]string{k.Scope, k.Key}]
+	if ok {
+		stale := existing.CompletedAt == nil && existing.LockedUntil.Before(now) && existing.RequestHash == k.RequestHash
+		expired := existing.ExpiresAt != nil && existing.ExpiresAt.Before(now)
+		if !stale && !expired {
+			return false, nil
+		}
+	}
+
+	s.nextID++
+	lockedUntil := now.Add(models.IdempotencyKeyLockTTL)
+	k.ID = s.nextID
+	k.LockedUntil = &lockedUntil
+	k.CompletedAt, k.ExpiresAt, k.ResponseStatus, k.ResponseBody = nil, nil, 0, nil
+	s.keys[[2]string{k.Scope, k.Key}] = *k
+	return true, nil
+}
+
+// Get implements models.IIdempotencyKey.
+func (s *IdempotencyKeys) Get(scope string, key string) (*models.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[[2]string{scope, key}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &k, nil
+}
+
+// Complete implements models.IIdempotencyKey.
+func (s *IdempotencyKeys) Complete(k *models.IdempotencyKey, responseStatus int, responseBody []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.keys[[2]string{k.Scope, k.Key}]
+	if !ok || existing.ID != k.ID || existing.CompletedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(models.IdempotencyKeyRetention)
+	existing.ResponseStatus = responseStatus
+	existing.ResponseBody = append([]byte(nil), responseBody...)
+	existing.CompletedAt = &now
+	existing.ExpiresAt = &expiresAt
+	s.keys[[2]string{k.Scope, k.Key}] = existing
+	return nil
+}
+
+// Release implements models.IIdempotencyKey.
+func (s *IdempotencyKeys) Release(k *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.keys[[2]string{k.Scope, k.Key}]
+	if ok && existing.ID == k.ID && existing.CompletedAt == nil {
+		delete(s.keys, [2]string{k.Scope, k.Key})
+	}
+	return nil
+}
+
+// DeleteExpired implements models.IIdempotencyKey.
+func (s *IdempotencyKeys) DeleteExpired() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	now := time.Now()
+	for key, k := range s.keys {
+		if (k.ExpiresAt != nil && k.ExpiresAt.Before(now)) || (k.CompletedAt == nil && k.LockedUntil.Before(now)) {
+			delete(s.keys, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,68 @@
+package modelstest
+
+import (
+	"coinpe/models"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StreamTickets is a models.IStreamTicket keeping tickets in memory.
+type StreamTickets struct {
+	mu      sync.Mutex
+	issued  int
+	tickets map[string]models.StreamTicket
+}
+
+// NewStreamTickets returns an empty StreamTickets.
+func NewStreamTickets() *StreamTickets {
+	return &StreamTickets{tickets: map[string]models.StreamTicket{}}
+}
+
+// Issue implements models.IStreamTicket.
+func (s *StreamTickets) Issue(accountUUID string, role string, ttl time.Duration) (string, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.issued++
+	ticket := fmt.Sprintf("%s%d", models.EntityStreamTicket, s.issued)
+	expiresAt := time.Now().Add(ttl)
+	s.tickets[ticket] = models.StreamTicket{
+		ID:          uint64(s.issued),
+		AccountUUID: accountUUID,
+		Role:        role,
+		ExpiresAt:   expiresAt,
+	}
+	return ticket, expiresAt, nil
+}
+
+// Redeem implements models.IStreamTicket.
+func (s *StreamTickets) Redeem(ticket string) (*models.StreamTicket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tickets[ticket]
+	if !ok || !t.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	delete(s.tickets, ticket)
+	return &t, nil
+}
+
+// DeleteExpired implements models.IStreamTicket.
+func (s *StreamTickets) DeleteExpired() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	now := time.Now()
+	for ticket, t := range s.tickets {
+		if !t.ExpiresAt.After(now) {
+			delete(s.tickets, ticket)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,303 @@
+package models
+
+import (
+	"coinpe/database"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntityPaymentRequest = "prq_"
+	EntitySplitBill      = "spl_"
+)
+
+type PaymentRequestStatus string
+
+const (
+	PaymentRequestPending   PaymentRequestStatus = "PENDING"
+	PaymentRequestPaid      PaymentRequestStatus = "PAID"
+	PaymentRequestDeclined  PaymentRequestStatus = "DECLINED"
+	PaymentRequestCancelled PaymentRequestStatus = "CANCELLED"
+	PaymentRequestExpired   PaymentRequestStatus = "EXPIRED"
+)
+
+var (
+	ErrPaymentRequestSelf       = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgPaymentRequestToSelf)
+	ErrPaymentRequestNotPending = errorConst.New(errorConst.ErrorConflict, errorConst.MsgPaymentRequestIsNoLongerPending)
+	ErrPaymentRequestExpired    = errorConst.New(errorConst.ErrorConflict, errorConst.MsgPaymentRequestHasExpired)
+)
+
+// PaymentRequest asks the owner of the payer wallet to pay AmountInCents to
+// the requester's wallet. It is PENDING until the payer approves it, which
+// transfers the amount (PAID, with the ReferenceID of the transfer), or
+// declines it, the requester cancels it or ExpiresAt passes.
+type PaymentRequest struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID          string  `json:"uuid" gorm:"unique;not null"`
+	SplitBillID   *uint64 `json:"split_bill_id,omitempty" gorm:"index"`
+	SplitBillUUID string  `json:"split_bill_uuid,omitempty"`
+
+	RequesterAccountUUID string `json:"requester_account_uuid" gorm:"not null;index"`
+	RequesterWalletID    uint64 `json:"requester_wallet_id" gorm:"not null"`
+	RequesterWalletUUID  string `json:"requester_wallet_uuid" gorm:"not null"`
+	PayerAccountUUID     string `json:"payer_account_uuid" gorm:"not null;index"`
+	PayerWalletID        uint64 `json:"payer_wallet_id" gorm:"not null"`
+	PayerWalletUUID      string `json:"payer_wallet_uuid" gorm:"not null"`
+	AmountInCents        int    `json:"amount_in_cents" gorm:"not null"`
+	Note                 string `json:"note,omitempty"`
+
+	Status        PaymentRequestStatus `json:"status" gorm:"not null;index:idx_payment_requests_expiry"`
+	ExpiresAt     time.Time            `json:"expires_at" gorm:"not null;index:idx_payment_requests_expiry"`
+	RespondedAt   *time.Time           `json:"responded_at,omitempty"`
+	DeclineReason string               `json:"decline_reason,omitempty"`
+	ReferenceID   string               `json:"reference_id,omitempty"`
+}
+
+// SplitBill fans one bill out to several payers as payment requests, which
+// track who has paid.
+type SplitBill struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID                 string    `json:"uuid" gorm:"unique;not null"`
+	RequesterAccountUUID string    `json:"requester_account_uuid" gorm:"not null;index"`
+	RequesterWalletUUID  string    `json:"requester_wallet_uuid" gorm:"not null"`
+	TotalInCents         int       `json:"total_in_cents" gorm:"not null"`
+	Note                 string    `json:"note,omitempty"`
+	ExpiresAt            time.Time `json:"expires_at" gorm:"not null"`
+}
+
+type paymentRequestRepo struct {
+	db *gorm.DB
+}
+
+func (p *PaymentRequest) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.UUID == "" {
+		p.UUID, err = utils.GenerateNanoID(16, EntityPaymentRequest)
+	}
+	return err
+}
+
+func (s *SplitBill) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.UUID == "" {
+		s.UUID, err = utils.GenerateNanoID(16, EntitySplitBill)
+	}
+	return err
+}
+
+// CurrentStatus is the status of p, EXPIRED once a PENDING request is past
+// ExpiresAt even before the expire-payment-requests job marks it.
+func (p *PaymentRequest) CurrentStatus() PaymentRequestStatus {
+	if p.Status == PaymentRequestPending && !time.Now().Before(p.ExpiresAt) {
+		return PaymentRequestExpired
+	}
+	return p.Status
+}
+
+// Create implements IPaymentRequest. It stores requests PENDING, and split
+// first when it is set, all of them or none.
+func (r *paymentRequestRepo) Create(requests []PaymentRequest, split *SplitBill) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if split != nil {
+			err := tx.Create(split).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := range requests {
+			if requests[i].PayerAccountUUID == requests[i].RequesterAccountUUID {
+				return ErrPaymentRequestSelf
+			}
+			if split != nil {
+				requests[i].SplitBillID = &split.ID
+				requests[i].SplitBillUUID = split.UUID
+			}
+			requests[i].Status = PaymentRequestPending
+		}
+		return tx.Create(&requests).Error
+	})
+	if err != nil {
+		logger.Error("unable to create payment requests | err: ", err)
+		return err
+	}
+	return nil
+}
+
+// Get implements IPaymentRequest.
+func (r *paymentRequestRepo) Get(where *PaymentRequest) (*PaymentRequest, error) {
+	var p PaymentRequest
+	err := r.db.Where(where).First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List implements IPaymentRequest. It returns the payment requests the
+// account has to pay, or has made when outgoing, newest first and only
+// those in status when it is set.
+func (r *paymentRequestRepo) List(accountUUID string, outgoing bool, status PaymentRequestStatus, beforeID uint64, limit int) ([]PaymentRequest, error) {
+	requests := []PaymentRequest{}
+
+	where := &PaymentRequest{PayerAccountUUID: accountUUID}
+	if outgoing {
+		where = &PaymentRequest{RequesterAccountUUID: accountUUID}
+	}
+	builder := database.Replica(r.db).Where(where)
+
+	// requests past their expiry are EXPIRED whether the job marked them yet or not
+	now := time.Now()
+	switch status {
+	case "":
+	case PaymentRequestPending:
+		builder = builder.Where("status = ? AND expires_at > ?", status, now)
+	case PaymentRequestExpired:
+		builder = builder.Where("status = ? OR (status = ? AND expires_at <= ?)", status, PaymentRequestPending, now)
+	default:
+		builder = builder.Where("status = ?", status)
+	}
+	if beforeID > 0 {
+		builder = builder.Where("id < ?", beforeID)
+	}
+
+	err := builder.Order("id desc").
+		Limit(limit).
+		Find(&requests).Error
+	if err != nil {
+		logger.Error("unable to list payment requests | err: ", err)
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetSplit implements IPaymentRequest. It returns the split bill with its
+// payment requests in the order they were made.
+func (r *paymentRequestRepo) GetSplit(where *SplitBill) (*SplitBill, []PaymentRequest, error) {
+	var split SplitBill
+	err := r.db.Where(where).First(&split).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requests := []PaymentRequest{}
+	err = r.db.Where(&PaymentRequest{SplitBillID: &split.ID}).Order("id asc").Find(&requests).Error
+	if err != nil {
+		logger.Error("unable to list split bill payment requests | err: ", err)
+		return nil, nil, err
+	}
+	return &split, requests, nil
+}
+
+// respond locks the payment request id, checks that it is still pending and
+// applies respond to it in the same database transaction.
+func (r *paymentRequestRepo) respond(id uint64, respond func(tx *gorm.DB, p *PaymentRequest) error) (*PaymentRequest, error) {
+	var p PaymentRequest
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		p = PaymentRequest{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&p, id).Error
+		if err != nil {
+			return err
+		}
+
+		switch p.CurrentStatus() {
+		case PaymentRequestPending:
+		case PaymentRequestExpired:
+			return ErrPaymentRequestExpired
+		default:
+			return ErrPaymentRequestNotPending
+		}
+
+		err = respond(tx, &p)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		p.RespondedAt = &now
+		return tx.Model(&PaymentRequest{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+			"status":         p.Status,
+			"responded_at":   p.RespondedAt,
+			"decline_reason": p.DeclineReason,
+			"reference_id":   p.ReferenceID,
+		}).Error
+	})
+	if err != nil {
+		logger.Error("unable to respond to payment request | err: ", err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Approve implements IPaymentRequest. It transfers the amount from the
+// payer's wallet to the requester's and marks the request PAID, or changes
+// nothing. It returns the request and the legs of the transfer.
+func (r *paymentRequestRepo) Approve(id uint64) (*PaymentRequest, *Posting, error) {
+	var posting *Posting
+	p, err := r.respond(id, func(tx *gorm.DB, p *PaymentRequest) error {
+		walletRepo := InitWalletRepo(tx)
+
+		from, err := walletRepo.GetWithTx(tx, &Wallet{ID: p.PayerWalletID})
+		if err != nil {
+			return err
+		}
+
+		to, err := walletRepo.GetWithTx(tx, &Wallet{ID: p.RequesterWalletID})
+		if err != nil {
+			return err
+		}
+
+		posting, err = walletRepo.TransferWithFeeTx(tx, from, to, p.AmountInCents, purposecodes.PurposeCodeTransfer, p.Note)
+		if err != nil {
+			return err
+		}
+
+		p.Status = PaymentRequestPaid
+		p.ReferenceID = posting.Debit.ReferenceID
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, posting, nil
+}
+
+// Decline implements IPaymentRequest.
+func (r *paymentRequestRepo) Decline(id uint64, reason string) (*PaymentRequest, error) {
+	return r.respond(id, func(tx *gorm.DB, p *PaymentRequest) error {
+		p.Status = PaymentRequestDeclined
+		p.DeclineReason = reason
+		return nil
+	})
+}
+
+// Cancel implements IPaymentRequest.
+func (r *paymentRequestRepo) Cancel(id uint64) (*PaymentRequest, error) {
+	return r.respond(id, func(tx *gorm.DB, p *PaymentRequest) error {
+		p.Status = PaymentRequestCancelled
+		return nil
+	})
+}
+
+// ExpireDue implements IPaymentRequest. It marks PENDING requests past
+// their expiry EXPIRED and returns how many there were.
+func (r *paymentRequestRepo) ExpireDue() (int64, error) {
+	result := r.db.Model(&PaymentRequest{}).
+		Where("status = ? AND expires_at <= ?", PaymentRequestPending, time.Now()).
+		Update("status", PaymentRequestExpired)
+	if result.Error != nil {
+		logger.Error("unable to expire payment requests | err: ", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,122 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPaymentRequestCurrentStatus(t *testing.T) {
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Minute)
+	tests := []struct {
+		request models.PaymentRequest
+		want    models.PaymentRequestStatus
+	}{
+		{models.PaymentRequest{Status: models.PaymentRequestPending, ExpiresAt: future}, models.PaymentRequestPending},
+		{models.PaymentRequest{Status: models.PaymentRequestPending, ExpiresAt: past}, models.PaymentRequestExpired},
+		{models.PaymentRequest{Status: models.PaymentRequestPaid, ExpiresAt: past}, models.PaymentRequestPaid},
+	}
+	for _, tt := range tests {
+		if got := tt.request.CurrentStatus(); got != tt.want {
+			t.Errorf("%s request expiring %s is %s, want %s", tt.request.Status, tt.request.ExpiresAt, got, tt.want)
+		}
+	}
+}
+
+// TestPaymentRequests approves, declines and expires requests between two
+// wallets, and refuses to respond to one twice.
+func TestPaymentRequests(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo         = models.InitWalletRepo(db)
+		paymentRequestRepo = models.InitPaymentRequestRepo(db)
+	)
+
+	requester := &models.Wallet{UserUUID: "acc_requester", Currency: models.EntityINR}
+	payer := &models.Wallet{UserUUID: "acc_payer", Currency: models.EntityINR}
+	for _, w := range []*models.Wallet{requester, payer} {
+		err = walletRepo.Create(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = walletRepo.Credit(payer, &models.Transaction{AmountInCents: 1000}, purposecodes.PurposeCodeAddFunds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(expiresAt time.Time) models.PaymentRequest {
+		return models.PaymentRequest{
+			RequesterAccountUUID: requester.UserUUID,
+			RequesterWalletID:    requester.ID,
+			RequesterWalletUUID:  requester.UUID,
+			PayerAccountUUID:     payer.UserUUID,
+			PayerWalletID:        payer.ID,
+			PayerWalletUUID:      payer.UUID,
+			AmountInCents:        400,
+			Status:               models.PaymentRequestPending,
+			ExpiresAt:            expiresAt,
+		}
+	}
+	requests := []models.PaymentRequest{
+		request(time.Now().Add(time.Hour)),
+		request(time.Now().Add(time.Hour)),
+		request(time.Now().Add(-time.Minute)),
+	}
+	err = paymentRequestRepo.Create(requests, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approved, declined, expired := requests[0], requests[1], requests[2]
+
+	paid, posting, err := paymentRequestRepo.Approve(approved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paid.Status != models.PaymentRequestPaid || paid.ReferenceID != posting.Debit.ReferenceID || paid.RespondedAt == nil {
+		t.Errorf("approved request %+v, want it PAID with the transfer's reference", paid)
+	}
+	_, _, err = paymentRequestRepo.Approve(approved.ID)
+	if !errors.Is(err, models.ErrPaymentRequestNotPending) {
+		t.Errorf("approving twice got %v, want ErrPaymentRequestNotPending", err)
+	}
+
+	got, err := paymentRequestRepo.Decline(declined.ID, "not mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.PaymentRequestDeclined || got.DeclineReason != "not mine" {
+		t.Errorf("declined request %+v, want it DECLINED with the reason", got)
+	}
+
+	_, _, err = paymentRequestRepo.Approve(expired.ID)
+	if !errors.Is(err, models.ErrPaymentRequestExpired) {
+		t.Errorf("approving an expired request got %v, want ErrPaymentRequestExpired", err)
+	}
+	count, err := paymentRequestRepo.ExpireDue()
+	if err != nil || count != 1 {
+		t.Errorf("expired %d requests and got %v, want 1", count, err)
+	}
+
+	for _, w := range []struct {
+		wallet *models.Wallet
+		want   int
+	}{{payer, 600}, {requester, 400}} {
+		got, err := walletRepo.Get(&models.Wallet{ID: w.wallet.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.TotalBalanceInCents != w.want {
+			t.Errorf("wallet %s holds %d, want %d", got.UUID, got.TotalBalanceInCents, w.want)
+		}
+	}
+}
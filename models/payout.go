@@ -0,0 +1,537 @@
+package models
+
+import (
+	"coinpe/database"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntityPayout     = "pay_"
+	EntityPayoutItem = "pyi_"
+
+	// MaxPayoutItems caps the rows of one payout
+	MaxPayoutItems = 100000
+
+	// row problems reported back for an invalid payout
+	maxPayoutErrors = 50
+
+	payoutItemSavePoint = "post_payout_item"
+)
+
+type PayoutStatus string
+
+const (
+	PayoutProcessing PayoutStatus = "PROCESSING"
+	PayoutCompleted  PayoutStatus = "COMPLETED"
+)
+
+type PayoutItemStatus string
+
+const (
+	PayoutItemPending   PayoutItemStatus = "PENDING"
+	PayoutItemSucceeded PayoutItemStatus = "SUCCEEDED"
+	PayoutItemFailed    PayoutItemStatus = "FAILED"
+)
+
+var (
+	ErrPayoutEmpty   = errorConst.New(errorConst.ErrorValidation, errorConst.MsgPayoutHasNoRows)
+	ErrPayoutInvalid = errorConst.New(errorConst.ErrorValidation, errorConst.MsgPayoutIsInvalid)
+	ErrPayoutTooBig  = errorConst.New(errorConst.ErrorValidation, errorConst.MsgPayoutTooBig, MaxPayoutItems)
+)
+
+// Payout credits many wallets from the treasury at once. Its items are
+// validated up front, then posted in chunks by the queue workers, see
+// ProcessNextChunk; it is COMPLETED once no item is PENDING.
+type Payout struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID         string                              `json:"uuid" gorm:"unique;not null"`
+	CreatedBy    string                              `json:"created_by" gorm:"not null"`
+	Filename     string                              `json:"filename,omitempty"`
+	FromWalletID uint64                              `json:"from_wallet_id" gorm:"not null"`
+	PurposeCode  purposecodes.TransactionPurposeCode `json:"purpose_code" gorm:"not null"`
+	Description  string                              `json:"description,omitempty"`
+
+	Status           PayoutStatus `json:"status" gorm:"not null;index"`
+	ItemCount        int          `json:"item_count" gorm:"not null"`
+	TotalInCents     int          `json:"total_in_cents" gorm:"not null"`
+	SucceededCount   int          `json:"succeeded_count" gorm:"not null;default:0"`
+	SucceededInCents int          `json:"succeeded_in_cents" gorm:"not null;default:0"`
+	FailedCount      int          `json:"failed_count" gorm:"not null;default:0"`
+	CompletedAt      *time.Time   `json:"completed_at,omitempty"`
+}
+
+// PayoutItem is one credit of a payout.
+type PayoutItem struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID          string `json:"uuid" gorm:"unique;not null"`
+	PayoutID      uint64 `json:"payout_id" gorm:"not null;uniqueIndex:idx_payout_items_payout_row"`
+	RowNumber     int    `json:"row_number" gorm:"not null;uniqueIndex:idx_payout_items_payout_row"`
+	WalletID      uint64 `json:"wallet_id" gorm:"not null"`
+	WalletUUID    string `json:"wallet_uuid" gorm:"not null"`
+	AmountInCents int    `json:"amount_in_cents" gorm:"not null"`
+	Description   string `json:"description,omitempty"`
+
+	Status      PayoutItemStatus `json:"status" gorm:"not null"`
+	ReferenceID string           `json:"reference_id,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	// chunks that could not post the item for a reason of its own, see
+	// ProcessNextChunk
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
+
+type payoutRepo struct {
+	db *gorm.DB
+}
+
+func (p *Payout) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.UUID == "" {
+		p.UUID, err = utils.GenerateNanoID(16, EntityPayout)
+	}
+	return err
+}
+
+func (i *PayoutItem) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.UUID == "" {
+		i.UUID, err = utils.GenerateNanoID(20, EntityPayoutItem)
+	}
+	return err
+}
+
+// ParsePayoutCSV reads the rows of a payout file. The header row names the
+// columns, in any order: wallet_uuid and amount, in rupees, are required,
+// description is optional. Rows are numbered from 2 like the lines of the
+// file. Wallets are checked by Create.
+func ParsePayoutCSV(r io.Reader) ([]PayoutItem, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrPayoutEmpty
+	}
+	if err != nil {
+		return nil, ErrPayoutInvalid.WithFields([]errorConst.Error{{Field: "row 1", Description: err.Error()}})
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"wallet_uuid", "amount"} {
+		if _, ok := columns[required]; !ok {
+			return nil, ErrPayoutInvalid.WithFields([]errorConst.Error{{Field: "row 1", Description: "missing column " + required}})
+		}
+	}
+
+	var (
+		items    []PayoutItem
+		problems []errorConst.Error
+	)
+	for row := 2; ; row++ {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(items) >= MaxPayoutItems {
+			return nil, ErrPayoutTooBig
+		}
+
+		field := "row " + strconv.Itoa(row)
+		if err != nil {
+			problems = append(problems, errorConst.Error{Field: field, Description: err.Error()})
+			if len(problems) >= maxPayoutErrors {
+				break
+			}
+			continue
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(line) {
+				return ""
+			}
+			return strings.TrimSpace(line[i])
+		}
+
+		amount, err := utils.ParseDecimalCents(value("amount"))
+		if err != nil {
+			problems = append(problems, errorConst.Error{Field: field, Description: err.Error()})
+			if len(problems) >= maxPayoutErrors {
+				break
+			}
+			continue
+		}
+
+		items = append(items, PayoutItem{
+			RowNumber:     row,
+			WalletUUID:    value("wallet_uuid"),
+			AmountInCents: amount,
+			Description:   value("description"),
+		})
+	}
+
+	if len(problems) > 0 {
+		return nil, ErrPayoutInvalid.WithFields(problems)
+	}
+	return items, nil
+}
+
+// validateWithTx resolves the wallets of items and checks every row: a
+// known wallet in the currency of from, other than from, credited once, a
+// positive amount. It also checks that from covers the total with the fees
+// it pays on the items, quoted now. It returns the problems found, at most
+// maxPayoutErrors.
+func (r *payoutRepo) validateWithTx(tx *gorm.DB, from *Wallet, purposeCode purposecodes.TransactionPurposeCode, items []PayoutItem) ([]errorConst.Error, error) {
+	uuids := make([]string, 0, len(items))
+	for _, item := range items {
+		uuids = append(uuids, item.WalletUUID)
+	}
+
+	wallets := map[string]Wallet{}
+	for chunk := range slices.Chunk(uuids, 1000) {
+		var found []Wallet
+		err := tx.Select("id", "uuid", "user_uuid", "currency", "parent_wallet_id").Where("uuid IN ?", chunk).Find(&found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, wallet := range found {
+			wallets[wallet.UUID] = wallet
+		}
+	}
+
+	var (
+		problems = []errorConst.Error{}
+		seen     = map[string]int{}
+		total    int
+	)
+	for i := range items {
+		item := &items[i]
+		field := "row " + strconv.Itoa(item.RowNumber)
+
+		wallet, ok := wallets[item.WalletUUID]
+		var problem string
+		switch {
+		case item.WalletUUID == "":
+			problem = "wallet_uuid is required"
+		case !ok || wallet.ParentWalletID != nil:
+			problem = "wallet " + item.WalletUUID + " not found"
+		case wallet.ID == from.ID:
+			problem = "cannot pay out to the treasury"
+		case wallet.Currency != from.Currency:
+			problem = "wallet " + item.WalletUUID + " is not in " + from.Currency
+		case seen[item.WalletUUID] != 0:
+			problem = fmt.Sprintf("wallet %s is already paid on row %d", item.WalletUUID, seen[item.WalletUUID])
+		case item.AmountInCents <= 0:
+			problem = "amount must be positive"
+		}
+		if problem != "" {
+			problems = append(problems, errorConst.Error{Field: field, Description: problem})
+			if len(problems) >= maxPayoutErrors {
+				return problems, nil
+			}
+			continue
+		}
+
+		seen[item.WalletUUID] = item.RowNumber
+		item.WalletID = wallet.ID
+		total += item.AmountInCents
+	}
+
+	if len(problems) > 0 {
+		return problems, nil
+	}
+
+	// items are posted with TransferWithFeeTx, which also debits the fees
+	// the treasury pays
+	var fees int
+	for i := range items {
+		to := wallets[items[i].WalletUUID]
+		quote, err := quoteFeeWithTx(tx, from, &to, items[i].AmountInCents, purposeCode)
+		if err != nil {
+			return nil, err
+		}
+		if quote.Payer == FeePayerSender {
+			fees += quote.FeeInCents
+		}
+	}
+
+	if !InitWalletRepo(tx).CanDebit(from, total+fees) {
+		problems = append(problems, errorConst.Error{
+			Field:       "total",
+			Description: fmt.Sprintf("the treasury holds %d, the payout needs %d, %d of it fees", from.TotalBalanceInCents, total+fees, fees),
+		})
+	}
+	return problems, nil
+}
+
+// Create implements IPayout. It validates every item against the treasury
+// and stores the payout PROCESSING with its items PENDING, or nothing and
+// ErrPayoutInvalid with the problems found.
+func (r *payoutRepo) Create(payout *Payout, items []PayoutItem) error {
+	if len(items) == 0 {
+		return ErrPayoutEmpty
+	}
+	if len(items) > MaxPayoutItems {
+		return ErrPayoutTooBig
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		from, err := InitWalletRepo(tx).GetWithTx(tx, &Wallet{ID: CoinpeWallet.ID})
+		if err != nil {
+			return err
+		}
+
+		problems, err := r.validateWithTx(tx, from, payout.PurposeCode, items)
+		if err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			return ErrPayoutInvalid.WithFields(problems)
+		}
+
+		payout.FromWalletID = from.ID
+		payout.Status = PayoutProcessing
+		payout.ItemCount = len(items)
+		payout.TotalInCents = 0
+		for _, item := range items {
+			payout.TotalInCents += item.AmountInCents
+		}
+		err = tx.Create(payout).Error
+		if err != nil {
+			logger.Error("unable to create payout | err: ", err)
+			return err
+		}
+
+		for i := range items {
+			items[i].PayoutID = payout.ID
+			items[i].Status = PayoutItemPending
+		}
+		err = tx.CreateInBatches(items, 1000).Error
+		if err != nil {
+			logger.Error("unable to create payout items | err: ", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// Get implements IPayout.
+func (r *payoutRepo) Get(where *Payout) (*Payout, error) {
+	var payout Payout
+	err := r.db.Where(where).First(&payout).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payout, nil
+}
+
+// ListItems implements IPayout. Items are returned in file order after row
+// afterRow, only those in status when it is set.
+func (r *payoutRepo) ListItems(payoutID uint64, status PayoutItemStatus, afterRow int, limit int) ([]PayoutItem, error) {
+	items := []PayoutItem{}
+	err := database.Replica(r.db).
+		Where(&PayoutItem{PayoutID: payoutID, Status: status}).
+		Where("row_number > ?", afterRow).
+		Order("row_number asc").
+		Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		logger.Error("unable to list payout items | err: ", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// StreamItems implements IPayout. It calls each with every item of the
+// payout in file order, reading them through a cursor.
+func (r *payoutRepo) StreamItems(payoutID uint64, each func(item *PayoutItem) error) error {
+	db := database.Replica(r.db)
+	rows, err := db.Model(&PayoutItem{}).
+		Where(&PayoutItem{PayoutID: payoutID}).
+		Order("row_number asc").
+		Rows()
+	if err != nil {
+		logger.Error("unable to list payout items | err: ", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item PayoutItem
+		err = db.ScanRows(rows, &item)
+		if err != nil {
+			return err
+		}
+		err = each(&item)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ProcessNextChunk implements IPayout. It claims the oldest PROCESSING
+// payout no other worker holds and posts up to chunkSize of its PENDING
+// items, each behind a savepoint, in one database transaction: a crash
+// loses at most the chunk, which is posted again. Domain errors fail an
+// item. An item racing another posting runs the chunk again, see
+// TransactWithRetry. Other errors of an item count an attempt and leave it
+// PENDING for the next chunk, until it fails on its maxAttempts-th. It
+// reports whether a payout was due.
+func (r *payoutRepo) ProcessNextChunk(chunkSize int, maxAttempts int) (bool, error) {
+	var due bool
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		due = false
+		var payouts []Payout
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(&Payout{Status: PayoutProcessing}).
+			Order("id asc").
+			Limit(1).
+			Find(&payouts).Error
+		if err != nil || len(payouts) == 0 {
+			return err
+		}
+		due = true
+		payout := &payouts[0]
+
+		var items []PayoutItem
+		err = tx.Where(&PayoutItem{PayoutID: payout.ID, Status: PayoutItemPending}).
+			Order("row_number asc").
+			Limit(chunkSize).
+			Find(&items).Error
+		if err != nil {
+			return err
+		}
+
+		var pending int
+		for i := range items {
+			err = r.postItemWithTx(tx, payout, &items[i], maxAttempts)
+			if err != nil {
+				return err
+			}
+			if items[i].Status == PayoutItemPending {
+				pending++
+			}
+		}
+
+		updates := map[string]interface{}{
+			"succeeded_count":    payout.SucceededCount,
+			"succeeded_in_cents": payout.SucceededInCents,
+			"failed_count":       payout.FailedCount,
+		}
+		if len(items) < chunkSize && pending == 0 {
+			now := time.Now()
+			updates["status"] = PayoutCompleted
+			updates["completed_at"] = &now
+			logger.Infof("payout %s completed: %d succeeded, %d failed", payout.UUID, payout.SucceededCount, payout.FailedCount)
+		}
+		return tx.Model(&Payout{}).Where("id = ?", payout.ID).Updates(updates).Error
+	})
+	if err != nil {
+		logger.Error("unable to process payout | err: ", err)
+		return due, err
+	}
+	return due, nil
+}
+
+// postItemWithTx credits item from the payout's wallet and records the
+// outcome on item and the payout's counts.
+func (r *payoutRepo) postItemWithTx(tx *gorm.DB, payout *Payout, item *PayoutItem, maxAttempts int) error {
+	err := tx.SavePoint(payoutItemSavePoint).Error
+	if err != nil {
+		return err
+	}
+
+	referenceID, err := r.transferWithTx(tx, payout, item)
+	if err != nil {
+		rollbackErr := tx.RollbackTo(payoutItemSavePoint).Error
+		if rollbackErr != nil {
+			return rollbackErr
+		}
+	}
+
+	var domainErr *errorConst.DomainError
+	switch {
+	case err == nil:
+		item.Status = PayoutItemSucceeded
+		item.ReferenceID = referenceID
+		payout.SucceededCount++
+		payout.SucceededInCents += item.AmountInCents
+	case errors.As(err, &domainErr):
+		item.Status = PayoutItemFailed
+		item.Error = domainErr.Message
+		payout.FailedCount++
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		item.Status = PayoutItemFailed
+		item.Error = "wallet not found"
+		payout.FailedCount++
+	case IsRetryableBalanceError(err):
+		return err
+	default:
+		item.Attempts++
+		logger.Errorf("payout item %s failed attempt %d of %d | err: %s", item.UUID, item.Attempts, maxAttempts, err)
+		if item.Attempts < maxAttempts {
+			return tx.Model(&PayoutItem{}).Where("id = ?", item.ID).Update("attempts", item.Attempts).Error
+		}
+		item.Status = PayoutItemFailed
+		item.Error = "transfer could not be posted"
+		payout.FailedCount++
+	}
+
+	now := time.Now()
+	item.ProcessedAt = &now
+	return tx.Model(&PayoutItem{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+		"status":       item.Status,
+		"reference_id": item.ReferenceID,
+		"error":        item.Error,
+		"attempts":     item.Attempts,
+		"processed_at": item.ProcessedAt,
+	}).Error
+}
+
+func (r *payoutRepo) transferWithTx(tx *gorm.DB, payout *Payout, item *PayoutItem) (string, error) {
+	walletRepo := InitWalletRepo(tx)
+
+	from, err := walletRepo.GetWithTx(tx, &Wallet{ID: payout.FromWalletID})
+	if err != nil {
+		return "", err
+	}
+
+	to, err := walletRepo.GetWithTx(tx, &Wallet{ID: item.WalletID})
+	if err != nil {
+		return "", err
+	}
+
+	description := item.Description
+	if description == "" {
+		description = payout.Description
+	}
+
+	posting, err := walletRepo.TransferWithFeeTx(tx, from, to, item.AmountInCents, payout.PurposeCode, description)
+	if err != nil {
+		return "", err
+	}
+	return posting.Debit.ReferenceID, nil
+}
@@ -0,0 +1,210 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParsePayoutCSV(t *testing.T) {
+	file := "\ufeffAmount, Wallet_UUID,description\n" +
+		"\"1,000.50\",wa_first,Diwali bonus\n" +
+		"25,wa_second,\n"
+
+	items, err := models.ParsePayoutCSV(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.PayoutItem{
+		{RowNumber: 2, WalletUUID: "wa_first", AmountInCents: 100050, Description: "Diwali bonus"},
+		{RowNumber: 3, WalletUUID: "wa_second", AmountInCents: 2500},
+	}
+	if fmt.Sprint(items) != fmt.Sprint(want) {
+		t.Errorf("parsed %+v, want %+v", items, want)
+	}
+}
+
+func TestParsePayoutCSVErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		err    error
+		fields []string
+	}{
+		{"empty", "", models.ErrPayoutEmpty, nil},
+		{"missing column", "wallet_uuid,description\nwa_first,bonus\n", models.ErrPayoutInvalid, []string{"row 1"}},
+		{
+			name:   "bad amounts",
+			file:   "wallet_uuid,amount\nwa_first,10\nwa_second,ten\nwa_third,1.005\n",
+			err:    models.ErrPayoutInvalid,
+			fields: []string{"row 3", "row 4"},
+		},
+		{
+			name:   "too big",
+			file:   "wallet_uuid,amount\n" + strings.Repeat("wa_first,1\n", models.MaxPayoutItems+1),
+			err:    models.ErrPayoutTooBig,
+			fields: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := models.ParsePayoutCSV(strings.NewReader(tt.file))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			var fields []string
+			for _, f := range errorConst.FromError(err).Fields {
+				fields = append(fields, f.Field)
+			}
+			if fmt.Sprint(fields) != fmt.Sprint(tt.fields) {
+				t.Errorf("problems on %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+// TestPayoutChunks posts a payout in chunks with a fee the treasury pays.
+// One recipient's legs fail with an error that is neither a domain error nor
+// a race, so its item is retried by the next chunk and then failed, while
+// the others post.
+func TestPayoutChunks(t *testing.T) {
+	const (
+		amount = 1000
+		fee    = 100
+	)
+
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo = models.InitWalletRepo(db)
+		payoutRepo = models.InitPayoutRepo(db)
+	)
+
+	err = models.InitFeeRepo(db).Create(&models.FeeSchedule{
+		Name:        "payout fee",
+		PurposeCode: purposecodes.PurposeCodeReward,
+		Payer:       models.FeePayerSender,
+		Type:        models.FeeFlat,
+		FlatInCents: fee,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipients := make([]*models.Wallet, 4)
+	for i := range recipients {
+		w := &models.Wallet{UserUUID: fmt.Sprintf("acc_payout%d", i), Currency: models.EntityINR}
+		err = walletRepo.Create(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipients[i] = w
+	}
+
+	treasury, err := walletRepo.Get(&models.Wallet{ID: models.CoinpeWallet.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the amounts alone fit, with the fees they do not
+	capacity := treasury.TotalBalanceInCents + int(treasury.OverdraftLimitInCents)
+	err = payoutRepo.Create(&models.Payout{CreatedBy: "acc_admin", PurposeCode: purposecodes.PurposeCodeReward}, []models.PayoutItem{
+		{RowNumber: 2, WalletUUID: recipients[0].UUID, AmountInCents: capacity - fee + 1},
+	})
+	if !errors.Is(err, models.ErrPayoutInvalid) {
+		t.Fatalf("payout needing %d of %d with its fee got %v, want ErrPayoutInvalid", capacity+1, capacity, err)
+	}
+
+	payout := &models.Payout{CreatedBy: "acc_admin", PurposeCode: purposecodes.PurposeCodeReward}
+	items := make([]models.PayoutItem, len(recipients))
+	for i, w := range recipients {
+		items[i] = models.PayoutItem{RowNumber: i + 2, WalletUUID: w.UUID, AmountInCents: amount}
+	}
+	err = payoutRepo.Create(payout, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broken := recipients[1]
+	err = db.Exec(`
+		CREATE FUNCTION reject_payout_leg() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'wallet is closed for the test';
+		END;
+		$$ LANGUAGE plpgsql`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Exec(fmt.Sprintf(`
+		CREATE TRIGGER reject_payout_leg BEFORE INSERT ON transactions
+			FOR EACH ROW WHEN (NEW.wallet_id = %d) EXECUTE FUNCTION reject_payout_leg()`, broken.ID)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// three chunks of two: the broken item is attempted by the first two,
+	// fails on the second and the payout completes with the third
+	const maxAttempts = 2
+	for chunk := 1; chunk <= 3; chunk++ {
+		due, err := payoutRepo.ProcessNextChunk(2, maxAttempts)
+		if err != nil {
+			t.Fatalf("chunk %d: %s", chunk, err)
+		}
+		if !due {
+			t.Fatalf("chunk %d found no payout due", chunk)
+		}
+	}
+	due, err := payoutRepo.ProcessNextChunk(2, maxAttempts)
+	if err != nil || due {
+		t.Fatalf("completed payout was still due (%v) or failed: %v", due, err)
+	}
+
+	payout, err = payoutRepo.Get(&models.Payout{ID: payout.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payout.Status != models.PayoutCompleted || payout.SucceededCount != 3 || payout.FailedCount != 1 {
+		t.Errorf("payout is %s with %d succeeded and %d failed, want COMPLETED with 3 and 1",
+			payout.Status, payout.SucceededCount, payout.FailedCount)
+	}
+
+	failed, err := payoutRepo.ListItems(payout.ID, models.PayoutItemFailed, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].WalletID != broken.ID || failed[0].Attempts != maxAttempts {
+		t.Errorf("failed items %+v, want the broken wallet's after %d attempts", failed, maxAttempts)
+	}
+
+	for _, w := range recipients {
+		want := amount
+		if w.ID == broken.ID {
+			want = 0
+		}
+		got, err := walletRepo.Get(&models.Wallet{ID: w.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.TotalBalanceInCents != want {
+			t.Errorf("wallet %s holds %d, want %d", w.UUID, got.TotalBalanceInCents, want)
+		}
+	}
+
+	revenue, err := walletRepo.Get(&models.Wallet{UUID: models.FeeRevenueWallet.UUID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revenue.TotalBalanceInCents != 3*fee {
+		t.Errorf("fee revenue wallet holds %d, want %d", revenue.TotalBalanceInCents, 3*fee)
+	}
+}
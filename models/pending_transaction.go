@@ -0,0 +1,365 @@
+package models
+
+import (
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntityPendingTransaction = "ptx_"
+	EntityTransferBatch      = "tbt_"
+
+	// queued rows a worker looks at before giving up on busy wallets
+	maxClaimCandidates = 10
+
+	pendingTransactionSavePoint = "post_pending_transaction"
+	queueWalletLockPrefix       = "coinpe:queue:wallet:"
+
+	maxQueueBackoff = time.Hour
+)
+
+type PendingTransactionStatus string
+
+const (
+	PendingTransactionQueued    PendingTransactionStatus = "QUEUED"
+	PendingTransactionSucceeded PendingTransactionStatus = "SUCCEEDED"
+	PendingTransactionFailed    PendingTransactionStatus = "FAILED"
+)
+
+type WebhookStatus string
+
+const (
+	WebhookPending   WebhookStatus = "PENDING"
+	WebhookDelivered WebhookStatus = "DELIVERED"
+	WebhookFailed    WebhookStatus = "FAILED"
+)
+
+// PendingTransaction is a transfer queued to be posted by a queue worker.
+// It is QUEUED until posted (SUCCEEDED, with the ReferenceID of the
+// transfer) or given up on (FAILED, with the Error). A WebhookURL is called
+// once it is final.
+type PendingTransaction struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID        string `json:"uuid" gorm:"unique;not null"`
+	BatchUUID   string `json:"batch_uuid,omitempty" gorm:"index"`
+	SubmittedBy string `json:"submitted_by" gorm:"not null;index"`
+
+	FromWalletID   uint64                              `json:"from_wallet_id" gorm:"not null"`
+	FromWalletUUID string                              `json:"from_wallet_uuid" gorm:"not null"`
+	ToWalletID     uint64                              `json:"to_wallet_id" gorm:"not null"`
+	ToWalletUUID   string                              `json:"to_wallet_uuid" gorm:"not null"`
+	AmountInCents  int                                 `json:"amount_in_cents" gorm:"not null"`
+	PurposeCode    purposecodes.TransactionPurposeCode `json:"purpose_code" gorm:"not null"`
+	Description    string                              `json:"description,omitempty"`
+
+	Status      PendingTransactionStatus `json:"status" gorm:"not null;index:idx_pending_transactions_due"`
+	RunAfter    time.Time                `json:"run_after" gorm:"not null;index:idx_pending_transactions_due"`
+	Attempts    int                      `json:"attempts" gorm:"not null;default:0"`
+	ReferenceID string                   `json:"reference_id,omitempty"`
+	Error       string                   `json:"error,omitempty"`
+	ProcessedAt *time.Time               `json:"processed_at,omitempty"`
+
+	WebhookURL      string        `json:"webhook_url,omitempty"`
+	WebhookStatus   WebhookStatus `json:"webhook_status,omitempty" gorm:"index:idx_pending_transactions_webhook_due"`
+	WebhookAttempts int           `json:"webhook_attempts" gorm:"not null;default:0"`
+	WebhookAfter    *time.Time    `json:"webhook_after,omitempty" gorm:"index:idx_pending_transactions_webhook_due"`
+}
+
+type pendingTransactionRepo struct {
+	db *gorm.DB
+}
+
+func (p *PendingTransaction) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.UUID == "" {
+		p.UUID, err = utils.GenerateNanoID(20, EntityPendingTransaction)
+	}
+	return err
+}
+
+// IsFinal reports whether the queue is done with p.
+func (p *PendingTransaction) IsFinal() bool {
+	return p.Status == PendingTransactionSucceeded || p.Status == PendingTransactionFailed
+}
+
+// webhookClaimGrace is added to the webhook timeout for how long a claimed
+// webhook is kept from other workers, see DeliverNextWebhook.
+const webhookClaimGrace = time.Minute
+
+// queueBackoff is the wait before the next attempt after attempts failed
+// ones, doubling from base up to maxQueueBackoff.
+func queueBackoff(base time.Duration, attempts int) time.Duration {
+	if attempts > 16 {
+		return maxQueueBackoff
+	}
+	return min(base<<(attempts-1), maxQueueBackoff)
+}
+
+// Submit implements IPendingTransaction. It queues pending, all of them or
+// none, due right away.
+func (r *pendingTransactionRepo) Submit(pending []PendingTransaction) error {
+	now := time.Now()
+	for i := range pending {
+		pending[i].Status = PendingTransactionQueued
+		pending[i].RunAfter = now
+	}
+
+	err := r.db.CreateInBatches(pending, 500).Error
+	if err != nil {
+		logger.Error("unable to queue pending transactions | err: ", err)
+		return err
+	}
+	return nil
+}
+
+func (r *pendingTransactionRepo) Get(where *PendingTransaction) (*PendingTransaction, error) {
+	var o PendingTransaction
+	err := r.db.Where(where).First(&o).Error
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ListBatch implements IPendingTransaction.
+func (r *pendingTransactionRepo) ListBatch(batchUUID string) ([]PendingTransaction, error) {
+	var pending []PendingTransaction
+	err := r.db.Where(&PendingTransaction{BatchUUID: batchUUID}).Order("id asc").Find(&pending).Error
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// ProcessNext implements IPendingTransaction. It claims the oldest due
+// pending transaction whose source wallet has one of its walletConcurrency
+// slots free (no limit when 0), posts it and records the outcome, all in
+// one database transaction: a worker dying halfway leaves it queued. It
+// returns nil when nothing can be processed now.
+func (r *pendingTransactionRepo) ProcessNext(walletConcurrency int, maxAttempts int, backoff time.Duration) (*PendingTransaction, error) {
+	var processed *PendingTransaction
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var busy []uint64
+		for range maxClaimCandidates {
+			p, err := r.claimWithTx(tx, busy)
+			if err != nil || p == nil {
+				return err
+			}
+
+			free, err := r.takeWalletSlotWithTx(tx, p.FromWalletID, walletConcurrency)
+			if err != nil {
+				return err
+			}
+			if !free {
+				// stays locked, and skipped by others, until this transaction ends
+				busy = append(busy, p.FromWalletID)
+				continue
+			}
+
+			err = r.postWithTx(tx, p, maxAttempts, backoff)
+			if err != nil {
+				return err
+			}
+			processed = p
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("unable to process pending transaction | err: ", err)
+		return nil, err
+	}
+	return processed, nil
+}
+
+// claimWithTx locks the oldest due pending transaction no other worker has
+// locked, skipping the source wallets in busy.
+func (r *pendingTransactionRepo) claimWithTx(tx *gorm.DB, busy []uint64) (*PendingTransaction, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND run_after <= ?", PendingTransactionQueued, time.Now())
+	if len(busy) > 0 {
+		query = query.Where("from_wallet_id NOT IN ?", busy)
+	}
+
+	var pending []PendingTransaction
+	err := query.Order("id asc").Limit(1).Find(&pending).Error
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+	return &pending[0], nil
+}
+
+// takeWalletSlotWithTx takes one of the concurrency slots of walletID, an
+// advisory lock released when tx ends, and reports whether one was free.
+func (r *pendingTransactionRepo) takeWalletSlotWithTx(tx *gorm.DB, walletID uint64, walletConcurrency int) (bool, error) {
+	if walletConcurrency <= 0 {
+		return true, nil
+	}
+
+	for slot := range walletConcurrency {
+		var taken bool
+		err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", fmt.Sprintf("%s%d:%d", queueWalletLockPrefix, walletID, slot)).
+			Scan(&taken).Error
+		if err != nil {
+			return false, err
+		}
+		if taken {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// postWithTx posts the transfer of p behind a savepoint and records the
+// outcome on p. Domain errors, like insufficient funds, fail it right away.
+// Anything else is retried with backoff until maxAttempts.
+func (r *pendingTransactionRepo) postWithTx(tx *gorm.DB, p *PendingTransaction, maxAttempts int, backoff time.Duration) error {
+	err := tx.SavePoint(pendingTransactionSavePoint).Error
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	p.Attempts++
+	updates := map[string]interface{}{"attempts": p.Attempts}
+
+	referenceID, err := r.transferWithTx(tx, p)
+	if err != nil {
+		rollbackErr := tx.RollbackTo(pendingTransactionSavePoint).Error
+		if rollbackErr != nil {
+			return rollbackErr
+		}
+	}
+
+	var domainErr *errorConst.DomainError
+	switch {
+	case err == nil:
+		p.Status = PendingTransactionSucceeded
+		p.ReferenceID = referenceID
+		p.Error = ""
+	case errors.As(err, &domainErr):
+		p.Status = PendingTransactionFailed
+		p.Error = domainErr.Message
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		p.Status = PendingTransactionFailed
+		p.Error = "wallet not found"
+	case p.Attempts >= maxAttempts:
+		logger.Error("giving up on pending transaction ", p.UUID, " | err: ", err)
+		p.Status = PendingTransactionFailed
+		p.Error = err.Error()
+	default:
+		logger.Warnf("pending transaction %s attempt %d failed, retrying: %s", p.UUID, p.Attempts, err)
+		p.RunAfter = now.Add(queueBackoff(backoff, p.Attempts))
+		p.Error = err.Error()
+		updates["run_after"] = p.RunAfter
+	}
+
+	updates["status"] = p.Status
+	updates["error"] = p.Error
+	if p.IsFinal() {
+		p.ProcessedAt = &now
+		updates["reference_id"] = p.ReferenceID
+		updates["processed_at"] = p.ProcessedAt
+		if p.WebhookURL != "" {
+			p.WebhookStatus = WebhookPending
+			p.WebhookAfter = &now
+			updates["webhook_status"] = p.WebhookStatus
+			updates["webhook_after"] = p.WebhookAfter
+		}
+	}
+
+	return tx.Model(&PendingTransaction{}).Where("id = ?", p.ID).Updates(updates).Error
+}
+
+func (r *pendingTransactionRepo) transferWithTx(tx *gorm.DB, p *PendingTransaction) (string, error) {
+	walletRepo := InitWalletRepo(tx)
+
+	from, err := walletRepo.GetWithTx(tx, &Wallet{ID: p.FromWalletID})
+	if err != nil {
+		return "", err
+	}
+
+	to, err := walletRepo.GetWithTx(tx, &Wallet{ID: p.ToWalletID})
+	if err != nil {
+		return "", err
+	}
+
+	posting, err := walletRepo.TransferWithFeeTx(tx, from, to, p.AmountInCents, p.PurposeCode, p.Description)
+	if err != nil {
+		return "", err
+	}
+	return posting.Debit.ReferenceID, nil
+}
+
+// DeliverNextWebhook implements IPendingTransaction. It claims the next due
+// webhook and calls deliver with it once the claim is committed, so no row
+// stays locked during the HTTP call, then records the outcome. The claim
+// keeps other workers off the webhook for timeout and webhookClaimGrace, after
+// which a delivery that never reported, e.g. from a crashed worker, is
+// claimed again: webhooks are delivered at least once. A failed delivery is
+// retried with backoff until maxAttempts. It reports whether a webhook was
+// due.
+func (r *pendingTransactionRepo) DeliverNextWebhook(maxAttempts int, backoff time.Duration, timeout time.Duration, deliver func(p *PendingTransaction) error) (bool, error) {
+	var p *PendingTransaction
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var pending []PendingTransaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("webhook_status = ? AND webhook_after <= ?", WebhookPending, time.Now()).
+			Order("webhook_after asc").
+			Limit(1).
+			Find(&pending).Error
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+
+		p = &pending[0]
+		p.WebhookAttempts++
+		return tx.Model(&PendingTransaction{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+			"webhook_attempts": p.WebhookAttempts,
+			"webhook_after":    time.Now().Add(timeout + webhookClaimGrace),
+		}).Error
+	})
+	if err != nil {
+		logger.Error("unable to claim webhook | err: ", err)
+		return false, err
+	}
+	if p == nil {
+		return false, nil
+	}
+
+	updates := map[string]interface{}{}
+	err = deliver(p)
+	switch {
+	case err == nil:
+		updates["webhook_status"] = WebhookDelivered
+	case p.WebhookAttempts >= maxAttempts:
+		logger.Error("giving up on webhook of pending transaction ", p.UUID, " | err: ", err)
+		updates["webhook_status"] = WebhookFailed
+	default:
+		logger.Warnf("webhook of pending transaction %s attempt %d failed: %s", p.UUID, p.WebhookAttempts, err)
+		updates["webhook_after"] = time.Now().Add(queueBackoff(backoff, p.WebhookAttempts))
+	}
+
+	// a worker that claimed the webhook again after the claim ran out owns it now
+	result := r.db.Model(&PendingTransaction{}).
+		Where("id = ? AND webhook_status = ? AND webhook_attempts = ?", p.ID, WebhookPending, p.WebhookAttempts).
+		Updates(updates)
+	if result.Error != nil {
+		logger.Error("unable to record webhook delivery | err: ", result.Error)
+		return true, result.Error
+	}
+	if result.RowsAffected == 0 {
+		logger.Warnf("webhook of pending transaction %s was claimed again while attempt %d ran", p.UUID, p.WebhookAttempts)
+	}
+	return true, nil
+}
@@ -0,0 +1,117 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQueueBackoff(t *testing.T) {
+	tests := []struct {
+		base     time.Duration
+		attempts int
+		want     time.Duration
+	}{
+		{time.Second, 1, time.Second},
+		{time.Second, 2, 2 * time.Second},
+		{time.Second, 4, 8 * time.Second},
+		{time.Minute, 7, time.Hour},
+		{time.Second, 17, time.Hour},
+		{time.Second, 64, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := models.QueueBackoff(tt.base, tt.attempts); got != tt.want {
+			t.Errorf("backoff from %s after %d attempts is %s, want %s", tt.base, tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// TestProcessNext queues two transfers the sender can only fund one of. The
+// first posts, the second fails on its domain error without a retry, and
+// the webhook of the first is retried after a failed delivery.
+func TestProcessNext(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo  = models.InitWalletRepo(db)
+		pendingRepo = models.InitPendingTransactionRepo(db)
+	)
+
+	from := &models.Wallet{UserUUID: "acc_queuefrom", Currency: models.EntityINR}
+	to := &models.Wallet{UserUUID: "acc_queueto", Currency: models.EntityINR}
+	for _, w := range []*models.Wallet{from, to} {
+		err = walletRepo.Create(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = walletRepo.Credit(from, &models.Transaction{AmountInCents: 1000}, purposecodes.PurposeCodeAddFunds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending := make([]models.PendingTransaction, 2)
+	for i := range pending {
+		pending[i] = models.PendingTransaction{
+			SubmittedBy:    from.UserUUID,
+			FromWalletID:   from.ID,
+			FromWalletUUID: from.UUID,
+			ToWalletID:     to.ID,
+			ToWalletUUID:   to.UUID,
+			AmountInCents:  600,
+			PurposeCode:    purposecodes.PurposeCodeTransfer,
+			WebhookURL:     "https://example.com/hooks",
+		}
+	}
+	err = pendingRepo.Submit(pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const maxAttempts = 3
+	posted, err := pendingRepo.ProcessNext(1, maxAttempts, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted == nil || posted.ID != pending[0].ID || posted.Status != models.PendingTransactionSucceeded || posted.ReferenceID == "" {
+		t.Fatalf("first processed %+v, want the first transfer SUCCEEDED", posted)
+	}
+
+	failed, err := pendingRepo.ProcessNext(1, maxAttempts, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed == nil || failed.ID != pending[1].ID || failed.Status != models.PendingTransactionFailed || failed.Attempts != 1 {
+		t.Fatalf("second processed %+v, want the second transfer FAILED on its first attempt", failed)
+	}
+
+	next, err := pendingRepo.ProcessNext(1, maxAttempts, time.Second)
+	if err != nil || next != nil {
+		t.Fatalf("got %+v and %v, want nothing left to process", next, err)
+	}
+
+	delivered := 0
+	due, err := pendingRepo.DeliverNextWebhook(maxAttempts, time.Hour, time.Second, func(p *models.PendingTransaction) error {
+		delivered++
+		return errors.New("webhook answered 503 Service Unavailable")
+	})
+	if err != nil || !due || delivered != 1 {
+		t.Fatalf("first delivery was due %v with %d calls and %v, want one failed call", due, delivered, err)
+	}
+
+	got, err := pendingRepo.Get(&models.PendingTransaction{ID: posted.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.WebhookStatus != models.WebhookPending || got.WebhookAttempts != 1 || got.WebhookAfter == nil || !got.WebhookAfter.After(time.Now().Add(30*time.Minute)) {
+		t.Errorf("webhook %s after %d attempts due %v, want it PENDING an hour out", got.WebhookStatus, got.WebhookAttempts, got.WebhookAfter)
+	}
+}
@@ -0,0 +1,366 @@
+package models
+
+import (
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reconciliation matches settlement records to the transactions they settle,
+// first by reference: a record whose reference is the reference of a
+// transaction is MATCHED, or MISMATCHED when the amount or currency differ or
+// they are further apart than the date window. A record without one is
+// MATCHED to the only unmatched transaction with its amount inside the date
+// window. Anything else stays UNMATCHED until an operator resolves it.
+
+type SettlementMatchRule string
+
+const (
+	SettlementMatchReference  SettlementMatchRule = "REFERENCE"
+	SettlementMatchAmountDate SettlementMatchRule = "AMOUNT_DATE"
+	SettlementMatchManual     SettlementMatchRule = "MANUAL"
+)
+
+type SettlementResolution string
+
+const (
+	// SettlementResolutionMatch links the record to a transaction chosen by
+	// the operator.
+	SettlementResolutionMatch SettlementResolution = "MATCH"
+	// SettlementResolutionAccept settles a mismatched record as matched, e.g.
+	// when the gateway deducted a fee.
+	SettlementResolutionAccept SettlementResolution = "ACCEPT"
+	// SettlementResolutionIgnore closes a record that settles no CoinPe
+	// transaction.
+	SettlementResolutionIgnore SettlementResolution = "IGNORE"
+	// SettlementResolutionReopen undoes a match or resolution and runs the
+	// matching rules again.
+	SettlementResolutionReopen SettlementResolution = "REOPEN"
+)
+
+const (
+	// DefaultReconciliationDateWindow is how far apart a record and its
+	// transaction may be when RECONCILIATION_DATE_WINDOW is unset.
+	DefaultReconciliationDateWindow = 72 * time.Hour
+
+	// matching and resolving are serialised so two records never claim the
+	// same transaction
+	reconciliationLockName = "coinpe:reconciliation"
+)
+
+// ExternallySettledPurposeCodes are the purpose codes of transactions backed
+// by a real money movement, the ones settlement files are reconciled against.
+var ExternallySettledPurposeCodes = []purposecodes.TransactionPurposeCode{
+	purposecodes.PurposeCodeAddFunds,
+}
+
+var (
+	ErrResolutionNoteEmpty         = errorConst.New(errorConst.ErrorValidation, errorConst.MsgResolutionNoteIsRequired)
+	ErrInvalidResolution           = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgInvalidResolution)
+	ErrSettlementRecordResolved    = errorConst.New(errorConst.ErrorConflict, errorConst.MsgSettlementRecordResolved)
+	ErrSettlementRecordNotMismatch = errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgSettlementRecordNotMismatched)
+	ErrSettlementTransactionTaken  = errorConst.New(errorConst.ErrorConflict, errorConst.MsgTransactionAlreadyMatched)
+	ErrSettlementTransactionWrong  = errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgTransactionDirectionMismatch)
+)
+
+// ReconciliationResult counts the records a Reconcile run looked at by the
+// status they ended in.
+type ReconciliationResult map[SettlementRecordStatus]int
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// Reconcile implements ISettlement. It runs the matching rules over the
+// UNMATCHED and MISMATCHED records of the import, or of every import when
+// importID is zero, so transactions that arrive after a file was imported
+// are still picked up.
+func (r *settlementRepo) Reconcile(importID uint64, window time.Duration) (ReconciliationResult, error) {
+	var (
+		result = ReconciliationResult{}
+		lastID uint64
+	)
+
+	for {
+		var pending []SettlementRecord
+		builder := r.db.Model(&SettlementRecord{}).
+			Where("status IN ?", []SettlementRecordStatus{SettlementRecordUnmatched, SettlementRecordMismatched}).
+			Where("id > ?", lastID)
+		if importID > 0 {
+			builder = builder.Where(&SettlementRecord{ImportID: importID})
+		}
+		err := builder.Order("id asc").Limit(500).Find(&pending).Error
+		if err != nil {
+			logger.Error("unable to list pending settlement records | err: ", err)
+			return nil, err
+		}
+		if len(pending) == 0 {
+			return result, nil
+		}
+
+		for _, record := range pending {
+			lastID = record.ID
+			err = r.db.Transaction(func(tx *gorm.DB) error {
+				err := r.lock(tx, &record)
+				if err != nil {
+					return err
+				}
+				// resolved by an operator since it was listed
+				if record.Status != SettlementRecordUnmatched && record.Status != SettlementRecordMismatched {
+					return nil
+				}
+
+				err = r.match(tx, &record, window)
+				if err != nil {
+					return err
+				}
+				result[record.Status]++
+				return r.saveMatch(tx, &record)
+			})
+			if err != nil {
+				logger.Error("unable to reconcile settlement record | err: ", err)
+				return nil, err
+			}
+		}
+	}
+}
+
+// lock takes the reconciliation lock and reloads record for update.
+func (r *settlementRepo) lock(tx *gorm.DB, record *SettlementRecord) error {
+	err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", reconciliationLockName).Error
+	if err != nil {
+		return err
+	}
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(record, record.ID).Error
+}
+
+// matchedBy is the UUID of the record other than recordID matched to the
+// transaction, empty when there is none.
+func (r *settlementRepo) matchedBy(tx *gorm.DB, transactionID uint64, recordID uint64) (string, error) {
+	var others []SettlementRecord
+	err := tx.Select("uuid").
+		Where(&SettlementRecord{MatchedTransactionID: &transactionID}).
+		Where("id <> ?", recordID).
+		Limit(1).
+		Find(&others).Error
+	if err != nil || len(others) == 0 {
+		return "", err
+	}
+	return others[0].UUID, nil
+}
+
+func (r *settlementRepo) walletCurrency(tx *gorm.DB, walletID uint64) (string, error) {
+	var currency string
+	err := tx.Model(&Wallet{}).Select("currency").Where("id = ?", walletID).Scan(&currency).Error
+	return currency, err
+}
+
+// match applies the matching rules to record, see the top of this file.
+func (r *settlementRepo) match(tx *gorm.DB, record *SettlementRecord, window time.Duration) error {
+	record.Status = SettlementRecordUnmatched
+	record.MatchRule = ""
+	record.MatchedTransactionID = nil
+	record.Detail = ""
+
+	if record.Reference != "" {
+		var candidates []Transaction
+		err := tx.Where(&Transaction{ReferenceID: record.Reference, Type: record.Direction, Status: constants.EntitySuccess}).
+			Where("purpose_code IN ?", ExternallySettledPurposeCodes).
+			Order("id asc").
+			Find(&candidates).Error
+		if err != nil {
+			return err
+		}
+
+		if len(candidates) > 0 {
+			t := candidates[0]
+			for _, candidate := range candidates {
+				if candidate.AmountInCents == record.AmountInCents {
+					t = candidate
+					break
+				}
+			}
+
+			taken, err := r.matchedBy(tx, t.ID, record.ID)
+			if err != nil {
+				return err
+			}
+			if taken != "" {
+				record.Detail = fmt.Sprintf("transaction %s is already matched to %s, possibly a duplicate settlement", t.UUID, taken)
+				return nil
+			}
+
+			currency, err := r.walletCurrency(tx, t.WalletID)
+			if err != nil {
+				return err
+			}
+
+			var problems []string
+			if t.AmountInCents != record.AmountInCents {
+				problems = append(problems, fmt.Sprintf("settled %s, the transaction is %s", utils.DecimalCents(record.AmountInCents), utils.DecimalCents(t.AmountInCents)))
+			}
+			if currency != record.Currency {
+				problems = append(problems, fmt.Sprintf("settled in %s, the wallet is in %s", record.Currency, currency))
+			}
+			if gap := absDuration(record.SettledAt.Sub(*t.CreatedAt)); gap > window {
+				problems = append(problems, fmt.Sprintf("settled %s from the transaction, outside the %s window", gap.Round(time.Minute), window))
+			}
+
+			record.MatchRule = SettlementMatchReference
+			record.MatchedTransactionID = &t.ID
+			record.Status = SettlementRecordMatched
+			if len(problems) > 0 {
+				record.Status = SettlementRecordMismatched
+				record.Detail = strings.Join(problems, "; ")
+			}
+			return nil
+		}
+	}
+
+	var candidates []Transaction
+	err := tx.Where(&Transaction{Type: record.Direction, AmountInCents: record.AmountInCents, Status: constants.EntitySuccess}).
+		Where("purpose_code IN ?", ExternallySettledPurposeCodes).
+		Where("created_at BETWEEN ? AND ?", record.SettledAt.Add(-window), record.SettledAt.Add(window)).
+		Where("NOT EXISTS (SELECT 1 FROM settlement_records s WHERE s.matched_transaction_id = transactions.id AND s.id <> ?)", record.ID).
+		Order("id asc").
+		Limit(2).
+		Find(&candidates).Error
+	if err != nil {
+		return err
+	}
+
+	switch len(candidates) {
+	case 0:
+		record.Detail = "no transaction with this reference, or with this amount inside the date window"
+	case 1:
+		record.MatchRule = SettlementMatchAmountDate
+		record.MatchedTransactionID = &candidates[0].ID
+		record.Status = SettlementRecordMatched
+	default:
+		record.Detail = "several transactions have this amount inside the date window, resolve it manually"
+	}
+	return nil
+}
+
+func (r *settlementRepo) saveMatch(tx *gorm.DB, record *SettlementRecord) error {
+	return tx.Model(record).
+		Select("status", "match_rule", "matched_transaction_id", "detail", "resolution", "resolution_note", "resolved_by", "resolved_at", "updated_at").
+		Updates(record).Error
+}
+
+// Resolve implements ISettlement. An operator closes or reopens a record,
+// see SettlementResolution; transactionUUID is only read for MATCH. The note
+// and operator are kept on the record.
+func (r *settlementRepo) Resolve(recordUUID string, resolution SettlementResolution, transactionUUID string, note string, resolvedBy string, window time.Duration) (*SettlementRecord, error) {
+	if strings.TrimSpace(note) == "" {
+		return nil, ErrResolutionNoteEmpty
+	}
+
+	record := &SettlementRecord{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(&SettlementRecord{UUID: recordUUID}).First(record).Error
+		if err != nil {
+			return err
+		}
+		err = r.lock(tx, record)
+		if err != nil {
+			return err
+		}
+
+		if resolution != SettlementResolutionReopen && record.Status == SettlementRecordResolved {
+			return ErrSettlementRecordResolved
+		}
+
+		switch resolution {
+		case SettlementResolutionMatch:
+			var t Transaction
+			err = tx.Where(&Transaction{UUID: transactionUUID}).First(&t).Error
+			if err != nil {
+				return err
+			}
+			if t.Status != constants.EntitySuccess || t.Type != record.Direction {
+				return ErrSettlementTransactionWrong
+			}
+			taken, err := r.matchedBy(tx, t.ID, record.ID)
+			if err != nil {
+				return err
+			}
+			if taken != "" {
+				return ErrSettlementTransactionTaken
+			}
+			record.MatchRule = SettlementMatchManual
+			record.MatchedTransactionID = &t.ID
+		case SettlementResolutionAccept:
+			if record.Status != SettlementRecordMismatched {
+				return ErrSettlementRecordNotMismatch
+			}
+		case SettlementResolutionIgnore:
+			record.MatchRule = ""
+			record.MatchedTransactionID = nil
+		case SettlementResolutionReopen:
+			err = r.match(tx, record, window)
+			if err != nil {
+				return err
+			}
+		default:
+			return ErrInvalidResolution
+		}
+
+		now := time.Now()
+		record.Resolution = resolution
+		record.ResolutionNote = note
+		record.ResolvedBy = resolvedBy
+		record.ResolvedAt = &now
+		if resolution != SettlementResolutionReopen {
+			record.Status = SettlementRecordResolved
+		}
+		return r.saveMatch(tx, record)
+	})
+	if err != nil {
+		var domainErr *errorConst.DomainError
+		if !errors.As(err, &domainErr) {
+			logger.Error("unable to resolve settlement record | err: ", err)
+		}
+		return nil, err
+	}
+
+	err = r.db.Preload("MatchedTransaction").First(record, record.ID).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// UnmatchedTransactions implements ISettlement. They are externally settled
+// transactions created in [from, to] that no settlement record is matched
+// to, oldest first, at most limit.
+func (r *settlementRepo) UnmatchedTransactions(from time.Time, to time.Time, limit int) ([]Transaction, error) {
+	var (
+		transactions = []Transaction{}
+	)
+	err := r.db.Model(&Transaction{}).
+		Where(&Transaction{Status: constants.EntitySuccess}).
+		Where("purpose_code IN ?", ExternallySettledPurposeCodes).
+		Where("created_at BETWEEN ? AND ?", from, to).
+		Where("NOT EXISTS (SELECT 1 FROM settlement_records s WHERE s.matched_transaction_id = transactions.id)").
+		Order("created_at asc, id asc").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		logger.Error("unable to list unmatched transactions | err: ", err)
+		return nil, err
+	}
+	return transactions, nil
+}
@@ -14,6 +14,7 @@ const (
 	RoleSuperAdmin RoleID = iota + 1
 	RoleAdmin
 	RoleCustomer
+	RoleMerchant
 )
 
 type Role struct {
@@ -174,6 +175,14 @@ var (
 			SystemDefined: true,
 			IsActive:      &trueVal,
 		},
+		{
+			ID:            4,
+			Name:          RoleTypeMerchant,
+			DisplayName:   "Merchant",
+			Description:   "business accepting coins",
+			SystemDefined: true,
+			IsActive:      &trueVal,
+		},
 	}
 )
 
@@ -6,4 +6,5 @@ const (
 	RoleTypeAdmin      RoleType = "ADMIN"
 	RoleTypeCustomer   RoleType = "CUSTOMER"
 	RoleTypeSuperAdmin RoleType = "SUPER_ADMIN"
+	RoleTypeMerchant   RoleType = "MERCHANT"
 )
@@ -0,0 +1,536 @@
+package models
+
+import (
+	"coinpe/database"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/recurrence"
+	"coinpe/pkg/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntityScheduledTransfer    = "stf_"
+	EntityScheduledTransferRun = "str_"
+
+	scheduledTransferSavePoint = "post_scheduled_transfer"
+
+	// a one-off transfer may start this far in the past, for clock skew
+	scheduledTransferStartGrace = time.Minute
+
+	// a scheduled transfer whose run failed is postponed at least this long
+	minScheduledTransferPostpone = time.Minute
+)
+
+type ScheduledTransferStatus string
+
+const (
+	ScheduledTransferActive    ScheduledTransferStatus = "ACTIVE"
+	ScheduledTransferPaused    ScheduledTransferStatus = "PAUSED"
+	ScheduledTransferCancelled ScheduledTransferStatus = "CANCELLED"
+	ScheduledTransferCompleted ScheduledTransferStatus = "COMPLETED"
+)
+
+type ScheduledTransferRunStatus string
+
+const (
+	ScheduledTransferRunSucceeded ScheduledTransferRunStatus = "SUCCEEDED"
+	ScheduledTransferRunRetrying  ScheduledTransferRunStatus = "RETRYING"
+	ScheduledTransferRunFailed    ScheduledTransferRunStatus = "FAILED"
+)
+
+var (
+	ErrScheduledTransferNoOccurrence = errorConst.New(errorConst.ErrorValidation, errorConst.MsgScheduleHasNoFutureOccurrence)
+	ErrScheduledTransferNotActive    = errorConst.New(errorConst.ErrorConflict, errorConst.MsgScheduledTransferIsNotActive)
+	ErrScheduledTransferNotPaused    = errorConst.New(errorConst.ErrorConflict, errorConst.MsgScheduledTransferIsNotPaused)
+	ErrScheduledTransferEnded        = errorConst.New(errorConst.ErrorConflict, errorConst.MsgScheduledTransferHasAlreadyEnded)
+)
+
+// ScheduledTransfer is a standing instruction to transfer AmountInCents
+// from a wallet on every occurrence of Recurrence, a cron expression or an
+// RRULE in Timezone, or once at StartAt when it is empty. NextOccurrenceAt
+// is the occurrence due, attempted at NextRunAt, which is later while an
+// occurrence is retried for insufficient funds. Occurrences missed while
+// the job was not running are posted once, not once each.
+type ScheduledTransfer struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID        string `json:"uuid" gorm:"unique;not null"`
+	AccountUUID string `json:"account_uuid" gorm:"not null;index"`
+
+	FromWalletID   uint64 `json:"from_wallet_id" gorm:"not null"`
+	FromWalletUUID string `json:"from_wallet_uuid" gorm:"not null"`
+	ToWalletID     uint64 `json:"to_wallet_id" gorm:"not null"`
+	ToWalletUUID   string `json:"to_wallet_uuid" gorm:"not null"`
+	AmountInCents  int    `json:"amount_in_cents" gorm:"not null"`
+	Description    string `json:"description,omitempty"`
+
+	Recurrence string     `json:"recurrence,omitempty"`
+	Timezone   string     `json:"timezone" gorm:"not null"`
+	StartAt    time.Time  `json:"start_at" gorm:"not null"`
+	EndAt      *time.Time `json:"end_at,omitempty"`
+
+	// insufficient funds retries of one occurrence and the time between them
+	MaxRetries           int `json:"max_retries" gorm:"not null"`
+	RetryIntervalSeconds int `json:"retry_interval_seconds" gorm:"not null"`
+
+	Status           ScheduledTransferStatus `json:"status" gorm:"not null;index:idx_scheduled_transfers_due"`
+	NextOccurrenceAt *time.Time              `json:"next_occurrence_at,omitempty"`
+	NextRunAt        *time.Time              `json:"next_run_at,omitempty" gorm:"index:idx_scheduled_transfers_due"`
+	Retries          int                     `json:"retries" gorm:"not null;default:0"`
+	SucceededCount   int                     `json:"succeeded_count" gorm:"not null;default:0"`
+	FailedCount      int                     `json:"failed_count" gorm:"not null;default:0"`
+	LastRunAt        *time.Time              `json:"last_run_at,omitempty"`
+}
+
+// ScheduledTransferRun is the outcome of one occurrence of a scheduled
+// transfer. There is at most one per occurrence, which keeps an occurrence
+// from being posted twice.
+type ScheduledTransferRun struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID                string                     `json:"uuid" gorm:"unique;not null"`
+	ScheduledTransferID uint64                     `json:"scheduled_transfer_id" gorm:"not null;uniqueIndex:idx_scheduled_transfer_runs_occurrence"`
+	OccurrenceAt        time.Time                  `json:"occurrence_at" gorm:"not null;uniqueIndex:idx_scheduled_transfer_runs_occurrence"`
+	Status              ScheduledTransferRunStatus `json:"status" gorm:"not null"`
+	Attempts            int                        `json:"attempts" gorm:"not null"`
+	ReferenceID         string                     `json:"reference_id,omitempty"`
+	Error               string                     `json:"error,omitempty"`
+}
+
+type scheduledTransferRepo struct {
+	db *gorm.DB
+}
+
+func (s *ScheduledTransfer) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.UUID == "" {
+		s.UUID, err = utils.GenerateNanoID(16, EntityScheduledTransfer)
+	}
+	return err
+}
+
+func (r *ScheduledTransferRun) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.UUID == "" {
+		r.UUID, err = utils.GenerateNanoID(20, EntityScheduledTransferRun)
+	}
+	return err
+}
+
+// Schedule returns the occurrences of s, in its timezone.
+func (s *ScheduledTransfer) Schedule() (recurrence.Schedule, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return recurrence.Parse(s.Recurrence, s.StartAt.In(loc))
+}
+
+// nextOccurrence returns the first occurrence of s after t, nil when there
+// is none before EndAt.
+func (s *ScheduledTransfer) nextOccurrence(t time.Time) (*time.Time, error) {
+	schedule, err := s.Schedule()
+	if err != nil {
+		return nil, err
+	}
+
+	next := schedule.Next(t)
+	if next.IsZero() || (s.EndAt != nil && next.After(*s.EndAt)) {
+		return nil, nil
+	}
+	next = next.UTC()
+	return &next, nil
+}
+
+// Create implements IScheduledTransfer. It stores s ACTIVE with its first
+// occurrence from now on.
+func (r *scheduledTransferRepo) Create(s *ScheduledTransfer) error {
+	from := s.StartAt
+	if grace := time.Now().Add(-scheduledTransferStartGrace); from.Before(grace) {
+		from = grace
+	}
+
+	next, err := s.nextOccurrence(from.Add(-time.Nanosecond))
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		return ErrScheduledTransferNoOccurrence
+	}
+
+	s.Status = ScheduledTransferActive
+	s.NextOccurrenceAt = next
+	s.NextRunAt = next
+	err = r.db.Create(s).Error
+	if err != nil {
+		logger.Error("unable to create scheduled transfer | err: ", err)
+		return err
+	}
+	return nil
+}
+
+// Get implements IScheduledTransfer.
+func (r *scheduledTransferRepo) Get(where *ScheduledTransfer) (*ScheduledTransfer, error) {
+	var s ScheduledTransfer
+	err := r.db.Where(where).First(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List implements IScheduledTransfer. Scheduled transfers of the account
+// are returned newest first, only those in status when it is set.
+func (r *scheduledTransferRepo) List(accountUUID string, status ScheduledTransferStatus, beforeID uint64, limit int) ([]ScheduledTransfer, error) {
+	transfers := []ScheduledTransfer{}
+
+	builder := database.Replica(r.db).Where(&ScheduledTransfer{AccountUUID: accountUUID, Status: status})
+	if beforeID > 0 {
+		builder = builder.Where("id < ?", beforeID)
+	}
+
+	err := builder.Order("id desc").
+		Limit(limit).
+		Find(&transfers).Error
+	if err != nil {
+		logger.Error("unable to list scheduled transfers | err: ", err)
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// ListRuns implements IScheduledTransfer. Runs are returned latest
+// occurrence first.
+func (r *scheduledTransferRepo) ListRuns(scheduledTransferID uint64, limit int) ([]ScheduledTransferRun, error) {
+	runs := []ScheduledTransferRun{}
+	err := database.Replica(r.db).
+		Where(&ScheduledTransferRun{ScheduledTransferID: scheduledTransferID}).
+		Order("occurrence_at desc").
+		Limit(limit).
+		Find(&runs).Error
+	if err != nil {
+		logger.Error("unable to list scheduled transfer runs | err: ", err)
+		return nil, err
+	}
+	return runs, nil
+}
+
+// update locks the scheduled transfer id and applies change to it, saving
+// its schedule fields when change succeeds.
+func (r *scheduledTransferRepo) update(id uint64, change func(tx *gorm.DB, s *ScheduledTransfer) error) (*ScheduledTransfer, error) {
+	var s ScheduledTransfer
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, id).Error
+		if err != nil {
+			return err
+		}
+
+		err = change(tx, &s)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&ScheduledTransfer{}).Where("id = ?", s.ID).Updates(map[string]interface{}{
+			"status":             s.Status,
+			"next_occurrence_at": s.NextOccurrenceAt,
+			"next_run_at":        s.NextRunAt,
+			"retries":            s.Retries,
+			"failed_count":       s.FailedCount,
+		}).Error
+	})
+	if err != nil {
+		logger.Error("unable to update scheduled transfer | err: ", err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+// stopRetryWithTx fails the occurrence of s being retried, if any, with
+// reason.
+func (r *scheduledTransferRepo) stopRetryWithTx(tx *gorm.DB, s *ScheduledTransfer, reason string) error {
+	if s.Retries == 0 || s.NextOccurrenceAt == nil {
+		return nil
+	}
+
+	err := tx.Model(&ScheduledTransferRun{}).
+		Where(&ScheduledTransferRun{ScheduledTransferID: s.ID, OccurrenceAt: *s.NextOccurrenceAt, Status: ScheduledTransferRunRetrying}).
+		Updates(map[string]interface{}{"status": ScheduledTransferRunFailed, "error": reason}).Error
+	if err != nil {
+		return err
+	}
+	s.Retries = 0
+	s.FailedCount++
+	return nil
+}
+
+// Pause implements IScheduledTransfer. A paused transfer keeps its next
+// occurrence until it is resumed, an occurrence being retried fails.
+func (r *scheduledTransferRepo) Pause(id uint64) (*ScheduledTransfer, error) {
+	return r.update(id, func(tx *gorm.DB, s *ScheduledTransfer) error {
+		if s.Status != ScheduledTransferActive {
+			return ErrScheduledTransferNotActive
+		}
+		s.Status = ScheduledTransferPaused
+		return r.stopRetryWithTx(tx, s, "scheduled transfer paused")
+	})
+}
+
+// Resume implements IScheduledTransfer. Occurrences missed while paused are
+// skipped, the transfer COMPLETES when none is left.
+func (r *scheduledTransferRepo) Resume(id uint64) (*ScheduledTransfer, error) {
+	return r.update(id, func(tx *gorm.DB, s *ScheduledTransfer) error {
+		if s.Status != ScheduledTransferPaused {
+			return ErrScheduledTransferNotPaused
+		}
+
+		now := time.Now()
+		if s.NextOccurrenceAt != nil && s.NextOccurrenceAt.After(now) {
+			s.Status = ScheduledTransferActive
+			s.NextRunAt = s.NextOccurrenceAt
+			return nil
+		}
+		return s.advance(now)
+	})
+}
+
+// Cancel implements IScheduledTransfer.
+func (r *scheduledTransferRepo) Cancel(id uint64) (*ScheduledTransfer, error) {
+	return r.update(id, func(tx *gorm.DB, s *ScheduledTransfer) error {
+		if s.Status != ScheduledTransferActive && s.Status != ScheduledTransferPaused {
+			return ErrScheduledTransferEnded
+		}
+
+		err := r.stopRetryWithTx(tx, s, "scheduled transfer cancelled")
+		if err != nil {
+			return err
+		}
+		s.Status = ScheduledTransferCancelled
+		s.NextOccurrenceAt = nil
+		s.NextRunAt = nil
+		return nil
+	})
+}
+
+// advance moves s to its first occurrence after t, or COMPLETES it.
+func (s *ScheduledTransfer) advance(t time.Time) error {
+	next, err := s.nextOccurrence(t)
+	if err != nil {
+		return err
+	}
+
+	s.Retries = 0
+	s.NextOccurrenceAt = next
+	s.NextRunAt = next
+	if next == nil {
+		s.Status = ScheduledTransferCompleted
+	} else {
+		s.Status = ScheduledTransferActive
+	}
+	return nil
+}
+
+// RunNext implements IScheduledTransfer. It claims the ACTIVE scheduled
+// transfer due first that no other job holds and posts its occurrence
+// behind a savepoint, recording the run and the next occurrence in the same
+// database transaction, again when it races another posting, see
+// TransactWithRetry. Insufficient funds and errors that are not domain
+// errors are retried MaxRetries times, RetryIntervalSeconds apart and before
+// the next occurrence; other domain errors fail the occurrence. When the
+// transaction fails anyway the scheduled transfer is postponed, so the ones
+// due after it still run. It returns the run, nil when nothing is due.
+func (r *scheduledTransferRepo) RunNext() (*ScheduledTransferRun, error) {
+	var (
+		run     *ScheduledTransferRun
+		claimed *ScheduledTransfer
+	)
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		run, claimed = nil, nil
+
+		var due []ScheduledTransfer
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", ScheduledTransferActive, time.Now()).
+			Order("next_run_at asc").
+			Limit(1).
+			Find(&due).Error
+		if err != nil || len(due) == 0 {
+			return err
+		}
+		s := &due[0]
+		claimed = &ScheduledTransfer{ID: s.ID, NextRunAt: s.NextRunAt, RetryIntervalSeconds: s.RetryIntervalSeconds}
+
+		run, err = r.runWithTx(tx, s)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&ScheduledTransfer{}).Where("id = ?", s.ID).Updates(map[string]interface{}{
+			"status":             s.Status,
+			"next_occurrence_at": s.NextOccurrenceAt,
+			"next_run_at":        s.NextRunAt,
+			"retries":            s.Retries,
+			"succeeded_count":    s.SucceededCount,
+			"failed_count":       s.FailedCount,
+			"last_run_at":        s.LastRunAt,
+		}).Error
+	})
+	if err != nil {
+		logger.Error("unable to run scheduled transfer | err: ", err)
+		if claimed != nil {
+			r.postpone(claimed)
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// postpone moves the next run of s back by its retry interval after its run
+// failed. It only does when the next run is still the one s was claimed
+// with, a run committed in between is left alone.
+func (r *scheduledTransferRepo) postpone(s *ScheduledTransfer) {
+	retryAt := time.Now().Add(max(time.Duration(s.RetryIntervalSeconds)*time.Second, minScheduledTransferPostpone))
+	err := r.db.Model(&ScheduledTransfer{}).
+		Where("id = ? AND next_run_at = ?", s.ID, s.NextRunAt).
+		Update("next_run_at", retryAt).Error
+	if err != nil {
+		logger.Error("unable to postpone scheduled transfer | err: ", err)
+	}
+}
+
+// runWithTx posts the due occurrence of s unless it already has a final
+// run, records the run and moves s on.
+func (r *scheduledTransferRepo) runWithTx(tx *gorm.DB, s *ScheduledTransfer) (*ScheduledTransferRun, error) {
+	var (
+		now        = time.Now()
+		occurrence = *s.NextOccurrenceAt
+		runs       []ScheduledTransferRun
+	)
+
+	err := tx.Where(&ScheduledTransferRun{ScheduledTransferID: s.ID, OccurrenceAt: occurrence}).
+		Limit(1).
+		Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	run := &ScheduledTransferRun{ScheduledTransferID: s.ID, OccurrenceAt: occurrence}
+	if len(runs) > 0 {
+		run = &runs[0]
+		if run.Status != ScheduledTransferRunRetrying {
+			// posted or given up on already, only the schedule is behind
+			return run, s.advance(now)
+		}
+	}
+
+	err = tx.SavePoint(scheduledTransferSavePoint).Error
+	if err != nil {
+		return nil, err
+	}
+
+	referenceID, err := r.transferWithTx(tx, s)
+	if err != nil {
+		rollbackErr := tx.RollbackTo(scheduledTransferSavePoint).Error
+		if rollbackErr != nil {
+			return nil, rollbackErr
+		}
+	}
+
+	run.Attempts++
+	s.LastRunAt = &now
+
+	var domainErr *errorConst.DomainError
+	switch {
+	case err == nil:
+		run.Status = ScheduledTransferRunSucceeded
+		run.ReferenceID = referenceID
+		run.Error = ""
+		s.SucceededCount++
+	case errors.Is(err, ErrInsufficientFunds) && s.Retries < s.MaxRetries:
+		run.Status = ScheduledTransferRunRetrying
+		run.Error = ErrInsufficientFunds.Message
+	case errors.As(err, &domainErr):
+		run.Status = ScheduledTransferRunFailed
+		run.Error = domainErr.Message
+		s.FailedCount++
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		run.Status = ScheduledTransferRunFailed
+		run.Error = "wallet not found"
+		s.FailedCount++
+	case IsRetryableBalanceError(err):
+		return nil, err
+	case s.Retries < s.MaxRetries:
+		logger.Error("scheduled transfer ", s.UUID, " will be retried | err: ", err)
+		run.Status = ScheduledTransferRunRetrying
+		run.Error = "transfer could not be posted"
+	default:
+		logger.Error("scheduled transfer ", s.UUID, " failed | err: ", err)
+		run.Status = ScheduledTransferRunFailed
+		run.Error = "transfer could not be posted"
+		s.FailedCount++
+	}
+
+	if run.Status == ScheduledTransferRunRetrying {
+		retryAt := now.Add(time.Duration(s.RetryIntervalSeconds) * time.Second)
+		next, err := s.nextOccurrence(occurrence)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil || retryAt.Before(*next) {
+			s.Retries++
+			s.NextRunAt = &retryAt
+		} else {
+			// the next occurrence comes first, this one is given up on
+			run.Status = ScheduledTransferRunFailed
+			s.FailedCount++
+		}
+	}
+
+	if run.ID == 0 {
+		err = tx.Create(run).Error
+	} else {
+		err = tx.Model(&ScheduledTransferRun{}).Where("id = ?", run.ID).Updates(map[string]interface{}{
+			"status":       run.Status,
+			"attempts":     run.Attempts,
+			"reference_id": run.ReferenceID,
+			"error":        run.Error,
+		}).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if run.Status != ScheduledTransferRunRetrying {
+		// missed occurrences collapse into the one just run
+		err = s.advance(now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return run, nil
+}
+
+func (r *scheduledTransferRepo) transferWithTx(tx *gorm.DB, s *ScheduledTransfer) (string, error) {
+	walletRepo := InitWalletRepo(tx)
+
+	from, err := walletRepo.GetWithTx(tx, &Wallet{ID: s.FromWalletID})
+	if err != nil {
+		return "", err
+	}
+
+	to, err := walletRepo.GetWithTx(tx, &Wallet{ID: s.ToWalletID})
+	if err != nil {
+		return "", err
+	}
+
+	posting, err := walletRepo.TransferWithFeeTx(tx, from, to, s.AmountInCents, purposecodes.PurposeCodeTransfer, s.Description)
+	if err != nil {
+		return "", err
+	}
+	return posting.Debit.ReferenceID, nil
+}
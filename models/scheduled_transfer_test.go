@@ -0,0 +1,124 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestRunNextRetriesOtherErrors runs a scheduled transfer whose recipient's
+// legs fail with an error that is neither a domain error nor a race. Its
+// occurrence is retried later rather than holding up the transfer due after
+// it, and fails once its retries are used up.
+func TestRunNextRetriesOtherErrors(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo            = models.InitWalletRepo(db)
+		scheduledTransferRepo = models.InitScheduledTransferRepo(db)
+	)
+
+	wallets := make([]*models.Wallet, 3)
+	for i := range wallets {
+		w := &models.Wallet{UserUUID: fmt.Sprintf("acc_scheduled%d", i), Currency: models.EntityINR}
+		err = walletRepo.Create(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wallets[i] = w
+	}
+	from, broken, ok := wallets[0], wallets[1], wallets[2]
+	_, err = walletRepo.Credit(from, &models.Transaction{AmountInCents: 10000}, purposecodes.PurposeCodeAddFunds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.Exec(`
+		CREATE FUNCTION reject_scheduled_leg() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'wallet is closed for the test';
+		END;
+		$$ LANGUAGE plpgsql`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Exec(fmt.Sprintf(`
+		CREATE TRIGGER reject_scheduled_leg BEFORE INSERT ON transactions
+			FOR EACH ROW WHEN (NEW.wallet_id = %d) EXECUTE FUNCTION reject_scheduled_leg()`, broken.ID)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schedule := func(to *models.Wallet, startAt time.Time) *models.ScheduledTransfer {
+		s := &models.ScheduledTransfer{
+			AccountUUID:          from.UserUUID,
+			FromWalletID:         from.ID,
+			FromWalletUUID:       from.UUID,
+			ToWalletID:           to.ID,
+			ToWalletUUID:         to.UUID,
+			AmountInCents:        100,
+			Timezone:             "UTC",
+			StartAt:              startAt,
+			MaxRetries:           1,
+			RetryIntervalSeconds: 3600,
+		}
+		err := scheduledTransferRepo.Create(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	now := time.Now()
+	failing := schedule(broken, now.Add(-20*time.Second))
+	healthy := schedule(ok, now.Add(-10*time.Second))
+
+	run, err := scheduledTransferRepo.RunNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run == nil || run.ScheduledTransferID != failing.ID || run.Status != models.ScheduledTransferRunRetrying {
+		t.Fatalf("first run %+v, want the failing transfer RETRYING", run)
+	}
+
+	run, err = scheduledTransferRepo.RunNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run == nil || run.ScheduledTransferID != healthy.ID || run.Status != models.ScheduledTransferRunSucceeded {
+		t.Fatalf("second run %+v, want the healthy transfer SUCCEEDED", run)
+	}
+
+	run, err = scheduledTransferRepo.RunNext()
+	if err != nil || run != nil {
+		t.Fatalf("got run %+v and %v, want nothing due before the retry", run, err)
+	}
+
+	// the retry is due, it is the last one
+	err = db.Model(&models.ScheduledTransfer{}).Where("id = ?", failing.ID).Update("next_run_at", time.Now()).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err = scheduledTransferRepo.RunNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run == nil || run.ScheduledTransferID != failing.ID || run.Status != models.ScheduledTransferRunFailed || run.Attempts != 2 {
+		t.Fatalf("retry %+v, want the failing transfer FAILED after 2 attempts", run)
+	}
+
+	got, err := scheduledTransferRepo.Get(&models.ScheduledTransfer{ID: failing.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FailedCount != 1 || got.Status == models.ScheduledTransferActive {
+		t.Errorf("failing transfer is %s with %d failed, want it ended with 1", got.Status, got.FailedCount)
+	}
+}
@@ -31,3 +31,87 @@ func InitWalletRepo(DB *gorm.DB) IWallet {
 		db: DB,
 	}
 }
+
+func InitTransactionRepo(DB *gorm.DB) ITransaction {
+	return &transactionRepo{
+		db: DB,
+	}
+}
+
+func InitIdempotencyKeyRepo(DB *gorm.DB) IIdempotencyKey {
+	return &idempotencyKeyRepo{
+		db: DB,
+	}
+}
+
+func InitLedgerRepo(DB *gorm.DB) ILedger {
+	return &ledgerRepo{
+		db: DB,
+	}
+}
+
+func InitSettlementRepo(DB *gorm.DB) ISettlement {
+	return &settlementRepo{
+		db: DB,
+	}
+}
+
+func InitPendingTransactionRepo(DB *gorm.DB) IPendingTransaction {
+	return &pendingTransactionRepo{
+		db: DB,
+	}
+}
+
+func InitPayoutRepo(DB *gorm.DB) IPayout {
+	return &payoutRepo{
+		db: DB,
+	}
+}
+
+func InitScheduledTransferRepo(DB *gorm.DB) IScheduledTransfer {
+	return &scheduledTransferRepo{
+		db: DB,
+	}
+}
+
+func InitPaymentRequestRepo(DB *gorm.DB) IPaymentRequest {
+	return &paymentRequestRepo{
+		db: DB,
+	}
+}
+
+func InitHandleRepo(DB *gorm.DB) IHandle {
+	return &handleRepo{
+		db: DB,
+	}
+}
+
+func InitMerchantRepo(DB *gorm.DB) IMerchant {
+	return &merchantRepo{
+		db: DB,
+	}
+}
+
+func InitCheckoutSessionRepo(DB *gorm.DB) ICheckoutSession {
+	return &checkoutSessionRepo{
+		db: DB,
+	}
+}
+
+func InitFeeRepo(DB *gorm.DB) IFee {
+	return &feeRepo{
+		db: DB,
+	}
+}
+
+func InitSettlementBatchRepo(DB *gorm.DB) ISettlementBatch {
+	return &settlementBatchRepo{
+		db: DB,
+	}
+}
+
+func InitStreamTicketRepo(DB *gorm.DB) IStreamTicket {
+	return &streamTicketRepo{
+		db: DB,
+	}
+}
@@ -0,0 +1,360 @@
+package models
+
+import (
+	"coinpe/database"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/utils"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	EntitySettlementImport = "sti_"
+	EntitySettlementRecord = "str_"
+
+	// row problems reported back for an invalid file
+	maxSettlementFileErrors = 50
+)
+
+type SettlementRecordStatus string
+
+const (
+	SettlementRecordUnmatched  SettlementRecordStatus = "UNMATCHED"
+	SettlementRecordMatched    SettlementRecordStatus = "MATCHED"
+	SettlementRecordMismatched SettlementRecordStatus = "MISMATCHED"
+	SettlementRecordResolved   SettlementRecordStatus = "RESOLVED"
+)
+
+var (
+	ErrSettlementFileEmpty    = errorConst.New(errorConst.ErrorValidation, errorConst.MsgSettlementFileHasNoRecords)
+	ErrSettlementFileInvalid  = errorConst.New(errorConst.ErrorValidation, errorConst.MsgSettlementFileIsInvalid)
+	ErrSettlementFileImported = errorConst.New(errorConst.ErrorConflict, errorConst.MsgSettlementFileWasAlreadyImported)
+)
+
+// SettlementImport is one settlement file from a bank or payment gateway.
+// The same file (by checksum) can be imported once per source.
+type SettlementImport struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	UUID        string    `json:"uuid" gorm:"unique;not null"`
+	Source      string    `json:"source" gorm:"not null;uniqueIndex:idx_settlement_imports_source_checksum"`
+	Filename    string    `json:"filename"`
+	Checksum    string    `json:"checksum" gorm:"not null;uniqueIndex:idx_settlement_imports_source_checksum"`
+	ImportedBy  string    `json:"imported_by" gorm:"not null"`
+	RecordCount int       `json:"record_count" gorm:"not null"`
+	SettledFrom time.Time `json:"settled_from" gorm:"not null"`
+	SettledTo   time.Time `json:"settled_to" gorm:"not null"`
+}
+
+// SettlementRecord is one line of a settlement file. Reconciliation links it
+// to the CoinPe transaction it settles, see reconciliation.go.
+type SettlementRecord struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	UUID          string                    `json:"uuid" gorm:"unique;not null"`
+	ImportID      uint64                    `json:"import_id" gorm:"not null;index"`
+	RowNumber     int                       `json:"row_number" gorm:"not null"`
+	Reference     string                    `json:"reference,omitempty" gorm:"index"`
+	ExternalID    string                    `json:"external_id,omitempty"`
+	Direction     constants.TransactionType `json:"direction" gorm:"not null"`
+	AmountInCents int                       `json:"amount_in_cents" gorm:"not null"`
+	Currency      string                    `json:"currency" gorm:"not null"`
+	SettledAt     time.Time                 `json:"settled_at" gorm:"not null"`
+	Description   string                    `json:"description,omitempty"`
+
+	Status               SettlementRecordStatus `json:"status" gorm:"not null;index"`
+	MatchRule            SettlementMatchRule    `json:"match_rule,omitempty"`
+	MatchedTransactionID *uint64                `json:"matched_transaction_id,omitempty" gorm:"uniqueIndex"`
+	MatchedTransaction   *Transaction           `json:"matched_transaction,omitempty" gorm:"foreignKey:MatchedTransactionID"`
+	// Detail explains an unmatched or mismatched record
+	Detail string `json:"detail,omitempty"`
+
+	Resolution     SettlementResolution `json:"resolution,omitempty"`
+	ResolutionNote string               `json:"resolution_note,omitempty"`
+	ResolvedBy     string               `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time           `json:"resolved_at,omitempty"`
+}
+
+type settlementRepo struct {
+	db *gorm.DB
+}
+
+func (i *SettlementImport) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.UUID == "" {
+		i.UUID, err = utils.GenerateNanoID(16, EntitySettlementImport)
+	}
+	return err
+}
+
+func (s *SettlementRecord) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.UUID == "" {
+		s.UUID, err = utils.GenerateNanoID(20, EntitySettlementRecord)
+	}
+	return err
+}
+
+// settlementTimeLayouts are tried in order, the ones without a zone are read
+// in the location of the import
+var settlementTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006",
+}
+
+func parseSettledAt(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range settlementTimeLayouts {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid settled_at, expected RFC 3339, YYYY-MM-DD [HH:MM[:SS]] or DD/MM/YYYY")
+}
+
+// ParseSettlementCSV reads a settlement file. The header row names the
+// columns, in any order: amount and settled_at are required; reference,
+// external_id, direction (CREDIT, the default, or DEBIT from CoinPe's
+// side), currency (INR by default) and description are optional. Times
+// without a zone are read in loc. The import holds the checksum, row count
+// and settled period of the file.
+func ParseSettlementCSV(r io.Reader, loc *time.Location) (*SettlementImport, []SettlementRecord, error) {
+	var (
+		hash   = sha256.New()
+		reader = csv.NewReader(io.TeeReader(r, hash))
+	)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, ErrSettlementFileEmpty
+	}
+	if err != nil {
+		return nil, nil, ErrSettlementFileInvalid.WithFields([]errorConst.Error{{Field: "row 1", Description: err.Error()}})
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"amount", "settled_at"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, ErrSettlementFileInvalid.WithFields([]errorConst.Error{{Field: "row 1", Description: "missing column " + required}})
+		}
+	}
+
+	var (
+		records  []SettlementRecord
+		problems []errorConst.Error
+	)
+	for row := 2; ; row++ {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		field := "row " + strconv.Itoa(row)
+		if err != nil {
+			problems = append(problems, errorConst.Error{Field: field, Description: err.Error()})
+			if len(problems) >= maxSettlementFileErrors {
+				break
+			}
+			continue
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(line) {
+				return ""
+			}
+			return strings.TrimSpace(line[i])
+		}
+
+		record := SettlementRecord{
+			RowNumber:   row,
+			Reference:   value("reference"),
+			ExternalID:  value("external_id"),
+			Direction:   constants.TransactionType(strings.ToUpper(value("direction"))),
+			Currency:    strings.ToUpper(value("currency")),
+			Description: value("description"),
+			Status:      SettlementRecordUnmatched,
+		}
+		if record.Direction == "" {
+			record.Direction = constants.TransactionTypeCredit
+		}
+		if record.Currency == "" {
+			record.Currency = EntityINR
+		}
+
+		var rowProblems []string
+		if record.Direction != constants.TransactionTypeCredit && record.Direction != constants.TransactionTypeDebit {
+			rowProblems = append(rowProblems, "invalid direction, expected CREDIT or DEBIT")
+		}
+		record.AmountInCents, err = utils.ParseDecimalCents(value("amount"))
+		if err != nil {
+			rowProblems = append(rowProblems, err.Error())
+		} else if record.AmountInCents <= 0 {
+			rowProblems = append(rowProblems, "amount must be positive, use direction for debits")
+		}
+		record.SettledAt, err = parseSettledAt(value("settled_at"), loc)
+		if err != nil {
+			rowProblems = append(rowProblems, err.Error())
+		}
+
+		if len(rowProblems) > 0 {
+			problems = append(problems, errorConst.Error{Field: field, Description: strings.Join(rowProblems, "; ")})
+			if len(problems) >= maxSettlementFileErrors {
+				break
+			}
+			continue
+		}
+		records = append(records, record)
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, ErrSettlementFileInvalid.WithFields(problems)
+	}
+	if len(records) == 0 {
+		return nil, nil, ErrSettlementFileEmpty
+	}
+
+	imp := &SettlementImport{
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		RecordCount: len(records),
+		SettledFrom: records[0].SettledAt,
+		SettledTo:   records[0].SettledAt,
+	}
+	for _, record := range records {
+		if record.SettledAt.Before(imp.SettledFrom) {
+			imp.SettledFrom = record.SettledAt
+		}
+		if record.SettledAt.After(imp.SettledTo) {
+			imp.SettledTo = record.SettledAt
+		}
+	}
+	return imp, records, nil
+}
+
+// Import implements ISettlement. The records are stored UNMATCHED, it fails
+// with ErrSettlementFileImported when source already imported the file.
+func (r *settlementRepo) Import(imp *SettlementImport, records []SettlementRecord) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		err := tx.Model(&SettlementImport{}).
+			Where(&SettlementImport{Source: imp.Source, Checksum: imp.Checksum}).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrSettlementFileImported
+		}
+
+		err = tx.Create(imp).Error
+		if err != nil {
+			logger.Error("unable to create settlement import | err: ", err)
+			return err
+		}
+
+		for i := range records {
+			records[i].ImportID = imp.ID
+		}
+		err = tx.CreateInBatches(records, 500).Error
+		if err != nil {
+			logger.Error("unable to create settlement records | err: ", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// GetImport implements ISettlement.
+func (r *settlementRepo) GetImport(where *SettlementImport) (*SettlementImport, error) {
+	var imp SettlementImport
+	err := r.db.Model(&SettlementImport{}).Where(where).First(&imp).Error
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// ListImports implements ISettlement. Imports are returned newest first; a
+// non-zero beforeID returns the page after that import.
+func (r *settlementRepo) ListImports(beforeID uint64, limit int) ([]SettlementImport, error) {
+	var (
+		imports = []SettlementImport{}
+	)
+
+	builder := database.Replica(r.db).Model(&SettlementImport{})
+	if beforeID > 0 {
+		builder = builder.Where("id < ?", beforeID)
+	}
+
+	err := builder.Order("id desc").
+		Limit(limit).
+		Find(&imports).Error
+	if err != nil {
+		logger.Error("unable to list settlement imports | err: ", err)
+		return nil, err
+	}
+	return imports, nil
+}
+
+// ListRecords implements ISettlement. Records of the import are returned in
+// file order with their matched transaction, only those in status when it
+// is set.
+func (r *settlementRepo) ListRecords(importID uint64, status SettlementRecordStatus) ([]SettlementRecord, error) {
+	var (
+		records = []SettlementRecord{}
+	)
+	err := r.db.Model(&SettlementRecord{}).
+		Preload("MatchedTransaction").
+		Where(&SettlementRecord{ImportID: importID, Status: status}).
+		Order("row_number asc").
+		Find(&records).Error
+	if err != nil {
+		logger.Error("unable to list settlement records | err: ", err)
+		return nil, err
+	}
+	return records, nil
+}
+
+// CountRecords implements ISettlement, by status.
+func (r *settlementRepo) CountRecords(importID uint64) (map[SettlementRecordStatus]int, error) {
+	var rows []struct {
+		Status SettlementRecordStatus
+		Count  int
+	}
+	err := r.db.Model(&SettlementRecord{}).
+		Select("status, count(*) AS count").
+		Where(&SettlementRecord{ImportID: importID}).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		logger.Error("unable to count settlement records | err: ", err)
+		return nil, err
+	}
+
+	counts := map[SettlementRecordStatus]int{}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,281 @@
+package models
+
+import (
+	"coinpe/database"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntitySettlementBatch = "stb_"
+
+	settlementBatchDescription = "settlement "
+
+	// checkout sessions netted by one settlement batch, a merchant with more
+	// due gets several batches
+	maxSettlementBatchSessions = 5000
+)
+
+// SettlementStatus is where the payment of a checkout session stands
+// between the merchant's pending wallet and its available wallet.
+type SettlementStatus string
+
+const (
+	// paid into the pending wallet, waiting for its batch
+	SettlementPending SettlementStatus = "PENDING"
+	// netted by a settlement batch into the available wallet
+	SettlementSettled SettlementStatus = "SETTLED"
+)
+
+// SettlementBatch moves the receivables of a merchant's checkout sessions
+// paid before CutoffAt from its pending wallet to its available wallet.
+// The refunds and fees taken from the pending wallet are netted:
+// NetInCents is GrossInCents less RefundsInCents and FeesInCents, posted
+// with purpose code SETTLEMENT under ReferenceID, empty when nothing was
+// left to move.
+type SettlementBatch struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	UUID              string    `json:"uuid" gorm:"unique;not null"`
+	MerchantID        uint64    `json:"merchant_id" gorm:"not null;index"`
+	MerchantUUID      string    `json:"merchant_uuid" gorm:"not null"`
+	PendingWalletID   uint64    `json:"pending_wallet_id" gorm:"not null"`
+	PendingWalletUUID string    `json:"pending_wallet_uuid" gorm:"not null"`
+	WalletID          uint64    `json:"wallet_id" gorm:"not null"`
+	WalletUUID        string    `json:"wallet_uuid" gorm:"not null"`
+	Currency          string    `json:"currency" gorm:"not null"`
+	CutoffAt          time.Time `json:"cutoff_at" gorm:"not null"`
+
+	SessionCount   int    `json:"session_count" gorm:"not null"`
+	GrossInCents   int    `json:"gross_in_cents" gorm:"not null"`
+	RefundsInCents int    `json:"refunds_in_cents" gorm:"not null"`
+	FeesInCents    int    `json:"fees_in_cents" gorm:"not null"`
+	NetInCents     int    `json:"net_in_cents" gorm:"not null"`
+	ReferenceID    string `json:"reference_id,omitempty"`
+}
+
+// SettlementLine is what one checkout session of a batch contributed to
+// it. Refunds and their fees count only when paid from the pending wallet.
+type SettlementLine struct {
+	Session        CheckoutSession
+	RefundsInCents int
+	FeesInCents    int
+}
+
+type settlementBatchRepo struct {
+	db *gorm.DB
+}
+
+func (b *SettlementBatch) BeforeCreate(tx *gorm.DB) (err error) {
+	if b.UUID == "" {
+		b.UUID, err = utils.GenerateNanoID(20, EntitySettlementBatch)
+	}
+	return err
+}
+
+// SettlementCutoff returns the cutoff of the batches closed at now for a
+// T+delayDays cycle: the sessions paid on a UTC day settle delayDays days
+// later.
+func SettlementCutoff(now time.Time, delayDays int) time.Time {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.AddDate(0, 0, 1-delayDays)
+}
+
+// DueMerchants implements ISettlementBatch. It returns the IDs of the
+// merchants with pending sessions paid before cutoff.
+func (r *settlementBatchRepo) DueMerchants(cutoff time.Time) ([]uint64, error) {
+	var merchantIDs []uint64
+	err := r.db.Model(&CheckoutSession{}).
+		Where("settlement_status = ? AND paid_at < ?", SettlementPending, cutoff).
+		Distinct().
+		Order("merchant_id").
+		Pluck("merchant_id", &merchantIDs).Error
+	if err != nil {
+		logger.Error("unable to query merchants due for settlement | err: ", err)
+		return nil, err
+	}
+	return merchantIDs, nil
+}
+
+// Close implements ISettlementBatch. It nets up to
+// maxSettlementBatchSessions pending sessions of the merchant paid before
+// cutoff into a batch and moves the net amount to the merchant's available
+// wallet, in one database transaction. The sessions are locked, so a
+// refund either lands in the batch or waits for it and is paid from the
+// available wallet. It returns nil when no session is due.
+func (r *settlementBatchRepo) Close(merchantID uint64, cutoff time.Time) (*SettlementBatch, error) {
+	var batch *SettlementBatch
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		batch = nil
+
+		var m Merchant
+		err := tx.First(&m, merchantID).Error
+		if err != nil {
+			return err
+		}
+
+		var sessions []CheckoutSession
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("merchant_id = ? AND settlement_status = ? AND paid_at < ?", m.ID, SettlementPending, cutoff).
+			Order("id asc").
+			Limit(maxSettlementBatchSessions).
+			Find(&sessions).Error
+		if err != nil || len(sessions) == 0 {
+			return err
+		}
+
+		walletRepo := InitWalletRepo(tx)
+		pending, err := pendingWalletWithTx(tx, &m)
+		if err != nil {
+			return err
+		}
+		available, err := walletRepo.GetWithTx(tx, &Wallet{ID: m.WalletID})
+		if err != nil {
+			return err
+		}
+
+		lines, err := settlementLinesWithTx(tx, pending.ID, sessions)
+		if err != nil {
+			return err
+		}
+
+		b := &SettlementBatch{
+			MerchantID:        m.ID,
+			MerchantUUID:      m.UUID,
+			PendingWalletID:   pending.ID,
+			PendingWalletUUID: pending.UUID,
+			WalletID:          available.ID,
+			WalletUUID:        available.UUID,
+			Currency:          pending.Currency,
+			CutoffAt:          cutoff,
+			SessionCount:      len(lines),
+		}
+		ids := make([]uint64, 0, len(lines))
+		for _, line := range lines {
+			ids = append(ids, line.Session.ID)
+			b.GrossInCents += line.Session.AmountInCents
+			b.RefundsInCents += line.RefundsInCents
+			b.FeesInCents += line.FeesInCents
+			b.NetInCents += line.Session.ReceivableInCents
+		}
+		if b.NetInCents != b.GrossInCents-b.RefundsInCents-b.FeesInCents {
+			return fmt.Errorf("receivables of merchant %s do not add up: net %d, gross %d, refunds %d, fees %d",
+				m.UUID, b.NetInCents, b.GrossInCents, b.RefundsInCents, b.FeesInCents)
+		}
+
+		err = tx.Create(b).Error
+		if err != nil {
+			return err
+		}
+
+		if b.NetInCents > 0 {
+			debit, _, err := walletRepo.TransferWithTx(tx, pending, available, b.NetInCents, purposecodes.PurposeCodeSettlement, settlementBatchDescription+b.UUID)
+			if err != nil {
+				return err
+			}
+			b.ReferenceID = debit.ReferenceID
+			err = tx.Model(&SettlementBatch{}).Where("id = ?", b.ID).Update("reference_id", b.ReferenceID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&CheckoutSession{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"settlement_status":     SettlementSettled,
+			"settlement_batch_id":   b.ID,
+			"settlement_batch_uuid": b.UUID,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		batch = b
+		return nil
+	})
+	if err != nil {
+		logger.Error("unable to close settlement batch | err: ", err)
+		return nil, err
+	}
+	return batch, nil
+}
+
+// settlementLinesWithTx adds to sessions the refunds and refund fees paid
+// from the pending wallet.
+func settlementLinesWithTx(tx *gorm.DB, pendingWalletID uint64, sessions []CheckoutSession) ([]SettlementLine, error) {
+	ids := make([]uint64, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+
+	var refunds []Refund
+	err := tx.Where("checkout_session_id IN ? AND wallet_id = ?", ids, pendingWalletID).Find(&refunds).Error
+	if err != nil {
+		logger.Error("unable to query refunds | err: ", err)
+		return nil, err
+	}
+
+	lines := make([]SettlementLine, len(sessions))
+	index := make(map[uint64]int, len(sessions))
+	for i, s := range sessions {
+		lines[i] = SettlementLine{Session: s, FeesInCents: s.MerchantFeeInCents}
+		index[s.ID] = i
+	}
+	for _, refund := range refunds {
+		line := &lines[index[refund.CheckoutSessionID]]
+		line.RefundsInCents += refund.AmountInCents
+		line.FeesInCents += refund.MerchantFeeInCents
+	}
+	return lines, nil
+}
+
+// Get implements ISettlementBatch.
+func (r *settlementBatchRepo) Get(where *SettlementBatch) (*SettlementBatch, error) {
+	var b SettlementBatch
+	err := r.db.Where(where).First(&b).Error
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// List implements ISettlementBatch. It returns the settlement batches of a
+// merchant newest first.
+func (r *settlementBatchRepo) List(merchantID uint64, beforeID uint64, limit int) ([]SettlementBatch, error) {
+	batches := []SettlementBatch{}
+	builder := database.Replica(r.db).Where(&SettlementBatch{MerchantID: merchantID})
+	if beforeID > 0 {
+		builder = builder.Where("id < ?", beforeID)
+	}
+
+	err := builder.Order("id desc").
+		Limit(limit).
+		Find(&batches).Error
+	if err != nil {
+		logger.Error("unable to list settlement batches | err: ", err)
+		return nil, err
+	}
+	return batches, nil
+}
+
+// Lines implements ISettlementBatch. It returns what each checkout session
+// of a batch contributed to it, in the order they were paid.
+func (r *settlementBatchRepo) Lines(b *SettlementBatch) ([]SettlementLine, error) {
+	var sessions []CheckoutSession
+	err := r.db.Where(&CheckoutSession{SettlementBatchID: &b.ID}).Order("paid_at asc, id asc").Find(&sessions).Error
+	if err != nil {
+		logger.Error("unable to query settlement batch sessions | err: ", err)
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return []SettlementLine{}, nil
+	}
+	return settlementLinesWithTx(r.db, b.PendingWalletID, sessions)
+}
@@ -0,0 +1,162 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"testing"
+	"time"
+)
+
+func TestSettlementCutoff(t *testing.T) {
+	var (
+		now = time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC)
+		ist = time.FixedZone("IST", 5*3600+1800)
+	)
+	tests := []struct {
+		now   time.Time
+		delay int
+		want  time.Time
+	}{
+		{now, 0, time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{now, 1, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{now, 2, time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 1, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		// still the 5th in UTC
+		{time.Date(2025, 3, 6, 2, 0, 0, 0, ist), 1, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := models.SettlementCutoff(tt.now, tt.delay); !got.Equal(tt.want) {
+			t.Errorf("T+%d cutoff at %s is %s, want %s", tt.delay, tt.now, got, tt.want)
+		}
+	}
+}
+
+// TestCloseSettlementBatch settles two sessions paid before the cutoff, one
+// of them partly refunded from the pending wallet, and leaves the one paid
+// after it. The batch nets the merchant's fees and the refund, and a refund
+// after settlement is paid from the merchant's available wallet.
+func TestCloseSettlementBatch(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo          = models.InitWalletRepo(db)
+		checkoutSessionRepo = models.InitCheckoutSessionRepo(db)
+		settlementBatchRepo = models.InitSettlementBatchRepo(db)
+	)
+
+	m, available := newMerchant(t, db, "acc_settlemerchant")
+	customer := &models.Wallet{UserUUID: "acc_settlecustomer", Currency: models.EntityINR}
+	err = walletRepo.Create(customer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = walletRepo.Credit(customer, &models.Transaction{AmountInCents: 10000}, purposecodes.PurposeCodeAddFunds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []models.FeeSchedule{
+		{Name: "payment fee", MerchantID: &m.ID, MerchantUUID: m.UUID, PurposeCode: purposecodes.PurposeCodePayment,
+			Payer: models.FeePayerRecipient, Type: models.FeeFlat, FlatInCents: 20},
+		{Name: "refund fee", MerchantID: &m.ID, MerchantUUID: m.UUID, PurposeCode: purposecodes.PurposeCodeRefund,
+			Payer: models.FeePayerSender, Type: models.FeeFlat, FlatInCents: 5},
+	} {
+		err = models.InitFeeRepo(db).Create(&s)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions := []*models.CheckoutSession{
+		newCheckoutSession(t, db, m, "order-1", 1000),
+		newCheckoutSession(t, db, m, "order-2", 500),
+		newCheckoutSession(t, db, m, "order-3", 700),
+	}
+	for _, s := range sessions {
+		_, _, err = checkoutSessionRepo.Pay(s.ID, customer)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _, err = checkoutSessionRepo.Refund(sessions[0].ID, 300, "one cup short")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first two were paid the day before
+	cutoff := models.SettlementCutoff(time.Now(), 1)
+	err = db.Model(&models.CheckoutSession{}).
+		Where("id IN ?", []uint64{sessions[0].ID, sessions[1].ID}).
+		Update("paid_at", cutoff.Add(-time.Hour)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := settlementBatchRepo.DueMerchants(cutoff)
+	if err != nil || len(due) != 1 || due[0] != m.ID {
+		t.Fatalf("merchants due %v and %v, want only %d", due, err, m.ID)
+	}
+
+	batch, err := settlementBatchRepo.Close(m.ID, cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 980 and 480 received after the payment fees, less the refund and its fee
+	if batch == nil || batch.SessionCount != 2 || batch.GrossInCents != 1500 || batch.RefundsInCents != 300 ||
+		batch.FeesInCents != 45 || batch.NetInCents != 1155 || batch.ReferenceID == "" {
+		t.Fatalf("batch %+v, want 2 sessions netting 1500 less 300 refunded and 45 of fees to 1155", batch)
+	}
+
+	again, err := settlementBatchRepo.Close(m.ID, cutoff)
+	if err != nil || again != nil {
+		t.Errorf("closing again got %+v and %v, want no batch", again, err)
+	}
+
+	lines, err := settlementBatchRepo.Lines(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0].Session.ID != sessions[0].ID || lines[0].RefundsInCents != 300 || lines[0].FeesInCents != 25 {
+		t.Errorf("lines %+v, want the first session with its refund and 25 of fees first", lines)
+	}
+
+	_, refund, err := checkoutSessionRepo.Refund(sessions[0].ID, 100, "late complaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.WalletID != available.ID {
+		t.Errorf("refund after settlement paid from wallet %d, want the available wallet %d", refund.WalletID, available.ID)
+	}
+
+	for _, w := range []struct {
+		id   uint64
+		want int
+	}{
+		{available.ID, 1155 - 105},
+		// what the third session left there
+		{*m.PendingWalletID, 680},
+		{customer.ID, 10000 - 2200 + 300 + 100},
+	} {
+		got, err := walletRepo.Get(&models.Wallet{ID: w.id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.TotalBalanceInCents != w.want {
+			t.Errorf("wallet %s holds %d, want %d", got.UUID, got.TotalBalanceInCents, w.want)
+		}
+	}
+
+	third, err := checkoutSessionRepo.Get(&models.CheckoutSession{ID: sessions[2].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.SettlementStatus != models.SettlementPending || third.SettlementBatchID != nil {
+		t.Errorf("session paid after the cutoff is %s in batch %v, want it PENDING", third.SettlementStatus, third.SettlementBatchID)
+	}
+}
@@ -0,0 +1,172 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSettlementCSV(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	file := "\ufeffSettled_At, Amount,Reference,Direction,currency\n" +
+		"2025-03-02 10:00,\"1,234.5\",bank-1,,\n" +
+		"2025-03-01T08:00:00Z,10,bank-2,debit,usd\n" +
+		"03/03/2025,0.05,,CREDIT,INR\n"
+
+	imp, records, err := models.ParseSettlementCSV(strings.NewReader(file), ist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp.RecordCount != 3 || len(records) != 3 || imp.Checksum == "" {
+		t.Fatalf("import %+v with %d records, want 3 and a checksum", imp, len(records))
+	}
+
+	first := records[0]
+	if first.RowNumber != 2 || first.AmountInCents != 123450 || first.Reference != "bank-1" ||
+		first.Direction != constants.TransactionTypeCredit || first.Currency != models.EntityINR ||
+		first.Status != models.SettlementRecordUnmatched {
+		t.Errorf("first record %+v, want row 2 crediting 123450 INR for bank-1", first)
+	}
+	if want := time.Date(2025, 3, 2, 10, 0, 0, 0, ist); !first.SettledAt.Equal(want) {
+		t.Errorf("first record settled at %s, want %s read in the import's zone", first.SettledAt, want)
+	}
+	if records[1].Direction != constants.TransactionTypeDebit || records[1].Currency != "USD" {
+		t.Errorf("second record %+v, want a USD debit", records[1])
+	}
+	if !imp.SettledFrom.Equal(records[1].SettledAt) || !imp.SettledTo.Equal(records[2].SettledAt) {
+		t.Errorf("settled from %s to %s, want the earliest and latest records", imp.SettledFrom, imp.SettledTo)
+	}
+
+	// the same bytes read elsewhere are the same file
+	again, _, err := models.ParseSettlementCSV(strings.NewReader(file), time.UTC)
+	if err != nil || again.Checksum != imp.Checksum {
+		t.Errorf("checksum %s then %s (%v), want it to depend on the file only", imp.Checksum, again.Checksum, err)
+	}
+}
+
+func TestParseSettlementCSVErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		err    error
+		fields []string
+	}{
+		{"empty", "", models.ErrSettlementFileEmpty, nil},
+		{"header only", "amount,settled_at\n", models.ErrSettlementFileEmpty, nil},
+		{"missing column", "amount,reference\n10,bank-1\n", models.ErrSettlementFileInvalid, []string{"row 1"}},
+		{
+			name: "bad rows",
+			file: "amount,settled_at,direction\n" +
+				"10,2025-03-01,\n" +
+				"-10,2025-03-01,\n" +
+				"1.234,yesterday,\n" +
+				"10,2025-03-01,SIDEWAYS\n",
+			err:    models.ErrSettlementFileInvalid,
+			fields: []string{"row 3", "row 4", "row 5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := models.ParseSettlementCSV(strings.NewReader(tt.file), time.UTC)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			var fields []string
+			for _, f := range errorConst.FromError(err).Fields {
+				fields = append(fields, f.Field)
+			}
+			if fmt.Sprint(fields) != fmt.Sprint(tt.fields) {
+				t.Errorf("problems on %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+// TestReconcile imports a settlement file with a record for each outcome of
+// the matching rules and refuses to import it twice.
+func TestReconcile(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo     = models.InitWalletRepo(db)
+		settlementRepo = models.InitSettlementRepo(db)
+	)
+
+	wallet := &models.Wallet{UserUUID: "acc_settled", Currency: models.EntityINR}
+	err = walletRepo.Create(wallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, funding := range []models.Transaction{
+		{AmountInCents: 1000, ReferenceID: "bank-1"},
+		{AmountInCents: 2500, ReferenceID: "bank-2"},
+		{AmountInCents: 777},
+	} {
+		_, err = walletRepo.Credit(wallet, &funding, purposecodes.PurposeCodeAddFunds)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	settledAt := time.Now().UTC().Format(time.RFC3339)
+	file := "reference,amount,settled_at\n" +
+		"bank-1,10.00," + settledAt + "\n" +
+		"bank-2,24.00," + settledAt + "\n" +
+		",7.77," + settledAt + "\n" +
+		",99.99," + settledAt + "\n"
+	parse := func() (*models.SettlementImport, []models.SettlementRecord) {
+		imp, records, err := models.ParseSettlementCSV(strings.NewReader(file), time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		imp.Source = "bank"
+		imp.ImportedBy = "acc_admin"
+		return imp, records
+	}
+
+	imp, records := parse()
+	err = settlementRepo.Import(imp, records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = settlementRepo.Import(parse())
+	if !errors.Is(err, models.ErrSettlementFileImported) {
+		t.Errorf("second import got %v, want ErrSettlementFileImported", err)
+	}
+
+	result, err := settlementRepo.Reconcile(imp.ID, models.DefaultReconciliationDateWindow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.ReconciliationResult{
+		models.SettlementRecordMatched:    2,
+		models.SettlementRecordMismatched: 1,
+		models.SettlementRecordUnmatched:  1,
+	}
+	if fmt.Sprint(result) != fmt.Sprint(want) {
+		t.Errorf("reconciled %v, want %v", result, want)
+	}
+
+	matched, err := settlementRepo.ListRecords(imp.ID, models.SettlementRecordMatched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := map[models.SettlementMatchRule]bool{}
+	for _, record := range matched {
+		rules[record.MatchRule] = true
+	}
+	if !rules[models.SettlementMatchReference] || !rules[models.SettlementMatchAmountDate] {
+		t.Errorf("matched by %v, want one record by reference and one by amount and date", rules)
+	}
+}
@@ -0,0 +1,291 @@
+package models
+
+import (
+	"coinpe/database"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	dayFormat = "2006-01-02"
+
+	MaxStatementTransactions = 5000
+
+	signedAmountSQL = "CASE WHEN type = 'DEBIT' THEN -amount_in_cents ELSE amount_in_cents END"
+)
+
+var ErrStatementTooLarge = errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgStatementTooManyTransactions)
+
+// BalanceSnapshot is the balance of a wallet at the end of Day (UTC), for
+// wallets with transactions that day.
+type BalanceSnapshot struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	WalletID       uint64    `json:"wallet_id" gorm:"not null;uniqueIndex:idx_balance_snapshots_wallet_day"`
+	Day            time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_balance_snapshots_wallet_day"`
+	BalanceInCents int       `json:"balance_in_cents" gorm:"not null"`
+}
+
+// Statement covers the transactions of a wallet created in [From, To).
+type Statement struct {
+	Wallet                Wallet
+	From                  time.Time
+	To                    time.Time
+	OpeningBalanceInCents int
+	ClosingBalanceInCents int
+	TotalCreditsInCents   int
+	TotalDebitsInCents    int
+	Transactions          []Transaction
+	Checksum              string
+}
+
+// StatementChecksum is computed as a statement is written out. It covers the
+// wallet, the period, the opening balance, the Hash of every transaction in
+// order and the closing balance, so any export of the same statement, CSV,
+// PDF or JSON, carries the same checksum.
+type StatementChecksum struct {
+	h hash.Hash
+}
+
+func NewStatementChecksum(walletUUID string, from time.Time, to time.Time, openingBalanceInCents int) *StatementChecksum {
+	s := &StatementChecksum{h: sha256.New()}
+	s.write(
+		"coinpe-statement",
+		walletUUID,
+		strconv.FormatInt(from.UnixMicro(), 10),
+		strconv.FormatInt(to.UnixMicro(), 10),
+		strconv.Itoa(openingBalanceInCents),
+	)
+	return s
+}
+
+func (s *StatementChecksum) write(fields ...string) {
+	for _, f := range fields {
+		s.h.Write([]byte(strconv.Itoa(len(f)) + ":" + f))
+	}
+}
+
+func (s *StatementChecksum) Add(t *Transaction) {
+	s.write(t.Hash)
+}
+
+// Sum returns the hex checksum, closing the statement at closingBalanceInCents.
+func (s *StatementChecksum) Sum(closingBalanceInCents int) string {
+	s.write(strconv.Itoa(closingBalanceInCents))
+	return hex.EncodeToString(s.h.Sum(nil))
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// BalanceAt implements ILedger. It is the balance after every successful
+// transaction created at or before at, summed over the shards of a sharded
+// wallet. It is history, so it may be served by a replica.
+func (r *ledgerRepo) BalanceAt(walletID uint64, at time.Time) (int, error) {
+	var (
+		db      = database.Replica(r.db)
+		rowIDs  []uint64
+		balance int
+	)
+
+	err := db.Model(&Wallet{}).
+		Where("id = ? OR parent_wallet_id = ?", walletID, walletID).
+		Pluck("id", &rowIDs).Error
+	if err != nil {
+		logger.Error("unable to get wallet shards | err: ", err)
+		return 0, err
+	}
+
+	for _, rowID := range rowIDs {
+		rowBalance, err := r.rowBalanceAt(db, rowID, at)
+		if err != nil {
+			return 0, err
+		}
+		balance += rowBalance
+	}
+	return balance, nil
+}
+
+// rowBalanceAt is BalanceAt of one wallet row: the latest snapshot that ends
+// before at plus the transactions since.
+func (r *ledgerRepo) rowBalanceAt(db *gorm.DB, walletID uint64, at time.Time) (int, error) {
+	var (
+		snapshots []BalanceSnapshot
+		balance   int
+	)
+
+	err := db.Where("wallet_id = ? AND day < ?", walletID, startOfDay(at).Format(dayFormat)).
+		Order("day desc").
+		Limit(1).
+		Find(&snapshots).Error
+	if err != nil {
+		logger.Error("unable to get balance snapshot | err: ", err)
+		return 0, err
+	}
+
+	builder := db.Model(&Transaction{}).
+		Select("coalesce(sum("+signedAmountSQL+"), 0)").
+		Where(&Transaction{WalletID: walletID, Status: constants.EntitySuccess}).
+		Where("created_at <= ?", at)
+
+	if len(snapshots) > 0 {
+		balance = snapshots[0].BalanceInCents
+		builder = builder.Where("created_at >= ?", startOfDay(snapshots[0].Day).AddDate(0, 0, 1))
+	}
+
+	var sinceSnapshot int
+	err = builder.Scan(&sinceSnapshot).Error
+	if err != nil {
+		logger.Error("unable to sum transactions | err: ", err)
+		return 0, err
+	}
+
+	return balance + sinceSnapshot, nil
+}
+
+// Statement implements ILedger. It fails with ErrStatementTooLarge past
+// MaxStatementTransactions line items.
+func (r *ledgerRepo) Statement(wallet *Wallet, from time.Time, to time.Time) (*Statement, error) {
+	// postgres keeps microseconds, so this excludes exactly from onwards
+	opening, err := r.BalanceAt(wallet.ID, from.Add(-time.Microsecond))
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{
+		Wallet:                *wallet,
+		From:                  from,
+		To:                    to,
+		OpeningBalanceInCents: opening,
+		ClosingBalanceInCents: opening,
+		Transactions:          []Transaction{},
+	}
+
+	err = database.Replica(r.db).Model(&Transaction{}).
+		Where(ofWalletSQL, wallet.ID, wallet.ID).
+		Where(&Transaction{Status: constants.EntitySuccess}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at asc, id asc").
+		Limit(MaxStatementTransactions + 1).
+		Find(&statement.Transactions).Error
+	if err != nil {
+		logger.Error("unable to list statement transactions | err: ", err)
+		return nil, err
+	}
+
+	if len(statement.Transactions) > MaxStatementTransactions {
+		return nil, ErrStatementTooLarge
+	}
+
+	checksum := NewStatementChecksum(wallet.UUID, from, to, opening)
+	for i, t := range statement.Transactions {
+		checksum.Add(&statement.Transactions[i])
+		if t.Type == constants.TransactionTypeDebit {
+			statement.TotalDebitsInCents += t.AmountInCents
+		} else {
+			statement.TotalCreditsInCents += t.AmountInCents
+		}
+	}
+	statement.ClosingBalanceInCents = opening + statement.TotalCreditsInCents - statement.TotalDebitsInCents
+	statement.Checksum = checksum.Sum(statement.ClosingBalanceInCents)
+
+	return statement, nil
+}
+
+// StreamStatement implements ILedger. It is Statement without the line item
+// limit: fn gets the opening balance, then each transaction is scanned from a
+// cursor and passed to each as it is read, so large periods are never held
+// in memory. fn or each can stop the stream by returning an error.
+func (r *ledgerRepo) StreamStatement(walletID uint64, from time.Time, to time.Time, fn func(openingBalanceInCents int) error, each func(t *Transaction) error) error {
+	opening, err := r.BalanceAt(walletID, from.Add(-time.Microsecond))
+	if err != nil {
+		return err
+	}
+	err = fn(opening)
+	if err != nil {
+		return err
+	}
+
+	db := database.Replica(r.db)
+	rows, err := db.Model(&Transaction{}).
+		Where(ofWalletSQL, walletID, walletID).
+		Where(&Transaction{Status: constants.EntitySuccess}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at asc, id asc").
+		Rows()
+	if err != nil {
+		logger.Error("unable to list statement transactions | err: ", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Transaction
+		err = db.ScanRows(rows, &t)
+		if err != nil {
+			return err
+		}
+		err = each(&t)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// NextSnapshotDay implements ILedger. It is the day after the latest
+// snapshot, or the day of the first transaction; ok is false when there are
+// no transactions yet.
+func (r *ledgerRepo) NextSnapshotDay() (day time.Time, ok bool, err error) {
+	var latest []BalanceSnapshot
+	err = r.db.Order("day desc").Limit(1).Find(&latest).Error
+	if err != nil {
+		return day, false, err
+	}
+	if len(latest) > 0 {
+		return startOfDay(latest[0].Day).AddDate(0, 0, 1), true, nil
+	}
+
+	var first []Transaction
+	err = r.db.Select("created_at").Order("id asc").Limit(1).Find(&first).Error
+	if err != nil || len(first) == 0 || first[0].CreatedAt == nil {
+		return day, false, err
+	}
+	return startOfDay(*first[0].CreatedAt), true, nil
+}
+
+// SnapshotBalances implements ILedger. It snapshots the end of day balance
+// of every wallet with transactions that day and returns how many were
+// written. Snapshotting a day again leaves existing snapshots untouched.
+func (r *ledgerRepo) SnapshotBalances(day time.Time) (int64, error) {
+	day = startOfDay(day)
+
+	result := r.db.Exec(`INSERT INTO balance_snapshots (created_at, wallet_id, day, balance_in_cents)
+		SELECT now(), t.wallet_id, ?::date,
+			coalesce((
+				SELECT s.balance_in_cents FROM balance_snapshots s
+				WHERE s.wallet_id = t.wallet_id AND s.day < ?::date
+				ORDER BY s.day DESC LIMIT 1
+			), 0) + sum(`+signedAmountSQL+`)
+		FROM transactions t
+		WHERE t.status = ? AND t.deleted_at IS NULL AND t.created_at >= ? AND t.created_at < ?
+		GROUP BY t.wallet_id
+		ON CONFLICT (wallet_id, day) DO NOTHING`,
+		day.Format(dayFormat), day.Format(dayFormat), constants.EntitySuccess, day, day.AddDate(0, 0, 1))
+	if result.Error != nil {
+		logger.Error("unable to snapshot balances | err: ", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
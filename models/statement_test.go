@@ -0,0 +1,130 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"testing"
+	"time"
+)
+
+func TestStatementChecksum(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	sum := func(walletUUID string, opening int, hashes []string, closing int) string {
+		c := models.NewStatementChecksum(walletUUID, from, to, opening)
+		for _, h := range hashes {
+			c.Add(&models.Transaction{Hash: h})
+		}
+		return c.Sum(closing)
+	}
+
+	want := sum("wa_statement", 100, []string{"a", "b"}, 300)
+	if got := sum("wa_statement", 100, []string{"a", "b"}, 300); got != want {
+		t.Fatalf("same statement summed to %s and %s", want, got)
+	}
+
+	for name, got := range map[string]string{
+		"wallet":   sum("wa_other", 100, []string{"a", "b"}, 300),
+		"opening":  sum("wa_statement", 101, []string{"a", "b"}, 300),
+		"order":    sum("wa_statement", 100, []string{"b", "a"}, 300),
+		"missing":  sum("wa_statement", 100, []string{"a"}, 300),
+		"boundary": sum("wa_statement", 100, []string{"ab", ""}, 300),
+		"closing":  sum("wa_statement", 100, []string{"a", "b"}, 299),
+	} {
+		if got == want {
+			t.Errorf("changing the %s kept the checksum", name)
+		}
+	}
+}
+
+// TestBalanceAt posts a transaction a day, snapshots the first two days and
+// reads balances and a statement across the snapshots.
+func TestBalanceAt(t *testing.T) {
+	db := databasetest.New(t)
+	err := models.AddSystemData(db, constants.EnvTesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		walletRepo = models.InitWalletRepo(db)
+		ledgerRepo = models.InitLedgerRepo(db)
+	)
+
+	wallet := &models.Wallet{UserUUID: "acc_statement", Currency: models.EntityINR}
+	err = walletRepo.Create(wallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	postAt := func(at time.Time, amount int, post func(*models.Wallet, *models.Transaction, purposecodes.TransactionPurposeCode) (*models.Wallet, error)) {
+		t.Helper()
+		_, err := post(wallet, &models.Transaction{AmountInCents: amount}, purposecodes.PurposeCodeAdjustment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = db.Model(&models.Transaction{}).
+			Where("wallet_id = ? AND amount_in_cents = ?", wallet.ID, amount).
+			Update("created_at", at).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	postAt(day.Add(10*time.Hour), 1000, walletRepo.Credit)
+	postAt(day.AddDate(0, 0, 1).Add(10*time.Hour), 300, walletRepo.Debit)
+	postAt(day.AddDate(0, 0, 2).Add(10*time.Hour), 200, walletRepo.Credit)
+
+	for i := range 2 {
+		_, err = ledgerRepo.SnapshotBalances(day.AddDate(0, 0, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// snapshotting a day again changes nothing
+	written, err := ledgerRepo.SnapshotBalances(day)
+	if err != nil || written != 0 {
+		t.Fatalf("snapshotting again wrote %d and got %v, want nothing", written, err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want int
+	}{
+		{day.Add(9 * time.Hour), 0},
+		{day.Add(10 * time.Hour), 1000},
+		{day.AddDate(0, 0, 1).Add(9 * time.Hour), 1000},
+		{day.AddDate(0, 0, 1).Add(12 * time.Hour), 700},
+		{day.AddDate(0, 0, 2).Add(9 * time.Hour), 700},
+		{day.AddDate(0, 0, 2).Add(12 * time.Hour), 900},
+	}
+	for _, tt := range tests {
+		got, err := ledgerRepo.BalanceAt(wallet.ID, tt.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("balance at %s is %d, want %d", tt.at, got, tt.want)
+		}
+	}
+
+	from, to := day.AddDate(0, 0, 1), day.AddDate(0, 0, 3)
+	statement, err := ledgerRepo.Statement(wallet, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statement.OpeningBalanceInCents != 1000 || statement.TotalDebitsInCents != 300 ||
+		statement.TotalCreditsInCents != 200 || statement.ClosingBalanceInCents != 900 || len(statement.Transactions) != 2 {
+		t.Errorf("statement %+v, want 1000 opening, 300 debited, 200 credited and 900 closing in 2 transactions", statement)
+	}
+
+	checksum := models.NewStatementChecksum(wallet.UUID, from, to, statement.OpeningBalanceInCents)
+	for i := range statement.Transactions {
+		checksum.Add(&statement.Transactions[i])
+	}
+	if sum := checksum.Sum(statement.ClosingBalanceInCents); sum != statement.Checksum {
+		t.Errorf("statement checksum %s, want %s", statement.Checksum, sum)
+	}
+}
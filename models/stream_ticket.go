@@ -0,0 +1,90 @@
+package models
+
+import (
+	"coinpe/pkg/logger"
+	"coinpe/pkg/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntityStreamTicket = "st_"
+	streamTicketLength = 32
+)
+
+// StreamTicket lets a client that cannot send an Authorization header, like
+// a browser EventSource, open a wallet event stream as AccountUUID. It is
+// redeemed once, before ExpiresAt.
+type StreamTicket struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	TicketHash  string    `json:"-" gorm:"not null;uniqueIndex"`
+	AccountUUID string    `json:"account_uuid" gorm:"not null"`
+	Role        string    `json:"role" gorm:"not null"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+type streamTicketRepo struct {
+	db *gorm.DB
+}
+
+func hashStreamTicket(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue implements IStreamTicket. It returns the ticket, which is not stored,
+// and when it expires.
+func (r *streamTicketRepo) Issue(accountUUID string, role string, ttl time.Duration) (string, time.Time, error) {
+	ticket, err := utils.GenerateNanoID(streamTicketLength, EntityStreamTicket)
+	if err != nil {
+		logger.Error("unable to generate nano id | err: ", err)
+		return "", time.Time{}, err
+	}
+
+	t := &StreamTicket{
+		TicketHash:  hashStreamTicket(ticket),
+		AccountUUID: accountUUID,
+		Role:        role,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	err = r.db.Create(t).Error
+	if err != nil {
+		logger.Error("unable to create stream ticket | err: ", err)
+		return "", time.Time{}, err
+	}
+	return ticket, t.ExpiresAt, nil
+}
+
+// Redeem implements IStreamTicket. It deletes the ticket and returns it,
+// gorm.ErrRecordNotFound when it is unknown, redeemed or expired.
+func (r *streamTicketRepo) Redeem(ticket string) (*StreamTicket, error) {
+	var redeemed []StreamTicket
+	err := r.db.Clauses(clause.Returning{}).
+		Where("ticket_hash = ? AND expires_at > ?", hashStreamTicket(ticket), time.Now()).
+		Delete(&redeemed).Error
+	if err != nil {
+		logger.Error("unable to redeem stream ticket | err: ", err)
+		return nil, err
+	}
+	if len(redeemed) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &redeemed[0], nil
+}
+
+// DeleteExpired implements IStreamTicket and returns how many tickets
+// expired without being redeemed.
+func (r *streamTicketRepo) DeleteExpired() (int64, error) {
+	result := r.db.Where("expires_at <= ?", time.Now()).Delete(&StreamTicket{})
+	if result.Error != nil {
+		logger.Error("unable to delete expired stream tickets | err: ", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -1,14 +1,131 @@
 package models
 
 import (
+	"coinpe/fixtures"
 	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"fmt"
+	"slices"
 
 	"gorm.io/gorm"
 )
 
+// Seeder inserts default data. Seeders run on every boot, so they must leave
+// existing rows untouched.
+type Seeder struct {
+	Name string
+	// Envs the seeder runs in, every environment when empty.
+	Envs []constants.AppEnv
+	Seed func(tx *gorm.DB) error
+}
+
+var Seeders = []Seeder{
+	{
+		Name: "permissions",
+		Seed: func(tx *gorm.DB) error {
+			err := InitPermissionRepo(tx).BulkCreate(PermissionsToMigrate)
+			if err != nil {
+				return err
+			}
+			return syncSequence(tx, "permissions")
+		},
+	},
+	{
+		Name: "roles",
+		Seed: func(tx *gorm.DB) error {
+			err := InitRoleRepo(tx).BulkCreate(&RolesToMigrate)
+			if err != nil {
+				return err
+			}
+			return syncSequence(tx, "roles")
+		},
+	},
+	{
+		Name: "treasury wallet",
+		Seed: seedTreasuryWallet,
+	},
+	{
+		Name: "fee revenue wallet",
+		Seed: seedFeeRevenueWallet,
+	},
+	{
+		Name: "demo accounts",
+		Envs: []constants.AppEnv{constants.EnvLocal, constants.EnvSandbox},
+		Seed: func(tx *gorm.DB) error {
+			return LoadFixture(tx, fixtures.FS, "demo.yaml")
+		},
+	},
+}
+
+// seedTreasuryWallet creates CoinpeWallet empty and funds it with an
+// ADD_FUNDS credit, so the ledger verifier can account for its balance.
+func seedTreasuryWallet(tx *gorm.DB) error {
+	var existing Wallet
+	err := tx.Where(&Wallet{ID: CoinpeWallet.ID}).Limit(1).Find(&existing).Error
+	if err != nil {
+		return err
+	}
+	if existing.ID != 0 {
+		return syncSequence(tx, "wallets")
+	}
+
+	walletRepo := InitWalletRepo(tx)
+	wallet := CoinpeWallet
+	wallet.TotalBalanceInCents = 0
+	err = walletRepo.CreateWithTx(tx, &wallet)
+	if err != nil {
+		return err
+	}
+
+	err = syncSequence(tx, "wallets")
+	if err != nil {
+		return err
+	}
+
+	_, err = walletRepo.CreditWithTx(tx, &wallet, &Transaction{
+		AmountInCents: CoinpeWallet.TotalBalanceInCents,
+		Description:   "treasury opening balance",
+	}, purposecodes.PurposeCodeAddFunds)
+	return err
+}
+
+// seedFeeRevenueWallet creates FeeRevenueWallet empty. Unlike the
+// treasury it has no fixed id, so it cannot collide with wallets created
+// before it.
+func seedFeeRevenueWallet(tx *gorm.DB) error {
+	var existing Wallet
+	err := tx.Where(&Wallet{UUID: FeeRevenueWallet.UUID}).Limit(1).Find(&existing).Error
+	if err != nil || existing.ID != 0 {
+		return err
+	}
+
+	wallet := FeeRevenueWallet
+	return InitWalletRepo(tx).CreateWithTx(tx, &wallet)
+}
+
+// syncSequence moves the id sequence of table past the ids seeded
+// explicitly, otherwise the next insert would reuse one of them.
+func syncSequence(tx *gorm.DB, table string) error {
+	return tx.Exec("SELECT setval(pg_get_serial_sequence(?, 'id'), GREATEST((SELECT max(id) FROM "+table+"), 1))", table).Error
+}
+
+func (s Seeder) enabledIn(env constants.AppEnv) bool {
+	return len(s.Envs) == 0 || slices.Contains(s.Envs, env)
+}
+
 // AddSystemData: Use this hook to populate any default data to the database.
-func AddSystemData(db *gorm.DB, env constants.AppEnv) {
-	InitPermissionRepo(db).BulkCreate(PermissionsToMigrate)
-	InitRoleRepo(db).BulkCreate(&RolesToMigrate)
-	InitWalletRepo(db).Create(&CoinpeWallet)
+// Each seeder enabled in env runs in its own transaction and the first one
+// failing stops the rest.
+func AddSystemData(db *gorm.DB, env constants.AppEnv) error {
+	for _, seeder := range Seeders {
+		if !seeder.enabledIn(env) {
+			continue
+		}
+
+		err := db.Transaction(seeder.Seed)
+		if err != nil {
+			return fmt.Errorf("seeder %s failed: %w", seeder.Name, err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"coinpe/database"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"coinpe/pkg/utils"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	EntityTransaction = "txn_"
+	EntityTransfer    = "trf_"
+)
+
+type Transaction struct {
+	ID        uint64         `json:"id" gorm:"primaryKey"`
+	CreatedAt *time.Time     `json:"created_at,omitempty"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+
+	UUID                  string                              `json:"uuid" gorm:"unique;not null"`
+	WalletID              uint64                              `json:"wallet_id" gorm:"not null;index"`
+	ParentWalletID        *uint64                             `json:"parent_wallet_id,omitempty" gorm:"index"` // set on legs posted to a shard, see ofWalletSQL
+	Type                  constants.TransactionType           `json:"type" gorm:"not null"`
+	AmountInCents         int                                 `json:"amount_in_cents" gorm:"not null"`
+	OpeningBalanceInCents int                                 `json:"opening_balance_in_cents"`
+	ClosingBalanceInCents int                                 `json:"closing_balance_in_cents"`
+	Status                constants.EntityStatus              `json:"status" gorm:"not null"`
+	FromWalletUUID        string                              `json:"from_wallet_uuid,omitempty"`
+	ToWalletUUID          string                              `json:"to_wallet_uuid,omitempty"`
+	PurposeCode           purposecodes.TransactionPurposeCode `json:"purpose_code" gorm:"not null"`
+	ReferenceID           string                              `json:"reference_id,omitempty" gorm:"index"`
+	Description           string                              `json:"description,omitempty"`
+	Metadata              datatypes.JSON                      `json:"metadata,omitempty"`
+
+	// hash chains, see transaction_chain.go
+	WalletSequence int64  `json:"wallet_sequence" gorm:"not null"`
+	PrevHash       string `json:"prev_hash" gorm:"not null"`
+	Hash           string `json:"hash" gorm:"not null"`
+	GlobalSequence *int64 `json:"global_sequence,omitempty" gorm:"uniqueIndex"`
+	GlobalHash     string `json:"global_hash,omitempty"`
+}
+
+type transactionRepo struct {
+	db *gorm.DB
+}
+
+func (t *Transaction) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.UUID == "" {
+		t.UUID, err = utils.GenerateNanoID(20, EntityTransaction)
+		if err != nil {
+			return err
+		}
+	}
+	return t.link(tx)
+}
+
+// Create implements ITransaction.
+func (r *transactionRepo) Create(t *Transaction) error {
+	return r.CreateWithTx(r.db, t)
+}
+
+// CreateWithTx implements ITransaction.
+func (r *transactionRepo) CreateWithTx(tx *gorm.DB, t *Transaction) error {
+	err := tx.Model(&Transaction{}).Create(t).Error
+	if err != nil {
+		logger.Error("unable to create transaction | err: ", err)
+		return err
+	}
+	return nil
+}
+
+// Get implements ITransaction.
+func (r *transactionRepo) Get(where *Transaction) (*Transaction, error) {
+	return r.GetWithTx(r.db, where)
+}
+
+// GetWithTx implements ITransaction.
+func (r *transactionRepo) GetWithTx(tx *gorm.DB, where *Transaction) (*Transaction, error) {
+	var t Transaction
+	err := tx.Model(&Transaction{}).Where(where).First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindByReference implements ITransaction. It may be served by a replica.
+func (r *transactionRepo) FindByReference(referenceID string) ([]Transaction, error) {
+	var (
+		transactions = []Transaction{}
+	)
+	err := database.Replica(r.db).Model(&Transaction{}).
+		Where(&Transaction{ReferenceID: referenceID}).
+		Order("id asc").
+		Find(&transactions).Error
+	if err != nil {
+		logger.Error("unable to find transactions by reference | err: ", err)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ofWalletSQL selects the transactions of a wallet, including the legs
+// posted to its shards. Pass the wallet id twice.
+const ofWalletSQL = "(wallet_id = ? OR parent_wallet_id = ?)"
+
+// OfWallet reports whether t was posted to the wallet or to one of its
+// shards.
+func (t *Transaction) OfWallet(walletID uint64) bool {
+	return t.WalletID == walletID || (t.ParentWalletID != nil && *t.ParentWalletID == walletID)
+}
+
+// ListForWallet implements ITransaction. Transactions are returned newest
+// first; a non-zero beforeID returns the page after that transaction. It is
+// history, so it may be served by a replica.
+func (r *transactionRepo) ListForWallet(walletID uint64, beforeID uint64, limit int) ([]Transaction, error) {
+	var (
+		transactions = []Transaction{}
+	)
+
+	builder := database.Replica(r.db).Model(&Transaction{}).
+		Where(ofWalletSQL, walletID, walletID)
+
+	if beforeID > 0 {
+		builder = builder.Where("id < ?", beforeID)
+	}
+
+	err := builder.Order("id desc").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		logger.Error("unable to list transactions | err: ", err)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ListForWalletAfterSequence implements ITransaction. It returns the
+// transactions posted to walletID itself, an unsharded wallet or one shard,
+// after afterSequence in wallet_sequence order. A wallet's next sequence is
+// only taken once the previous one has committed, so this is commit order.
+// Event streams call it right after a commit, so it always reads the primary.
+func (r *transactionRepo) ListForWalletAfterSequence(walletID uint64, afterSequence int64, limit int) ([]Transaction, error) {
+	var (
+		transactions = []Transaction{}
+	)
+	err := r.db.Model(&Transaction{}).
+		Where("wallet_id = ? AND wallet_sequence > ?", walletID, afterSequence).
+		Order("wallet_sequence asc").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		logger.Error("unable to list transactions | err: ", err)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// LatestSequenceForWallet implements ITransaction. It returns the
+// wallet_sequence of the last transaction posted to walletID itself, 0 when
+// there is none.
+func (r *transactionRepo) LatestSequenceForWallet(walletID uint64) (int64, error) {
+	var (
+		latestSequence int64
+	)
+	err := r.db.Model(&Transaction{}).
+		Where("wallet_id = ?", walletID).
+		Select("coalesce(max(wallet_sequence), 0)").
+		Scan(&latestSequence).Error
+	if err != nil {
+		logger.Error("unable to get latest wallet sequence | err: ", err)
+		return 0, err
+	}
+	return latestSequence, nil
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Transactions form two hash chains. Per wallet, Hash covers the contents of
+// the transaction and the Hash of the previous transaction of the wallet
+// (PrevHash), linked when the transaction is inserted. Globally, the
+// checkpoint-ledger job seals committed transactions in id order, chaining
+// GlobalHash over their Hash, and signs the head into a LedgerCheckpoint.
+// Editing a transaction breaks its Hash, deleting one leaves a gap in either
+// sequence.
+//
+// The global order is not commit order: a transaction still in flight when
+// the job runs is sealed by a later run, after transactions with higher ids.
+// Until then it is only covered by its wallet chain.
+
+func chainFields(fields ...string) string {
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(strconv.Itoa(len(f)))
+		b.WriteString(":")
+		b.WriteString(f)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// ContentHash is the hash of the contents of t chained to t.PrevHash.
+// Metadata is not covered since postgres normalises jsonb. Migrations
+// 0003_transaction_hash_chain and 0017_opening_balance_entries compute the
+// same hash in SQL, keep them in step.
+func (t *Transaction) ContentHash() string {
+	var createdAt int64
+	if t.CreatedAt != nil {
+		createdAt = t.CreatedAt.UnixMicro()
+	}
+
+	var parentWalletID string
+	if t.ParentWalletID != nil {
+		parentWalletID = strconv.FormatUint(*t.ParentWalletID, 10)
+	}
+
+	return chainFields(
+		t.UUID,
+		strconv.FormatUint(t.WalletID, 10),
+		parentWalletID,
+		strconv.FormatInt(t.WalletSequence, 10),
+		string(t.Type),
+		strconv.Itoa(t.AmountInCents),
+		strconv.Itoa(t.OpeningBalanceInCents),
+		strconv.Itoa(t.ClosingBalanceInCents),
+		string(t.Status),
+		t.FromWalletUUID,
+		t.ToWalletUUID,
+		string(t.PurposeCode),
+		t.ReferenceID,
+		t.Description,
+		strconv.FormatInt(createdAt, 10),
+		t.PrevHash,
+	)
+}
+
+// GlobalChainHash is the global chain hash of the transaction hash sealed at
+// sequence after prevGlobalHash.
+func GlobalChainHash(sequence int64, hash string, prevGlobalHash string) string {
+	return chainFields(strconv.FormatInt(sequence, 10), hash, prevGlobalHash)
+}
+
+// link appends t to the chain of its wallet. Two transactions racing for the
+// same wallet get the same WalletSequence and the unique index rejects one.
+func (t *Transaction) link(tx *gorm.DB) error {
+	if t.CreatedAt == nil {
+		// postgres keeps microseconds, hash what will be stored
+		now := time.Now().Truncate(time.Microsecond)
+		t.CreatedAt = &now
+	}
+
+	var prev Transaction
+	err := tx.Session(&gorm.Session{NewDB: true}).Unscoped().
+		Select("wallet_sequence", "hash").
+		Where(&Transaction{WalletID: t.WalletID}).
+		Order("wallet_sequence desc").
+		Limit(1).
+		Find(&prev).Error
+	if err != nil {
+		return err
+	}
+
+	t.WalletSequence = prev.WalletSequence + 1
+	t.PrevHash = prev.Hash
+	t.Hash = t.ContentHash()
+	return nil
+}
@@ -0,0 +1,69 @@
+package models_test
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"testing"
+	"time"
+)
+
+// TestContentHash checks every hashed field changes the hash, so editing any
+// of them breaks the chain.
+func TestContentHash(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	parentWalletID := uint64(7)
+	base := models.Transaction{
+		UUID:                  "txn_chain",
+		WalletID:              9,
+		ParentWalletID:        &parentWalletID,
+		WalletSequence:        3,
+		Type:                  constants.TransactionTypeCredit,
+		AmountInCents:         500,
+		OpeningBalanceInCents: 100,
+		ClosingBalanceInCents: 600,
+		Status:                constants.EntitySuccess,
+		FromWalletUUID:        "wa_from",
+		ToWalletUUID:          "wa_to",
+		PurposeCode:           "TRANSFER",
+		ReferenceID:           "tr_chain",
+		Description:           "rent",
+		CreatedAt:             &createdAt,
+		PrevHash:              "abc",
+	}
+
+	otherParent := uint64(8)
+	later := createdAt.Add(time.Microsecond)
+	edits := map[string]func(t *models.Transaction){
+		"uuid":             func(t *models.Transaction) { t.UUID = "txn_other" },
+		"wallet_id":        func(t *models.Transaction) { t.WalletID = 10 },
+		"parent_wallet_id": func(t *models.Transaction) { t.ParentWalletID = &otherParent },
+		"no parent":        func(t *models.Transaction) { t.ParentWalletID = nil },
+		"wallet_sequence":  func(t *models.Transaction) { t.WalletSequence = 4 },
+		"type":             func(t *models.Transaction) { t.Type = constants.TransactionTypeDebit },
+		"amount":           func(t *models.Transaction) { t.AmountInCents = 501 },
+		"opening balance":  func(t *models.Transaction) { t.OpeningBalanceInCents = 101 },
+		"closing balance":  func(t *models.Transaction) { t.ClosingBalanceInCents = 601 },
+		"status":           func(t *models.Transaction) { t.Status = constants.EntityFailed },
+		"from wallet":      func(t *models.Transaction) { t.FromWalletUUID = "wa_other" },
+		"to wallet":        func(t *models.Transaction) { t.ToWalletUUID = "wa_other" },
+		"purpose code":     func(t *models.Transaction) { t.PurposeCode = "FEE" },
+		"reference id":     func(t *models.Transaction) { t.ReferenceID = "tr_other" },
+		"description":      func(t *models.Transaction) { t.Description = "rent!" },
+		"created_at":       func(t *models.Transaction) { t.CreatedAt = &later },
+		"prev_hash":        func(t *models.Transaction) { t.PrevHash = "abd" },
+		// <len>:<value> keeps fields from running into each other
+		"field boundary": func(t *models.Transaction) { t.FromWalletUUID, t.ToWalletUUID = "wa_fromwa_", "to" },
+	}
+
+	want := base.ContentHash()
+	if again := base.ContentHash(); again != want {
+		t.Fatalf("hash is not stable: %s, then %s", want, again)
+	}
+	for name, edit := range edits {
+		edited := base
+		edit(&edited)
+		if edited.ContentHash() == want {
+			t.Errorf("editing %s keeps the hash", name)
+		}
+	}
+}
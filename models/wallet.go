@@ -1,8 +1,15 @@
 package models
 
 import (
+	"cmp"
+	"coinpe/database"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
 	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
 	"coinpe/pkg/utils"
+	"errors"
+	"slices"
 	"time"
 
 	"gorm.io/datatypes"
@@ -28,8 +35,28 @@ type Wallet struct {
 
 	AdditionalInfo        datatypes.JSON `json:"additional_info"`
 	OverdraftLimitInCents uint           `json:"overdraft_limit_in_cents"`
+
+	// Version is bumped by every balance update, see UpdateBalance
+	Version int64 `json:"version" gorm:"not null;default:0"`
+
+	// A sharded wallet (ShardCount > 1) spreads its balance over its own
+	// row, shard 0, and ShardCount-1 shard wallets with ParentWalletID
+	// pointing at it, see wallet_shard.go.
+	ParentWalletID *uint64 `json:"parent_wallet_id,omitempty" gorm:"index"`
+	ShardIndex     int     `json:"shard_index" gorm:"not null;default:0"`
+	ShardCount     int     `json:"shard_count" gorm:"not null;default:1"`
 }
 
+var (
+	ErrInsufficientFunds  = errorConst.New(errorConst.ErrorInsufficientFunds, errorConst.MsgInsufficientFunds)
+	ErrSameWalletTransfer = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgCannotTransferToTheSameWallet)
+	ErrCurrencyMismatch   = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgWalletCurrenciesDoNotMatch)
+
+	// ErrWalletVersionConflict is returned by UpdateBalance when the wallet
+	// changed since it was read, the transaction can be retried.
+	ErrWalletVersionConflict = errors.New("wallet balance was updated concurrently")
+)
+
 type walletRepo struct {
 	db *gorm.DB
 }
@@ -43,6 +70,14 @@ var CoinpeWallet = Wallet{
 	OverdraftLimitInCents: 10000,
 }
 
+// FeeRevenueWallet collects the fees charged on transfers, see
+// TransferWithFeeTx.
+var FeeRevenueWallet = Wallet{
+	UserUUID: "cpe_D7iIue5xBivgNdm7xxk4",
+	UUID:     "wa_65mAAtOeUcSHHydwjEoL",
+	Currency: EntityINR,
+}
+
 func (w *Wallet) BeforeCreate(tx *gorm.DB) (err error) {
 	tx.Statement.AddClause(clause.OnConflict{
 		DoNothing: true,
@@ -60,12 +95,21 @@ func (r *walletRepo) Get(where *Wallet) (*Wallet, error) {
 	return r.GetWithTx(r.db, where)
 }
 
+// GetWithTx implements IWallet. The balance of a sharded wallet is the sum
+// of its shards.
 func (r *walletRepo) GetWithTx(tx *gorm.DB, where *Wallet) (*Wallet, error) {
 	var o Wallet
 	err := tx.Model(&Wallet{}).Where(where).First(&o).Error
 	if err != nil {
 		return nil, err
 	}
+
+	if o.IsSharded() {
+		err = r.addShardBalancesWithTx(tx, &o)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &o, nil
 }
 
@@ -89,107 +133,269 @@ func (r *walletRepo) Delete(walletID string) error {
 	return nil
 }
 
-// func (r *walletRepo) Credit(wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
-// 	tx := r.db.Begin()
-// 	w, err := r.CreditWithTx(tx, wallet, transaction, purposeCode)
-// 	if err != nil {
-// 		logger.Error(err)
-// 		return wallet, err
-// 	}
-
-// 	err = tx.Commit().Error
-// 	if err != nil {
-// 		logger.Error(err)
-// 		return w, err
-// 	}
-
-// 	return w, nil
-// }
-
-// func (r *walletRepo) CreditWithTx(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
-// 	transactionRepo := InitTransactionRepo(r.db)
-// 	updatedWalletBalance := wallet.TotalBalanceInCents + transaction.AmountInCents
-// 	transaction.Type = constants.TransactionTypeCredit
-// 	transaction.WalletID = wallet.ID
-// 	transaction.OpeningBalanceInCents = wallet.TotalBalanceInCents
-// 	transaction.ClosingBalanceInCents = updatedWalletBalance
-// 	transaction.Status = constants.EntitySuccess
-// 	transaction.ToWalletUUID = wallet.UUID
-// 	transaction.PurposeCode = purposeCode
-
-// 	err := transactionRepo.CreateWithTx(tx, transaction)
-// 	if err != nil {
-// 		tx.Rollback()
-// 		logger.Error(err)
-// 		return wallet, err
-// 	}
-
-// 	err = tx.Model(&wallet).Select("total_balance_in_cents").Updates(map[string]interface{}{"total_balance_in_cents": updatedWalletBalance}).Error
-// 	if err != nil {
-// 		tx.Rollback()
-// 		logger.Error(err)
-// 		return wallet, err
-// 	}
-
-// 	wallet.TotalBalanceInCents = updatedWalletBalance
-// 	return wallet, nil
-// }
-
-// func (r *walletRepo) Debit(wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
-// 	tx := r.db.Begin()
-// 	w, err := r.DebitWithTx(tx, wallet, transaction, purposeCode)
-// 	if err != nil {
-// 		logger.Error(err)
-// 		return wallet, err
-// 	}
-
-// 	err = tx.Commit().Error
-// 	if err != nil {
-// 		logger.Error(err)
-// 		return w, err
-// 	}
-
-// 	return w, nil
-// }
-
-// func (r *walletRepo) DebitWithTx(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
-// 	if !r.CanDebit(wallet, transaction.AmountInCents) {
-// 		return nil, errors.New("insufficient funds")
-// 	}
-
-// 	transactionRepo := InitTransactionRepo(r.db)
-// 	updatedWalletBalance := wallet.TotalBalanceInCents - transaction.AmountInCents
-// 	transaction.Type = constants.TransactionTypeDebit
-// 	transaction.WalletID = wallet.ID
-// 	transaction.OpeningBalanceInCents = wallet.TotalBalanceInCents
-// 	transaction.ClosingBalanceInCents = updatedWalletBalance
-// 	transaction.Status = constants.EntitySuccess
-// 	transaction.FromWalletUUID = wallet.UUID
-// 	transaction.PurposeCode = purposeCode
-
-// 	err := transactionRepo.CreateWithTx(tx, transaction)
-// 	if err != nil {
-// 		tx.Rollback()
-// 		logger.Error(err)
-// 		return wallet, err
-// 	}
-
-// 	err = tx.Model(&wallet).Updates(map[string]interface{}{"total_balance_in_cents": updatedWalletBalance}).Error
-// 	if err != nil {
-// 		tx.Rollback()
-// 		logger.Error(err)
-// 		return wallet, err
-// 	}
-
-// 	wallet.TotalBalanceInCents = updatedWalletBalance
-// 	return wallet, nil
-// }
-
-func (r *walletRepo) UpdateBalance(tx *gorm.DB, walletID string, amountInCents int) error {
-	err := tx.Model(&Wallet{}).Where(&Wallet{UUID: walletID}).Updates(map[string]interface{}{"total_balance_in_cents": amountInCents}).Error
+// IsRetryableBalanceError reports whether a balance mutation failed only
+// because it raced another one, so its transaction can run again.
+func IsRetryableBalanceError(err error) bool {
+	return errors.Is(err, ErrWalletVersionConflict) ||
+		database.IsRetryable(err) ||
+		database.IsUniqueViolation(err, "idx_transactions_wallet_sequence")
+}
+
+// TransactWithRetry runs fn in a transaction of db, again when it fails with
+// IsRetryableBalanceError. Wallets and transactions must be read and created
+// inside fn so every attempt starts afresh. When every attempt raced, the
+// caller gets a retryable conflict.
+func TransactWithRetry(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	err := database.TransactionWithRetry(db, IsRetryableBalanceError, fn)
+	if err != nil && IsRetryableBalanceError(err) {
+		logger.Error("balance update kept conflicting | err: ", err)
+		return errorConst.Wrap(err, errorConst.ErrorConflict, errorConst.MsgWalletIsBusyRetryTheRequest).WithRetryable(true)
+	}
+	return err
+}
+
+// Credit posts transaction in a transaction of its own, see
+// TransactWithRetry, and returns the wallet reloaded after the update.
+func (r *walletRepo) Credit(wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
+	return r.postWithRetry(wallet, transaction, purposeCode, r.CreditWithTx)
+}
+
+// postWithRetry runs post until it does not race and returns the wallet as
+// it is once transaction is posted, with the sum of its shards. wallet is
+// left as passed in.
+func (r *walletRepo) postWithRetry(wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode, post func(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error)) (*Wallet, error) {
+	var updated *Wallet
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		// a failed attempt leaves ids, hashes and balances behind, keep them
+		// off transaction and wallet
+		row := *wallet
+		attempt := *transaction
+		_, err := post(tx, &row, &attempt, purposeCode)
+		if err != nil {
+			return err
+		}
+
+		updated, err = r.GetWithTx(tx, &Wallet{ID: wallet.ID})
+		if err != nil {
+			return err
+		}
+		*transaction = attempt
+		return nil
+	})
 	if err != nil {
-		return err
+		logger.Error(err)
+		return wallet, err
 	}
+	return updated, nil
+}
+
+// CreditWithTx locks the wallet, so its balance is the committed one, then
+// posts transaction and moves the balance. A sharded wallet is credited on
+// one of its shards, which is returned.
+func (r *walletRepo) CreditWithTx(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
+	row, err := r.pickShardWithTx(tx, wallet, transaction.AmountInCents, false)
+	if err != nil {
+		return wallet, err
+	}
+
+	err = r.LockWithTx(tx, row)
+	if err != nil {
+		return wallet, err
+	}
+
+	return r.creditRowWithTx(tx, row, transaction, purposeCode)
+}
+
+// creditRowWithTx posts transaction to the wallet row, which the caller
+// has locked.
+func (r *walletRepo) creditRowWithTx(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
+	transactionRepo := InitTransactionRepo(tx)
+	updatedWalletBalance := wallet.TotalBalanceInCents + transaction.AmountInCents
+	transaction.Type = constants.TransactionTypeCredit
+	transaction.WalletID = wallet.ID
+	transaction.ParentWalletID = wallet.ParentWalletID
+	transaction.OpeningBalanceInCents = wallet.TotalBalanceInCents
+	transaction.ClosingBalanceInCents = updatedWalletBalance
+	transaction.Status = constants.EntitySuccess
+	transaction.ToWalletUUID = wallet.UUID
+	transaction.PurposeCode = purposeCode
+
+	err := transactionRepo.CreateWithTx(tx, transaction)
+	if err != nil {
+		logger.Error(err)
+		return wallet, err
+	}
+
+	err = r.UpdateBalance(tx, wallet, updatedWalletBalance)
+	if err != nil {
+		logger.Error(err)
+		return wallet, err
+	}
+
+	return wallet, nil
+}
+
+// Debit is Credit for debits.
+func (r *walletRepo) Debit(wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
+	return r.postWithRetry(wallet, transaction, purposeCode, r.DebitWithTx)
+}
+
+// DebitWithTx locks the wallet, so funds are checked against the committed
+// balance, then posts transaction and moves the balance. A sharded wallet is
+// debited on one of its shards, which is returned.
+func (r *walletRepo) DebitWithTx(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
+	row, err := r.pickShardWithTx(tx, wallet, transaction.AmountInCents, true)
+	if err != nil {
+		return wallet, err
+	}
+
+	row, err = r.lockDebitWithTx(tx, row, transaction.AmountInCents)
+	if err != nil {
+		return wallet, err
+	}
+
+	return r.debitRowWithTx(tx, row, transaction, purposeCode)
+}
+
+// debitRowWithTx posts transaction to the wallet row, which the caller has
+// locked.
+func (r *walletRepo) debitRowWithTx(tx *gorm.DB, wallet *Wallet, transaction *Transaction, purposeCode purposecodes.TransactionPurposeCode) (*Wallet, error) {
+	if !r.CanDebit(wallet, transaction.AmountInCents) {
+		return wallet, ErrInsufficientFunds
+	}
+
+	transactionRepo := InitTransactionRepo(tx)
+	updatedWalletBalance := wallet.TotalBalanceInCents - transaction.AmountInCents
+	transaction.Type = constants.TransactionTypeDebit
+	transaction.WalletID = wallet.ID
+	transaction.ParentWalletID = wallet.ParentWalletID
+	transaction.OpeningBalanceInCents = wallet.TotalBalanceInCents
+	transaction.ClosingBalanceInCents = updatedWalletBalance
+	transaction.Status = constants.EntitySuccess
+	transaction.FromWalletUUID = wallet.UUID
+	transaction.PurposeCode = purposeCode
+
+	err := transactionRepo.CreateWithTx(tx, transaction)
+	if err != nil {
+		logger.Error(err)
+		return wallet, err
+	}
+
+	err = r.UpdateBalance(tx, wallet, updatedWalletBalance)
+	if err != nil {
+		logger.Error(err)
+		return wallet, err
+	}
+
+	return wallet, nil
+}
+
+// TransferWithTx debits from and credits to as two legs sharing one reference ID.
+// Each leg names the other wallet, not the shard it was posted to.
+func (r *walletRepo) TransferWithTx(tx *gorm.DB, from *Wallet, to *Wallet, amountInCents int, purposeCode purposecodes.TransactionPurposeCode, description string) (*Transaction, *Transaction, error) {
+	if from.UUID == to.UUID {
+		return nil, nil, ErrSameWalletTransfer
+	}
+
+	if from.Currency != to.Currency {
+		return nil, nil, ErrCurrencyMismatch
+	}
+
+	fromRow, err := r.pickShardWithTx(tx, from, amountInCents, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toRow, err := r.pickShardWithTx(tx, to, amountInCents, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// both wallets are locked up front in id order, so transfers crossing
+	// in opposite directions cannot deadlock
+	fromRow, err = r.lockDebitWithTx(tx, fromRow, amountInCents, toRow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r.transferRowsWithTx(tx, fromRow, toRow, from.UUID, to.UUID, amountInCents, purposeCode, description)
+}
+
+// transferRowsWithTx posts the two legs of a transfer between wallet rows
+// the caller has locked, fromUUID and toUUID name the counterparties.
+func (r *walletRepo) transferRowsWithTx(tx *gorm.DB, fromRow *Wallet, toRow *Wallet, fromUUID string, toUUID string, amountInCents int, purposeCode purposecodes.TransactionPurposeCode, description string) (*Transaction, *Transaction, error) {
+	referenceID, err := utils.GenerateNanoID(20, EntityTransfer)
+	if err != nil {
+		logger.Error("unable to generate nano id | err: ", err)
+		return nil, nil, err
+	}
+
+	debit := &Transaction{
+		AmountInCents: amountInCents,
+		ToWalletUUID:  toUUID,
+		ReferenceID:   referenceID,
+		Description:   description,
+	}
+	_, err = r.debitRowWithTx(tx, fromRow, debit, purposeCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	credit := &Transaction{
+		AmountInCents:  amountInCents,
+		FromWalletUUID: fromUUID,
+		ReferenceID:    referenceID,
+		Description:    description,
+	}
+	_, err = r.creditRowWithTx(tx, toRow, credit, purposeCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return debit, credit, nil
+}
+
+// LockWithTx locks the rows of wallets FOR UPDATE until tx ends, in id
+// order, and reloads them so balances read afterwards are the committed
+// ones.
+func (r *walletRepo) LockWithTx(tx *gorm.DB, wallets ...*Wallet) error {
+	ordered := slices.Clone(wallets)
+	slices.SortFunc(ordered, func(a, b *Wallet) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	for _, wallet := range ordered {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", wallet.ID).
+			First(wallet).Error
+		if err != nil {
+			logger.Error("unable to lock wallet | err: ", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateBalance implements IWallet. It sets the balance of wallet only if
+// its version is still the one read, failing with ErrWalletVersionConflict
+// otherwise, and bumps the version. Lock the wallet first (LockWithTx) to
+// not race at all.
+func (r *walletRepo) UpdateBalance(tx *gorm.DB, wallet *Wallet, amountInCents int) error {
+	result := tx.Model(&Wallet{}).
+		Where("id = ? AND version = ?", wallet.ID, wallet.Version).
+		Updates(map[string]interface{}{
+			"total_balance_in_cents": amountInCents,
+			"version":                gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWalletVersionConflict
+	}
+
+	wallet.TotalBalanceInCents = amountInCents
+	wallet.Version++
 	return nil
 }
 
@@ -0,0 +1,320 @@
+package models
+
+import (
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// MaxWalletShards caps the shards of one wallet.
+const MaxWalletShards = 64
+
+var (
+	ErrWalletShardCount  = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgWalletShardCount, MaxWalletShards)
+	ErrWalletShardRemove = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgWalletShardsCanOnlyBeAdded)
+	ErrWalletIsShard     = errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgWalletShardCannotBeSharded)
+
+	// ErrShardInvariant is returned when the shards of a wallet are not
+	// what its row says, nothing is moved until it is fixed by hand.
+	ErrShardInvariant = errors.New("wallet shard invariant violated")
+)
+
+// IsSharded reports whether the balance of w is spread over shards.
+func (w *Wallet) IsSharded() bool {
+	return w.ShardCount > 1
+}
+
+// ShardRebalance is the outcome of rebalancing a sharded wallet.
+type ShardRebalance struct {
+	WalletUUID     string
+	Shards         int
+	BalanceInCents int
+	Moves          int
+	MovedInCents   int
+}
+
+// ShardsWithTx implements IWallet. It returns the rows of wallet ordered by
+// shard index, the wallet row itself being shard 0, as they are stored.
+func (r *walletRepo) ShardsWithTx(tx *gorm.DB, wallet *Wallet) ([]Wallet, error) {
+	var shards []Wallet
+	err := tx.Where("id = ? OR parent_wallet_id = ?", wallet.ID, wallet.ID).
+		Order("shard_index asc").
+		Find(&shards).Error
+	if err != nil {
+		logger.Error("unable to get wallet shards | err: ", err)
+		return nil, err
+	}
+	return shards, nil
+}
+
+// ShardedWallets implements IWallet.
+func (r *walletRepo) ShardedWallets() ([]Wallet, error) {
+	var wallets []Wallet
+	err := r.db.Where("shard_count > 1").Order("id asc").Find(&wallets).Error
+	if err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// addShardBalancesWithTx adds the balances of the shards of wallet, other
+// than its own row, to wallet.
+func (r *walletRepo) addShardBalancesWithTx(tx *gorm.DB, wallet *Wallet) error {
+	var total int
+	err := tx.Model(&Wallet{}).
+		Select("COALESCE(SUM(total_balance_in_cents), 0)").
+		Where("parent_wallet_id = ?", wallet.ID).
+		Scan(&total).Error
+	if err != nil {
+		logger.Error("unable to sum wallet shards | err: ", err)
+		return err
+	}
+	wallet.TotalBalanceInCents += total
+	return nil
+}
+
+// pickShardWithTx picks the row of wallet to post amountInCents to, wallet
+// itself when it is not sharded. Credits go to a random shard. Debits go to
+// the first shard, from a random one on, whose balance covered the amount
+// when read, so concurrent postings rarely queue on one row. Balances are
+// read without a lock, lock the row with lockDebitWithTx.
+func (r *walletRepo) pickShardWithTx(tx *gorm.DB, wallet *Wallet, amountInCents int, debit bool) (*Wallet, error) {
+	if !wallet.IsSharded() {
+		return wallet, nil
+	}
+
+	shards, err := r.ShardsWithTx(tx, wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	start := rand.IntN(len(shards))
+	if debit {
+		for i := range shards {
+			shard := &shards[(start+i)%len(shards)]
+			if r.CanDebit(shard, amountInCents) {
+				return shard, nil
+			}
+		}
+	}
+	return &shards[start], nil
+}
+
+// lockDebitWithTx locks row, picked by pickShardWithTx to debit
+// amountInCents from, together with others in id order, and returns the row
+// to debit. When row, as read, does not cover the amount and is a shard,
+// every shard of its wallet is locked in its place and the balances of the
+// others are moved to shard 0, which also holds the overdraft, and shard 0
+// is returned. Which rows to lock is decided from the balances as read, so
+// no shard is locked after others. A shard debited by someone else since it was read fails with
+// ErrWalletVersionConflict, so the transaction runs again and picks anew.
+// The wallet's balance does not change.
+func (r *walletRepo) lockDebitWithTx(tx *gorm.DB, row *Wallet, amountInCents int, others ...*Wallet) (*Wallet, error) {
+	sharded := row.ParentWalletID != nil || row.IsSharded()
+	if !sharded || r.CanDebit(row, amountInCents) {
+		err := r.LockWithTx(tx, append([]*Wallet{row}, others...)...)
+		if err != nil {
+			return nil, err
+		}
+		if sharded && !r.CanDebit(row, amountInCents) {
+			return nil, ErrWalletVersionConflict
+		}
+		return row, nil
+	}
+
+	parentID := row.ID
+	if row.ParentWalletID != nil {
+		parentID = *row.ParentWalletID
+	}
+
+	shards, err := r.lockShardsWithTx(tx, parentID, others...)
+	if err != nil {
+		return nil, err
+	}
+
+	main := &shards[0]
+	for i := 1; i < len(shards) && !r.CanDebit(main, amountInCents); i++ {
+		shard := &shards[i]
+		move := min(shard.TotalBalanceInCents, amountInCents-main.TotalBalanceInCents-int(main.OverdraftLimitInCents))
+		if move <= 0 {
+			continue
+		}
+		_, _, err = r.transferRowsWithTx(tx, shard, main, shard.UUID, main.UUID, move, purposecodes.PurposeCodeRebalance, "shard consolidation")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return main, nil
+}
+
+// lockShardsWithTx locks every row of the sharded wallet parentID, with
+// others, in id order and returns the shards ordered by shard index.
+func (r *walletRepo) lockShardsWithTx(tx *gorm.DB, parentID uint64, others ...*Wallet) ([]Wallet, error) {
+	shards, err := r.ShardsWithTx(tx, &Wallet{ID: parentID})
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 || shards[0].ID != parentID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	rows := slices.Clone(others)
+	for i := range shards {
+		rows = append(rows, &shards[i])
+	}
+	err = r.LockWithTx(tx, rows...)
+	if err != nil {
+		return nil, err
+	}
+	return shards, nil
+}
+
+// Shard implements IWallet. It grows the wallet to shardCount shards, the
+// new ones empty. Existing shards are never removed.
+func (r *walletRepo) Shard(walletID uint64, shardCount int) (*Wallet, error) {
+	if shardCount < 1 || shardCount > MaxWalletShards {
+		return nil, ErrWalletShardCount
+	}
+
+	var wallet Wallet
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		wallet = Wallet{ID: walletID}
+		err := r.LockWithTx(tx, &wallet)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case wallet.ParentWalletID != nil:
+			return ErrWalletIsShard
+		case shardCount < wallet.ShardCount:
+			return ErrWalletShardRemove
+		case shardCount == wallet.ShardCount:
+			return nil
+		}
+
+		for index := max(wallet.ShardCount, 1); index < shardCount; index++ {
+			shard := Wallet{
+				// shards belong to no account, user lookups never find them
+				UserUUID:       fmt.Sprintf("%s:shard:%d", wallet.UserUUID, index),
+				Currency:       wallet.Currency,
+				ParentWalletID: &wallet.ID,
+				ShardIndex:     index,
+			}
+			err = r.CreateWithTx(tx, &shard)
+			if err != nil {
+				return err
+			}
+			if shard.ID == 0 {
+				return fmt.Errorf("%w: shard %d of wallet %s already exists", ErrShardInvariant, index, wallet.UUID)
+			}
+		}
+
+		err = tx.Model(&Wallet{}).Where("id = ?", wallet.ID).Update("shard_count", shardCount).Error
+		if err != nil {
+			return err
+		}
+		wallet.ShardCount = shardCount
+		return nil
+	})
+	if err != nil {
+		logger.Error("unable to shard wallet | err: ", err)
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// checkShards returns ErrShardInvariant when the shards of parent are not
+// exactly shard 0 to ShardCount-1 in the parent's currency, or when a shard
+// other than 0 has an overdraft or a negative balance.
+func checkShards(parent *Wallet, shards []Wallet) error {
+	if len(shards) != parent.ShardCount {
+		return fmt.Errorf("%w: wallet %s has %d shards, expected %d", ErrShardInvariant, parent.UUID, len(shards), parent.ShardCount)
+	}
+	for i, shard := range shards {
+		switch {
+		case shard.ShardIndex != i:
+			return fmt.Errorf("%w: wallet %s is missing shard %d", ErrShardInvariant, parent.UUID, i)
+		case shard.Currency != parent.Currency:
+			return fmt.Errorf("%w: shard %s is in %s, wallet %s in %s", ErrShardInvariant, shard.UUID, shard.Currency, parent.UUID, parent.Currency)
+		case i > 0 && shard.OverdraftLimitInCents != 0:
+			return fmt.Errorf("%w: shard %s has an overdraft", ErrShardInvariant, shard.UUID)
+		case i > 0 && shard.TotalBalanceInCents < 0:
+			return fmt.Errorf("%w: shard %s has a negative balance", ErrShardInvariant, shard.UUID)
+		}
+	}
+	return nil
+}
+
+// RebalanceShards implements IWallet. It checks the shards of the wallet
+// and evens their balances out with REBALANCE transfers, shard 0 keeping
+// the remainder, or everything when the wallet is overdrawn. The wallet's
+// balance is the same afterwards or nothing is committed.
+func (r *walletRepo) RebalanceShards(walletID uint64) (*ShardRebalance, error) {
+	var result *ShardRebalance
+	err := TransactWithRetry(r.db, func(tx *gorm.DB) error {
+		shards, err := r.lockShardsWithTx(tx, walletID)
+		if err != nil {
+			return err
+		}
+
+		parent := shards[0]
+		err = checkShards(&parent, shards)
+		if err != nil {
+			return err
+		}
+
+		result = &ShardRebalance{WalletUUID: parent.UUID, Shards: len(shards)}
+		for _, shard := range shards {
+			result.BalanceInCents += shard.TotalBalanceInCents
+		}
+
+		targets := make([]int, len(shards))
+		if result.BalanceInCents > 0 {
+			share := result.BalanceInCents / len(shards)
+			for i := range targets {
+				targets[i] = share
+			}
+			targets[0] += result.BalanceInCents - share*len(shards)
+		} else {
+			targets[0] = result.BalanceInCents
+		}
+
+		// pair shards above their target with shards below it
+		to := 0
+		for from := range shards {
+			for shards[from].TotalBalanceInCents > targets[from] {
+				for shards[to].TotalBalanceInCents >= targets[to] {
+					to++
+				}
+				move := min(shards[from].TotalBalanceInCents-targets[from], targets[to]-shards[to].TotalBalanceInCents)
+				_, _, err = r.transferRowsWithTx(tx, &shards[from], &shards[to], shards[from].UUID, shards[to].UUID, move, purposecodes.PurposeCodeRebalance, "shard rebalance")
+				if err != nil {
+					return err
+				}
+				result.Moves++
+				result.MovedInCents += move
+			}
+		}
+
+		balance := 0
+		for _, shard := range shards {
+			balance += shard.TotalBalanceInCents
+		}
+		if balance != result.BalanceInCents {
+			return fmt.Errorf("%w: rebalancing wallet %s changed its balance from %d to %d", ErrShardInvariant, parent.UUID, result.BalanceInCents, balance)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("unable to rebalance wallet shards | err: ", err)
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package models_test
+
+import (
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/purposecodes"
+	"testing"
+	"time"
+)
+
+// TestShardedWalletHistory credits a sharded wallet until every shard has
+// legs and checks its history, balance and statement cover all of them.
+func TestShardedWalletHistory(t *testing.T) {
+	const (
+		shards  = 4
+		credits = 40
+	)
+
+	db := databasetest.New(t)
+	walletRepo := models.InitWalletRepo(db)
+
+	wallet := &models.Wallet{UserUUID: "acc_shardhistory", Currency: models.EntityINR}
+	err := walletRepo.Create(wallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet, err = walletRepo.Shard(wallet.ID, shards)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now()
+	for i := 1; i <= credits; i++ {
+		_, err = walletRepo.Credit(wallet, &models.Transaction{AmountInCents: i}, purposecodes.PurposeCodeAddFunds)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	to := time.Now()
+	total := credits * (credits + 1) / 2
+
+	history, err := models.InitTransactionRepo(db).ListForWallet(wallet.ID, 0, credits+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != credits {
+		t.Fatalf("history has %d legs, want %d", len(history), credits)
+	}
+
+	rows := map[uint64]bool{}
+	for _, leg := range history {
+		rows[leg.WalletID] = true
+		if !leg.OfWallet(wallet.ID) {
+			t.Errorf("leg %s on row %d does not belong to wallet %d", leg.UUID, leg.WalletID, wallet.ID)
+		}
+		if (leg.WalletID == wallet.ID) != (leg.ParentWalletID == nil) {
+			t.Errorf("leg %s on row %d has parent_wallet_id %v", leg.UUID, leg.WalletID, leg.ParentWalletID)
+		}
+	}
+	if len(rows) < 2 {
+		t.Errorf("every credit went to one shard, %v", rows)
+	}
+
+	ledgerRepo := models.InitLedgerRepo(db)
+	balance, err := ledgerRepo.BalanceAt(wallet.ID, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != total {
+		t.Errorf("BalanceAt is %d, want %d", balance, total)
+	}
+
+	statement, err := ledgerRepo.Statement(wallet, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statement.Transactions) != credits || statement.TotalCreditsInCents != total || statement.ClosingBalanceInCents != total {
+		t.Errorf("statement has %d legs, credits %d and closing balance %d, want %d, %d and %d",
+			len(statement.Transactions), statement.TotalCreditsInCents, statement.ClosingBalanceInCents, credits, total, total)
+	}
+}
@@ -0,0 +1,170 @@
+package models_test
+
+import (
+	"coinpe/database"
+	"coinpe/database/databasetest"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/purposecodes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestConcurrentBalanceUpdates credits, debits and transfers concurrently on
+// one hot wallet and between wallet pairs in both directions. Every call must
+// succeed: row locks in id order leave nothing to conflict on, and whatever
+// does race must be retried rather than reach the caller.
+func TestConcurrentBalanceUpdates(t *testing.T) {
+	const (
+		workers   = 16
+		perWorker = 25
+		opening   = 1000000
+	)
+
+	db := databasetest.New(t)
+	walletRepo := models.InitWalletRepo(db)
+
+	wallets := make([]*models.Wallet, 4)
+	for i := range wallets {
+		w := &models.Wallet{UserUUID: fmt.Sprintf("acc_concurrent%d", i), Currency: models.EntityINR}
+		err := walletRepo.Create(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		funded, err := walletRepo.Credit(w, &models.Transaction{AmountInCents: opening}, purposecodes.PurposeCodeAddFunds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if funded.TotalBalanceInCents != opening || funded.Version != w.Version+1 {
+			t.Fatalf("Credit returned balance %d version %d, want the reloaded %d version %d",
+				funded.TotalBalanceInCents, funded.Version, opening, w.Version+1)
+		}
+		if w.TotalBalanceInCents != 0 {
+			t.Fatalf("Credit changed the wallet passed in to %d", w.TotalBalanceInCents)
+		}
+		wallets[i] = funded
+	}
+	hot := wallets[0]
+
+	// pairs cross: every wallet both sends to and receives from the others
+	pairs := [][2]int{{0, 1}, {1, 0}, {1, 2}, {2, 1}, {2, 3}, {3, 2}, {3, 0}, {0, 3}}
+
+	var (
+		expected = make([]int64, len(wallets))
+		failures atomic.Int64
+		wg       sync.WaitGroup
+	)
+	for i := range expected {
+		expected[i] = opening
+	}
+
+	var mu sync.Mutex
+	apply := func(index int, delta int) {
+		mu.Lock()
+		expected[index] += int64(delta)
+		mu.Unlock()
+	}
+
+	check := func(op string, err error) bool {
+		if err == nil {
+			return true
+		}
+		failures.Add(1)
+		switch {
+		case errors.Is(err, models.ErrWalletVersionConflict):
+			t.Errorf("%s: version conflict escaped the retry: %s", op, err)
+		case database.IsRetryable(err):
+			t.Errorf("%s: deadlock or serialization failure was not retried: %s", op, err)
+		default:
+			t.Errorf("%s: %s", op, err)
+		}
+		return false
+	}
+
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				amount := 1 + (worker*perWorker+i)%97
+
+				switch (worker + i) % 3 {
+				case 0:
+					_, err := walletRepo.Credit(hot, &models.Transaction{AmountInCents: amount}, purposecodes.PurposeCodeAddFunds)
+					if check("credit", err) {
+						apply(0, amount)
+					}
+				case 1:
+					_, err := walletRepo.Debit(hot, &models.Transaction{AmountInCents: amount}, purposecodes.PurposeCodeAdjustment)
+					if check("debit", err) {
+						apply(0, -amount)
+					}
+				default:
+					pair := pairs[(worker+i)%len(pairs)]
+					from, to := wallets[pair[0]], wallets[pair[1]]
+					err := models.TransactWithRetry(db, func(tx *gorm.DB) error {
+						// TransferWithTx reloads the rows it locks, give every attempt its own
+						fromRow, toRow := *from, *to
+						_, _, err := models.InitWalletRepo(tx).TransferWithTx(tx, &fromRow, &toRow, amount, purposecodes.PurposeCodeTransfer, "concurrency test")
+						return err
+					})
+					if check(fmt.Sprintf("transfer %s to %s", from.UUID, to.UUID), err) {
+						apply(pair[0], -amount)
+						apply(pair[1], amount)
+					}
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if failures.Load() > 0 {
+		t.Fatalf("%d of %d calls failed", failures.Load(), workers*perWorker)
+	}
+
+	ledgerRepo := models.InitLedgerRepo(db)
+	for i, w := range wallets {
+		reloaded, err := walletRepo.Get(&models.Wallet{ID: w.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var legs int64
+		err = db.Model(&models.Transaction{}).
+			Select("coalesce(sum(CASE WHEN type = ? THEN amount_in_cents ELSE -amount_in_cents END), 0)", constants.TransactionTypeCredit).
+			Where("wallet_id = ? AND status = ?", w.ID, constants.EntitySuccess).
+			Scan(&legs).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if int64(reloaded.TotalBalanceInCents) != legs {
+			t.Errorf("wallet %s balance %d, its legs sum to %d", w.UUID, reloaded.TotalBalanceInCents, legs)
+		}
+		if legs != expected[i] {
+			t.Errorf("wallet %s legs sum to %d, the successful calls to %d", w.UUID, legs, expected[i])
+		}
+
+		v, err := ledgerRepo.VerifyWallet(w.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v.OK() {
+			t.Errorf("wallet %s: %v", w.UUID, v.Issues)
+		}
+
+		chain, err := ledgerRepo.VerifyWalletChain(w.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !chain.OK() {
+			t.Errorf("wallet %s chain: %v", w.UUID, chain.Issues)
+		}
+	}
+}
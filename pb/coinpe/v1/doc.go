@@ -0,0 +1,5 @@
+// Package coinpev1 holds the code generated from proto/coinpe/v1. Do not edit
+// the *.pb.go files by hand; change the .proto sources and regenerate.
+package coinpev1
+
+//go:generate protoc -I ../../../proto --go_out=../../.. --go_opt=module=coinpe --go-grpc_out=../../.. --go-grpc_opt=module=coinpe coinpe/v1/account.proto coinpe/v1/wallet.proto coinpe/v1/balance.proto coinpe/v1/transfer.proto
@@ -0,0 +1,10 @@
+package api
+
+type CreateAccountRequest struct {
+	FirstName   string `json:"first_name,omitempty"`
+	LastName    string `json:"last_name,omitempty"`
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+	Email       string `json:"email" validate:"required,email"`
+	Role        string `json:"role" validate:"required,oneof=SUPER_ADMIN ADMIN CUSTOMER"`
+	IsPartial   bool   `json:"is_partial,omitempty"`
+}
@@ -0,0 +1,38 @@
+// Package api holds the request and response bodies of the /v1 HTTP API. It
+// is shared by the controllers and the Go client so both sides of the wire
+// always agree on the contract.
+package api
+
+type GoTo string
+
+const (
+	GoToVerifyAccount  GoTo = "VERIFY_ACCOUNT"
+	GotToCreateAccount GoTo = "CREATE_ACCOUNT"
+	GoToContinue       GoTo = "CONTINUE"
+)
+
+type AuthenticateRequest struct {
+	Username string `json:"username" validate:"required,e164|email"`
+	Role     string `json:"role" validate:"required,oneof=SUPER_ADMIN ADMIN CUSTOMER"`
+}
+
+type AuthenticateResponse struct {
+	AccessToken                 string `json:"access_token,omitempty"`
+	RefreshToken                string `json:"refresh_token,omitempty"`
+	AccessTokenExpiryInSeconds  int32  `json:"access_token_expiry_in_seconds,omitempty"`
+	RefreshTokenExpiryInSeconds int32  `json:"refresh_token_expiry_in_seconds,omitempty"`
+	IsNewUser                   bool   `json:"is_new_user,omitempty"`
+	GoTo                        GoTo   `json:"goto,omitempty"`
+	VerificationChannel         string `json:"verification_channel,omitempty"`
+	Handle                      string `json:"handle,omitempty"`
+}
+
+type VerifyAuthRequest struct {
+	Otp                        string `json:"otp,omitempty" validate:"required,numeric,len=6"`
+	AccessToken                string `json:"access_token,omitempty" validate:"required"`
+	AccessTokenExpiryInSeconds int32  `json:"access_token_expiry_in_seconds,omitempty" validate:"gte=0"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
@@ -0,0 +1,76 @@
+package api
+
+import "time"
+
+// FeeTier is a band of a TIERED fee schedule for amounts up to
+// UpToInCents, 0 for no upper bound.
+type FeeTier struct {
+	UpToInCents int `json:"up_to_in_cents" validate:"min=0"`
+	FlatInCents int `json:"flat_in_cents" validate:"min=0"`
+	BasisPoints int `json:"basis_points" validate:"min=0,max=10000"`
+}
+
+// CreateFeeScheduleRequest sets a fee on the transfers with PurposeCode, to
+// or from the wallet of MerchantUUID, whose charged account has Role, or on
+// every transfer when none of them is set. FLAT charges FlatInCents,
+// PERCENTAGE BasisPoints of the amount plus FlatInCents and TIERED the
+// same for the tier the amount falls in, Tiers sorted by UpToInCents with
+// the unbounded tier last. Payer defaults to SENDER.
+type CreateFeeScheduleRequest struct {
+	Name         string    `json:"name" validate:"required,max=100"`
+	PurposeCode  string    `json:"purpose_code,omitempty"`
+	Role         string    `json:"role,omitempty" validate:"omitempty,oneof=SUPER_ADMIN ADMIN CUSTOMER MERCHANT"`
+	MerchantUUID string    `json:"merchant_uuid,omitempty"`
+	Payer        string    `json:"payer,omitempty" validate:"omitempty,oneof=SENDER RECIPIENT"`
+	Type         string    `json:"type" validate:"required,oneof=FLAT PERCENTAGE TIERED"`
+	FlatInCents  int       `json:"flat_in_cents,omitempty" validate:"min=0"`
+	BasisPoints  int       `json:"basis_points,omitempty" validate:"min=0,max=10000"`
+	Tiers        []FeeTier `json:"tiers,omitempty" validate:"required_if=Type TIERED,max=20,dive"`
+	MinInCents   int       `json:"min_in_cents,omitempty" validate:"min=0"`
+	MaxInCents   int       `json:"max_in_cents,omitempty" validate:"min=0"`
+}
+
+type FeeSchedule struct {
+	UUID         string     `json:"uuid"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	Name         string     `json:"name"`
+	PurposeCode  string     `json:"purpose_code,omitempty"`
+	Role         string     `json:"role,omitempty"`
+	MerchantUUID string     `json:"merchant_uuid,omitempty"`
+	Payer        string     `json:"payer"`
+	Type         string     `json:"type"`
+	FlatInCents  int        `json:"flat_in_cents"`
+	BasisPoints  int        `json:"basis_points"`
+	Tiers        []FeeTier  `json:"tiers,omitempty"`
+	MinInCents   int        `json:"min_in_cents"`
+	MaxInCents   int        `json:"max_in_cents"`
+}
+
+type ListFeeSchedulesResponse struct {
+	FeeSchedules []FeeSchedule `json:"fee_schedules"`
+}
+
+// FeeQuoteRequest asks what a transfer from the authenticated account's
+// wallet to ToWalletUUID, or the one ToHandle points at, would be charged.
+// PurposeCode defaults to TRANSFER.
+type FeeQuoteRequest struct {
+	ToWalletUUID  string `json:"to_wallet_uuid,omitempty" validate:"required_without=ToHandle"`
+	ToHandle      string `json:"to_handle,omitempty"`
+	AmountInCents int    `json:"amount_in_cents" validate:"required,gt=0"`
+	PurposeCode   string `json:"purpose_code,omitempty"`
+}
+
+// FeeQuote is what a transfer would be charged under the fee schedules in
+// force now. DebitedInCents leaves the sender's wallet and CreditedInCents
+// reaches the recipient's, net of the fee. FeeScheduleUUID is empty when
+// the transfer is free.
+type FeeQuote struct {
+	ToWalletUUID    string `json:"to_wallet_uuid"`
+	AmountInCents   int    `json:"amount_in_cents"`
+	PurposeCode     string `json:"purpose_code"`
+	FeeInCents      int    `json:"fee_in_cents"`
+	FeePayer        string `json:"fee_payer"`
+	FeeScheduleUUID string `json:"fee_schedule_uuid,omitempty"`
+	DebitedInCents  int    `json:"debited_in_cents"`
+	CreditedInCents int    `json:"credited_in_cents"`
+}
@@ -0,0 +1,57 @@
+package api
+
+import "time"
+
+// SetHandleRequest gives the authenticated account's wallet a handle,
+// replacing the one it had.
+type SetHandleRequest struct {
+	Handle string `json:"handle" validate:"required"`
+}
+
+type HandleResponse struct {
+	Handle     string `json:"handle"`
+	WalletUUID string `json:"wallet_uuid"`
+}
+
+// ResolvedHandle is whom a payment to Handle reaches.
+type ResolvedHandle struct {
+	Handle      string `json:"handle"`
+	WalletUUID  string `json:"wallet_uuid"`
+	DisplayName string `json:"display_name"`
+	Currency    string `json:"currency"`
+}
+
+// CreatePaymentQRRequest is the query of a payment QR code to the
+// authenticated account's handle. Without an amount the payer chooses it,
+// ExpiresAt defaults to the configured expiry.
+type CreatePaymentQRRequest struct {
+	AmountInCents int        `form:"amount_in_cents" json:"amount_in_cents,omitempty" validate:"min=0"`
+	ExpiresAt     *time.Time `form:"expires_at" json:"expires_at,omitempty"`
+	Size          int        `form:"size" json:"size,omitempty" validate:"omitempty,min=128,max=1024"`
+}
+
+// PaymentQR is a signed payment QR payload, the string the QR code
+// encodes.
+type PaymentQR struct {
+	Payload       string    `json:"payload"`
+	Handle        string    `json:"handle"`
+	WalletUUID    string    `json:"wallet_uuid"`
+	Currency      string    `json:"currency"`
+	AmountInCents int       `json:"amount_in_cents,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type ParsePaymentQRRequest struct {
+	Payload string `json:"payload" validate:"required,max=1024"`
+}
+
+// ParsedPaymentQR is what a valid payment QR payload asks for, with the
+// name of whom it pays.
+type ParsedPaymentQR struct {
+	Handle        string    `json:"handle"`
+	WalletUUID    string    `json:"wallet_uuid"`
+	DisplayName   string    `json:"display_name"`
+	Currency      string    `json:"currency"`
+	AmountInCents int       `json:"amount_in_cents,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
@@ -0,0 +1,176 @@
+package api
+
+import "time"
+
+// CreateMerchantRequest makes the authenticated MERCHANT account's profile.
+// Checkout events are POSTed to WebhookURL when it is set.
+type CreateMerchantRequest struct {
+	Name       string `json:"name" validate:"required,max=100"`
+	WebhookURL string `json:"webhook_url,omitempty" validate:"omitempty,webhook_url,max=2048"`
+}
+
+// UpdateMerchantRequest replaces the name and webhook URL of the
+// authenticated account's merchant profile, an empty WebhookURL turns
+// webhooks off.
+type UpdateMerchantRequest struct {
+	Name       string `json:"name" validate:"required,max=100"`
+	WebhookURL string `json:"webhook_url,omitempty" validate:"omitempty,webhook_url,max=2048"`
+}
+
+// Merchant is shown to its own account only, WebhookSecret signs its
+// webhooks. Checkout payments wait in PendingWalletUUID until they settle
+// into WalletUUID.
+type Merchant struct {
+	UUID              string     `json:"uuid"`
+	CreatedAt         *time.Time `json:"created_at,omitempty"`
+	Name              string     `json:"name"`
+	WalletUUID        string     `json:"wallet_uuid"`
+	PendingWalletUUID string     `json:"pending_wallet_uuid,omitempty"`
+	WebhookURL        string     `json:"webhook_url,omitempty"`
+	WebhookSecret     string     `json:"webhook_secret"`
+}
+
+// CreateCheckoutSessionRequest asks a customer to pay the merchant for the
+// order OrderID, one session per order. ExpiresAt defaults to the
+// configured expiry.
+type CreateCheckoutSessionRequest struct {
+	OrderID       string     `json:"order_id" validate:"required,max=64"`
+	AmountInCents int        `json:"amount_in_cents" validate:"required,gt=0"`
+	Description   string     `json:"description,omitempty" validate:"max=255"`
+	SuccessURL    string     `json:"success_url,omitempty" validate:"omitempty,webhook_url,max=2048"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// CheckoutSession is OPEN until it is COMPLETED, with the ReferenceID of the
+// payment, CANCELLED or EXPIRED. A completed session fully refunded is
+// REFUNDED. Its merchant also sees whether the payment is PENDING or
+// SETTLED, and by which settlement batch.
+type CheckoutSession struct {
+	UUID               string     `json:"uuid"`
+	CreatedAt          time.Time  `json:"created_at"`
+	MerchantUUID       string     `json:"merchant_uuid"`
+	MerchantName       string     `json:"merchant_name,omitempty"`
+	MerchantWalletUUID string     `json:"merchant_wallet_uuid"`
+	OrderID            string     `json:"order_id"`
+	AmountInCents      int        `json:"amount_in_cents"`
+	Currency           string     `json:"currency"`
+	Description        string     `json:"description,omitempty"`
+	SuccessURL         string     `json:"success_url,omitempty"`
+	Status             string     `json:"status"`
+	ExpiresAt          time.Time  `json:"expires_at"`
+	CustomerWalletUUID string     `json:"customer_wallet_uuid,omitempty"`
+	ReferenceID        string     `json:"reference_id,omitempty"`
+	PaidAt             *time.Time `json:"paid_at,omitempty"`
+	MerchantFeeInCents int        `json:"merchant_fee_in_cents,omitempty"`
+	RefundedInCents    int        `json:"refunded_in_cents"`
+	Refunds            []Refund   `json:"refunds,omitempty"`
+
+	SettlementStatus    string `json:"settlement_status,omitempty"`
+	SettlementBatchUUID string `json:"settlement_batch_uuid,omitempty"`
+}
+
+// ListCheckoutSessionsRequest pages through the authenticated merchant's
+// checkout sessions, newest first. Before is the UUID of the last session
+// of the previous page.
+type ListCheckoutSessionsRequest struct {
+	Status string `form:"status" json:"status,omitempty" validate:"omitempty,oneof=OPEN COMPLETED REFUNDED CANCELLED EXPIRED"`
+	Before string `form:"before" json:"before,omitempty"`
+	Limit  int    `form:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type ListCheckoutSessionsResponse struct {
+	CheckoutSessions []CheckoutSession `json:"checkout_sessions"`
+}
+
+// PayCheckoutSessionResponse is the completed session with the legs of its
+// payment.
+type PayCheckoutSessionResponse struct {
+	CheckoutSession CheckoutSession `json:"checkout_session"`
+	Transactions    []Transaction   `json:"transactions"`
+}
+
+// CreateRefundRequest refunds AmountInCents of a paid checkout session, or
+// what is left of it when AmountInCents is 0.
+type CreateRefundRequest struct {
+	AmountInCents int    `json:"amount_in_cents,omitempty" validate:"min=0"`
+	Reason        string `json:"reason,omitempty" validate:"max=255"`
+}
+
+type Refund struct {
+	UUID                string    `json:"uuid"`
+	CreatedAt           time.Time `json:"created_at"`
+	CheckoutSessionUUID string    `json:"checkout_session_uuid"`
+	AmountInCents       int       `json:"amount_in_cents"`
+	Reason              string    `json:"reason,omitempty"`
+	ReferenceID         string    `json:"reference_id"`
+}
+
+// CreateRefundResponse is the refund with its checkout session.
+type CreateRefundResponse struct {
+	Refund          Refund          `json:"refund"`
+	CheckoutSession CheckoutSession `json:"checkout_session"`
+}
+
+// MerchantWebhook is POSTed to a merchant's webhook URL, signed with its
+// webhook secret in the Coinpe-Signature header. Event is
+// checkout_session.completed or refund.succeeded, with the Refund. ID is
+// the same on every delivery attempt of an event.
+type MerchantWebhook struct {
+	ID              string          `json:"id"`
+	Event           string          `json:"event"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CheckoutSession CheckoutSession `json:"checkout_session"`
+	Refund          *Refund         `json:"refund,omitempty"`
+}
+
+// SettlementBatch moved the receivables of a merchant's checkout sessions
+// paid before CutoffAt from its pending wallet to its wallet: NetInCents,
+// GrossInCents less the RefundsInCents and FeesInCents taken from the
+// pending wallet, under ReferenceID.
+type SettlementBatch struct {
+	UUID              string    `json:"uuid"`
+	CreatedAt         time.Time `json:"created_at"`
+	MerchantUUID      string    `json:"merchant_uuid"`
+	PendingWalletUUID string    `json:"pending_wallet_uuid"`
+	WalletUUID        string    `json:"wallet_uuid"`
+	Currency          string    `json:"currency"`
+	CutoffAt          time.Time `json:"cutoff_at"`
+	SessionCount      int       `json:"session_count"`
+	GrossInCents      int       `json:"gross_in_cents"`
+	RefundsInCents    int       `json:"refunds_in_cents"`
+	FeesInCents       int       `json:"fees_in_cents"`
+	NetInCents        int       `json:"net_in_cents"`
+	ReferenceID       string    `json:"reference_id,omitempty"`
+}
+
+// ListSettlementBatchesRequest pages through the authenticated merchant's
+// settlement batches, newest first. Before is the UUID of the last batch of
+// the previous page.
+type ListSettlementBatchesRequest struct {
+	Before string `form:"before" json:"before,omitempty"`
+	Limit  int    `form:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type ListSettlementBatchesResponse struct {
+	SettlementBatches []SettlementBatch `json:"settlement_batches"`
+}
+
+// SettlementLine is what one checkout session contributed to a settlement
+// batch.
+type SettlementLine struct {
+	CheckoutSessionUUID string    `json:"checkout_session_uuid"`
+	OrderID             string    `json:"order_id"`
+	PaidAt              time.Time `json:"paid_at"`
+	ReferenceID         string    `json:"reference_id"`
+	AmountInCents       int       `json:"amount_in_cents"`
+	RefundsInCents      int       `json:"refunds_in_cents"`
+	FeesInCents         int       `json:"fees_in_cents"`
+	NetInCents          int       `json:"net_in_cents"`
+}
+
+// SettlementReport is a settlement batch with its lines, in the order the
+// sessions were paid.
+type SettlementReport struct {
+	SettlementBatch SettlementBatch  `json:"settlement_batch"`
+	Lines           []SettlementLine `json:"lines"`
+}
@@ -0,0 +1,93 @@
+package api
+
+import "time"
+
+// CreatePaymentRequestRequest asks the owner of PayerWalletUUID, or of the
+// wallet PayerHandle points at, to pay the authenticated account's wallet. ExpiresAt defaults to the configured
+// expiry.
+type CreatePaymentRequestRequest struct {
+	PayerWalletUUID string     `json:"payer_wallet_uuid,omitempty" validate:"required_without=PayerHandle"`
+	PayerHandle     string     `json:"payer_handle,omitempty"`
+	AmountInCents   int        `json:"amount_in_cents" validate:"required,gt=0"`
+	Note            string     `json:"note,omitempty" validate:"max=255"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+// PaymentRequest is PENDING until it is PAID, with the ReferenceID of the
+// transfer, DECLINED, CANCELLED or EXPIRED.
+type PaymentRequest struct {
+	UUID                string     `json:"uuid"`
+	CreatedAt           time.Time  `json:"created_at"`
+	SplitBillUUID       string     `json:"split_bill_uuid,omitempty"`
+	RequesterWalletUUID string     `json:"requester_wallet_uuid"`
+	PayerWalletUUID     string     `json:"payer_wallet_uuid"`
+	AmountInCents       int        `json:"amount_in_cents"`
+	Note                string     `json:"note,omitempty"`
+	Status              string     `json:"status"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	RespondedAt         *time.Time `json:"responded_at,omitempty"`
+	DeclineReason       string     `json:"decline_reason,omitempty"`
+	ReferenceID         string     `json:"reference_id,omitempty"`
+}
+
+// ListPaymentRequestsRequest pages through the payment requests the
+// authenticated account has to pay, or has made when Direction is
+// outgoing, newest first. Before is the UUID of the last request of the
+// previous page.
+type ListPaymentRequestsRequest struct {
+	Direction string `form:"direction" json:"direction,omitempty" validate:"omitempty,oneof=incoming outgoing"`
+	Status    string `form:"status" json:"status,omitempty" validate:"omitempty,oneof=PENDING PAID DECLINED CANCELLED EXPIRED"`
+	Before    string `form:"before" json:"before,omitempty"`
+	Limit     int    `form:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type ListPaymentRequestsResponse struct {
+	PaymentRequests []PaymentRequest `json:"payment_requests"`
+}
+
+type DeclinePaymentRequestRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=255"`
+}
+
+// ApprovePaymentRequestResponse is the paid request with the legs of its
+// transfer.
+type ApprovePaymentRequestResponse struct {
+	PaymentRequest PaymentRequest `json:"payment_request"`
+	Transactions   []Transaction  `json:"transactions"`
+}
+
+// SplitPayer owes AmountInCents of a split bill, or an equal share of it
+// when no payer has an amount.
+type SplitPayer struct {
+	WalletUUID    string `json:"wallet_uuid,omitempty" validate:"required_without=Handle"`
+	Handle        string `json:"handle,omitempty"`
+	AmountInCents int    `json:"amount_in_cents,omitempty" validate:"min=0"`
+}
+
+// CreateSplitBillRequest sends every payer a payment request for their
+// share of TotalInCents. Equal shares include the requester's own unless
+// ExcludeRequester is set, left over cents go to the first payers. Payers
+// with amounts owe at most the total between them, the requester covers
+// the rest.
+type CreateSplitBillRequest struct {
+	TotalInCents     int          `json:"total_in_cents" validate:"required,gt=0"`
+	Note             string       `json:"note,omitempty" validate:"max=255"`
+	Payers           []SplitPayer `json:"payers" validate:"required,min=1,max=50,dive"`
+	ExcludeRequester bool         `json:"exclude_requester,omitempty"`
+	ExpiresAt        *time.Time   `json:"expires_at,omitempty"`
+}
+
+// SplitBill tracks who has paid their share.
+type SplitBill struct {
+	UUID                string           `json:"uuid"`
+	CreatedAt           time.Time        `json:"created_at"`
+	RequesterWalletUUID string           `json:"requester_wallet_uuid"`
+	TotalInCents        int              `json:"total_in_cents"`
+	Note                string           `json:"note,omitempty"`
+	ExpiresAt           time.Time        `json:"expires_at"`
+	RequestedInCents    int              `json:"requested_in_cents"`
+	PaidInCents         int              `json:"paid_in_cents"`
+	PaidCount           int              `json:"paid_count"`
+	PendingCount        int              `json:"pending_count"`
+	PaymentRequests     []PaymentRequest `json:"payment_requests"`
+}
@@ -0,0 +1,72 @@
+package api
+
+import "time"
+
+// PayoutItemRequest is one credit of a payout.
+type PayoutItemRequest struct {
+	WalletUUID    string `json:"wallet_uuid" validate:"required"`
+	AmountInCents int    `json:"amount_in_cents" validate:"required,gt=0"`
+	Description   string `json:"description,omitempty" validate:"max=255"`
+}
+
+// CreatePayoutRequest credits up to 100000 wallets from the treasury.
+// PurposeCode is REWARD unless set, Description applies to items without
+// their own.
+type CreatePayoutRequest struct {
+	PurposeCode string              `json:"purpose_code,omitempty"`
+	Description string              `json:"description,omitempty" validate:"max=255"`
+	Items       []PayoutItemRequest `json:"items" validate:"required,min=1,max=100000,dive"`
+}
+
+// ImportPayoutFileRequest is sent as query parameters, the CSV file is the
+// request body with the columns wallet_uuid, amount in rupees and an
+// optional description.
+type ImportPayoutFileRequest struct {
+	PurposeCode string `form:"purpose_code" json:"purpose_code,omitempty"`
+	Description string `form:"description" json:"description,omitempty" validate:"max=255"`
+	Filename    string `form:"filename" json:"filename,omitempty" validate:"max=255"`
+}
+
+// Payout is PROCESSING until every item SUCCEEDED or FAILED, then
+// COMPLETED.
+type Payout struct {
+	UUID             string     `json:"uuid"`
+	CreatedAt        time.Time  `json:"created_at"`
+	CreatedBy        string     `json:"created_by"`
+	Filename         string     `json:"filename,omitempty"`
+	PurposeCode      string     `json:"purpose_code"`
+	Description      string     `json:"description,omitempty"`
+	Status           string     `json:"status"`
+	ItemCount        int        `json:"item_count"`
+	TotalInCents     int        `json:"total_in_cents"`
+	SucceededCount   int        `json:"succeeded_count"`
+	SucceededInCents int        `json:"succeeded_in_cents"`
+	FailedCount      int        `json:"failed_count"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+}
+
+// PayoutItem is PENDING until it SUCCEEDED, with the ReferenceID of its
+// transfer, or FAILED, with the Error.
+type PayoutItem struct {
+	UUID          string     `json:"uuid"`
+	RowNumber     int        `json:"row_number"`
+	WalletUUID    string     `json:"wallet_uuid"`
+	AmountInCents int        `json:"amount_in_cents"`
+	Description   string     `json:"description,omitempty"`
+	Status        string     `json:"status"`
+	ReferenceID   string     `json:"reference_id,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+}
+
+// ListPayoutItemsRequest pages through items in file order, AfterRow is the
+// row number of the last item of the previous page.
+type ListPayoutItemsRequest struct {
+	Status   string `form:"status" json:"status,omitempty" validate:"omitempty,oneof=PENDING SUCCEEDED FAILED"`
+	AfterRow int    `form:"after_row" json:"after_row,omitempty" validate:"min=0"`
+	Limit    int    `form:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=1000"`
+}
+
+type ListPayoutItemsResponse struct {
+	Items []PayoutItem `json:"items"`
+}
@@ -0,0 +1,58 @@
+package api
+
+import "time"
+
+// SubmitTransferRequest queues a transfer from the authenticated account's
+// wallet. CallbackURL is sent a PendingTransactionWebhook once the transfer
+// is posted or has failed.
+type SubmitTransferRequest struct {
+	ToWalletUUID  string `json:"to_wallet_uuid,omitempty" validate:"required_without=ToHandle"`
+	ToHandle      string `json:"to_handle,omitempty"`
+	AmountInCents int    `json:"amount_in_cents" validate:"required,gt=0"`
+	PurposeCode   string `json:"purpose_code,omitempty"`
+	Description   string `json:"description,omitempty"`
+	CallbackURL   string `json:"callback_url,omitempty" validate:"omitempty,webhook_url,max=2048"`
+}
+
+// SubmitTransferBatchRequest queues up to 1000 transfers at once, each with
+// the batch's CallbackURL unless it has its own.
+type SubmitTransferBatchRequest struct {
+	Transfers   []SubmitTransferRequest `json:"transfers" validate:"required,min=1,max=1000,dive"`
+	CallbackURL string                  `json:"callback_url,omitempty" validate:"omitempty,webhook_url,max=2048"`
+}
+
+// PendingTransaction is a queued transfer. Status is QUEUED until it is
+// SUCCEEDED, with the ReferenceID of the transfer, or FAILED, with the Error.
+type PendingTransaction struct {
+	UUID           string     `json:"uuid"`
+	BatchUUID      string     `json:"batch_uuid,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Status         string     `json:"status"`
+	FromWalletUUID string     `json:"from_wallet_uuid"`
+	ToWalletUUID   string     `json:"to_wallet_uuid"`
+	AmountInCents  int        `json:"amount_in_cents"`
+	PurposeCode    string     `json:"purpose_code"`
+	Description    string     `json:"description,omitempty"`
+	Attempts       int        `json:"attempts"`
+	ReferenceID    string     `json:"reference_id,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	ProcessedAt    *time.Time `json:"processed_at,omitempty"`
+	CallbackURL    string     `json:"callback_url,omitempty"`
+	WebhookStatus  string     `json:"webhook_status,omitempty"`
+	// the legs of the transfer once it SUCCEEDED, not sent in webhooks
+	Transactions []Transaction `json:"transactions,omitempty"`
+}
+
+type TransferBatch struct {
+	BatchUUID           string               `json:"batch_uuid"`
+	PendingTransactions []PendingTransaction `json:"pending_transactions"`
+}
+
+// PendingTransactionWebhook is POSTed to the callback URL of a pending
+// transaction, Event is pending_transaction.succeeded or
+// pending_transaction.failed. The Coinpe-Signature header carries
+// sha256=<hex HMAC-SHA256 of the body> when a webhook secret is set.
+type PendingTransactionWebhook struct {
+	Event              string             `json:"event"`
+	PendingTransaction PendingTransaction `json:"pending_transaction"`
+}
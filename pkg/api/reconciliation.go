@@ -0,0 +1,90 @@
+package api
+
+import "time"
+
+// ImportSettlementFileRequest is sent as query parameters, the CSV file is
+// the request body.
+type ImportSettlementFileRequest struct {
+	Source   string `form:"source" json:"source" validate:"required,max=64"`
+	Filename string `form:"filename" json:"filename,omitempty" validate:"max=255"`
+	// Timezone of settled_at values without a zone, UTC by default
+	Timezone string `form:"timezone" json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
+
+type SettlementImport struct {
+	UUID        string    `json:"uuid"`
+	CreatedAt   time.Time `json:"created_at"`
+	Source      string    `json:"source"`
+	Filename    string    `json:"filename,omitempty"`
+	Checksum    string    `json:"checksum"`
+	ImportedBy  string    `json:"imported_by"`
+	RecordCount int       `json:"record_count"`
+	SettledFrom time.Time `json:"settled_from"`
+	SettledTo   time.Time `json:"settled_to"`
+}
+
+// ReconciliationCounts counts settlement records by status.
+type ReconciliationCounts struct {
+	Matched    int `json:"matched"`
+	Mismatched int `json:"mismatched"`
+	Unmatched  int `json:"unmatched"`
+	Resolved   int `json:"resolved"`
+}
+
+type SettlementImportResponse struct {
+	Import SettlementImport     `json:"import"`
+	Counts ReconciliationCounts `json:"counts"`
+}
+
+// ListSettlementImportsRequest pages through imports newest first, Before
+// is the UUID of the last import of the previous page.
+type ListSettlementImportsRequest struct {
+	Before string `form:"before" json:"before,omitempty"`
+	Limit  int    `form:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type ListSettlementImportsResponse struct {
+	Imports []SettlementImport `json:"imports"`
+}
+
+type SettlementRecord struct {
+	UUID               string       `json:"uuid"`
+	RowNumber          int          `json:"row_number"`
+	Reference          string       `json:"reference,omitempty"`
+	ExternalID         string       `json:"external_id,omitempty"`
+	Direction          string       `json:"direction"`
+	AmountInCents      int          `json:"amount_in_cents"`
+	Currency           string       `json:"currency"`
+	SettledAt          time.Time    `json:"settled_at"`
+	Description        string       `json:"description,omitempty"`
+	Status             string       `json:"status"`
+	MatchRule          string       `json:"match_rule,omitempty"`
+	MatchedTransaction *Transaction `json:"matched_transaction,omitempty"`
+	Detail             string       `json:"detail,omitempty"`
+	Resolution         string       `json:"resolution,omitempty"`
+	ResolutionNote     string       `json:"resolution_note,omitempty"`
+	ResolvedBy         string       `json:"resolved_by,omitempty"`
+	ResolvedAt         *time.Time   `json:"resolved_at,omitempty"`
+}
+
+// ReconciliationReportRequest filters the records of a report by status.
+type ReconciliationReportRequest struct {
+	Status string `form:"status" json:"status,omitempty" validate:"omitempty,oneof=UNMATCHED MATCHED MISMATCHED RESOLVED"`
+}
+
+// ReconciliationReport lists the records of an import and the externally
+// settled transactions around its settled period that no record matches.
+type ReconciliationReport struct {
+	Import                SettlementImport     `json:"import"`
+	Counts                ReconciliationCounts `json:"counts"`
+	Records               []SettlementRecord   `json:"records"`
+	UnmatchedTransactions []Transaction        `json:"unmatched_transactions"`
+}
+
+// ResolveSettlementRecordRequest closes or reopens a settlement record.
+// TransactionUUID is required to MATCH.
+type ResolveSettlementRecordRequest struct {
+	Resolution      string `json:"resolution" validate:"required,oneof=MATCH ACCEPT IGNORE REOPEN"`
+	TransactionUUID string `json:"transaction_uuid,omitempty" validate:"required_if=Resolution MATCH"`
+	Note            string `json:"note" validate:"required,max=1000"`
+}
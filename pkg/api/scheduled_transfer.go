@@ -0,0 +1,71 @@
+package api
+
+import "time"
+
+// CreateScheduledTransferRequest schedules transfers from the authenticated
+// account's wallet. Recurrence is a five field cron expression, a
+// descriptor such as @monthly, or an RRULE such as
+// FREQ=MONTHLY;BYMONTHDAY=1, in Timezone; without it the transfer happens
+// once at StartAt. An occurrence short of funds is retried MaxRetries
+// times, RetryIntervalSeconds apart.
+type CreateScheduledTransferRequest struct {
+	ToWalletUUID         string     `json:"to_wallet_uuid,omitempty" validate:"required_without=ToHandle"`
+	ToHandle             string     `json:"to_handle,omitempty"`
+	AmountInCents        int        `json:"amount_in_cents" validate:"required,gt=0"`
+	Description          string     `json:"description,omitempty" validate:"max=255"`
+	StartAt              time.Time  `json:"start_at" validate:"required"`
+	EndAt                *time.Time `json:"end_at,omitempty" validate:"omitempty,gtfield=StartAt"`
+	Recurrence           string     `json:"recurrence,omitempty" validate:"max=255"`
+	Timezone             string     `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	MaxRetries           *int       `json:"max_retries,omitempty" validate:"omitempty,min=0,max=10"`
+	RetryIntervalSeconds int        `json:"retry_interval_seconds,omitempty" validate:"omitempty,min=60,max=604800"`
+}
+
+// ScheduledTransfer is ACTIVE, or PAUSED, until it is CANCELLED or has no
+// occurrence left (COMPLETED).
+type ScheduledTransfer struct {
+	UUID                 string     `json:"uuid"`
+	CreatedAt            time.Time  `json:"created_at"`
+	FromWalletUUID       string     `json:"from_wallet_uuid"`
+	ToWalletUUID         string     `json:"to_wallet_uuid"`
+	AmountInCents        int        `json:"amount_in_cents"`
+	Description          string     `json:"description,omitempty"`
+	StartAt              time.Time  `json:"start_at"`
+	EndAt                *time.Time `json:"end_at,omitempty"`
+	Recurrence           string     `json:"recurrence,omitempty"`
+	Timezone             string     `json:"timezone"`
+	MaxRetries           int        `json:"max_retries"`
+	RetryIntervalSeconds int        `json:"retry_interval_seconds"`
+	Status               string     `json:"status"`
+	NextOccurrenceAt     *time.Time `json:"next_occurrence_at,omitempty"`
+	NextRunAt            *time.Time `json:"next_run_at,omitempty"`
+	SucceededCount       int        `json:"succeeded_count"`
+	FailedCount          int        `json:"failed_count"`
+	LastRunAt            *time.Time `json:"last_run_at,omitempty"`
+	// the latest runs, on single scheduled transfers only
+	Runs []ScheduledTransferRun `json:"runs,omitempty"`
+}
+
+// ScheduledTransferRun is the outcome of one occurrence: SUCCEEDED, with
+// the ReferenceID of the transfer, RETRYING for insufficient funds, or
+// FAILED, with the Error.
+type ScheduledTransferRun struct {
+	UUID         string    `json:"uuid"`
+	OccurrenceAt time.Time `json:"occurrence_at"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	ReferenceID  string    `json:"reference_id,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// ListScheduledTransfersRequest pages through scheduled transfers newest
+// first, Before is the UUID of the last one of the previous page.
+type ListScheduledTransfersRequest struct {
+	Status string `form:"status" json:"status,omitempty" validate:"omitempty,oneof=ACTIVE PAUSED CANCELLED COMPLETED"`
+	Before string `form:"before" json:"before,omitempty"`
+	Limit  int    `form:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type ListScheduledTransfersResponse struct {
+	ScheduledTransfers []ScheduledTransfer `json:"scheduled_transfers"`
+}
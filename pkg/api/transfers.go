@@ -0,0 +1,24 @@
+package api
+
+// CreateTransferRequest pays the wallet ToWalletUUID, or the one ToHandle
+// points at.
+type CreateTransferRequest struct {
+	ToWalletUUID  string `json:"to_wallet_uuid,omitempty" validate:"required_without=ToHandle"`
+	ToHandle      string `json:"to_handle,omitempty"`
+	AmountInCents int    `json:"amount_in_cents" validate:"required,gt=0"`
+	PurposeCode   string `json:"purpose_code,omitempty"`
+	Description   string `json:"description,omitempty"`
+}
+
+type TransferResponse struct {
+	ReferenceID    string `json:"reference_id"`
+	FromWalletUUID string `json:"from_wallet_uuid"`
+	ToWalletUUID   string `json:"to_wallet_uuid"`
+	AmountInCents  int    `json:"amount_in_cents"`
+	PurposeCode    string `json:"purpose_code"`
+	Description    string `json:"description,omitempty"`
+	// fee charged on the transfer, to the sender or the recipient
+	FeeInCents   int           `json:"fee_in_cents,omitempty"`
+	FeePayer     string        `json:"fee_payer,omitempty"`
+	Transactions []Transaction `json:"transactions"`
+}
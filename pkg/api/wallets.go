@@ -0,0 +1,94 @@
+package api
+
+import "time"
+
+type WalletBalanceResponse struct {
+	WalletUUID              string `json:"wallet_uuid"`
+	Currency                string `json:"currency"`
+	TotalBalanceInCents     int    `json:"total_balance_in_cents"`
+	OverdraftLimitInCents   uint   `json:"overdraft_limit_in_cents"`
+	AvailableBalanceInCents int    `json:"available_balance_in_cents"`
+}
+
+// Transaction is the wire form of models.Transaction.
+type Transaction struct {
+	UUID                  string     `json:"uuid"`
+	CreatedAt             *time.Time `json:"created_at,omitempty"`
+	Type                  string     `json:"type"`
+	AmountInCents         int        `json:"amount_in_cents"`
+	OpeningBalanceInCents int        `json:"opening_balance_in_cents"`
+	ClosingBalanceInCents int        `json:"closing_balance_in_cents"`
+	Status                string     `json:"status"`
+	FromWalletUUID        string     `json:"from_wallet_uuid,omitempty"`
+	ToWalletUUID          string     `json:"to_wallet_uuid,omitempty"`
+	PurposeCode           string     `json:"purpose_code"`
+	ReferenceID           string     `json:"reference_id,omitempty"`
+	Description           string     `json:"description,omitempty"`
+	WalletSequence        int64      `json:"wallet_sequence"`
+	PrevHash              string     `json:"prev_hash"`
+	Hash                  string     `json:"hash"`
+}
+
+// StreamTicket opens one wallet event stream of the account it was issued
+// to, as the ticket query param, until ExpiresAt.
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ChainIssue struct {
+	Kind            string `json:"kind"`
+	TransactionUUID string `json:"transaction_uuid,omitempty"`
+	Detail          string `json:"detail"`
+}
+
+// LedgerCheckpoint is a signed head of the global transaction chain. The
+// signature is ed25519 over "coinpe-ledger-checkpoint:<global_sequence>:<global_hash>".
+type LedgerCheckpoint struct {
+	GlobalSequence int64      `json:"global_sequence"`
+	GlobalHash     string     `json:"global_hash"`
+	KeyID          string     `json:"key_id"`
+	Signature      string     `json:"signature"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+}
+
+type WalletChainResponse struct {
+	WalletUUID       string            `json:"wallet_uuid"`
+	Valid            bool              `json:"valid"`
+	TransactionCount int               `json:"transaction_count"`
+	HeadSequence     int64             `json:"head_sequence"`
+	HeadHash         string            `json:"head_hash"`
+	Issues           []ChainIssue      `json:"issues"`
+	LatestCheckpoint *LedgerCheckpoint `json:"latest_checkpoint,omitempty"`
+}
+
+type BalanceAtRequest struct {
+	At time.Time `form:"at" json:"at" validate:"required"`
+}
+
+type BalanceAtResponse struct {
+	WalletUUID     string    `json:"wallet_uuid"`
+	Currency       string    `json:"currency"`
+	At             time.Time `json:"at"`
+	BalanceInCents int       `json:"balance_in_cents"`
+}
+
+// StatementRequest selects the transactions created in [from, to).
+type StatementRequest struct {
+	From time.Time `form:"from" json:"from" validate:"required"`
+	To   time.Time `form:"to" json:"to" validate:"required,gtfield=From"`
+}
+
+type StatementResponse struct {
+	WalletUUID            string        `json:"wallet_uuid"`
+	Currency              string        `json:"currency"`
+	From                  time.Time     `json:"from"`
+	To                    time.Time     `json:"to"`
+	OpeningBalanceInCents int           `json:"opening_balance_in_cents"`
+	ClosingBalanceInCents int           `json:"closing_balance_in_cents"`
+	TotalCreditsInCents   int           `json:"total_credits_in_cents"`
+	TotalDebitsInCents    int           `json:"total_debits_in_cents"`
+	Transactions          []Transaction `json:"transactions"`
+	// Checksum matches the one on the CSV and PDF exports of the same period.
+	Checksum string `json:"checksum"`
+}
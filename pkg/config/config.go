@@ -27,6 +27,9 @@ func getPwd() string {
 
 func parseFlags() {
 	flag.String("job", "", "Name of the job to be executed. On completion of the job the server exits.")
+	flag.Int("steps", 1, "Number of migrations the migrate:rollback job rolls back.")
+	flag.Bool("repair", false, "Let the verify-ledger job post adjustment entries for drifted wallets.")
+	flag.String("reason", "", "Audit reason recorded on adjustment entries.")
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
@@ -3,6 +3,7 @@ package config
 import (
 	"coinpe/database"
 	"coinpe/pkg/constants"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -24,12 +25,103 @@ type Config struct {
 	FeatureFlags       string                   `env:"FEATURE_FLAGS"`
 	VPCProxyCIDR       string                   `env:"VPC_PROXY_CIDR"`
 	JWTConfiguration   JWTConfiguration
+	Scheduler          SchedulerConfiguration          `env:",prefix=SCHEDULER_"`
+	Ledger             LedgerConfiguration             `env:",prefix=LEDGER_"`
+	Reconciliation     ReconciliationConfiguration     `env:",prefix=RECONCILIATION_"`
+	Sharding           ShardingConfiguration           `env:",prefix=SHARDING_"`
+	Queue              QueueConfiguration              `env:",prefix=QUEUE_"`
+	ScheduledTransfers ScheduledTransferConfiguration  `env:",prefix=SCHEDULED_TRANSFERS_"`
+	PaymentRequests    PaymentRequestConfiguration     `env:",prefix=PAYMENT_REQUESTS_"`
+	Handles            HandleConfiguration             `env:",prefix=HANDLES_"`
+	Checkout           CheckoutConfiguration           `env:",prefix=CHECKOUT_"`
+	MerchantSettlement MerchantSettlementConfiguration `env:",prefix=MERCHANT_SETTLEMENT_"`
+	Streams            StreamConfiguration             `env:",prefix=STREAMS_"`
 }
 
 type ServerConfiguration struct {
 	Port      string `env:"PORT"`
 	GRPCPort  string `env:"GRPC_PORT"`
 	PublicURL string `env:"PUBLIC_URL"`
+	// time the HTTP and gRPC servers get to finish their requests on shutdown
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT,default=5s"`
+	// time the background workers get afterwards to finish their work
+	WorkerDrainTimeout time.Duration `env:"WORKER_DRAIN_TIMEOUT,default=30s"`
+}
+
+type SchedulerConfiguration struct {
+	Enabled bool `env:"ENABLED"`
+	// Schedules maps job names to cron expressions, separated by semicolons:
+	// rebuild-balances=0 3 * * *;seed=@daily
+	Schedules map[string]string `env:"SCHEDULES,delimiter=;,separator=="`
+}
+
+type LedgerConfiguration struct {
+	// base64 encoded 32 byte ed25519 seed signing ledger checkpoints
+	CheckpointSigningKey string `env:"CHECKPOINT_SIGNING_KEY"`
+}
+
+type ReconciliationConfiguration struct {
+	// how far apart a settlement record and its transaction may be
+	DateWindow time.Duration `env:"DATE_WINDOW,default=72h"`
+}
+
+type ShardingConfiguration struct {
+	// shards of the treasury wallet, 1 keeps it a single row
+	TreasuryShards int `env:"TREASURY_SHARDS,default=8"`
+	// Wallets maps the UUIDs of other high-traffic wallets to their shard
+	// count, separated by semicolons: wa_abc=4;wa_def=2
+	Wallets map[string]int `env:"WALLETS,delimiter=;,separator=="`
+}
+
+type QueueConfiguration struct {
+	// workers posting queued transfers inside the server, 0 disables them
+	Workers int `env:"WORKERS,default=4"`
+	// queued transfers of one source wallet posted at the same time, across
+	// every instance, 0 for no limit
+	WalletConcurrency int           `env:"WALLET_CONCURRENCY,default=2"`
+	PollInterval      time.Duration `env:"POLL_INTERVAL,default=1s"`
+	MaxAttempts       int           `env:"MAX_ATTEMPTS,default=5"`
+	RetryBackoff      time.Duration `env:"RETRY_BACKOFF,default=2s"`
+	// payout items posted per database transaction
+	PayoutChunkSize int `env:"PAYOUT_CHUNK_SIZE,default=100"`
+
+	// signs webhook bodies with HMAC-SHA256 in the Coinpe-Signature header
+	WebhookSecret      string        `env:"WEBHOOK_SECRET"`
+	WebhookTimeout     time.Duration `env:"WEBHOOK_TIMEOUT,default=10s"`
+	WebhookMaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS,default=8"`
+	WebhookBackoff     time.Duration `env:"WEBHOOK_BACKOFF,default=30s"`
+}
+
+type ScheduledTransferConfiguration struct {
+	// insufficient funds retries of one occurrence, unless a scheduled
+	// transfer sets its own
+	MaxRetries    int           `env:"MAX_RETRIES,default=3"`
+	RetryInterval time.Duration `env:"RETRY_INTERVAL,default=1h"`
+}
+
+type PaymentRequestConfiguration struct {
+	// how long a payment request can be paid, unless it sets its own expiry
+	DefaultExpiry time.Duration `env:"DEFAULT_EXPIRY,default=72h"`
+	MaxExpiry     time.Duration `env:"MAX_EXPIRY,default=720h"`
+}
+
+type HandleConfiguration struct {
+	// base64 encoded 32 byte ed25519 seed signing payment QR payloads, QR
+	// codes are disabled without it
+	QRSigningKey    string        `env:"QR_SIGNING_KEY"`
+	QRDefaultExpiry time.Duration `env:"QR_DEFAULT_EXPIRY,default=24h"`
+	QRMaxExpiry     time.Duration `env:"QR_MAX_EXPIRY,default=8760h"`
+}
+
+type CheckoutConfiguration struct {
+	// how long a checkout session can be paid, unless it sets its own expiry
+	SessionExpiry    time.Duration `env:"SESSION_EXPIRY,default=30m"`
+	MaxSessionExpiry time.Duration `env:"MAX_SESSION_EXPIRY,default=24h"`
+}
+
+type MerchantSettlementConfiguration struct {
+	// checkout payments of a UTC day settle this many days later, T+N
+	DelayDays int `env:"DELAY_DAYS,default=1"`
 }
 
 type RedisConfiguration struct {
@@ -43,3 +135,11 @@ type JWTConfiguration struct {
 	FullAuthAccessTokenExpiryInSeconds    int    `env:"FULL_AUTH_ACCESS_TOKEN_EXPIRY_IN_SECONDS"`
 	FullAuthRefreshTokenExpiryInSeconds   int    `env:"FULL_AUTH_REFRESH_TOKEN_EXPIRY_IN_SECONDS"`
 }
+
+type StreamConfiguration struct {
+	// origins, e.g. https://app.example.com, whose pages may open wallet
+	// event WebSockets besides the server's own, separated by commas
+	AllowedOrigins []string `env:"ALLOWED_ORIGINS"`
+	// how long a stream ticket can be redeemed after it is issued
+	TicketTTL time.Duration `env:"TICKET_TTL,default=30s"`
+}
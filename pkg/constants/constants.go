@@ -3,6 +3,9 @@ package constants
 const (
 	MockOTP                         = "123123"
 	AuthorizationHeaderName         = "Authorization"
+	IdempotencyKeyHeaderName        = "Idempotency-Key"
+	IdempotentReplayedHeaderName    = "Idempotent-Replayed"
+	WebhookSignatureHeaderName      = "Coinpe-Signature"
 	AuthorizedAccountUUIDContextKey = "account_uuid"
 	AuthorizedAccountRoleContextKey = "role"
 	IsPartialContextKey             = "is_partial"
@@ -0,0 +1,16 @@
+package constants
+
+type TransactionType string
+
+const (
+	TransactionTypeCredit TransactionType = "CREDIT"
+	TransactionTypeDebit  TransactionType = "DEBIT"
+)
+
+type EntityStatus string
+
+const (
+	EntityPending EntityStatus = "PENDING"
+	EntitySuccess EntityStatus = "SUCCESS"
+	EntityFailed  EntityStatus = "FAILED"
+)
@@ -0,0 +1,154 @@
+package error
+
+import (
+	"errors"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain identifies CoinPe errors in grpc ErrorInfo details.
+const errorDomain = "coinpe"
+
+// DomainError is an error with a pkg/error code. The code decides the HTTP
+// and grpc status, MessageID and Args the text shown to the caller in their
+// language (see Localize) and Err, when set, is the underlying cause which
+// is only logged. Message is the English text, kept for logs and storage.
+// Errors are not retryable unless marked with WithRetryable.
+//
+//	return errorConst.Wrap(err, errorConst.ErrorNoRecordsFound, errorConst.MsgWalletNotFound)
+type DomainError struct {
+	Code           int
+	MessageID      MessageID
+	Args           []interface{}
+	Message        string
+	Retryable      bool
+	Fields         []Error
+	AdditionalInfo map[string]interface{}
+	Err            error
+}
+
+// New returns a DomainError with the message id formatted with args.
+func New(code int, id MessageID, args ...interface{}) *DomainError {
+	return &DomainError{
+		Code:      code,
+		MessageID: id,
+		Args:      args,
+		Message:   Text(id, args...),
+	}
+}
+
+// Wrap returns a DomainError caused by err.
+func Wrap(err error, code int, id MessageID, args ...interface{}) *DomainError {
+	e := New(code, id, args...)
+	e.Err = err
+	return e
+}
+
+// FromError returns the DomainError in err's chain, or an internal error
+// wrapping err when there is none.
+func FromError(err error) *DomainError {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+	return Wrap(err, ErrorInternalError, MsgInternalServerError)
+}
+
+func (e *DomainError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same code and message id, so copies made by
+// the With methods still match the sentinel they were made from.
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code && t.MessageID == e.MessageID
+}
+
+// WithRetryable returns a copy of e with the retryable flag overridden.
+func (e *DomainError) WithRetryable(retryable bool) *DomainError {
+	c := *e
+	c.Retryable = retryable
+	return &c
+}
+
+// WithFields returns a copy of e pointing at specific request fields.
+func (e *DomainError) WithFields(fields []Error) *DomainError {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+// WithAdditionalInfo returns a copy of e carrying additional info for the caller.
+func (e *DomainError) WithAdditionalInfo(additionalInfo map[string]interface{}) *DomainError {
+	c := *e
+	c.AdditionalInfo = additionalInfo
+	return &c
+}
+
+func (e *DomainError) HTTPStatus() int {
+	return GetHttpStatusCodeForError(e.Code)
+}
+
+// Response is the HTTP body for e, with the message in the language of the
+// Accept-Language header.
+func (e *DomainError) Response(acceptLanguage string) *ErrorResponse {
+	errResponse := &ErrorResponse{}
+	errResponse.GenerateWithErrors(e.Code, Localize(acceptLanguage, e.MessageID, e.Args...), e.Fields)
+	errResponse.MessageID = string(e.MessageID)
+	errResponse.AdditionalInfo = e.AdditionalInfo
+	errResponse.Retryable = e.Retryable
+	return errResponse
+}
+
+// Status is the grpc status for e. The pkg/error code and retryable flag are
+// attached as an ErrorInfo detail and invalid fields as a BadRequest detail.
+func (e *DomainError) Status(acceptLanguage string) *status.Status {
+	st := status.New(GetGRPCCodeForError(e.Code), Localize(acceptLanguage, e.MessageID, e.Args...))
+
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: ErrorText(e.Code),
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			"code":       strconv.Itoa(e.Code),
+			"message_id": string(e.MessageID),
+			"retryable":  strconv.FormatBool(e.Retryable),
+		},
+	})
+	if err != nil {
+		return st
+	}
+	st = withDetails
+
+	if len(e.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range e.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Description,
+			})
+		}
+		if withDetails, err := st.WithDetails(badRequest); err == nil {
+			st = withDetails
+		}
+	}
+
+	return st
+}
+
+// GRPCStatus lets status.FromError and status.Code understand DomainErrors.
+func (e *DomainError) GRPCStatus() *status.Status {
+	return e.Status("")
+}
@@ -1,12 +1,19 @@
 package error
 
-import "net/http"
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
 
 type ErrorResponse struct {
 	Code            int                    `json:"code,omitempty"`
 	CodeDescription string                 `json:"code_description,omitempty"`
 	Message         string                 `json:"message,omitempty"`
+	MessageID       string                 `json:"message_id,omitempty"`
 	AdditionalInfo  map[string]interface{} `json:"additional_info,omitempty"`
+	Retryable       bool                   `json:"retryable,omitempty"`
+	Errors          []Error                `json:"errors,omitempty"`
 }
 
 type Error struct {
@@ -24,17 +31,31 @@ func (er *ErrorResponse) Generate(errCode int, message string, additionalInfo ma
 	return er
 }
 
+// GenerateWithErrors is Generate for failures that point at specific request fields.
+func (er *ErrorResponse) GenerateWithErrors(errCode int, message string, errors []Error) *ErrorResponse {
+	er.Generate(errCode, message, EmptyInterface)
+	er.Errors = errors
+
+	return er
+}
+
 func ErrorText(code int) string {
 	return errorText[code]
 }
 
 var errorText = map[int]string{
-	ErrorBadFormat:      "BadFormatError",
-	ErrorBadRequest:     "BadRequest",
-	ErrorBindingRequest: "We're experiencing difficulties binding your request at the moment. Please ensure all required information is provided and try again. If the issue persists, kindly contact our support team for assistance.",
-	ErrorNoRecordsFound: "NoRecordsFound",
-	ErrorForbidden:      "Forbidden",
-	ErrorInternalError:  "InternalServerError",
+	ErrorBadFormat:          "BadFormatError",
+	ErrorBadRequest:         "BadRequest",
+	ErrorInsufficientFunds:  "InsufficientFunds",
+	ErrorBindingRequest:     "We're experiencing difficulties binding your request at the moment. Please ensure all required information is provided and try again. If the issue persists, kindly contact our support team for assistance.",
+	ErrorValidation:         "ValidationError",
+	ErrorNoRecordsFound:     "NoRecordsFound",
+	ErrorConflict:           "Conflict",
+	ErrorUnprocessable:      "UnprocessableEntity",
+	ErrorUnauthorized:       "Unauthorized",
+	ErrorForbidden:          "Forbidden",
+	ErrorInternalError:      "InternalServerError",
+	ErrorServiceUnavailable: "ServiceUnavailable",
 }
 
 func GetHttpStatusCodeForError(code int) int {
@@ -44,3 +65,11 @@ func GetHttpStatusCodeForError(code int) int {
 	}
 	return val
 }
+
+func GetGRPCCodeForError(code int) codes.Code {
+	val, ok := errorCodeToGRPCCodeMap[code]
+	if !ok {
+		return codes.Internal
+	}
+	return val
+}
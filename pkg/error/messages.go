@@ -0,0 +1,975 @@
+package error
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// MessageID identifies a caller facing message. Translations are keyed by
+// it rather than by the English text, so a message can be reworded without
+// losing them.
+type MessageID string
+
+const (
+	MsgInternalServerError                   MessageID = "internal_server_error"
+	MsgRouteNotFound                         MessageID = "route_not_found"
+	MsgRequestHasInvalidFields               MessageID = "request_has_invalid_fields"
+	MsgInvalidRole                           MessageID = "invalid_role"
+	MsgInvalidOTPProvided                    MessageID = "invalid_otp_provided"
+	MsgMissingAccessToken                    MessageID = "missing_access_token"
+	MsgInvalidAccessToken                    MessageID = "invalid_access_token"
+	MsgInvalidRefreshToken                   MessageID = "invalid_refresh_token"
+	MsgFullAuthScopedTokenRequired           MessageID = "full_auth_scoped_token_required"
+	MsgPartialAuthScopedTokenRequired        MessageID = "partial_auth_scoped_token_required"
+	MsgErrorInCommitting                     MessageID = "error_in_committing"
+	MsgErrorInCreatingAccount                MessageID = "error_in_creating_account"
+	MsgErrorInCreatingWallet                 MessageID = "error_in_creating_wallet"
+	MsgErrorInCreatingOTPSecret              MessageID = "error_in_creating_otp_secret"
+	MsgUnableToValidateUserOTP               MessageID = "unable_to_validate_user_otp"
+	MsgErrorInCreatingAccessToken            MessageID = "error_in_creating_access_token"
+	MsgUnableToCreateAccessToken             MessageID = "unable_to_create_access_token"
+	MsgUnableToCreateRefreshToken            MessageID = "unable_to_create_refresh_token"
+	MsgUnableToValidateRequest               MessageID = "unable_to_validate_request"
+	MsgErrorInGettingWallet                  MessageID = "error_in_getting_wallet"
+	MsgErrorInGettingBalance                 MessageID = "error_in_getting_balance"
+	MsgErrorInGettingDestinationWallet       MessageID = "error_in_getting_destination_wallet"
+	MsgErrorInCreatingTransfer               MessageID = "error_in_creating_transfer"
+	MsgErrorInGettingIdempotencyKey          MessageID = "error_in_getting_idempotency_key"
+	MsgErrorInReservingIdempotencyKey        MessageID = "error_in_reserving_idempotency_key"
+	MsgErrorInVerifyingWalletChain           MessageID = "error_in_verifying_wallet_chain"
+	MsgErrorInGettingLedgerCheckpoint        MessageID = "error_in_getting_ledger_checkpoint"
+	MsgErrorInGettingStatement               MessageID = "error_in_getting_statement"
+	MsgInvalidPageToken                      MessageID = "invalid_page_token"
+	MsgToWalletUUIDIsARequiredField          MessageID = "to_wallet_uuid_is_a_required_field"
+	MsgAmountInCentsMustBeGreaterThanZero    MessageID = "amount_in_cents_must_be_greater_than_zero"
+	MsgErrorInGettingTransfer                MessageID = "error_in_getting_transfer"
+	MsgErrorInListingTransactions            MessageID = "error_in_listing_transactions"
+	MsgReferenceIDIsARequiredField           MessageID = "reference_id_is_a_required_field"
+	MsgWalletNotFound                        MessageID = "wallet_not_found"
+	MsgDestinationWalletNotFound             MessageID = "destination_wallet_not_found"
+	MsgTransferNotFound                      MessageID = "transfer_not_found"
+	MsgInvalidPurposeCode                    MessageID = "invalid_purpose_code"
+	MsgPurposeCodeNotAllowedForRole          MessageID = "purpose_code_not_allowed_for_role"
+	MsgInsufficientFunds                     MessageID = "insufficient_funds"
+	MsgCannotTransferToTheSameWallet         MessageID = "cannot_transfer_to_the_same_wallet"
+	MsgWalletCurrenciesDoNotMatch            MessageID = "wallet_currencies_do_not_match"
+	MsgInvalidLastEventID                    MessageID = "invalid_last_event_id"
+	MsgInvalidStreamTicket                   MessageID = "invalid_stream_ticket"
+	MsgErrorInCreatingStreamTicket           MessageID = "error_in_creating_stream_ticket"
+	MsgErrorInRedeemingStreamTicket          MessageID = "error_in_redeeming_stream_ticket"
+	MsgIdempotencyKeyIsTooLong               MessageID = "idempotency_key_is_too_long"
+	MsgIdempotencyKeyReused                  MessageID = "idempotency_key_reused"
+	MsgIdempotencyKeyInProgress              MessageID = "idempotency_key_in_progress"
+	MsgUnreadableBody                        MessageID = "unreadable_body"
+	MsgUnreadableQuery                       MessageID = "unreadable_query"
+	MsgStatementTooManyTransactions          MessageID = "statement_too_many_transactions"
+	MsgAccountNotFound                       MessageID = "account_not_found"
+	MsgErrorInGettingAccount                 MessageID = "error_in_getting_account"
+	MsgErrorInExportingStatement             MessageID = "error_in_exporting_statement"
+	MsgStatementTooLargeForPDF               MessageID = "statement_too_large_for_pdf"
+	MsgSettlementFileHasNoRecords            MessageID = "settlement_file_has_no_records"
+	MsgSettlementFileIsInvalid               MessageID = "settlement_file_is_invalid"
+	MsgSettlementFileWasAlreadyImported      MessageID = "settlement_file_was_already_imported"
+	MsgResolutionNoteIsRequired              MessageID = "resolution_note_is_required"
+	MsgInvalidResolution                     MessageID = "invalid_resolution"
+	MsgSettlementRecordResolved              MessageID = "settlement_record_resolved"
+	MsgSettlementRecordNotMismatched         MessageID = "settlement_record_not_mismatched"
+	MsgTransactionAlreadyMatched             MessageID = "transaction_already_matched"
+	MsgTransactionDirectionMismatch          MessageID = "transaction_direction_mismatch"
+	MsgReconciliationInternalOnly            MessageID = "reconciliation_internal_only"
+	MsgSettlementImportNotFound              MessageID = "settlement_import_not_found"
+	MsgErrorInGettingSettlementImport        MessageID = "error_in_getting_settlement_import"
+	MsgErrorInGettingReconciliationReport    MessageID = "error_in_getting_reconciliation_report"
+	MsgUnableToReadSettlementFile            MessageID = "unable_to_read_settlement_file"
+	MsgErrorInImportingSettlementFile        MessageID = "error_in_importing_settlement_file"
+	MsgSettlementFileReconcileFailed         MessageID = "settlement_file_reconcile_failed"
+	MsgErrorInListingSettlementImports       MessageID = "error_in_listing_settlement_imports"
+	MsgErrorInReconcilingSettlementFile      MessageID = "error_in_reconciling_settlement_file"
+	MsgSettlementRecordOrTransactionNotFound MessageID = "settlement_record_or_transaction_not_found"
+	MsgErrorInResolvingSettlementRecord      MessageID = "error_in_resolving_settlement_record"
+	MsgWalletIsBusyRetryTheRequest           MessageID = "wallet_is_busy_retry_the_request"
+	MsgWalletShardCount                      MessageID = "wallet_shard_count"
+	MsgWalletShardsCanOnlyBeAdded            MessageID = "wallet_shards_can_only_be_added"
+	MsgWalletShardCannotBeSharded            MessageID = "wallet_shard_cannot_be_sharded"
+	MsgErrorInQueueingTransfer               MessageID = "error_in_queueing_transfer"
+	MsgInvalidTransferInBatch                MessageID = "invalid_transfer_in_batch"
+	MsgPendingTransactionNotFound            MessageID = "pending_transaction_not_found"
+	MsgErrorInGettingPendingTransaction      MessageID = "error_in_getting_pending_transaction"
+	MsgTransferBatchNotFound                 MessageID = "transfer_batch_not_found"
+	MsgErrorInGettingTransferBatch           MessageID = "error_in_getting_transfer_batch"
+	MsgPayoutHasNoRows                       MessageID = "payout_has_no_rows"
+	MsgPayoutIsInvalid                       MessageID = "payout_is_invalid"
+	MsgPayoutTooBig                          MessageID = "payout_too_big"
+	MsgPayoutsInternalOnly                   MessageID = "payouts_internal_only"
+	MsgPurposeCodeNotAllowedForPayouts       MessageID = "purpose_code_not_allowed_for_payouts"
+	MsgErrorInCreatingPayout                 MessageID = "error_in_creating_payout"
+	MsgTreasuryWalletNotFound                MessageID = "treasury_wallet_not_found"
+	MsgUnableToReadPayoutFile                MessageID = "unable_to_read_payout_file"
+	MsgPayoutNotFound                        MessageID = "payout_not_found"
+	MsgErrorInGettingPayout                  MessageID = "error_in_getting_payout"
+	MsgErrorInListingPayoutItems             MessageID = "error_in_listing_payout_items"
+	MsgErrorInExportingPayoutResult          MessageID = "error_in_exporting_payout_result"
+	MsgScheduleHasNoFutureOccurrence         MessageID = "schedule_has_no_future_occurrence"
+	MsgScheduledTransferIsNotActive          MessageID = "scheduled_transfer_is_not_active"
+	MsgScheduledTransferIsNotPaused          MessageID = "scheduled_transfer_is_not_paused"
+	MsgScheduledTransferHasAlreadyEnded      MessageID = "scheduled_transfer_has_already_ended"
+	MsgInvalidRecurrence                     MessageID = "invalid_recurrence"
+	MsgErrorInCreatingScheduledTransfer      MessageID = "error_in_creating_scheduled_transfer"
+	MsgScheduledTransferNotFound             MessageID = "scheduled_transfer_not_found"
+	MsgErrorInGettingScheduledTransfer       MessageID = "error_in_getting_scheduled_transfer"
+	MsgErrorInListingScheduledTransfers      MessageID = "error_in_listing_scheduled_transfers"
+	MsgErrorInUpdatingScheduledTransfer      MessageID = "error_in_updating_scheduled_transfer"
+	MsgPaymentRequestToSelf                  MessageID = "payment_request_to_self"
+	MsgPaymentRequestIsNoLongerPending       MessageID = "payment_request_is_no_longer_pending"
+	MsgPaymentRequestHasExpired              MessageID = "payment_request_has_expired"
+	MsgInvalidExpiry                         MessageID = "invalid_expiry"
+	MsgPayerWalletNotFound                   MessageID = "payer_wallet_not_found"
+	MsgErrorInGettingPayerWallet             MessageID = "error_in_getting_payer_wallet"
+	MsgErrorInCreatingPaymentRequest         MessageID = "error_in_creating_payment_request"
+	MsgErrorInListingPaymentRequests         MessageID = "error_in_listing_payment_requests"
+	MsgPaymentRequestNotFound                MessageID = "payment_request_not_found"
+	MsgErrorInGettingPaymentRequest          MessageID = "error_in_getting_payment_request"
+	MsgErrorInRespondingToPaymentRequest     MessageID = "error_in_responding_to_payment_request"
+	MsgPaymentRequestPayerOnly               MessageID = "payment_request_payer_only"
+	MsgPaymentRequestRequesterOnly           MessageID = "payment_request_requester_only"
+	MsgSplitSharesExceedTheTotal             MessageID = "split_shares_exceed_the_total"
+	MsgSplitAmountsPartial                   MessageID = "split_amounts_partial"
+	MsgTotalIsTooSmallToSplit                MessageID = "total_is_too_small_to_split"
+	MsgPayerAppearsTwiceInTheSplit           MessageID = "payer_appears_twice_in_the_split"
+	MsgInvalidPayerInSplit                   MessageID = "invalid_payer_in_split"
+	MsgErrorInCreatingSplitBill              MessageID = "error_in_creating_split_bill"
+	MsgSplitBillNotFound                     MessageID = "split_bill_not_found"
+	MsgErrorInGettingSplitBill               MessageID = "error_in_getting_split_bill"
+	MsgInvalidHandle                         MessageID = "invalid_handle"
+	MsgHandleIsAlreadyTaken                  MessageID = "handle_is_already_taken"
+	MsgHandleNotFound                        MessageID = "handle_not_found"
+	MsgErrorInGettingHandle                  MessageID = "error_in_getting_handle"
+	MsgErrorInSettingHandle                  MessageID = "error_in_setting_handle"
+	MsgErrorInDeletingHandle                 MessageID = "error_in_deleting_handle"
+	MsgPaymentQRCodesAreNotEnabled           MessageID = "payment_qr_codes_are_not_enabled"
+	MsgPaymentQRNeedsHandle                  MessageID = "payment_qr_needs_handle"
+	MsgErrorInRenderingPaymentQRCode         MessageID = "error_in_rendering_payment_qr_code"
+	MsgInvalidPaymentQRCode                  MessageID = "invalid_payment_qr_code"
+	MsgPaymentQRCodeHasExpired               MessageID = "payment_qr_code_has_expired"
+	MsgPaymentQRCodeIsNoLongerValid          MessageID = "payment_qr_code_is_no_longer_valid"
+	MsgMerchantPaysOwnCheckoutSession        MessageID = "merchant_pays_own_checkout_session"
+	MsgMerchantProfileExists                 MessageID = "merchant_profile_exists"
+	MsgCheckoutSessionHasExpired             MessageID = "checkout_session_has_expired"
+	MsgCheckoutSessionHasNotBeenPaid         MessageID = "checkout_session_has_not_been_paid"
+	MsgCheckoutSessionIsNoLongerOpen         MessageID = "checkout_session_is_no_longer_open"
+	MsgCheckoutSessionNotFound               MessageID = "checkout_session_not_found"
+	MsgErrorInCreatingMerchant               MessageID = "error_in_creating_merchant"
+	MsgErrorInGettingCheckoutSession         MessageID = "error_in_getting_checkout_session"
+	MsgErrorInGettingMerchantWallet          MessageID = "error_in_getting_merchant_wallet"
+	MsgErrorInGettingMerchant                MessageID = "error_in_getting_merchant"
+	MsgErrorInListingCheckoutSessions        MessageID = "error_in_listing_checkout_sessions"
+	MsgErrorInUpdatingMerchant               MessageID = "error_in_updating_merchant"
+	MsgMerchantProfileNotFound               MessageID = "merchant_profile_not_found"
+	MsgMerchantWalletNotFound                MessageID = "merchant_wallet_not_found"
+	MsgOnlyMerchantsCanDoThis                MessageID = "only_merchants_can_do_this"
+	MsgCheckoutSessionMerchantOnly           MessageID = "checkout_session_merchant_only"
+	MsgOrderAlreadyHasACheckoutSession       MessageID = "order_already_has_a_checkout_session"
+	MsgRefundExceedsPayment                  MessageID = "refund_exceeds_payment"
+	MsgErrorInCreatingCheckoutSession        MessageID = "error_in_creating_checkout_session"
+	MsgErrorInPayingCheckoutSession          MessageID = "error_in_paying_checkout_session"
+	MsgErrorInCancellingCheckoutSession      MessageID = "error_in_cancelling_checkout_session"
+	MsgErrorInRefundingCheckoutSession       MessageID = "error_in_refunding_checkout_session"
+	MsgFeeSchedulesInternalOnly              MessageID = "fee_schedules_internal_only"
+	MsgPurposeCodeNotAllowedForFeeSchedules  MessageID = "purpose_code_not_allowed_for_fee_schedules"
+	MsgMinimumFeeExceedsMaximumFee           MessageID = "minimum_fee_exceeds_maximum_fee"
+	MsgOnlyTheLastFeeTierCanBeUnbounded      MessageID = "only_the_last_fee_tier_can_be_unbounded"
+	MsgFeeTiersMustBeSortedByAmount          MessageID = "fee_tiers_must_be_sorted_by_amount"
+	MsgMerchantNotFound                      MessageID = "merchant_not_found"
+	MsgFeeScheduleNotFound                   MessageID = "fee_schedule_not_found"
+	MsgErrorInGettingFeeSchedule             MessageID = "error_in_getting_fee_schedule"
+	MsgErrorInCreatingFeeSchedule            MessageID = "error_in_creating_fee_schedule"
+	MsgErrorInListingFeeSchedules            MessageID = "error_in_listing_fee_schedules"
+	MsgErrorInDeletingFeeSchedule            MessageID = "error_in_deleting_fee_schedule"
+	MsgErrorInQuotingFee                     MessageID = "error_in_quoting_fee"
+	MsgSettlementBatchNotFound               MessageID = "settlement_batch_not_found"
+	MsgErrorInGettingSettlementBatch         MessageID = "error_in_getting_settlement_batch"
+	MsgErrorInListingSettlementBatches       MessageID = "error_in_listing_settlement_batches"
+	MsgErrorInGettingSettlementReport        MessageID = "error_in_getting_settlement_report"
+)
+
+// supportedLanguages must stay in sync with the validator locales, English
+// first as it is the fallback.
+var supportedLanguages = []language.Tag{
+	language.English,
+	language.Spanish,
+	language.French,
+	language.BrazilianPortuguese,
+}
+
+var languageMatcher = language.NewMatcher(supportedLanguages)
+
+// messages holds the text of every MessageID in each supported language.
+// Messages with fmt verbs are formatted with the args of their DomainError,
+// every translation must keep the verbs of the English text.
+var messages = map[language.Tag]map[MessageID]string{
+	language.English: {
+		MsgInternalServerError:                   "internal server error",
+		MsgRouteNotFound:                         "route not found",
+		MsgRequestHasInvalidFields:               "request has invalid fields",
+		MsgInvalidRole:                           "invalid role",
+		MsgInvalidOTPProvided:                    "invalid otp provided",
+		MsgMissingAccessToken:                    "missing access token",
+		MsgInvalidAccessToken:                    "invalid access token",
+		MsgInvalidRefreshToken:                   "invalid refresh token",
+		MsgFullAuthScopedTokenRequired:           "full auth scoped token required",
+		MsgPartialAuthScopedTokenRequired:        "partial auth scoped token required",
+		MsgErrorInCommitting:                     "error in commiting",
+		MsgErrorInCreatingAccount:                "error in creating account",
+		MsgErrorInCreatingWallet:                 "error in creating wallet",
+		MsgErrorInCreatingOTPSecret:              "error in creating otp secret",
+		MsgUnableToValidateUserOTP:               "unable to validate user otp",
+		MsgErrorInCreatingAccessToken:            "error in creating access token",
+		MsgUnableToCreateAccessToken:             "unable to create access token",
+		MsgUnableToCreateRefreshToken:            "unable to create refresh token",
+		MsgUnableToValidateRequest:               "unable to validate request",
+		MsgErrorInGettingWallet:                  "error in getting wallet",
+		MsgErrorInGettingBalance:                 "error in getting balance",
+		MsgErrorInGettingDestinationWallet:       "error in getting destination wallet",
+		MsgErrorInCreatingTransfer:               "error in creating transfer",
+		MsgErrorInGettingIdempotencyKey:          "error in getting idempotency key",
+		MsgErrorInReservingIdempotencyKey:        "error in reserving idempotency key",
+		MsgErrorInVerifyingWalletChain:           "error in verifying wallet chain",
+		MsgErrorInGettingLedgerCheckpoint:        "error in getting ledger checkpoint",
+		MsgErrorInGettingStatement:               "error in getting statement",
+		MsgInvalidPageToken:                      "invalid page token",
+		MsgToWalletUUIDIsARequiredField:          "to_wallet_uuid is a required field",
+		MsgAmountInCentsMustBeGreaterThanZero:    "amount_in_cents must be greater than zero",
+		MsgErrorInGettingTransfer:                "error in getting transfer",
+		MsgErrorInListingTransactions:            "error in listing transactions",
+		MsgReferenceIDIsARequiredField:           "reference_id is a required field",
+		MsgWalletNotFound:                        "wallet not found",
+		MsgDestinationWalletNotFound:             "destination wallet not found",
+		MsgTransferNotFound:                      "transfer not found",
+		MsgInvalidPurposeCode:                    "invalid purpose code",
+		MsgPurposeCodeNotAllowedForRole:          "purpose code not allowed for role",
+		MsgInsufficientFunds:                     "insufficient funds",
+		MsgCannotTransferToTheSameWallet:         "cannot transfer to the same wallet",
+		MsgWalletCurrenciesDoNotMatch:            "wallet currencies do not match",
+		MsgInvalidLastEventID:                    "invalid last event id",
+		MsgInvalidStreamTicket:                   "invalid, used or expired stream ticket",
+		MsgErrorInCreatingStreamTicket:           "error in creating stream ticket",
+		MsgErrorInRedeemingStreamTicket:          "error in redeeming stream ticket",
+		MsgIdempotencyKeyIsTooLong:               "idempotency key is too long",
+		MsgIdempotencyKeyReused:                  "idempotency key was already used with a different request body",
+		MsgIdempotencyKeyInProgress:              "a request with this idempotency key is still in progress",
+		MsgUnreadableBody:                        "unable to read request body, please check the request format",
+		MsgUnreadableQuery:                       "unable to read query parameters, please check the request format",
+		MsgStatementTooManyTransactions:          "statement has too many transactions, narrow the period",
+		MsgAccountNotFound:                       "account not found",
+		MsgErrorInGettingAccount:                 "error in getting account",
+		MsgErrorInExportingStatement:             "error in exporting statement",
+		MsgStatementTooLargeForPDF:               "statement is too large for a PDF, export it as CSV",
+		MsgSettlementFileHasNoRecords:            "settlement file has no records",
+		MsgSettlementFileIsInvalid:               "settlement file is invalid",
+		MsgSettlementFileWasAlreadyImported:      "settlement file was already imported",
+		MsgResolutionNoteIsRequired:              "a resolution note is required",
+		MsgInvalidResolution:                     "invalid resolution, expected MATCH, ACCEPT, IGNORE or REOPEN",
+		MsgSettlementRecordResolved:              "settlement record is already resolved, reopen it first",
+		MsgSettlementRecordNotMismatched:         "only mismatched settlement records can be accepted",
+		MsgTransactionAlreadyMatched:             "transaction is already matched to another settlement record",
+		MsgTransactionDirectionMismatch:          "transaction must be successful and in the direction of the settlement record",
+		MsgReconciliationInternalOnly:            "reconciliation is restricted to internal roles",
+		MsgSettlementImportNotFound:              "settlement import not found",
+		MsgErrorInGettingSettlementImport:        "error in getting settlement import",
+		MsgErrorInGettingReconciliationReport:    "error in getting reconciliation report",
+		MsgUnableToReadSettlementFile:            "unable to read settlement file",
+		MsgErrorInImportingSettlementFile:        "error in importing settlement file",
+		MsgSettlementFileReconcileFailed:         "settlement file imported, reconciliation failed, retry it",
+		MsgErrorInListingSettlementImports:       "error in listing settlement imports",
+		MsgErrorInReconcilingSettlementFile:      "error in reconciling settlement file",
+		MsgSettlementRecordOrTransactionNotFound: "settlement record or transaction not found",
+		MsgErrorInResolvingSettlementRecord:      "error in resolving settlement record",
+		MsgWalletIsBusyRetryTheRequest:           "wallet is busy, retry the request",
+		MsgWalletShardCount:                      "a wallet has between 1 and %d shards",
+		MsgWalletShardsCanOnlyBeAdded:            "wallet shards can only be added",
+		MsgWalletShardCannotBeSharded:            "a wallet shard cannot be sharded",
+		MsgErrorInQueueingTransfer:               "error in queueing transfer",
+		MsgInvalidTransferInBatch:                "invalid transfer in batch",
+		MsgPendingTransactionNotFound:            "pending transaction not found",
+		MsgErrorInGettingPendingTransaction:      "error in getting pending transaction",
+		MsgTransferBatchNotFound:                 "transfer batch not found",
+		MsgErrorInGettingTransferBatch:           "error in getting transfer batch",
+		MsgPayoutHasNoRows:                       "payout has no rows",
+		MsgPayoutIsInvalid:                       "payout is invalid",
+		MsgPayoutTooBig:                          "a payout has at most %d rows",
+		MsgPayoutsInternalOnly:                   "payouts are restricted to internal roles",
+		MsgPurposeCodeNotAllowedForPayouts:       "purpose code not allowed for payouts",
+		MsgErrorInCreatingPayout:                 "error in creating payout",
+		MsgTreasuryWalletNotFound:                "treasury wallet not found",
+		MsgUnableToReadPayoutFile:                "unable to read payout file",
+		MsgPayoutNotFound:                        "payout not found",
+		MsgErrorInGettingPayout:                  "error in getting payout",
+		MsgErrorInListingPayoutItems:             "error in listing payout items",
+		MsgErrorInExportingPayoutResult:          "error in exporting payout result",
+		MsgScheduleHasNoFutureOccurrence:         "schedule has no future occurrence",
+		MsgScheduledTransferIsNotActive:          "scheduled transfer is not active",
+		MsgScheduledTransferIsNotPaused:          "scheduled transfer is not paused",
+		MsgScheduledTransferHasAlreadyEnded:      "scheduled transfer has already ended",
+		MsgInvalidRecurrence:                     "invalid recurrence",
+		MsgErrorInCreatingScheduledTransfer:      "error in creating scheduled transfer",
+		MsgScheduledTransferNotFound:             "scheduled transfer not found",
+		MsgErrorInGettingScheduledTransfer:       "error in getting scheduled transfer",
+		MsgErrorInListingScheduledTransfers:      "error in listing scheduled transfers",
+		MsgErrorInUpdatingScheduledTransfer:      "error in updating scheduled transfer",
+		MsgPaymentRequestToSelf:                  "cannot request a payment from yourself",
+		MsgPaymentRequestIsNoLongerPending:       "payment request is no longer pending",
+		MsgPaymentRequestHasExpired:              "payment request has expired",
+		MsgInvalidExpiry:                         "invalid expiry",
+		MsgPayerWalletNotFound:                   "payer wallet not found",
+		MsgErrorInGettingPayerWallet:             "error in getting payer wallet",
+		MsgErrorInCreatingPaymentRequest:         "error in creating payment request",
+		MsgErrorInListingPaymentRequests:         "error in listing payment requests",
+		MsgPaymentRequestNotFound:                "payment request not found",
+		MsgErrorInGettingPaymentRequest:          "error in getting payment request",
+		MsgErrorInRespondingToPaymentRequest:     "error in responding to payment request",
+		MsgPaymentRequestPayerOnly:               "only the payer can respond to a payment request",
+		MsgPaymentRequestRequesterOnly:           "only the requester can cancel a payment request",
+		MsgSplitSharesExceedTheTotal:             "split shares exceed the total",
+		MsgSplitAmountsPartial:                   "either every payer has an amount or none",
+		MsgTotalIsTooSmallToSplit:                "total is too small to split",
+		MsgPayerAppearsTwiceInTheSplit:           "a payer appears twice in the split",
+		MsgInvalidPayerInSplit:                   "invalid payer in split",
+		MsgErrorInCreatingSplitBill:              "error in creating split bill",
+		MsgSplitBillNotFound:                     "split bill not found",
+		MsgErrorInGettingSplitBill:               "error in getting split bill",
+		MsgInvalidHandle:                         "invalid handle",
+		MsgHandleIsAlreadyTaken:                  "handle is already taken",
+		MsgHandleNotFound:                        "handle not found",
+		MsgErrorInGettingHandle:                  "error in getting handle",
+		MsgErrorInSettingHandle:                  "error in setting handle",
+		MsgErrorInDeletingHandle:                 "error in deleting handle",
+		MsgPaymentQRCodesAreNotEnabled:           "payment qr codes are not enabled",
+		MsgPaymentQRNeedsHandle:                  "set a handle before creating a payment qr code",
+		MsgErrorInRenderingPaymentQRCode:         "error in rendering payment qr code",
+		MsgInvalidPaymentQRCode:                  "invalid payment qr code",
+		MsgPaymentQRCodeHasExpired:               "payment qr code has expired",
+		MsgPaymentQRCodeIsNoLongerValid:          "payment qr code is no longer valid",
+		MsgMerchantPaysOwnCheckoutSession:        "a merchant cannot pay its own checkout session",
+		MsgMerchantProfileExists:                 "account already has a merchant profile",
+		MsgCheckoutSessionHasExpired:             "checkout session has expired",
+		MsgCheckoutSessionHasNotBeenPaid:         "checkout session has not been paid",
+		MsgCheckoutSessionIsNoLongerOpen:         "checkout session is no longer open",
+		MsgCheckoutSessionNotFound:               "checkout session not found",
+		MsgErrorInCreatingMerchant:               "error in creating merchant",
+		MsgErrorInGettingCheckoutSession:         "error in getting checkout session",
+		MsgErrorInGettingMerchantWallet:          "error in getting merchant wallet",
+		MsgErrorInGettingMerchant:                "error in getting merchant",
+		MsgErrorInListingCheckoutSessions:        "error in listing checkout sessions",
+		MsgErrorInUpdatingMerchant:               "error in updating merchant",
+		MsgMerchantProfileNotFound:               "merchant profile not found",
+		MsgMerchantWalletNotFound:                "merchant wallet not found",
+		MsgOnlyMerchantsCanDoThis:                "only merchants can do this",
+		MsgCheckoutSessionMerchantOnly:           "only the merchant can change a checkout session",
+		MsgOrderAlreadyHasACheckoutSession:       "order already has a checkout session",
+		MsgRefundExceedsPayment:                  "refund exceeds what is left of the payment",
+		MsgErrorInCreatingCheckoutSession:        "error in creating checkout session",
+		MsgErrorInPayingCheckoutSession:          "error in paying checkout session",
+		MsgErrorInCancellingCheckoutSession:      "error in cancelling checkout session",
+		MsgErrorInRefundingCheckoutSession:       "error in refunding checkout session",
+		MsgFeeSchedulesInternalOnly:              "fee schedules are restricted to internal roles",
+		MsgPurposeCodeNotAllowedForFeeSchedules:  "purpose code not allowed for fee schedules",
+		MsgMinimumFeeExceedsMaximumFee:           "minimum fee exceeds maximum fee",
+		MsgOnlyTheLastFeeTierCanBeUnbounded:      "only the last fee tier can be unbounded",
+		MsgFeeTiersMustBeSortedByAmount:          "fee tiers must be sorted by amount",
+		MsgMerchantNotFound:                      "merchant not found",
+		MsgFeeScheduleNotFound:                   "fee schedule not found",
+		MsgErrorInGettingFeeSchedule:             "error in getting fee schedule",
+		MsgErrorInCreatingFeeSchedule:            "error in creating fee schedule",
+		MsgErrorInListingFeeSchedules:            "error in listing fee schedules",
+		MsgErrorInDeletingFeeSchedule:            "error in deleting fee schedule",
+		MsgErrorInQuotingFee:                     "error in quoting fee",
+		MsgSettlementBatchNotFound:               "settlement batch not found",
+		MsgErrorInGettingSettlementBatch:         "error in getting settlement batch",
+		MsgErrorInListingSettlementBatches:       "error in listing settlement batches",
+		MsgErrorInGettingSettlementReport:        "error in getting settlement report",
+	},
+	language.Spanish: {
+		MsgInternalServerError:                   "error interno del servidor",
+		MsgRouteNotFound:                         "ruta no encontrada",
+		MsgRequestHasInvalidFields:               "la solicitud tiene campos no válidos",
+		MsgInvalidRole:                           "rol no válido",
+		MsgInvalidOTPProvided:                    "el otp proporcionado no es válido",
+		MsgMissingAccessToken:                    "falta el token de acceso",
+		MsgInvalidAccessToken:                    "token de acceso no válido",
+		MsgInvalidRefreshToken:                   "token de actualización no válido",
+		MsgFullAuthScopedTokenRequired:           "se requiere un token con autenticación completa",
+		MsgPartialAuthScopedTokenRequired:        "se requiere un token con autenticación parcial",
+		MsgErrorInCommitting:                     "error al confirmar",
+		MsgErrorInCreatingAccount:                "error al crear la cuenta",
+		MsgErrorInCreatingWallet:                 "error al crear la billetera",
+		MsgErrorInCreatingOTPSecret:              "error al crear el secreto otp",
+		MsgUnableToValidateUserOTP:               "no se pudo validar el otp del usuario",
+		MsgErrorInCreatingAccessToken:            "error al crear el token de acceso",
+		MsgUnableToCreateAccessToken:             "no se pudo crear el token de acceso",
+		MsgUnableToCreateRefreshToken:            "no se pudo crear el token de actualización",
+		MsgUnableToValidateRequest:               "no se pudo validar la solicitud",
+		MsgErrorInGettingWallet:                  "error al obtener la billetera",
+		MsgErrorInGettingBalance:                 "error al obtener el saldo",
+		MsgErrorInGettingDestinationWallet:       "error al obtener la billetera de destino",
+		MsgErrorInCreatingTransfer:               "error al crear la transferencia",
+		MsgErrorInGettingIdempotencyKey:          "error al obtener la clave de idempotencia",
+		MsgErrorInReservingIdempotencyKey:        "error al reservar la clave de idempotencia",
+		MsgErrorInVerifyingWalletChain:           "error al verificar la cadena de la billetera",
+		MsgErrorInGettingLedgerCheckpoint:        "error al obtener el punto de control del libro mayor",
+		MsgErrorInGettingStatement:               "error al obtener el extracto",
+		MsgInvalidPageToken:                      "token de página no válido",
+		MsgToWalletUUIDIsARequiredField:          "to_wallet_uuid es un campo obligatorio",
+		MsgAmountInCentsMustBeGreaterThanZero:    "amount_in_cents debe ser mayor que cero",
+		MsgErrorInGettingTransfer:                "error al obtener la transferencia",
+		MsgErrorInListingTransactions:            "error al listar las transacciones",
+		MsgReferenceIDIsARequiredField:           "reference_id es un campo obligatorio",
+		MsgWalletNotFound:                        "billetera no encontrada",
+		MsgDestinationWalletNotFound:             "billetera de destino no encontrada",
+		MsgTransferNotFound:                      "transferencia no encontrada",
+		MsgInvalidPurposeCode:                    "código de propósito no válido",
+		MsgPurposeCodeNotAllowedForRole:          "código de propósito no permitido para el rol",
+		MsgInsufficientFunds:                     "fondos insuficientes",
+		MsgCannotTransferToTheSameWallet:         "no se puede transferir a la misma billetera",
+		MsgWalletCurrenciesDoNotMatch:            "las monedas de las billeteras no coinciden",
+		MsgInvalidLastEventID:                    "id del último evento no válido",
+		MsgInvalidStreamTicket:                   "ticket de flujo no válido, usado o caducado",
+		MsgErrorInCreatingStreamTicket:           "error al crear el ticket de flujo",
+		MsgErrorInRedeemingStreamTicket:          "error al canjear el ticket de flujo",
+		MsgIdempotencyKeyIsTooLong:               "la clave de idempotencia es demasiado larga",
+		MsgIdempotencyKeyReused:                  "la clave de idempotencia ya se usó con un cuerpo de solicitud diferente",
+		MsgIdempotencyKeyInProgress:              "una solicitud con esta clave de idempotencia aún está en curso",
+		MsgUnreadableBody:                        "no se pudo leer el cuerpo de la solicitud, revise el formato de la solicitud",
+		MsgUnreadableQuery:                       "no se pudieron leer los parámetros de la consulta, revise el formato de la solicitud",
+		MsgStatementTooManyTransactions:          "el extracto tiene demasiadas transacciones, acorte el periodo",
+		MsgAccountNotFound:                       "cuenta no encontrada",
+		MsgErrorInGettingAccount:                 "error al obtener la cuenta",
+		MsgErrorInExportingStatement:             "error al exportar el extracto",
+		MsgStatementTooLargeForPDF:               "el extracto es demasiado grande para un PDF, expórtelo como CSV",
+		MsgSettlementFileHasNoRecords:            "el archivo de liquidación no tiene registros",
+		MsgSettlementFileIsInvalid:               "el archivo de liquidación no es válido",
+		MsgSettlementFileWasAlreadyImported:      "el archivo de liquidación ya fue importado",
+		MsgResolutionNoteIsRequired:              "se requiere una nota de resolución",
+		MsgInvalidResolution:                     "resolución no válida, se espera MATCH, ACCEPT, IGNORE o REOPEN",
+		MsgSettlementRecordResolved:              "el registro de liquidación ya está resuelto, reábralo primero",
+		MsgSettlementRecordNotMismatched:         "solo se pueden aceptar registros de liquidación con discrepancias",
+		MsgTransactionAlreadyMatched:             "la transacción ya está conciliada con otro registro de liquidación",
+		MsgTransactionDirectionMismatch:          "la transacción debe ser exitosa y en la dirección del registro de liquidación",
+		MsgReconciliationInternalOnly:            "la conciliación está restringida a roles internos",
+		MsgSettlementImportNotFound:              "importación de liquidación no encontrada",
+		MsgErrorInGettingSettlementImport:        "error al obtener la importación de liquidación",
+		MsgErrorInGettingReconciliationReport:    "error al obtener el informe de conciliación",
+		MsgUnableToReadSettlementFile:            "no se pudo leer el archivo de liquidación",
+		MsgErrorInImportingSettlementFile:        "error al importar el archivo de liquidación",
+		MsgSettlementFileReconcileFailed:         "archivo de liquidación importado, la conciliación falló, reinténtela",
+		MsgErrorInListingSettlementImports:       "error al listar las importaciones de liquidación",
+		MsgErrorInReconcilingSettlementFile:      "error al conciliar el archivo de liquidación",
+		MsgSettlementRecordOrTransactionNotFound: "registro de liquidación o transacción no encontrado",
+		MsgErrorInResolvingSettlementRecord:      "error al resolver el registro de liquidación",
+		MsgWalletIsBusyRetryTheRequest:           "la billetera está ocupada, reintente la solicitud",
+		MsgWalletShardCount:                      "una billetera tiene entre 1 y %d fragmentos",
+		MsgWalletShardsCanOnlyBeAdded:            "los fragmentos de una billetera solo se pueden agregar",
+		MsgWalletShardCannotBeSharded:            "un fragmento de billetera no se puede fragmentar",
+		MsgErrorInQueueingTransfer:               "error al poner en cola la transferencia",
+		MsgInvalidTransferInBatch:                "transferencia no válida en el lote",
+		MsgPendingTransactionNotFound:            "transacción pendiente no encontrada",
+		MsgErrorInGettingPendingTransaction:      "error al obtener la transacción pendiente",
+		MsgTransferBatchNotFound:                 "lote de transferencias no encontrado",
+		MsgErrorInGettingTransferBatch:           "error al obtener el lote de transferencias",
+		MsgPayoutHasNoRows:                       "el pago masivo no tiene filas",
+		MsgPayoutIsInvalid:                       "el pago masivo no es válido",
+		MsgPayoutTooBig:                          "un pago masivo tiene como máximo %d filas",
+		MsgPayoutsInternalOnly:                   "los pagos masivos están restringidos a roles internos",
+		MsgPurposeCodeNotAllowedForPayouts:       "código de propósito no permitido para pagos masivos",
+		MsgErrorInCreatingPayout:                 "error al crear el pago masivo",
+		MsgTreasuryWalletNotFound:                "billetera de tesorería no encontrada",
+		MsgUnableToReadPayoutFile:                "no se puede leer el archivo de pago masivo",
+		MsgPayoutNotFound:                        "pago masivo no encontrado",
+		MsgErrorInGettingPayout:                  "error al obtener el pago masivo",
+		MsgErrorInListingPayoutItems:             "error al listar las filas del pago masivo",
+		MsgErrorInExportingPayoutResult:          "error al exportar el resultado del pago masivo",
+		MsgScheduleHasNoFutureOccurrence:         "la programación no tiene ninguna ocurrencia futura",
+		MsgScheduledTransferIsNotActive:          "la transferencia programada no está activa",
+		MsgScheduledTransferIsNotPaused:          "la transferencia programada no está en pausa",
+		MsgScheduledTransferHasAlreadyEnded:      "la transferencia programada ya ha terminado",
+		MsgInvalidRecurrence:                     "recurrencia no válida",
+		MsgErrorInCreatingScheduledTransfer:      "error al crear la transferencia programada",
+		MsgScheduledTransferNotFound:             "transferencia programada no encontrada",
+		MsgErrorInGettingScheduledTransfer:       "error al obtener la transferencia programada",
+		MsgErrorInListingScheduledTransfers:      "error al listar las transferencias programadas",
+		MsgErrorInUpdatingScheduledTransfer:      "error al actualizar la transferencia programada",
+		MsgPaymentRequestToSelf:                  "no puedes solicitar un pago a ti mismo",
+		MsgPaymentRequestIsNoLongerPending:       "la solicitud de pago ya no está pendiente",
+		MsgPaymentRequestHasExpired:              "la solicitud de pago ha expirado",
+		MsgInvalidExpiry:                         "vencimiento no válido",
+		MsgPayerWalletNotFound:                   "billetera del pagador no encontrada",
+		MsgErrorInGettingPayerWallet:             "error al obtener la billetera del pagador",
+		MsgErrorInCreatingPaymentRequest:         "error al crear la solicitud de pago",
+		MsgErrorInListingPaymentRequests:         "error al listar las solicitudes de pago",
+		MsgPaymentRequestNotFound:                "solicitud de pago no encontrada",
+		MsgErrorInGettingPaymentRequest:          "error al obtener la solicitud de pago",
+		MsgErrorInRespondingToPaymentRequest:     "error al responder a la solicitud de pago",
+		MsgPaymentRequestPayerOnly:               "solo el pagador puede responder a una solicitud de pago",
+		MsgPaymentRequestRequesterOnly:           "solo el solicitante puede cancelar una solicitud de pago",
+		MsgSplitSharesExceedTheTotal:             "las partes de la división superan el total",
+		MsgSplitAmountsPartial:                   "o todos los pagadores tienen un importe o ninguno",
+		MsgTotalIsTooSmallToSplit:                "el total es demasiado pequeño para dividirlo",
+		MsgPayerAppearsTwiceInTheSplit:           "un pagador aparece dos veces en la división",
+		MsgInvalidPayerInSplit:                   "pagador no válido en la división",
+		MsgErrorInCreatingSplitBill:              "error al crear la cuenta dividida",
+		MsgSplitBillNotFound:                     "cuenta dividida no encontrada",
+		MsgErrorInGettingSplitBill:               "error al obtener la cuenta dividida",
+		MsgInvalidHandle:                         "identificador no válido",
+		MsgHandleIsAlreadyTaken:                  "el identificador ya está en uso",
+		MsgHandleNotFound:                        "identificador no encontrado",
+		MsgErrorInGettingHandle:                  "error al obtener el identificador",
+		MsgErrorInSettingHandle:                  "error al establecer el identificador",
+		MsgErrorInDeletingHandle:                 "error al eliminar el identificador",
+		MsgPaymentQRCodesAreNotEnabled:           "los códigos QR de pago no están habilitados",
+		MsgPaymentQRNeedsHandle:                  "establece un identificador antes de crear un código QR de pago",
+		MsgErrorInRenderingPaymentQRCode:         "error al generar el código QR de pago",
+		MsgInvalidPaymentQRCode:                  "código QR de pago no válido",
+		MsgPaymentQRCodeHasExpired:               "el código QR de pago ha expirado",
+		MsgPaymentQRCodeIsNoLongerValid:          "el código QR de pago ya no es válido",
+		MsgMerchantPaysOwnCheckoutSession:        "un comercio no puede pagar su propia sesión de pago",
+		MsgMerchantProfileExists:                 "la cuenta ya tiene un perfil de comercio",
+		MsgCheckoutSessionHasExpired:             "la sesión de pago ha caducado",
+		MsgCheckoutSessionHasNotBeenPaid:         "la sesión de pago no se ha pagado",
+		MsgCheckoutSessionIsNoLongerOpen:         "la sesión de pago ya no está abierta",
+		MsgCheckoutSessionNotFound:               "sesión de pago no encontrada",
+		MsgErrorInCreatingMerchant:               "error al crear el comercio",
+		MsgErrorInGettingCheckoutSession:         "error al obtener la sesión de pago",
+		MsgErrorInGettingMerchantWallet:          "error al obtener la billetera del comercio",
+		MsgErrorInGettingMerchant:                "error al obtener el comercio",
+		MsgErrorInListingCheckoutSessions:        "error al listar las sesiones de pago",
+		MsgErrorInUpdatingMerchant:               "error al actualizar el comercio",
+		MsgMerchantProfileNotFound:               "perfil de comercio no encontrado",
+		MsgMerchantWalletNotFound:                "billetera del comercio no encontrada",
+		MsgOnlyMerchantsCanDoThis:                "solo los comercios pueden hacer esto",
+		MsgCheckoutSessionMerchantOnly:           "solo el comercio puede modificar una sesión de pago",
+		MsgOrderAlreadyHasACheckoutSession:       "el pedido ya tiene una sesión de pago",
+		MsgRefundExceedsPayment:                  "el reembolso supera lo que queda del pago",
+		MsgErrorInCreatingCheckoutSession:        "error al crear la sesión de pago",
+		MsgErrorInPayingCheckoutSession:          "error al pagar la sesión de pago",
+		MsgErrorInCancellingCheckoutSession:      "error al cancelar la sesión de pago",
+		MsgErrorInRefundingCheckoutSession:       "error al reembolsar la sesión de pago",
+		MsgFeeSchedulesInternalOnly:              "las tarifas están restringidas a los roles internos",
+		MsgPurposeCodeNotAllowedForFeeSchedules:  "código de propósito no permitido para tarifas",
+		MsgMinimumFeeExceedsMaximumFee:           "la tarifa mínima excede la tarifa máxima",
+		MsgOnlyTheLastFeeTierCanBeUnbounded:      "solo el último tramo de tarifa puede no tener límite",
+		MsgFeeTiersMustBeSortedByAmount:          "los tramos de tarifa deben estar ordenados por monto",
+		MsgMerchantNotFound:                      "comercio no encontrado",
+		MsgFeeScheduleNotFound:                   "tarifa no encontrada",
+		MsgErrorInGettingFeeSchedule:             "error al obtener la tarifa",
+		MsgErrorInCreatingFeeSchedule:            "error al crear la tarifa",
+		MsgErrorInListingFeeSchedules:            "error al listar las tarifas",
+		MsgErrorInDeletingFeeSchedule:            "error al eliminar la tarifa",
+		MsgErrorInQuotingFee:                     "error al cotizar la tarifa",
+		MsgSettlementBatchNotFound:               "lote de liquidación no encontrado",
+		MsgErrorInGettingSettlementBatch:         "error al obtener el lote de liquidación",
+		MsgErrorInListingSettlementBatches:       "error al listar los lotes de liquidación",
+		MsgErrorInGettingSettlementReport:        "error al obtener el informe de liquidación",
+	},
+	language.French: {
+		MsgInternalServerError:                   "erreur interne du serveur",
+		MsgRouteNotFound:                         "route introuvable",
+		MsgRequestHasInvalidFields:               "la requête contient des champs invalides",
+		MsgInvalidRole:                           "rôle invalide",
+		MsgInvalidOTPProvided:                    "l'otp fourni est invalide",
+		MsgMissingAccessToken:                    "jeton d'accès manquant",
+		MsgInvalidAccessToken:                    "jeton d'accès invalide",
+		MsgInvalidRefreshToken:                   "jeton de rafraîchissement invalide",
+		MsgFullAuthScopedTokenRequired:           "un jeton d'authentification complète est requis",
+		MsgPartialAuthScopedTokenRequired:        "un jeton d'authentification partielle est requis",
+		MsgErrorInCommitting:                     "erreur lors de la validation",
+		MsgErrorInCreatingAccount:                "erreur lors de la création du compte",
+		MsgErrorInCreatingWallet:                 "erreur lors de la création du portefeuille",
+		MsgErrorInCreatingOTPSecret:              "erreur lors de la création du secret otp",
+		MsgUnableToValidateUserOTP:               "impossible de valider l'otp de l'utilisateur",
+		MsgErrorInCreatingAccessToken:            "erreur lors de la création du jeton d'accès",
+		MsgUnableToCreateAccessToken:             "impossible de créer le jeton d'accès",
+		MsgUnableToCreateRefreshToken:            "impossible de créer le jeton de rafraîchissement",
+		MsgUnableToValidateRequest:               "impossible de valider la requête",
+		MsgErrorInGettingWallet:                  "erreur lors de la récupération du portefeuille",
+		MsgErrorInGettingBalance:                 "erreur lors de la récupération du solde",
+		MsgErrorInGettingDestinationWallet:       "erreur lors de la récupération du portefeuille de destination",
+		MsgErrorInCreatingTransfer:               "erreur lors de la création du virement",
+		MsgErrorInGettingIdempotencyKey:          "erreur lors de la récupération de la clé d'idempotence",
+		MsgErrorInReservingIdempotencyKey:        "erreur lors de la réservation de la clé d'idempotence",
+		MsgErrorInVerifyingWalletChain:           "erreur lors de la vérification de la chaîne du portefeuille",
+		MsgErrorInGettingLedgerCheckpoint:        "erreur lors de la récupération du point de contrôle du grand livre",
+		MsgErrorInGettingStatement:               "erreur lors de la récupération du relevé",
+		MsgInvalidPageToken:                      "jeton de page invalide",
+		MsgToWalletUUIDIsARequiredField:          "to_wallet_uuid est un champ obligatoire",
+		MsgAmountInCentsMustBeGreaterThanZero:    "amount_in_cents doit être supérieur à zéro",
+		MsgErrorInGettingTransfer:                "erreur lors de la récupération du virement",
+		MsgErrorInListingTransactions:            "erreur lors de la liste des transactions",
+		MsgReferenceIDIsARequiredField:           "reference_id est un champ obligatoire",
+		MsgWalletNotFound:                        "portefeuille introuvable",
+		MsgDestinationWalletNotFound:             "portefeuille de destination introuvable",
+		MsgTransferNotFound:                      "virement introuvable",
+		MsgInvalidPurposeCode:                    "code de motif invalide",
+		MsgPurposeCodeNotAllowedForRole:          "code de motif non autorisé pour ce rôle",
+		MsgInsufficientFunds:                     "fonds insuffisants",
+		MsgCannotTransferToTheSameWallet:         "impossible de virer vers le même portefeuille",
+		MsgWalletCurrenciesDoNotMatch:            "les devises des portefeuilles ne correspondent pas",
+		MsgInvalidLastEventID:                    "identifiant du dernier événement invalide",
+		MsgInvalidStreamTicket:                   "ticket de flux invalide, utilisé ou expiré",
+		MsgErrorInCreatingStreamTicket:           "erreur lors de la création du ticket de flux",
+		MsgErrorInRedeemingStreamTicket:          "erreur lors de l'utilisation du ticket de flux",
+		MsgIdempotencyKeyIsTooLong:               "la clé d'idempotence est trop longue",
+		MsgIdempotencyKeyReused:                  "la clé d'idempotence a déjà été utilisée avec un corps de requête différent",
+		MsgIdempotencyKeyInProgress:              "une requête avec cette clé d'idempotence est toujours en cours",
+		MsgUnreadableBody:                        "impossible de lire le corps de la requête, vérifiez le format de la requête",
+		MsgUnreadableQuery:                       "impossible de lire les paramètres de la requête, vérifiez le format de la requête",
+		MsgStatementTooManyTransactions:          "le relevé contient trop de transactions, réduisez la période",
+		MsgAccountNotFound:                       "compte introuvable",
+		MsgErrorInGettingAccount:                 "erreur lors de la récupération du compte",
+		MsgErrorInExportingStatement:             "erreur lors de l'export du relevé",
+		MsgStatementTooLargeForPDF:               "le relevé est trop volumineux pour un PDF, exportez-le en CSV",
+		MsgSettlementFileHasNoRecords:            "le fichier de règlement ne contient aucun enregistrement",
+		MsgSettlementFileIsInvalid:               "le fichier de règlement est invalide",
+		MsgSettlementFileWasAlreadyImported:      "le fichier de règlement a déjà été importé",
+		MsgResolutionNoteIsRequired:              "une note de résolution est requise",
+		MsgInvalidResolution:                     "résolution invalide, MATCH, ACCEPT, IGNORE ou REOPEN attendu",
+		MsgSettlementRecordResolved:              "l'enregistrement de règlement est déjà résolu, rouvrez-le d'abord",
+		MsgSettlementRecordNotMismatched:         "seuls les enregistrements de règlement discordants peuvent être acceptés",
+		MsgTransactionAlreadyMatched:             "la transaction est déjà rapprochée d'un autre enregistrement de règlement",
+		MsgTransactionDirectionMismatch:          "la transaction doit avoir réussi et aller dans le sens de l'enregistrement de règlement",
+		MsgReconciliationInternalOnly:            "le rapprochement est réservé aux rôles internes",
+		MsgSettlementImportNotFound:              "import de règlement introuvable",
+		MsgErrorInGettingSettlementImport:        "erreur lors de la récupération de l'import de règlement",
+		MsgErrorInGettingReconciliationReport:    "erreur lors de la récupération du rapport de rapprochement",
+		MsgUnableToReadSettlementFile:            "impossible de lire le fichier de règlement",
+		MsgErrorInImportingSettlementFile:        "erreur lors de l'import du fichier de règlement",
+		MsgSettlementFileReconcileFailed:         "fichier de règlement importé, le rapprochement a échoué, relancez-le",
+		MsgErrorInListingSettlementImports:       "erreur lors de la liste des imports de règlement",
+		MsgErrorInReconcilingSettlementFile:      "erreur lors du rapprochement du fichier de règlement",
+		MsgSettlementRecordOrTransactionNotFound: "enregistrement de règlement ou transaction introuvable",
+		MsgErrorInResolvingSettlementRecord:      "erreur lors de la résolution de l'enregistrement de règlement",
+		MsgWalletIsBusyRetryTheRequest:           "le portefeuille est occupé, réessayez la requête",
+		MsgWalletShardCount:                      "un portefeuille a entre 1 et %d fragments",
+		MsgWalletShardsCanOnlyBeAdded:            "les fragments de portefeuille peuvent seulement être ajoutés",
+		MsgWalletShardCannotBeSharded:            "un fragment de portefeuille ne peut pas être fragmenté",
+		MsgErrorInQueueingTransfer:               "erreur lors de la mise en file d'attente du transfert",
+		MsgInvalidTransferInBatch:                "transfert invalide dans le lot",
+		MsgPendingTransactionNotFound:            "transaction en attente introuvable",
+		MsgErrorInGettingPendingTransaction:      "erreur lors de la récupération de la transaction en attente",
+		MsgTransferBatchNotFound:                 "lot de transferts introuvable",
+		MsgErrorInGettingTransferBatch:           "erreur lors de la récupération du lot de transferts",
+		MsgPayoutHasNoRows:                       "le versement groupé ne contient aucune ligne",
+		MsgPayoutIsInvalid:                       "le versement groupé est invalide",
+		MsgPayoutTooBig:                          "un versement groupé contient au plus %d lignes",
+		MsgPayoutsInternalOnly:                   "les versements groupés sont réservés aux rôles internes",
+		MsgPurposeCodeNotAllowedForPayouts:       "code de motif non autorisé pour les versements groupés",
+		MsgErrorInCreatingPayout:                 "erreur lors de la création du versement groupé",
+		MsgTreasuryWalletNotFound:                "portefeuille de trésorerie introuvable",
+		MsgUnableToReadPayoutFile:                "impossible de lire le fichier de versement groupé",
+		MsgPayoutNotFound:                        "versement groupé introuvable",
+		MsgErrorInGettingPayout:                  "erreur lors de la récupération du versement groupé",
+		MsgErrorInListingPayoutItems:             "erreur lors du listage des lignes du versement groupé",
+		MsgErrorInExportingPayoutResult:          "erreur lors de l'exportation du résultat du versement groupé",
+		MsgScheduleHasNoFutureOccurrence:         "la planification n'a aucune occurrence à venir",
+		MsgScheduledTransferIsNotActive:          "le virement programmé n'est pas actif",
+		MsgScheduledTransferIsNotPaused:          "le virement programmé n'est pas en pause",
+		MsgScheduledTransferHasAlreadyEnded:      "le virement programmé est déjà terminé",
+		MsgInvalidRecurrence:                     "récurrence invalide",
+		MsgErrorInCreatingScheduledTransfer:      "erreur lors de la création du virement programmé",
+		MsgScheduledTransferNotFound:             "virement programmé introuvable",
+		MsgErrorInGettingScheduledTransfer:       "erreur lors de la récupération du virement programmé",
+		MsgErrorInListingScheduledTransfers:      "erreur lors du listage des virements programmés",
+		MsgErrorInUpdatingScheduledTransfer:      "erreur lors de la mise à jour du virement programmé",
+		MsgPaymentRequestToSelf:                  "vous ne pouvez pas vous demander un paiement à vous-même",
+		MsgPaymentRequestIsNoLongerPending:       "la demande de paiement n'est plus en attente",
+		MsgPaymentRequestHasExpired:              "la demande de paiement a expiré",
+		MsgInvalidExpiry:                         "expiration invalide",
+		MsgPayerWalletNotFound:                   "portefeuille du payeur introuvable",
+		MsgErrorInGettingPayerWallet:             "erreur lors de la récupération du portefeuille du payeur",
+		MsgErrorInCreatingPaymentRequest:         "erreur lors de la création de la demande de paiement",
+		MsgErrorInListingPaymentRequests:         "erreur lors de la liste des demandes de paiement",
+		MsgPaymentRequestNotFound:                "demande de paiement introuvable",
+		MsgErrorInGettingPaymentRequest:          "erreur lors de la récupération de la demande de paiement",
+		MsgErrorInRespondingToPaymentRequest:     "erreur lors de la réponse à la demande de paiement",
+		MsgPaymentRequestPayerOnly:               "seul le payeur peut répondre à une demande de paiement",
+		MsgPaymentRequestRequesterOnly:           "seul le demandeur peut annuler une demande de paiement",
+		MsgSplitSharesExceedTheTotal:             "les parts du partage dépassent le total",
+		MsgSplitAmountsPartial:                   "soit chaque payeur a un montant, soit aucun",
+		MsgTotalIsTooSmallToSplit:                "le total est trop petit pour être partagé",
+		MsgPayerAppearsTwiceInTheSplit:           "un payeur apparaît deux fois dans le partage",
+		MsgInvalidPayerInSplit:                   "payeur invalide dans le partage",
+		MsgErrorInCreatingSplitBill:              "erreur lors de la création de la note partagée",
+		MsgSplitBillNotFound:                     "note partagée introuvable",
+		MsgErrorInGettingSplitBill:               "erreur lors de la récupération de la note partagée",
+		MsgInvalidHandle:                         "identifiant invalide",
+		MsgHandleIsAlreadyTaken:                  "l'identifiant est déjà pris",
+		MsgHandleNotFound:                        "identifiant introuvable",
+		MsgErrorInGettingHandle:                  "erreur lors de la récupération de l'identifiant",
+		MsgErrorInSettingHandle:                  "erreur lors de la définition de l'identifiant",
+		MsgErrorInDeletingHandle:                 "erreur lors de la suppression de l'identifiant",
+		MsgPaymentQRCodesAreNotEnabled:           "les codes QR de paiement ne sont pas activés",
+		MsgPaymentQRNeedsHandle:                  "définissez un identifiant avant de créer un code QR de paiement",
+		MsgErrorInRenderingPaymentQRCode:         "erreur lors du rendu du code QR de paiement",
+		MsgInvalidPaymentQRCode:                  "code QR de paiement invalide",
+		MsgPaymentQRCodeHasExpired:               "le code QR de paiement a expiré",
+		MsgPaymentQRCodeIsNoLongerValid:          "le code QR de paiement n'est plus valide",
+		MsgMerchantPaysOwnCheckoutSession:        "un marchand ne peut pas payer sa propre session de paiement",
+		MsgMerchantProfileExists:                 "le compte a déjà un profil marchand",
+		MsgCheckoutSessionHasExpired:             "la session de paiement a expiré",
+		MsgCheckoutSessionHasNotBeenPaid:         "la session de paiement n'a pas été payée",
+		MsgCheckoutSessionIsNoLongerOpen:         "la session de paiement n'est plus ouverte",
+		MsgCheckoutSessionNotFound:               "session de paiement introuvable",
+		MsgErrorInCreatingMerchant:               "erreur lors de la création du marchand",
+		MsgErrorInGettingCheckoutSession:         "erreur lors de la récupération de la session de paiement",
+		MsgErrorInGettingMerchantWallet:          "erreur lors de la récupération du portefeuille du marchand",
+		MsgErrorInGettingMerchant:                "erreur lors de la récupération du marchand",
+		MsgErrorInListingCheckoutSessions:        "erreur lors de la liste des sessions de paiement",
+		MsgErrorInUpdatingMerchant:               "erreur lors de la mise à jour du marchand",
+		MsgMerchantProfileNotFound:               "profil marchand introuvable",
+		MsgMerchantWalletNotFound:                "portefeuille du marchand introuvable",
+		MsgOnlyMerchantsCanDoThis:                "seuls les marchands peuvent faire cela",
+		MsgCheckoutSessionMerchantOnly:           "seul le marchand peut modifier une session de paiement",
+		MsgOrderAlreadyHasACheckoutSession:       "la commande a déjà une session de paiement",
+		MsgRefundExceedsPayment:                  "le remboursement dépasse le reste du paiement",
+		MsgErrorInCreatingCheckoutSession:        "erreur lors de la création de la session de paiement",
+		MsgErrorInPayingCheckoutSession:          "erreur lors du paiement de la session de paiement",
+		MsgErrorInCancellingCheckoutSession:      "erreur lors de l'annulation de la session de paiement",
+		MsgErrorInRefundingCheckoutSession:       "erreur lors du remboursement de la session de paiement",
+		MsgFeeSchedulesInternalOnly:              "les barèmes de frais sont réservés aux rôles internes",
+		MsgPurposeCodeNotAllowedForFeeSchedules:  "code de motif non autorisé pour les barèmes de frais",
+		MsgMinimumFeeExceedsMaximumFee:           "les frais minimaux dépassent les frais maximaux",
+		MsgOnlyTheLastFeeTierCanBeUnbounded:      "seule la dernière tranche de frais peut être illimitée",
+		MsgFeeTiersMustBeSortedByAmount:          "les tranches de frais doivent être triées par montant",
+		MsgMerchantNotFound:                      "commerçant introuvable",
+		MsgFeeScheduleNotFound:                   "barème de frais introuvable",
+		MsgErrorInGettingFeeSchedule:             "erreur lors de la récupération du barème de frais",
+		MsgErrorInCreatingFeeSchedule:            "erreur lors de la création du barème de frais",
+		MsgErrorInListingFeeSchedules:            "erreur lors de la liste des barèmes de frais",
+		MsgErrorInDeletingFeeSchedule:            "erreur lors de la suppression du barème de frais",
+		MsgErrorInQuotingFee:                     "erreur lors du calcul des frais",
+		MsgSettlementBatchNotFound:               "lot de règlement introuvable",
+		MsgErrorInGettingSettlementBatch:         "erreur lors de la récupération du lot de règlement",
+		MsgErrorInListingSettlementBatches:       "erreur lors de la liste des lots de règlement",
+		MsgErrorInGettingSettlementReport:        "erreur lors de la récupération du rapport de règlement",
+	},
+	language.BrazilianPortuguese: {
+		MsgInternalServerError:                   "erro interno do servidor",
+		MsgRouteNotFound:                         "rota não encontrada",
+		MsgRequestHasInvalidFields:               "a requisição tem campos inválidos",
+		MsgInvalidRole:                           "papel inválido",
+		MsgInvalidOTPProvided:                    "o otp informado é inválido",
+		MsgMissingAccessToken:                    "token de acesso ausente",
+		MsgInvalidAccessToken:                    "token de acesso inválido",
+		MsgInvalidRefreshToken:                   "token de atualização inválido",
+		MsgFullAuthScopedTokenRequired:           "é necessário um token de autenticação completa",
+		MsgPartialAuthScopedTokenRequired:        "é necessário um token de autenticação parcial",
+		MsgErrorInCommitting:                     "erro ao confirmar",
+		MsgErrorInCreatingAccount:                "erro ao criar a conta",
+		MsgErrorInCreatingWallet:                 "erro ao criar a carteira",
+		MsgErrorInCreatingOTPSecret:              "erro ao criar o segredo otp",
+		MsgUnableToValidateUserOTP:               "não foi possível validar o otp do usuário",
+		MsgErrorInCreatingAccessToken:            "erro ao criar o token de acesso",
+		MsgUnableToCreateAccessToken:             "não foi possível criar o token de acesso",
+		MsgUnableToCreateRefreshToken:            "não foi possível criar o token de atualização",
+		MsgUnableToValidateRequest:               "não foi possível validar a solicitação",
+		MsgErrorInGettingWallet:                  "erro ao obter a carteira",
+		MsgErrorInGettingBalance:                 "erro ao obter o saldo",
+		MsgErrorInGettingDestinationWallet:       "erro ao obter a carteira de destino",
+		MsgErrorInCreatingTransfer:               "erro ao criar a transferência",
+		MsgErrorInGettingIdempotencyKey:          "erro ao obter a chave de idempotência",
+		MsgErrorInReservingIdempotencyKey:        "erro ao reservar a chave de idempotência",
+		MsgErrorInVerifyingWalletChain:           "erro ao verificar a cadeia da carteira",
+		MsgErrorInGettingLedgerCheckpoint:        "erro ao obter o ponto de verificação do livro-razão",
+		MsgErrorInGettingStatement:               "erro ao obter o extrato",
+		MsgInvalidPageToken:                      "token de página inválido",
+		MsgToWalletUUIDIsARequiredField:          "to_wallet_uuid é um campo obrigatório",
+		MsgAmountInCentsMustBeGreaterThanZero:    "amount_in_cents deve ser maior que zero",
+		MsgErrorInGettingTransfer:                "erro ao obter a transferência",
+		MsgErrorInListingTransactions:            "erro ao listar as transações",
+		MsgReferenceIDIsARequiredField:           "reference_id é um campo obrigatório",
+		MsgWalletNotFound:                        "carteira não encontrada",
+		MsgDestinationWalletNotFound:             "carteira de destino não encontrada",
+		MsgTransferNotFound:                      "transferência não encontrada",
+		MsgInvalidPurposeCode:                    "código de finalidade inválido",
+		MsgPurposeCodeNotAllowedForRole:          "código de finalidade não permitido para o papel",
+		MsgInsufficientFunds:                     "saldo insuficiente",
+		MsgCannotTransferToTheSameWallet:         "não é possível transferir para a mesma carteira",
+		MsgWalletCurrenciesDoNotMatch:            "as moedas das carteiras não correspondem",
+		MsgInvalidLastEventID:                    "id do último evento inválido",
+		MsgInvalidStreamTicket:                   "ticket de stream inválido, usado ou expirado",
+		MsgErrorInCreatingStreamTicket:           "erro ao criar o ticket de stream",
+		MsgErrorInRedeemingStreamTicket:          "erro ao resgatar o ticket de stream",
+		MsgIdempotencyKeyIsTooLong:               "a chave de idempotência é muito longa",
+		MsgIdempotencyKeyReused:                  "a chave de idempotência já foi usada com um corpo de requisição diferente",
+		MsgIdempotencyKeyInProgress:              "uma requisição com esta chave de idempotência ainda está em andamento",
+		MsgUnreadableBody:                        "não foi possível ler o corpo da requisição, verifique o formato da requisição",
+		MsgUnreadableQuery:                       "não foi possível ler os parâmetros da consulta, verifique o formato da requisição",
+		MsgStatementTooManyTransactions:          "o extrato tem transações demais, reduza o período",
+		MsgAccountNotFound:                       "conta não encontrada",
+		MsgErrorInGettingAccount:                 "erro ao obter a conta",
+		MsgErrorInExportingStatement:             "erro ao exportar o extrato",
+		MsgStatementTooLargeForPDF:               "o extrato é grande demais para um PDF, exporte-o como CSV",
+		MsgSettlementFileHasNoRecords:            "o arquivo de liquidação não tem registros",
+		MsgSettlementFileIsInvalid:               "o arquivo de liquidação é inválido",
+		MsgSettlementFileWasAlreadyImported:      "o arquivo de liquidação já foi importado",
+		MsgResolutionNoteIsRequired:              "uma nota de resolução é obrigatória",
+		MsgInvalidResolution:                     "resolução inválida, esperado MATCH, ACCEPT, IGNORE ou REOPEN",
+		MsgSettlementRecordResolved:              "o registro de liquidação já está resolvido, reabra-o primeiro",
+		MsgSettlementRecordNotMismatched:         "apenas registros de liquidação divergentes podem ser aceitos",
+		MsgTransactionAlreadyMatched:             "a transação já está conciliada com outro registro de liquidação",
+		MsgTransactionDirectionMismatch:          "a transação deve ter sido bem-sucedida e na direção do registro de liquidação",
+		MsgReconciliationInternalOnly:            "a conciliação é restrita a funções internas",
+		MsgSettlementImportNotFound:              "importação de liquidação não encontrada",
+		MsgErrorInGettingSettlementImport:        "erro ao obter a importação de liquidação",
+		MsgErrorInGettingReconciliationReport:    "erro ao obter o relatório de conciliação",
+		MsgUnableToReadSettlementFile:            "não foi possível ler o arquivo de liquidação",
+		MsgErrorInImportingSettlementFile:        "erro ao importar o arquivo de liquidação",
+		MsgSettlementFileReconcileFailed:         "arquivo de liquidação importado, a conciliação falhou, tente novamente",
+		MsgErrorInListingSettlementImports:       "erro ao listar as importações de liquidação",
+		MsgErrorInReconcilingSettlementFile:      "erro ao conciliar o arquivo de liquidação",
+		MsgSettlementRecordOrTransactionNotFound: "registro de liquidação ou transação não encontrado",
+		MsgErrorInResolvingSettlementRecord:      "erro ao resolver o registro de liquidação",
+		MsgWalletIsBusyRetryTheRequest:           "a carteira está ocupada, tente a solicitação novamente",
+		MsgWalletShardCount:                      "uma carteira tem entre 1 e %d fragmentos",
+		MsgWalletShardsCanOnlyBeAdded:            "os fragmentos de carteira só podem ser adicionados",
+		MsgWalletShardCannotBeSharded:            "um fragmento de carteira não pode ser fragmentado",
+		MsgErrorInQueueingTransfer:               "erro ao enfileirar a transferência",
+		MsgInvalidTransferInBatch:                "transferência inválida no lote",
+		MsgPendingTransactionNotFound:            "transação pendente não encontrada",
+		MsgErrorInGettingPendingTransaction:      "erro ao obter a transação pendente",
+		MsgTransferBatchNotFound:                 "lote de transferências não encontrado",
+		MsgErrorInGettingTransferBatch:           "erro ao obter o lote de transferências",
+		MsgPayoutHasNoRows:                       "o pagamento em lote não tem linhas",
+		MsgPayoutIsInvalid:                       "o pagamento em lote é inválido",
+		MsgPayoutTooBig:                          "um pagamento em lote tem no máximo %d linhas",
+		MsgPayoutsInternalOnly:                   "pagamentos em lote são restritos a funções internas",
+		MsgPurposeCodeNotAllowedForPayouts:       "código de finalidade não permitido para pagamentos em lote",
+		MsgErrorInCreatingPayout:                 "erro ao criar o pagamento em lote",
+		MsgTreasuryWalletNotFound:                "carteira de tesouraria não encontrada",
+		MsgUnableToReadPayoutFile:                "não foi possível ler o arquivo de pagamento em lote",
+		MsgPayoutNotFound:                        "pagamento em lote não encontrado",
+		MsgErrorInGettingPayout:                  "erro ao obter o pagamento em lote",
+		MsgErrorInListingPayoutItems:             "erro ao listar as linhas do pagamento em lote",
+		MsgErrorInExportingPayoutResult:          "erro ao exportar o resultado do pagamento em lote",
+		MsgScheduleHasNoFutureOccurrence:         "o agendamento não tem ocorrências futuras",
+		MsgScheduledTransferIsNotActive:          "a transferência agendada não está ativa",
+		MsgScheduledTransferIsNotPaused:          "a transferência agendada não está pausada",
+		MsgScheduledTransferHasAlreadyEnded:      "a transferência agendada já terminou",
+		MsgInvalidRecurrence:                     "recorrência inválida",
+		MsgErrorInCreatingScheduledTransfer:      "erro ao criar a transferência agendada",
+		MsgScheduledTransferNotFound:             "transferência agendada não encontrada",
+		MsgErrorInGettingScheduledTransfer:       "erro ao obter a transferência agendada",
+		MsgErrorInListingScheduledTransfers:      "erro ao listar as transferências agendadas",
+		MsgErrorInUpdatingScheduledTransfer:      "erro ao atualizar a transferência agendada",
+		MsgPaymentRequestToSelf:                  "não é possível solicitar um pagamento a si mesmo",
+		MsgPaymentRequestIsNoLongerPending:       "a solicitação de pagamento não está mais pendente",
+		MsgPaymentRequestHasExpired:              "a solicitação de pagamento expirou",
+		MsgInvalidExpiry:                         "expiração inválida",
+		MsgPayerWalletNotFound:                   "carteira do pagador não encontrada",
+		MsgErrorInGettingPayerWallet:             "erro ao obter a carteira do pagador",
+		MsgErrorInCreatingPaymentRequest:         "erro ao criar a solicitação de pagamento",
+		MsgErrorInListingPaymentRequests:         "erro ao listar as solicitações de pagamento",
+		MsgPaymentRequestNotFound:                "solicitação de pagamento não encontrada",
+		MsgErrorInGettingPaymentRequest:          "erro ao obter a solicitação de pagamento",
+		MsgErrorInRespondingToPaymentRequest:     "erro ao responder à solicitação de pagamento",
+		MsgPaymentRequestPayerOnly:               "apenas o pagador pode responder a uma solicitação de pagamento",
+		MsgPaymentRequestRequesterOnly:           "apenas o solicitante pode cancelar uma solicitação de pagamento",
+		MsgSplitSharesExceedTheTotal:             "as partes da divisão excedem o total",
+		MsgSplitAmountsPartial:                   "ou todos os pagadores têm um valor ou nenhum",
+		MsgTotalIsTooSmallToSplit:                "o total é pequeno demais para ser dividido",
+		MsgPayerAppearsTwiceInTheSplit:           "um pagador aparece duas vezes na divisão",
+		MsgInvalidPayerInSplit:                   "pagador inválido na divisão",
+		MsgErrorInCreatingSplitBill:              "erro ao criar a conta dividida",
+		MsgSplitBillNotFound:                     "conta dividida não encontrada",
+		MsgErrorInGettingSplitBill:               "erro ao obter a conta dividida",
+		MsgInvalidHandle:                         "identificador inválido",
+		MsgHandleIsAlreadyTaken:                  "o identificador já está em uso",
+		MsgHandleNotFound:                        "identificador não encontrado",
+		MsgErrorInGettingHandle:                  "erro ao obter o identificador",
+		MsgErrorInSettingHandle:                  "erro ao definir o identificador",
+		MsgErrorInDeletingHandle:                 "erro ao excluir o identificador",
+		MsgPaymentQRCodesAreNotEnabled:           "os códigos QR de pagamento não estão habilitados",
+		MsgPaymentQRNeedsHandle:                  "defina um identificador antes de criar um código QR de pagamento",
+		MsgErrorInRenderingPaymentQRCode:         "erro ao gerar o código QR de pagamento",
+		MsgInvalidPaymentQRCode:                  "código QR de pagamento inválido",
+		MsgPaymentQRCodeHasExpired:               "o código QR de pagamento expirou",
+		MsgPaymentQRCodeIsNoLongerValid:          "o código QR de pagamento não é mais válido",
+		MsgMerchantPaysOwnCheckoutSession:        "um lojista não pode pagar a própria sessão de pagamento",
+		MsgMerchantProfileExists:                 "a conta já tem um perfil de lojista",
+		MsgCheckoutSessionHasExpired:             "a sessão de pagamento expirou",
+		MsgCheckoutSessionHasNotBeenPaid:         "a sessão de pagamento não foi paga",
+		MsgCheckoutSessionIsNoLongerOpen:         "a sessão de pagamento não está mais aberta",
+		MsgCheckoutSessionNotFound:               "sessão de pagamento não encontrada",
+		MsgErrorInCreatingMerchant:               "erro ao criar o lojista",
+		MsgErrorInGettingCheckoutSession:         "erro ao obter a sessão de pagamento",
+		MsgErrorInGettingMerchantWallet:          "erro ao obter a carteira do lojista",
+		MsgErrorInGettingMerchant:                "erro ao obter o lojista",
+		MsgErrorInListingCheckoutSessions:        "erro ao listar as sessões de pagamento",
+		MsgErrorInUpdatingMerchant:               "erro ao atualizar o lojista",
+		MsgMerchantProfileNotFound:               "perfil de lojista não encontrado",
+		MsgMerchantWalletNotFound:                "carteira do lojista não encontrada",
+		MsgOnlyMerchantsCanDoThis:                "apenas lojistas podem fazer isso",
+		MsgCheckoutSessionMerchantOnly:           "apenas o lojista pode alterar uma sessão de pagamento",
+		MsgOrderAlreadyHasACheckoutSession:       "o pedido já tem uma sessão de pagamento",
+		MsgRefundExceedsPayment:                  "o reembolso excede o que resta do pagamento",
+		MsgErrorInCreatingCheckoutSession:        "erro ao criar a sessão de pagamento",
+		MsgErrorInPayingCheckoutSession:          "erro ao pagar a sessão de pagamento",
+		MsgErrorInCancellingCheckoutSession:      "erro ao cancelar a sessão de pagamento",
+		MsgErrorInRefundingCheckoutSession:       "erro ao reembolsar a sessão de pagamento",
+		MsgFeeSchedulesInternalOnly:              "as tabelas de tarifas são restritas a funções internas",
+		MsgPurposeCodeNotAllowedForFeeSchedules:  "código de finalidade não permitido para tabelas de tarifas",
+		MsgMinimumFeeExceedsMaximumFee:           "a tarifa mínima excede a tarifa máxima",
+		MsgOnlyTheLastFeeTierCanBeUnbounded:      "apenas a última faixa de tarifa pode ser ilimitada",
+		MsgFeeTiersMustBeSortedByAmount:          "as faixas de tarifa devem estar ordenadas por valor",
+		MsgMerchantNotFound:                      "lojista não encontrado",
+		MsgFeeScheduleNotFound:                   "tabela de tarifas não encontrada",
+		MsgErrorInGettingFeeSchedule:             "erro ao obter a tabela de tarifas",
+		MsgErrorInCreatingFeeSchedule:            "erro ao criar a tabela de tarifas",
+		MsgErrorInListingFeeSchedules:            "erro ao listar as tabelas de tarifas",
+		MsgErrorInDeletingFeeSchedule:            "erro ao excluir a tabela de tarifas",
+		MsgErrorInQuotingFee:                     "erro ao cotar a tarifa",
+		MsgSettlementBatchNotFound:               "lote de liquidação não encontrado",
+		MsgErrorInGettingSettlementBatch:         "erro ao obter o lote de liquidação",
+		MsgErrorInListingSettlementBatches:       "erro ao listar os lotes de liquidação",
+		MsgErrorInGettingSettlementReport:        "erro ao obter o relatório de liquidação",
+	},
+}
+
+// Text returns the English text of id formatted with args.
+func Text(id MessageID, args ...interface{}) string {
+	return format(messages[language.English], id, args)
+}
+
+// Localize returns the text of id formatted with args in the best supported
+// language of an Accept-Language header, English when none matches.
+func Localize(acceptLanguage string, id MessageID, args ...interface{}) string {
+	if acceptLanguage == "" {
+		return Text(id, args...)
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Text(id, args...)
+	}
+
+	_, index, confidence := languageMatcher.Match(tags...)
+	if confidence == language.No {
+		return Text(id, args...)
+	}
+
+	if _, ok := messages[supportedLanguages[index]][id]; !ok {
+		return Text(id, args...)
+	}
+	return format(messages[supportedLanguages[index]], id, args)
+}
+
+// format falls back to the ID itself for a message missing from texts.
+func format(texts map[MessageID]string, id MessageID, args []interface{}) string {
+	text, ok := texts[id]
+	if !ok {
+		text = string(id)
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
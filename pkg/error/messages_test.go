@@ -0,0 +1,85 @@
+package error
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestMessagesTranslated(t *testing.T) {
+	english := messages[language.English]
+	for _, tag := range supportedLanguages[1:] {
+		texts := messages[tag]
+		if len(texts) != len(english) {
+			t.Errorf("%s has %d messages, English has %d", tag, len(texts), len(english))
+		}
+		for id, text := range english {
+			translated, ok := texts[id]
+			if !ok {
+				t.Errorf("%s is missing %s", tag, id)
+				continue
+			}
+			want := verbPattern.FindAllString(text, -1)
+			got := verbPattern.FindAllString(translated, -1)
+			if len(want) != len(got) {
+				t.Errorf("%s %s has verbs %v, English has %v", tag, id, got, want)
+				continue
+			}
+			for i := range want {
+				if want[i] != got[i] {
+					t.Errorf("%s %s has verbs %v, English has %v", tag, id, got, want)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		id             MessageID
+		args           []interface{}
+		want           string
+	}{
+		{"", MsgWalletNotFound, nil, "wallet not found"},
+		{"es-MX,es;q=0.9", MsgWalletNotFound, nil, "billetera no encontrada"},
+		{"de-DE", MsgWalletNotFound, nil, "wallet not found"},
+		{"not a header;;", MsgWalletNotFound, nil, "wallet not found"},
+		{"", MsgWalletShardCount, []interface{}{16}, "a wallet has between 1 and 16 shards"},
+		{"pt-BR", MsgWalletShardCount, []interface{}{16}, "uma carteira tem entre 1 e 16 fragmentos"},
+		{"fr", MessageID("unknown_message"), nil, "unknown_message"},
+	}
+
+	for _, tt := range tests {
+		if got := Localize(tt.acceptLanguage, tt.id, tt.args...); got != tt.want {
+			t.Errorf("Localize(%q, %s) = %q, want %q", tt.acceptLanguage, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestDomainError(t *testing.T) {
+	err := New(ErrorUnprocessable, MsgWalletShardCount, 16)
+	if err.Message != "a wallet has between 1 and 16 shards" {
+		t.Errorf("Message = %q", err.Message)
+	}
+	if err.Retryable {
+		t.Error("errors are not retryable unless marked")
+	}
+	if FromError(nil).Retryable {
+		t.Error("internal errors are not retryable unless marked")
+	}
+
+	resp := err.WithRetryable(true).Response("es")
+	if resp.MessageID != "wallet_shard_count" || resp.Message != "una billetera tiene entre 1 y 16 fragmentos" || !resp.Retryable {
+		t.Errorf("Response = %+v", resp)
+	}
+
+	if !errors.Is(err.WithFields(nil), New(ErrorUnprocessable, MsgWalletShardCount, 8)) {
+		t.Error("errors with the same code and message id should match")
+	}
+}
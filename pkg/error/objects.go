@@ -1,22 +1,55 @@
 package error
 
-import "net/http"
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
 
 const (
-	EmptyMessage           = ""
-	ErrorBadFormat         = 40000
-	ErrorBadRequest        = 40001
-	ErrorInsufficientFunds = 40002
-	ErrorBindingRequest    = 40003
-	ErrorUnauthorized      = 40101
-	ErrorForbidden         = 40301
-	ErrorNoRecordsFound    = 40401
-	ErrorInternalError     = 50001
+	EmptyMessage            = ""
+	ErrorBadFormat          = 40000
+	ErrorBadRequest         = 40001
+	ErrorInsufficientFunds  = 40002
+	ErrorBindingRequest     = 40003
+	ErrorValidation         = 40004
+	ErrorUnauthorized       = 40101
+	ErrorForbidden          = 40301
+	ErrorNoRecordsFound     = 40401
+	ErrorConflict           = 40901
+	ErrorUnprocessable      = 42201
+	ErrorInternalError      = 50001
+	ErrorServiceUnavailable = 50301
 )
 
 var EmptyInterface map[string]interface{}
 
 var errorCodeToHttpStatusCodeMap = map[int]int{
-	ErrorInternalError: http.StatusInternalServerError,
-	ErrorForbidden:     http.StatusForbidden,
+	ErrorBadFormat:          http.StatusBadRequest,
+	ErrorBadRequest:         http.StatusBadRequest,
+	ErrorInsufficientFunds:  http.StatusBadRequest,
+	ErrorBindingRequest:     http.StatusBadRequest,
+	ErrorValidation:         http.StatusBadRequest,
+	ErrorUnauthorized:       http.StatusUnauthorized,
+	ErrorForbidden:          http.StatusForbidden,
+	ErrorNoRecordsFound:     http.StatusNotFound,
+	ErrorConflict:           http.StatusConflict,
+	ErrorUnprocessable:      http.StatusUnprocessableEntity,
+	ErrorInternalError:      http.StatusInternalServerError,
+	ErrorServiceUnavailable: http.StatusServiceUnavailable,
+}
+
+var errorCodeToGRPCCodeMap = map[int]codes.Code{
+	ErrorBadFormat:          codes.InvalidArgument,
+	ErrorBadRequest:         codes.InvalidArgument,
+	ErrorInsufficientFunds:  codes.FailedPrecondition,
+	ErrorBindingRequest:     codes.InvalidArgument,
+	ErrorValidation:         codes.InvalidArgument,
+	ErrorUnauthorized:       codes.Unauthenticated,
+	ErrorForbidden:          codes.PermissionDenied,
+	ErrorNoRecordsFound:     codes.NotFound,
+	ErrorConflict:           codes.Aborted,
+	ErrorUnprocessable:      codes.FailedPrecondition,
+	ErrorInternalError:      codes.Internal,
+	ErrorServiceUnavailable: codes.Unavailable,
 }
@@ -0,0 +1,152 @@
+package events
+
+import (
+	"coinpe/pkg/logger"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WalletEventsChannel is the postgres channel wallet changes are notified on
+// by the trigger in migrations/0002_wallet_events_trigger.up.sql.
+const WalletEventsChannel = "wallet_events"
+
+const reconnectDelay = 2 * time.Second
+
+// Broker fans postgres notifications out to the subscribers of this instance.
+// Every CoinPe instance runs its own Broker, so a commit on any instance
+// reaches streams connected to all of them.
+type Broker struct {
+	dsn string
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+	done        chan struct{}
+}
+
+func NewBroker(dsn string) *Broker {
+	return &Broker{
+		dsn:         dsn,
+		subscribers: map[string]map[chan struct{}]struct{}{},
+		done:        make(chan struct{}),
+	}
+}
+
+// Subscribe returns a channel signalled whenever the wallet changes. Signals
+// are coalesced, so receivers should re-read the wallet state on each one.
+// The channel is closed when the broker stops; call the returned func to
+// unsubscribe.
+func (b *Broker) Subscribe(walletUUID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	select {
+	case <-b.done:
+		close(ch)
+		b.mu.Unlock()
+		return ch, func() {}
+	default:
+	}
+	if b.subscribers[walletUUID] == nil {
+		b.subscribers[walletUUID] = map[chan struct{}]struct{}{}
+	}
+	b.subscribers[walletUUID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[walletUUID][ch]; !ok {
+			return
+		}
+		delete(b.subscribers[walletUUID], ch)
+		if len(b.subscribers[walletUUID]) == 0 {
+			delete(b.subscribers, walletUUID)
+		}
+		close(ch)
+	}
+}
+
+func (b *Broker) publish(walletUUID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[walletUUID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// a signal is already pending for this subscriber
+		}
+	}
+}
+
+// signalAll wakes every subscriber, used after reconnecting since
+// notifications sent while disconnected are lost.
+func (b *Broker) signalAll() {
+	b.mu.Lock()
+	walletUUIDs := make([]string, 0, len(b.subscribers))
+	for walletUUID := range b.subscribers {
+		walletUUIDs = append(walletUUIDs, walletUUID)
+	}
+	b.mu.Unlock()
+
+	for _, walletUUID := range walletUUIDs {
+		b.publish(walletUUID)
+	}
+}
+
+func (b *Broker) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	close(b.done)
+	for walletUUID, chans := range b.subscribers {
+		for ch := range chans {
+			close(ch)
+		}
+		delete(b.subscribers, walletUUID)
+	}
+}
+
+// Run listens on WalletEventsChannel until ctx is cancelled, reconnecting on
+// connection errors. All subscriber channels are closed when it returns.
+func (b *Broker) Run(ctx context.Context) {
+	defer b.stop()
+
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error("wallet events listener disconnected | err: ", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (b *Broker) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+WalletEventsChannel)
+	if err != nil {
+		return err
+	}
+	logger.Info("listening for wallet events on channel: ", WalletEventsChannel)
+	b.signalAll()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		b.publish(notification.Payload)
+	}
+}
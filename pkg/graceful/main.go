@@ -4,13 +4,17 @@ import (
 	"coinpe/pkg/logger"
 	"coinpe/pkg/utils"
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
+
+	"google.golang.org/grpc"
 )
 
 const (
@@ -28,10 +32,23 @@ func (state *ServerState) Shutdown() {
 	atomic.StoreInt32(&state.running, TERMINATING)
 }
 
+// Worker is a background process that is stopped after the servers, so it
+// can finish work already accepted by them.
+type Worker interface {
+	Shutdown(ctx context.Context) error
+}
+
 type Graceful struct {
-	HTTPServer      *http.Server
+	HTTPServer *http.Server
+	GRPCServer *grpc.Server
+	GRPCAddr   string
+	Workers    []Worker
+	// ShutdownTimeout bounds the shutdown of the servers
 	ShutdownTimeout time.Duration
-	State           *ServerState
+	// WorkerDrainTimeout bounds the shutdown of the workers, which starts
+	// once the servers are down
+	WorkerDrainTimeout time.Duration
+	State              *ServerState
 }
 
 func optimiseListenAddress(addr string) string {
@@ -53,6 +70,16 @@ func (g *Graceful) ListenAndServe(startupMessages ...string) {
 		go g.HTTPServer.ListenAndServe()
 	}
 
+	if g.GRPCServer != nil {
+		g.GRPCAddr = optimiseListenAddress(g.GRPCAddr)
+		listener, err := net.Listen("tcp", g.GRPCAddr)
+		if err != nil {
+			logger.Fatal("Error listening for grpc server: ", err)
+		}
+		logger.Info("gRPC Server listening on: ", g.GRPCAddr)
+		go g.GRPCServer.Serve(listener)
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 
@@ -87,6 +114,48 @@ func (g *Graceful) ListenAndServe(startupMessages ...string) {
 		}
 	}
 
+	if g.GRPCServer != nil {
+		logger.Info("Gracefully shutting down grpc server with timeout: ", g.ShutdownTimeout)
+		stopped := make(chan struct{})
+		go func() {
+			g.GRPCServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			// in-flight RPCs (e.g. long streams) did not finish in time
+			g.GRPCServer.Stop()
+		}
+	}
+
+	g.shutdownWorkers()
+
 	logger.Info("Server exiting...")
 	os.Exit(0)
 }
+
+// shutdownWorkers stops the workers at the same time, with their own
+// WorkerDrainTimeout rather than what the servers left of ShutdownTimeout.
+func (g *Graceful) shutdownWorkers() {
+	if len(g.Workers) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.WorkerDrainTimeout)
+	defer cancel()
+
+	logger.Info("Draining workers with timeout: ", g.WorkerDrainTimeout)
+	var wg sync.WaitGroup
+	for _, worker := range g.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := worker.Shutdown(ctx); err != nil {
+				logger.Error("Error shutting down worker: ", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
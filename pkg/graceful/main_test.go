@@ -0,0 +1,45 @@
+package graceful
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type blockingWorker struct {
+	started  chan struct{}
+	deadline time.Time
+}
+
+func (w *blockingWorker) Shutdown(ctx context.Context) error {
+	w.deadline, _ = ctx.Deadline()
+	w.started <- struct{}{}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestShutdownWorkers(t *testing.T) {
+	started := make(chan struct{}, 2)
+	workers := []*blockingWorker{{started: started}, {started: started}}
+	g := Graceful{WorkerDrainTimeout: 50 * time.Millisecond}
+	for _, w := range workers {
+		g.Workers = append(g.Workers, w)
+	}
+
+	begin := time.Now()
+	g.shutdownWorkers()
+	elapsed := time.Since(begin)
+
+	// both drained at the same time, each with the whole timeout
+	if len(started) != 2 {
+		t.Fatalf("%d workers were shut down, want 2", len(started))
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("workers took %v to drain, want them drained together within the timeout", elapsed)
+	}
+	for _, w := range workers {
+		if w.deadline.Sub(begin) < 50*time.Millisecond {
+			t.Errorf("worker got a deadline %v after shutdown began, want the whole drain timeout", w.deadline.Sub(begin))
+		}
+	}
+}
@@ -2,4 +2,7 @@ package jwtauth
 
 const (
 	JWTIssuer = "coinpe"
+
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
 )
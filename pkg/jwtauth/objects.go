@@ -9,6 +9,7 @@ type CustomClaims struct {
 	PhoneNumber   string `json:"phone_number,omitempty"`
 	Email         string `json:"email,omitempty"`
 	AccountUUID   string `json:"account_uuid,omitempty"`
+	TokenType     string `json:"token_type,omitempty"`
 }
 
 type JWTTokenClaims struct {
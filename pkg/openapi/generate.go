@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	Version = "3.0.3"
+
+	ContentTypeJSON        = "application/json"
+	ContentTypeEventStream = "text/event-stream"
+	ContentTypeCSV         = "text/csv"
+	ContentTypePDF         = "application/pdf"
+	ContentTypePNG         = "image/png"
+
+	securityBearer       = "bearerAuth"
+	securityStreamTicket = "streamTicket"
+)
+
+type AuthMode int
+
+const (
+	AuthNone AuthMode = iota
+	// AuthBearer requires a token in the Authorization header.
+	AuthBearer
+	// AuthBearerOrTicket also accepts a stream ticket as the ticket query
+	// param.
+	AuthBearerOrTicket
+)
+
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Route documents one registered gin route. Request and the Responses values
+// are zero values of the body types; their json and validate struct tags
+// become the schema.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tag         string
+	Auth        AuthMode
+	Query       []Param
+	Headers     []Param
+	Request     interface{}
+	Responses   map[int]interface{}
+	// ContentType of the success responses, JSON when empty.
+	ContentType string
+	// RequestContentType of Request, JSON when empty.
+	RequestContentType string
+}
+
+type Config struct {
+	Info Info
+	// ErrorResponse is documented as the body of every undocumented status.
+	ErrorResponse interface{}
+	// Ignore lists "METHOD /path" routes that are served but not documented.
+	Ignore []string
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// Generate builds the document for the registered gin routes. It also
+// returns an error listing the registered routes without a Route doc and the
+// Route docs without a registered route, the document then only has the
+// routes in both.
+func Generate(cfg Config, registered gin.RoutesInfo, routes []Route) (*Document, error) {
+	var (
+		problems   []string
+		handlers   = map[string]string{}
+		documented = map[string]bool{}
+		registry   = newSchemaRegistry()
+	)
+
+	for _, r := range registered {
+		key := routeKey(r.Method, r.Path)
+		if slices.Contains(cfg.Ignore, key) {
+			continue
+		}
+		handlers[key] = r.Handler
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    cfg.Info,
+		Paths:   map[string]map[string]*Operation{},
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityBearer: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+				},
+				securityStreamTicket: {
+					Type:        "apiKey",
+					In:          "query",
+					Name:        "ticket",
+					Description: "Single use ticket from POST /v1/wallets/me/stream-tickets",
+				},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		key := routeKey(route.Method, route.Path)
+		if documented[key] {
+			problems = append(problems, "documented twice: "+key)
+			continue
+		}
+		documented[key] = true
+
+		handler, ok := handlers[key]
+		if !ok {
+			problems = append(problems, "documented but not registered: "+key)
+			continue
+		}
+
+		path, operation := buildOperation(registry, cfg, route, handler)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = operation
+	}
+
+	for key := range handlers {
+		if !documented[key] {
+			problems = append(problems, "registered but not documented: "+key)
+		}
+	}
+
+	doc.Components.Schemas = registry.schemas
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return doc, errors.New("openapi routes drifted from the router:\n\t" + strings.Join(problems, "\n\t"))
+	}
+	return doc, nil
+}
+
+// operationID defaults to the handler method name, e.g.
+// "coinpe/controllers.(*BaseController).CreateAccount-fm" → "CreateAccount".
+func operationID(route Route, handler string) string {
+	if route.OperationID != "" {
+		return route.OperationID
+	}
+	name := strings.TrimSuffix(handler[strings.LastIndex(handler, ".")+1:], "-fm")
+	if name == "" || strings.HasPrefix(name, "func") {
+		return strings.ToLower(route.Method) + strings.NewReplacer("/", "_", ":", "").Replace(route.Path)
+	}
+	return name
+}
+
+func buildOperation(registry *schemaRegistry, cfg Config, route Route, handler string) (string, *Operation) {
+	operation := &Operation{
+		OperationID: operationID(route, handler),
+		Summary:     route.Summary,
+		Responses:   map[string]*Response{},
+	}
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+
+	// gin path params (":wallet_uuid") become OpenAPI templates ("{wallet_uuid}")
+	segments := strings.Split(route.Path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+
+	for _, p := range route.Query {
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	for _, p := range route.Headers {
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:        p.Name,
+			In:          "header",
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	switch route.Auth {
+	case AuthBearer:
+		operation.Security = []map[string][]string{{securityBearer: {}}}
+	case AuthBearerOrTicket:
+		operation.Security = []map[string][]string{{securityBearer: {}}, {securityStreamTicket: {}}}
+	}
+
+	if route.Request != nil {
+		requestContentType := route.RequestContentType
+		if requestContentType == "" {
+			requestContentType = ContentTypeJSON
+		}
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				requestContentType: {Schema: registry.schemaFor(reflect.TypeOf(route.Request))},
+			},
+		}
+	}
+
+	contentType := route.ContentType
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	for status, body := range route.Responses {
+		response := &Response{Description: http.StatusText(status)}
+		if body != nil {
+			mediaType := contentType
+			if status >= http.StatusBadRequest {
+				mediaType = ContentTypeJSON
+			}
+			response.Content = map[string]*MediaType{
+				mediaType: {Schema: registry.schemaFor(reflect.TypeOf(body))},
+			}
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+
+	if cfg.ErrorResponse != nil {
+		operation.Responses["default"] = &Response{
+			Description: "Error",
+			Content: map[string]*MediaType{
+				ContentTypeJSON: {Schema: registry.schemaFor(reflect.TypeOf(cfg.ErrorResponse))},
+			},
+		}
+	}
+
+	return strings.Join(segments, "/"), operation
+}
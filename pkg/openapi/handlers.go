@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// docsCSP keeps the docs UI to its own embedded assets, swagger-ui sets
+// inline styles.
+const docsCSP = "default-src 'self'; img-src 'self' data:; style-src 'self' 'unsafe-inline'"
+
+//go:embed ui/index.html
+var docsUI []byte
+
+// swagger-ui is embedded rather than loaded from a CDN, see ui/assets/NOTICE
+//
+//go:embed ui/assets
+var docsAssets embed.FS
+
+// JSONHandler serves the document. It is encoded once as it never changes
+// after startup.
+func JSONHandler(doc *Document) (gin.HandlerFunc, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}, nil
+}
+
+// UIHandler serves the docs UI, which renders /openapi.json.
+func UIHandler(c *gin.Context) {
+	c.Header("Content-Security-Policy", docsCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsUI)
+}
+
+// RegisterUI serves the docs UI at /docs and its assets under /docs/assets.
+func RegisterUI(routes gin.IRoutes) {
+	assets, err := fs.Sub(docsAssets, "ui/assets")
+	if err != nil {
+		// the embedded directory is always there
+		panic(err)
+	}
+	routes.GET("/docs", UIHandler)
+	routes.StaticFS("/docs/assets", http.FS(assets))
+}
@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	byteSliceType     = reflect.TypeOf([]byte{})
+	emptyInterfaceTyp = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// schemaRegistry turns Go types into schemas, collecting named structs into
+// components so every struct is described once and referenced by $ref.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// componentName is the type name, qualified with its package when two
+// packages export a struct with the same name.
+func (r *schemaRegistry) componentName(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	for other, otherName := range r.names {
+		if otherName == name && other != t {
+			name = path.Base(t.PkgPath()) + "." + t.Name()
+			break
+		}
+	}
+	r.names[t] = name
+	return name
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType, emptyInterfaceTyp:
+		return &Schema{}
+	case byteSliceType:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name := r.componentName(t)
+		if _, ok := r.schemas[name]; !ok {
+			// reserve the name first so self referencing structs terminate
+			r.schemas[name] = &Schema{}
+			*r.schemas[name] = *r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// embedded structs without a json name are flattened, as encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := r.structSchema(embedded)
+				for k, v := range inner.Properties {
+					schema.Properties[k] = v
+				}
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := r.schemaFor(field.Type)
+		if applyValidateTag(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// applyValidateTag maps go-playground validator rules onto the schema and
+// reports whether the field is required.
+func applyValidateTag(schema *Schema, tag string) bool {
+	required := false
+	if tag == "" || schema.Ref != "" {
+		return strings.Contains(tag, "required")
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		value, numErr := strconv.ParseFloat(param, 64)
+		isString := schema.Type == "string"
+
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "e164":
+			schema.Pattern = `^\+[1-9]\d{1,14}$`
+		case "url":
+			schema.Format = "uri"
+		case "webhook_url":
+			schema.Format = "uri"
+			schema.Pattern = "^https://"
+		case "len":
+			if numErr != nil || !isString {
+				continue
+			}
+			n := int(value)
+			schema.MinLength, schema.MaxLength = &n, &n
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "gt", "gte", "min":
+			if numErr != nil {
+				continue
+			}
+			if isString {
+				n := int(value)
+				if name == "gt" {
+					n++
+				}
+				schema.MinLength = &n
+			} else {
+				schema.Minimum = &value
+				schema.ExclusiveMinimum = name == "gt"
+			}
+		case "lt", "lte", "max":
+			if numErr != nil {
+				continue
+			}
+			if isString {
+				n := int(value)
+				if name == "lt" {
+					n--
+				}
+				schema.MaxLength = &n
+			} else {
+				schema.Maximum = &value
+				schema.ExclusiveMaximum = name == "lt"
+			}
+		}
+	}
+	return required
+}
@@ -0,0 +1,139 @@
+// Package paymentqr encodes payment instructions as signed strings, shown
+// as QR codes, and reads them back.
+package paymentqr
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+)
+
+// prefix of every payload, the query after it is signed
+const prefix = "coinpe://pay?"
+
+// signature is the last parameter of a payload
+const signatureParam = "&sig="
+
+var (
+	ErrMalformed    = errors.New("malformed payment qr payload")
+	ErrBadSignature = errors.New("payment qr payload signature is invalid")
+	ErrExpired      = errors.New("payment qr payload has expired")
+)
+
+// Payload asks to pay the wallet holding Handle. WalletUUID is the wallet
+// the handle pointed at when the payload was made, so a handle given up
+// and taken by someone else does not redirect it.
+type Payload struct {
+	Handle     string
+	WalletUUID string
+	Currency   string
+	// AmountInCents is 0 when the payer chooses the amount
+	AmountInCents int
+	ExpiresAt     time.Time
+}
+
+// ParseSigningKey parses a base64 encoded 32 byte ed25519 seed.
+func ParseSigningKey(encoded string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("payment qr signing key is not base64: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("payment qr signing key must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// Encode returns p as a coinpe://pay URI signed with key.
+func (p *Payload) Encode(key ed25519.PrivateKey) string {
+	values := url.Values{}
+	values.Set("handle", p.Handle)
+	values.Set("wallet", p.WalletUUID)
+	values.Set("currency", p.Currency)
+	if p.AmountInCents > 0 {
+		values.Set("amount", strconv.Itoa(p.AmountInCents))
+	}
+	values.Set("expires", strconv.FormatInt(p.ExpiresAt.Unix(), 10))
+
+	// Encode sorts the parameters, the signed bytes are the ones sent
+	unsigned := prefix + values.Encode()
+	signature := ed25519.Sign(key, []byte(unsigned))
+	return unsigned + signatureParam + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Parse checks that payload was signed by publicKey and has not expired at
+// now, and returns what it asks for.
+func Parse(payload string, publicKey ed25519.PublicKey, now time.Time) (*Payload, error) {
+	payload = strings.TrimSpace(payload)
+	i := strings.LastIndex(payload, signatureParam)
+	if !strings.HasPrefix(payload, prefix) || i < 0 {
+		return nil, ErrMalformed
+	}
+	unsigned := payload[:i]
+
+	signature, err := base64.RawURLEncoding.DecodeString(payload[i+len(signatureParam):])
+	if err != nil || !ed25519.Verify(publicKey, []byte(unsigned), signature) {
+		return nil, ErrBadSignature
+	}
+
+	values, err := url.ParseQuery(strings.TrimPrefix(unsigned, prefix))
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	p := &Payload{
+		Handle:     values.Get("handle"),
+		WalletUUID: values.Get("wallet"),
+		Currency:   values.Get("currency"),
+	}
+	if p.Handle == "" || p.WalletUUID == "" || p.Currency == "" {
+		return nil, ErrMalformed
+	}
+
+	if amount := values.Get("amount"); amount != "" {
+		p.AmountInCents, err = strconv.Atoi(amount)
+		if err != nil || p.AmountInCents <= 0 {
+			return nil, ErrMalformed
+		}
+	}
+
+	expires, err := strconv.ParseInt(values.Get("expires"), 10, 64)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	p.ExpiresAt = time.Unix(expires, 0).UTC()
+	if !now.Before(p.ExpiresAt) {
+		return nil, ErrExpired
+	}
+	return p, nil
+}
+
+// PNG renders payload as a size by size pixels QR code.
+func PNG(payload string, size int) ([]byte, error) {
+	code, err := qr.Encode(payload, qr.M, qr.Auto)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err = barcode.Scale(code, size, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, code)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package paymentqr
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(t *testing.T, fill byte) ed25519.PrivateKey {
+	t.Helper()
+	key, err := ParseSigningKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestParseSigningKey(t *testing.T) {
+	for _, encoded := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParseSigningKey(encoded); err == nil {
+			t.Errorf("ParseSigningKey(%q) accepted the key", encoded)
+		}
+	}
+}
+
+func TestEncodeParse(t *testing.T) {
+	var (
+		key = testKey(t, 1)
+		now = time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	)
+
+	for _, amount := range []int{0, 12550} {
+		want := Payload{
+			Handle:        "asha.rao",
+			WalletUUID:    "wa_asha",
+			Currency:      "INR",
+			AmountInCents: amount,
+			ExpiresAt:     now.Add(15 * time.Minute),
+		}
+		payload := want.Encode(key)
+		if !strings.HasPrefix(payload, "coinpe://pay?") {
+			t.Fatalf("payload %s is not a coinpe://pay URI", payload)
+		}
+
+		got, err := Parse(" "+payload+"\n", key.Public().(ed25519.PublicKey), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *got != want {
+			t.Errorf("parsed %+v, want %+v", *got, want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	var (
+		key       = testKey(t, 1)
+		publicKey = key.Public().(ed25519.PublicKey)
+		now       = time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+		valid     = Payload{Handle: "asha.rao", WalletUUID: "wa_asha", Currency: "INR", AmountInCents: 500, ExpiresAt: now.Add(time.Minute)}
+		payload   = valid.Encode(key)
+	)
+
+	expired := valid
+	expired.ExpiresAt = now
+	noHandle := valid
+	noHandle.Handle = ""
+
+	tests := []struct {
+		name    string
+		payload string
+		want    error
+	}{
+		{"other scheme", strings.Replace(payload, "coinpe://", "upi://", 1), ErrMalformed},
+		{"unsigned", payload[:strings.LastIndex(payload, signatureParam)], ErrMalformed},
+		{"amount changed", strings.Replace(payload, "amount=500", "amount=50000", 1), ErrBadSignature},
+		{"signature garbled", payload + "!", ErrBadSignature},
+		{"other key", valid.Encode(testKey(t, 2)), ErrBadSignature},
+		{"missing handle", noHandle.Encode(key), ErrMalformed},
+		{"expired", expired.Encode(key), ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.payload, publicKey, now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	payload := (&Payload{Handle: "asha.rao", WalletUUID: "wa_asha", Currency: "INR", ExpiresAt: time.Now()}).Encode(testKey(t, 1))
+
+	b, err := PNG(payload, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 256 || size.Y != 256 {
+		t.Errorf("QR code is %v, want 256x256", size)
+	}
+}
@@ -0,0 +1,37 @@
+package purposecodes
+
+type TransactionPurposeCode string
+
+const (
+	PurposeCodeTransfer   TransactionPurposeCode = "TRANSFER"
+	PurposeCodeAddFunds   TransactionPurposeCode = "ADD_FUNDS"
+	PurposeCodeReward     TransactionPurposeCode = "REWARD"
+	PurposeCodeCashback   TransactionPurposeCode = "CASHBACK"
+	PurposeCodeRefund     TransactionPurposeCode = "REFUND"
+	PurposeCodeAdjustment TransactionPurposeCode = "ADJUSTMENT"
+	// a customer paying a merchant's checkout session
+	PurposeCodePayment TransactionPurposeCode = "PAYMENT"
+	// a fee charged on another transfer, to the fee revenue wallet
+	PurposeCodeFee TransactionPurposeCode = "FEE"
+	// moves coins between the shards of one wallet
+	PurposeCodeRebalance TransactionPurposeCode = "REBALANCE"
+	// moves a merchant's settled receivables to its available wallet
+	PurposeCodeSettlement TransactionPurposeCode = "SETTLEMENT"
+)
+
+var validPurposeCodes = map[TransactionPurposeCode]bool{
+	PurposeCodeTransfer:   true,
+	PurposeCodeAddFunds:   true,
+	PurposeCodeReward:     true,
+	PurposeCodeCashback:   true,
+	PurposeCodeRefund:     true,
+	PurposeCodeAdjustment: true,
+	PurposeCodeRebalance:  true,
+	PurposeCodePayment:    true,
+	PurposeCodeFee:        true,
+	PurposeCodeSettlement: true,
+}
+
+func IsValid(code TransactionPurposeCode) bool {
+	return validPurposeCodes[code]
+}
@@ -0,0 +1,361 @@
+// Package recurrence computes the occurrences of standing instructions from
+// a cron expression or an RFC 5545 RRULE.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// periods in a row without an occurrence after which a rule is considered
+// exhausted, e.g. FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30. A rule that only
+// occurs after longer gaps, like FREQ=DAILY;BYMONTH=2;BYMONTHDAY=29 with
+// its 1400 odd days between leap days, ends early instead.
+const maxEmptyPeriods = 1000
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Schedule yields the occurrences of a recurrence.
+type Schedule interface {
+	// Next returns the first occurrence after t, the zero time when there
+	// is none.
+	Next(t time.Time) time.Time
+}
+
+// Parse returns the schedule of rule starting at start, in start's
+// location:
+//   - an empty rule occurs once, at start;
+//   - a rule with FREQ= is an RRULE, start being its DTSTART;
+//   - anything else is a standard five field cron expression or a
+//     descriptor such as @monthly, with no occurrence before start.
+func Parse(rule string, start time.Time) (Schedule, error) {
+	rule = strings.TrimSpace(rule)
+	switch {
+	case rule == "":
+		return once{at: start}, nil
+	case strings.Contains(strings.ToUpper(rule), "FREQ="):
+		return parseRRule(rule, start)
+	}
+
+	schedule, err := cron.ParseStandard(rule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+	return cronSchedule{schedule: schedule, start: start}, nil
+}
+
+type once struct {
+	at time.Time
+}
+
+func (o once) Next(t time.Time) time.Time {
+	if o.at.After(t) {
+		return o.at
+	}
+	return time.Time{}
+}
+
+type cronSchedule struct {
+	schedule cron.Schedule
+	start    time.Time
+}
+
+func (c cronSchedule) Next(t time.Time) time.Time {
+	if t.Before(c.start) {
+		// the start itself occurs when it matches
+		t = c.start.Add(-time.Nanosecond)
+	}
+	return c.schedule.Next(t.In(c.start.Location()))
+}
+
+// rrule is the subset of RFC 5545 recurrence rules standing instructions
+// need: FREQ of DAILY, WEEKLY, MONTHLY or YEARLY with INTERVAL, COUNT or
+// UNTIL, and BYDAY without ordinals, BYMONTHDAY and BYMONTH. Occurrences
+// take their time of day from start, months without a BYMONTHDAY are
+// skipped.
+//
+// A complete implementation such as github.com/teambition/rrule-go would
+// accept parts standing instructions cannot use, HOURLY or BYSETPOS say,
+// which would have to be rejected before it anyway, so the subset is kept
+// here where every accepted part is tested.
+type rrule struct {
+	start      time.Time
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []time.Weekday
+	byMonthDay []int
+	byMonth    []time.Month
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+func parseRRule(rule string, start time.Time) (*rrule, error) {
+	r := &rrule{start: start, interval: 1}
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: "+format, append([]any{ErrInvalidRule}, args...)...)
+	}
+
+	rule = strings.TrimPrefix(strings.ToUpper(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, invalid("malformed part %q", part)
+		}
+
+		var err error
+		switch name {
+		case "FREQ":
+			switch value {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				r.freq = value
+			default:
+				return nil, invalid("unsupported FREQ %s", value)
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err != nil || r.interval < 1 {
+				return nil, invalid("INTERVAL must be a positive number")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err != nil || r.count < 1 {
+				return nil, invalid("COUNT must be a positive number")
+			}
+		case "UNTIL":
+			r.until, err = parseUntil(value, start.Location())
+			if err != nil {
+				return nil, invalid("UNTIL must be a date or a date-time")
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return nil, invalid("unsupported BYDAY %s", day)
+				}
+				r.byDay = append(r.byDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, invalid("BYMONTHDAY must be between 1 and 31 or -31 and -1")
+				}
+				r.byMonthDay = append(r.byMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(month)
+				if err != nil || n < 1 || n > 12 {
+					return nil, invalid("BYMONTH must be between 1 and 12")
+				}
+				r.byMonth = append(r.byMonth, time.Month(n))
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, invalid("only WKST=MO is supported")
+			}
+		default:
+			return nil, invalid("unsupported part %s", name)
+		}
+	}
+
+	switch {
+	case r.freq == "":
+		return nil, invalid("FREQ is required")
+	case r.count > 0 && !r.until.IsZero():
+		return nil, invalid("COUNT and UNTIL cannot both be set")
+	case r.freq == "WEEKLY" && len(r.byMonthDay) > 0:
+		return nil, invalid("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	return r, nil
+}
+
+// parseUntil reads an UTC date-time, a local date-time or a date, which
+// includes the whole day.
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("20060102", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// Next implements Schedule. Occurrences are enumerated from start on every
+// call, which COUNT needs, so a call costs one step per period since start:
+// about 3650 for a daily rule ten years in, cheap next to the transfer it
+// schedules.
+func (r *rrule) Next(t time.Time) time.Time {
+	var n, empty int
+	for period := 0; empty < maxEmptyPeriods; period++ {
+		candidates := r.period(period)
+		if len(candidates) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+
+		for _, occurrence := range candidates {
+			if occurrence.Before(r.start) {
+				continue
+			}
+			if !r.until.IsZero() && occurrence.After(r.until) {
+				return time.Time{}
+			}
+			n++
+			if occurrence.After(t) {
+				return occurrence
+			}
+			if r.count > 0 && n >= r.count {
+				return time.Time{}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// period returns the candidate occurrences of the period-th day, week,
+// month or year since start, in order.
+func (r *rrule) period(period int) []time.Time {
+	var (
+		loc          = r.start.Location()
+		year, month  = r.start.Year(), r.start.Month()
+		hour, minute = r.start.Hour(), r.start.Minute()
+		second       = r.start.Second()
+		step         = period * r.interval
+		occurrences  []time.Time
+	)
+	at := func(year int, month time.Month, day int) time.Time {
+		return localTime(year, month, day, hour, minute, second, loc)
+	}
+
+	switch r.freq {
+	case "DAILY":
+		day := at(year, month, r.start.Day()+step)
+		if r.matches(day) {
+			occurrences = append(occurrences, day)
+		}
+	case "WEEKLY":
+		days := r.byDay
+		if len(days) == 0 {
+			days = []time.Weekday{r.start.Weekday()}
+		}
+		monday := r.start.Day() - daysFromMonday(r.start.Weekday()) + 7*step
+		for _, weekday := range days {
+			day := at(year, month, monday+daysFromMonday(weekday))
+			if len(r.byMonth) == 0 || slices.Contains(r.byMonth, day.Month()) {
+				occurrences = append(occurrences, day)
+			}
+		}
+	case "MONTHLY":
+		first := time.Date(year, month+time.Month(step), 1, 0, 0, 0, 0, loc)
+		if len(r.byMonth) == 0 || slices.Contains(r.byMonth, first.Month()) {
+			for _, day := range r.monthDays(first.Year(), first.Month()) {
+				occurrences = append(occurrences, at(first.Year(), first.Month(), day))
+			}
+		}
+	case "YEARLY":
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []time.Month{month}
+		}
+		for _, m := range months {
+			for _, day := range r.monthDays(year+step, m) {
+				occurrences = append(occurrences, at(year+step, m, day))
+			}
+		}
+	}
+
+	slices.SortFunc(occurrences, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(occurrences, func(a, b time.Time) bool { return a.Equal(b) })
+}
+
+// monthDays returns the days of month matching BYMONTHDAY and BYDAY, or
+// the day of the month of start when neither is set.
+func (r *rrule) monthDays(year int, month time.Month) []int {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	var days []int
+	switch {
+	case len(r.byMonthDay) > 0:
+		for _, day := range r.byMonthDay {
+			if day < 0 {
+				day += last + 1
+			}
+			if day >= 1 && day <= last {
+				days = append(days, day)
+			}
+		}
+	case len(r.byDay) > 0:
+		for day := 1; day <= last; day++ {
+			days = append(days, day)
+		}
+	case r.start.Day() <= last:
+		days = append(days, r.start.Day())
+	}
+
+	if len(r.byDay) > 0 {
+		days = slices.DeleteFunc(days, func(day int) bool {
+			return !slices.Contains(r.byDay, time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday())
+		})
+	}
+	return days
+}
+
+// matches reports whether day passes the BY filters of a DAILY rule.
+func (r *rrule) matches(day time.Time) bool {
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, day.Month()) {
+		return false
+	}
+	if len(r.byDay) > 0 && !slices.Contains(r.byDay, day.Weekday()) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		return slices.ContainsFunc(r.byMonthDay, func(n int) bool {
+			return n == day.Day() || n == day.Day()-last-1
+		})
+	}
+	return true
+}
+
+// localTime is time.Date, except that a time skipped by a daylight saving
+// transition is read with the offset from before it, as RFC 5545 does:
+// 02:30 on a day clocks go from 02:00 to 03:00 is 03:30. time.Date does not
+// say which offset it picks and may return 01:30.
+func localTime(year int, month time.Month, day, hour, minute, second int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, minute, second, 0, loc)
+	if t.Hour() == hour && t.Minute() == minute {
+		return t
+	}
+
+	// a day earlier is before the transition
+	_, offset := t.AddDate(0, 0, -1).Zone()
+	wall := time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	return wall.Add(-time.Duration(offset) * time.Second).In(loc)
+}
+
+func daysFromMonday(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
@@ -0,0 +1,179 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	ny := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, newYork)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		// want are the occurrences from start on, ending with the zero time
+		// when the schedule is exhausted
+		want []time.Time
+	}{
+		{
+			name:  "once",
+			start: utc(2024, 5, 1, 9, 0),
+			want:  []time.Time{utc(2024, 5, 1, 9, 0), {}},
+		},
+		{
+			name:  "count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: utc(2024, 1, 1, 10, 0),
+			want:  []time.Time{utc(2024, 1, 1, 10, 0), utc(2024, 1, 2, 10, 0), utc(2024, 1, 3, 10, 0), {}},
+		},
+		{
+			name:  "count skips months without the day",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			start: utc(2024, 1, 31, 8, 0),
+			want:  []time.Time{utc(2024, 1, 31, 8, 0), utc(2024, 3, 31, 8, 0), utc(2024, 5, 31, 8, 0), {}},
+		},
+		{
+			name:  "until date-time",
+			rule:  "FREQ=WEEKLY;UNTIL=20240115T100000Z",
+			start: utc(2024, 1, 1, 10, 0),
+			want:  []time.Time{utc(2024, 1, 1, 10, 0), utc(2024, 1, 8, 10, 0), utc(2024, 1, 15, 10, 0), {}},
+		},
+		{
+			name:  "until date includes the whole day",
+			rule:  "FREQ=DAILY;UNTIL=20240103",
+			start: ny(2024, 1, 1, 23, 30),
+			want:  []time.Time{ny(2024, 1, 1, 23, 30), ny(2024, 1, 2, 23, 30), ny(2024, 1, 3, 23, 30), {}},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=5",
+			start: utc(2023, 12, 31, 12, 0),
+			want: []time.Time{
+				utc(2023, 12, 31, 12, 0), utc(2024, 1, 31, 12, 0), utc(2024, 2, 29, 12, 0),
+				utc(2024, 3, 31, 12, 0), utc(2024, 4, 30, 12, 0), {},
+			},
+		},
+		{
+			name:  "31st skips short months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=4",
+			start: utc(2024, 1, 1, 12, 0),
+			want:  []time.Time{utc(2024, 1, 31, 12, 0), utc(2024, 3, 31, 12, 0), utc(2024, 5, 31, 12, 0), utc(2024, 7, 31, 12, 0), {}},
+		},
+		{
+			name:  "weekly by day across the year",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=4",
+			start: utc(2023, 12, 27, 9, 0), // a Wednesday
+			want:  []time.Time{utc(2023, 12, 29, 9, 0), utc(2024, 1, 1, 9, 0), utc(2024, 1, 5, 9, 0), utc(2024, 1, 8, 9, 0), {}},
+		},
+		{
+			name:  "fortnightly across a month",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU;COUNT=4",
+			start: utc(2024, 1, 23, 9, 0), // a Tuesday
+			want:  []time.Time{utc(2024, 1, 23, 9, 0), utc(2024, 1, 28, 9, 0), utc(2024, 2, 6, 9, 0), utc(2024, 2, 11, 9, 0), {}},
+		},
+		{
+			name:  "leap day",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;COUNT=3",
+			start: utc(2023, 6, 1, 0, 0),
+			want:  []time.Time{utc(2024, 2, 29, 0, 0), utc(2028, 2, 29, 0, 0), utc(2032, 2, 29, 0, 0), {}},
+		},
+		{
+			name:  "no occurrence",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			start: utc(2024, 1, 1, 0, 0),
+			want:  []time.Time{{}},
+		},
+		{
+			// 02:30 does not exist on 10 March, it moves to 03:30 EDT like
+			// time.Date does, and the time of day is kept after
+			name:  "daily across spring forward",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: ny(2024, 3, 9, 2, 30),
+			want: []time.Time{
+				ny(2024, 3, 9, 2, 30),
+				time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC),
+				time.Date(2024, 3, 11, 6, 30, 0, 0, time.UTC),
+				{},
+			},
+		},
+		{
+			name:  "daily across fall back",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: ny(2024, 11, 2, 9, 0),
+			want: []time.Time{
+				time.Date(2024, 11, 2, 13, 0, 0, 0, time.UTC),
+				time.Date(2024, 11, 3, 14, 0, 0, 0, time.UTC),
+				time.Date(2024, 11, 4, 14, 0, 0, 0, time.UTC),
+				{},
+			},
+		},
+		{
+			name:  "cron starting later",
+			rule:  "0 9 1 * *",
+			start: utc(2024, 6, 1, 9, 0),
+			want:  []time.Time{utc(2024, 6, 1, 9, 0), utc(2024, 7, 1, 9, 0), utc(2024, 8, 1, 9, 0)},
+		},
+		{
+			name:  "cron in the start's location",
+			rule:  "@daily",
+			start: ny(2024, 3, 9, 12, 0),
+			want:  []time.Time{ny(2024, 3, 10, 0, 0), ny(2024, 3, 11, 0, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.rule, tt.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// asking long before start must not yield anything earlier
+			after := tt.start.AddDate(-1, 0, 0)
+			for i, want := range tt.want {
+				got := schedule.Next(after)
+				if !got.Equal(want) {
+					t.Fatalf("occurrence %d is %s, want %s", i, got, want)
+				}
+				after = got
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rules := []string{
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240201",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=DAILY;COUNT",
+		"0 25 * * *",
+	}
+
+	for _, rule := range rules {
+		_, err := Parse(rule, start)
+		if !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Parse(%q) = %v, want ErrInvalidRule", rule, err)
+		}
+	}
+}
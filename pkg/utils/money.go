@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount, expected a decimal with at most two fractional digits")
+
+// DecimalCents formats cents as a plain decimal amount, "-1234.50", for
+// machine readable exports.
+func DecimalCents(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return sign + strconv.Itoa(cents/100) + "." + leftPad(strconv.Itoa(cents%100), 2)
+}
+
+// ParseDecimalCents parses a decimal amount as written in bank and gateway
+// reports, "1,234.5" or "-1234.50", into cents.
+func ParseDecimalCents(s string) (int, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	units, fraction, _ := strings.Cut(s, ".")
+	if units == "" || len(fraction) > 2 {
+		return 0, ErrInvalidAmount
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	cents, err := strconv.ParseUint(units+fraction, 10, 63)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		return -int(cents), nil
+	}
+	return int(cents), nil
+}
+
+// FormatMoney formats cents for people, "INR 1,23,456.50". INR amounts use
+// Indian digit grouping, other currencies group by thousands.
+func FormatMoney(cents int, currency string) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	units := strconv.Itoa(cents / 100)
+	if currency == "INR" {
+		units = groupDigits(units, 2)
+	} else {
+		units = groupDigits(units, 3)
+	}
+
+	return strings.TrimSpace(currency + " " + sign + units + "." + leftPad(strconv.Itoa(cents%100), 2))
+}
+
+// groupDigits separates the last three digits, then every size digits before
+// them, with commas.
+func groupDigits(digits string, size int) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	var groups []string
+	for len(head) > size {
+		groups = append([]string{head[len(head)-size:]}, groups...)
+		head = head[:len(head)-size]
+	}
+	groups = append([]string{head}, groups...)
+	return strings.Join(append(groups, tail), ",")
+}
+
+func leftPad(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return strings.Repeat("0", width-len(s)) + s
+}
@@ -0,0 +1,61 @@
+package utils
+
+import "testing"
+
+func TestDecimalCents(t *testing.T) {
+	tests := map[int]string{
+		0:        "0.00",
+		5:        "0.05",
+		123450:   "1234.50",
+		-123450:  "-1234.50",
+		-7:       "-0.07",
+		10000000: "100000.00",
+	}
+	for cents, want := range tests {
+		if got := DecimalCents(cents); got != want {
+			t.Errorf("DecimalCents(%d) = %q, want %q", cents, got, want)
+		}
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		cents    int
+		currency string
+		want     string
+	}{
+		{12345650, "INR", "INR 1,23,456.50"},
+		{1234567800, "INR", "INR 1,23,45,678.00"},
+		{99900, "INR", "INR 999.00"},
+		{12345650, "USD", "USD 123,456.50"},
+		{-100000, "USD", "USD -1,000.00"},
+		{5, "", "0.05"},
+	}
+	for _, tt := range tests {
+		if got := FormatMoney(tt.cents, tt.currency); got != tt.want {
+			t.Errorf("FormatMoney(%d, %q) = %q, want %q", tt.cents, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestParseDecimalCents(t *testing.T) {
+	tests := map[string]int{
+		"1,234.5":   123450,
+		" -1234.50": -123450,
+		"10":        1000,
+		"0.05":      5,
+		"7.":        700,
+	}
+	for s, want := range tests {
+		got, err := ParseDecimalCents(s)
+		if err != nil || got != want {
+			t.Errorf("ParseDecimalCents(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "-", ".50", "1.234", "1e3", "ten", "99999999999999999999"} {
+		if _, err := ParseDecimalCents(s); err != ErrInvalidAmount {
+			t.Errorf("ParseDecimalCents(%q) got %v, want ErrInvalidAmount", s, err)
+		}
+	}
+}
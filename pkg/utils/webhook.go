@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrWebhookURL is returned for webhook URLs that are not https or name
+	// an address CoinPe must not call, see CheckWebhookURL.
+	ErrWebhookURL = errors.New("webhook url must be https and reach a public address")
+
+	// ranges webhooks cannot reach besides the loopback, private, link-local,
+	// multicast and unspecified ones netip knows about
+	blockedWebhookPrefixes = []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),     // "this network"
+		netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT, some cloud metadata services
+		netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+		netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+		netip.MustParsePrefix("64:ff9b::/96"),  // NAT64 of any IPv4 address
+	}
+)
+
+// SignWebhook returns the signature of a webhook body, sha256=<hex
+// HMAC-SHA256 of body keyed with secret>.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckWebhookURL fails with ErrWebhookURL unless rawURL is an https URL
+// whose host is a name other than localhost or a public IP address. Names
+// are only resolved when dialled, see NewWebhookClient.
+func CheckWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return ErrWebhookURL
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookURL
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return ErrWebhookURL
+	}
+	return nil
+}
+
+// IsPublicAddr reports whether addr may be called by webhooks: it is not a
+// loopback, private, link-local, multicast or unspecified address, nor in
+// another range reserved for internal use.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range blockedWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewWebhookClient returns a client for calling webhook URLs given by API
+// callers. It refuses to connect to an address IsPublicAddr rejects. The
+// check runs on the resolved address of every connection, so a name that
+// resolves, or is rebound, to an internal address is refused too. It
+// ignores proxy settings and does not follow redirects, which are
+// returned as the response.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialPublicOnly,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func dialPublicOnly(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrWebhookURL, err)
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: refusing to dial %s", ErrWebhookURL, addrPort.Addr())
+	}
+	return nil
+}
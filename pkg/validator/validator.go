@@ -1,34 +1,126 @@
 package validator
 
 import (
+	errorConst "coinpe/pkg/error"
 	"coinpe/pkg/logger"
+	"coinpe/pkg/utils"
+	"errors"
+	"net/mail"
 	"reflect"
+	"regexp"
 	"strings"
 
+	"github.com/go-playground/locales"
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/pt_BR"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+	ptBRTranslations "github.com/go-playground/validator/v10/translations/pt_BR"
+	"golang.org/x/text/language"
 )
 
-func InitValidator() (*validator.Validate, ut.Translator, error) {
-	translator := en.New()
-	uni := ut.New(translator, translator)
+const DefaultLocale = "en"
 
-	// this is usually known or extracted from http 'Accept-Language' header
-	// also see uni.FindTranslator(...)
-	trans, found := uni.GetTranslator("en")
-	if !found {
-		logger.Fatal("translator not found")
-	}
+// e164Regex is stricter than the validator's builtin, which lets the country
+// code start with 0: a '+', a non zero digit and 7 to 14 more digits.
+var e164Regex = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+type localeTranslations struct {
+	locale   locales.Translator
+	register func(v *validator.Validate, trans ut.Translator) error
+	// custom messages for tags the builtin translations miss, keyed by tag
+	custom map[string]string
+}
+
+var supportedLocales = []localeTranslations{
+	{
+		locale:   en.New(),
+		register: enTranslations.RegisterDefaultTranslations,
+		custom: map[string]string{
+			"e164|email":  "{0} must be a valid E.164 phone number or email address",
+			"webhook_url": "{0} must be an https URL of a public host",
+		},
+	},
+	{
+		locale:   es.New(),
+		register: esTranslations.RegisterDefaultTranslations,
+		custom: map[string]string{
+			"e164|email":  "{0} debe ser un número de teléfono E.164 o una dirección de correo electrónico válida",
+			"webhook_url": "{0} debe ser una URL https de un host público",
+		},
+	},
+	{
+		locale:   fr.New(),
+		register: frTranslations.RegisterDefaultTranslations,
+		custom: map[string]string{
+			"e164":        "{0} doit être un numéro de téléphone au format E.164 valide",
+			"e164|email":  "{0} doit être un numéro de téléphone E.164 ou une adresse e-mail valide",
+			"webhook_url": "{0} doit être une URL https d'un hôte public",
+		},
+	},
+	{
+		locale:   pt_BR.New(),
+		register: ptBRTranslations.RegisterDefaultTranslations,
+		custom: map[string]string{
+			"e164":        "{0} deve ser um número de telefone válido no formato E.164",
+			"e164|email":  "{0} deve ser um número de telefone E.164 ou um endereço de e-mail válido",
+			"webhook_url": "{0} deve ser uma URL https de um host público",
+		},
+	},
+}
+
+func InitValidator() (*validator.Validate, *ut.UniversalTranslator, error) {
+	fallback := en.New()
+	uni := ut.New(fallback)
 
 	v := validator.New()
 
-	if err := enTranslations.RegisterDefaultTranslations(v, trans); err != nil {
-		logger.Fatal(err)
+	// override the builtin e164 and email rules with stricter ones, the
+	// builtin translations still apply as the tags are unchanged
+	err := v.RegisterValidation("e164", validateE164)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = v.RegisterValidation("email", validateEmail)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = v.RegisterValidation("webhook_url", validateWebhookURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, l := range supportedLocales {
+		err = uni.AddTranslator(l.locale, true)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		trans, _ := uni.GetTranslator(l.locale.Locale())
+		if err := l.register(v, trans); err != nil {
+			logger.Error("unable to register translations for ", l.locale.Locale(), " | err: ", err)
+			return nil, nil, err
+		}
+
+		for tag, text := range l.custom {
+			if err := registerTranslation(v, trans, tag, text); err != nil {
+				logger.Error("unable to register ", tag, " translation for ", l.locale.Locale(), " | err: ", err)
+				return nil, nil, err
+			}
+		}
+	}
+
+	trans, found := uni.GetTranslator(DefaultLocale)
+	if !found {
+		logger.Fatal("translator not found")
 	}
 
-	err := v.RegisterTranslation("required", trans, func(ut ut.Translator) error {
+	err = v.RegisterTranslation("required", trans, func(ut ut.Translator) error {
 		return ut.Add("required", "{0} is a required field", true) // see universal-translator for details
 	}, func(ut ut.Translator, fe validator.FieldError) string {
 		t, _ := ut.T("required", fe.Field())
@@ -46,5 +138,70 @@ func InitValidator() (*validator.Validate, ut.Translator, error) {
 		return name
 	})
 
-	return v, trans, nil
+	return v, uni, nil
+}
+
+func registerTranslation(v *validator.Validate, trans ut.Translator, tag, text string) error {
+	return v.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+		return ut.Add(tag, text, true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T(tag, fe.Field())
+		return t
+	})
+}
+
+func validateE164(fl validator.FieldLevel) bool {
+	return e164Regex.MatchString(fl.Field().String())
+}
+
+// validateEmail accepts a bare address (no display name) with a dotted domain.
+func validateEmail(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return false
+	}
+	_, domain, found := strings.Cut(address.Address, "@")
+	return found && strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+// validateWebhookURL accepts URLs CoinPe may POST webhooks to, see
+// utils.CheckWebhookURL.
+func validateWebhookURL(fl validator.FieldLevel) bool {
+	return utils.CheckWebhookURL(fl.Field().String()) == nil
+}
+
+// TranslatorFor picks the best supported translator for an Accept-Language
+// header, falling back to DefaultLocale.
+func TranslatorFor(uni *ut.UniversalTranslator, acceptLanguage string) ut.Translator {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+
+	candidates := make([]string, 0, len(tags)*2)
+	for _, tag := range tags {
+		// validator locales are named like "pt_BR", language tags like "pt-BR"
+		candidates = append(candidates, strings.ReplaceAll(tag.String(), "-", "_"))
+		base, _ := tag.Base()
+		candidates = append(candidates, base.String())
+	}
+
+	trans, _ := uni.FindTranslator(candidates...)
+	return trans
+}
+
+// FieldErrors translates validation failures into per field errors. It
+// returns nil when err is not a validation error.
+func FieldErrors(err error, trans ut.Translator) []errorConst.Error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fieldErrors := make([]errorConst.Error, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fieldErrors = append(fieldErrors, errorConst.Error{
+			Field:       fe.Field(),
+			Description: fe.Translate(trans),
+		})
+	}
+	return fieldErrors
 }
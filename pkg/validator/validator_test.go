@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"testing"
+)
+
+type signupRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+	Email       string `json:"email" validate:"omitempty,email"`
+	CallbackURL string `json:"callback_url" validate:"omitempty,webhook_url"`
+}
+
+func TestValidate(t *testing.T) {
+	v, uni, err := InitValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans := TranslatorFor(uni, "en")
+
+	cases := []struct {
+		name    string
+		request signupRequest
+		// field of the only error, empty for a valid request
+		field string
+		want  string
+	}{
+		{"valid", signupRequest{PhoneNumber: "+919100000001", Email: "a@coinpe.in", CallbackURL: "https://hooks.example.com/coinpe"}, "", ""},
+		{"missing phone", signupRequest{}, "phone_number", "phone_number is a required field"},
+		{"country code starting with 0", signupRequest{PhoneNumber: "+0919100000001"}, "phone_number", ""},
+		{"display name", signupRequest{PhoneNumber: "+919100000001", Email: "A <a@coinpe.in>"}, "email", ""},
+		{"undotted domain", signupRequest{PhoneNumber: "+919100000001", Email: "a@coinpe"}, "email", ""},
+		{"http callback", signupRequest{PhoneNumber: "+919100000001", CallbackURL: "http://hooks.example.com"}, "callback_url", "callback_url must be an https URL of a public host"},
+		{"private callback", signupRequest{PhoneNumber: "+919100000001", CallbackURL: "https://10.0.0.8/hook"}, "callback_url", "callback_url must be an https URL of a public host"},
+		{"metadata callback", signupRequest{PhoneNumber: "+919100000001", CallbackURL: "https://169.254.169.254/latest"}, "callback_url", ""},
+	}
+	for _, tc := range cases {
+		fieldErrors := FieldErrors(v.Struct(tc.request), trans)
+		if tc.field == "" {
+			if len(fieldErrors) != 0 {
+				t.Errorf("%s: got %v, want no errors", tc.name, fieldErrors)
+			}
+			continue
+		}
+		if len(fieldErrors) != 1 || fieldErrors[0].Field != tc.field {
+			t.Errorf("%s: got %v, want one error on %s", tc.name, fieldErrors, tc.field)
+			continue
+		}
+		if tc.want != "" && fieldErrors[0].Description != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, fieldErrors[0].Description, tc.want)
+		}
+	}
+}
+
+func TestTranslatorFor(t *testing.T) {
+	_, uni, err := InitValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"":                     DefaultLocale,
+		"fr-CA,fr;q=0.9":       "fr",
+		"pt-BR,pt;q=0.9":       "pt_BR",
+		"de-DE,es;q=0.5":       "es",
+		"de-DE":                DefaultLocale,
+		"not a language range": DefaultLocale,
+	}
+	for acceptLanguage, want := range cases {
+		if got := TranslatorFor(uni, acceptLanguage).Locale(); got != want {
+			t.Errorf("TranslatorFor(%q) = %s, want %s", acceptLanguage, got, want)
+		}
+	}
+}
+
+func TestFieldErrorsIgnoresOtherErrors(t *testing.T) {
+	_, uni, err := InitValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FieldErrors(nil, TranslatorFor(uni, "")); got != nil {
+		t.Errorf("FieldErrors(nil) = %v, want nil", got)
+	}
+}
@@ -1,17 +1,21 @@
 package middleware
 
 import (
+	"coinpe/models"
 	"coinpe/pkg/constants"
 	"coinpe/pkg/jwtauth"
 	"coinpe/pkg/logger"
-	"net/http"
 	"strings"
 
 	errorConst "coinpe/pkg/error"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// StreamTicketQueryParam carries the ticket of StreamAccessTokenMiddleware.
+const StreamTicketQueryParam = "ticket"
+
 func parseBearerToken(c *gin.Context) string {
 	authHeader := c.GetHeader(constants.AuthorizationHeaderName)
 	tokenString, found := strings.CutPrefix(authHeader, "Bearer")
@@ -22,23 +26,50 @@ func parseBearerToken(c *gin.Context) string {
 }
 
 func AccessTokenMiddleware(secretKey []byte, allowPartial bool, allowNoAuth bool) gin.HandlerFunc {
+	return accessTokenMiddleware(secretKey, allowPartial, allowNoAuth, parseBearerToken)
+}
+
+// StreamAccessTokenMiddleware authenticates long lived event streams with a
+// full auth scoped token in the Authorization header or, for browser
+// EventSource and WebSocket clients, which cannot set headers, a stream
+// ticket in the ticket query param. A ticket is redeemed once.
+func StreamAccessTokenMiddleware(secretKey []byte, tickets models.IStreamTicket) gin.HandlerFunc {
+	withToken := accessTokenMiddleware(secretKey, false, false, parseBearerToken)
 	return func(c *gin.Context) {
-		var (
-			errResponse = errorConst.ErrorResponse{}
-		)
+		ticket := c.Query(StreamTicketQueryParam)
+		if ticket == "" || parseBearerToken(c) != "" {
+			withToken(c)
+			return
+		}
 
-		tokenString := parseBearerToken(c)
+		redeemed, err := tickets.Redeem(ticket)
+		if err == gorm.ErrRecordNotFound {
+			logger.Error("stream ticket is invalid, used or expired")
+			abortWithError(c, errorConst.New(errorConst.ErrorUnauthorized, errorConst.MsgInvalidStreamTicket))
+			return
+		}
+		if err != nil {
+			abortWithError(c, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInRedeemingStreamTicket))
+			return
+		}
+
+		c.Set(constants.AuthorizedAccountUUIDContextKey, redeemed.AccountUUID)
+		c.Set(constants.AuthorizedAccountRoleContextKey, redeemed.Role)
+		c.Set(constants.IsPartialContextKey, false)
+		c.Next()
+	}
+}
+
+func accessTokenMiddleware(secretKey []byte, allowPartial bool, allowNoAuth bool, parseToken func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := parseToken(c)
 		if tokenString == "" {
 			logger.Error("auth header cannot be empty")
 			if allowNoAuth {
 				// if no auth is there  and token is not present allow them directly
 				c.Next()
 			} else {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, errResponse.Generate(
-					errorConst.ErrorForbidden,
-					errorConst.ErrorText(errorConst.ErrorForbidden),
-					errorConst.EmptyInterface,
-				))
+				abortWithError(c, errorConst.New(errorConst.ErrorUnauthorized, errorConst.MsgMissingAccessToken))
 			}
 			return
 		}
@@ -50,32 +81,26 @@ func AccessTokenMiddleware(secretKey []byte, allowPartial bool, allowNoAuth bool
 				// if no auth is there  and token is not present allow them directly
 				c.Next()
 			} else {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, errResponse.Generate(
-					errorConst.ErrorForbidden,
-					errorConst.ErrorText(errorConst.ErrorForbidden),
-					errorConst.EmptyInterface,
-				))
+				abortWithError(c, errorConst.Wrap(err, errorConst.ErrorUnauthorized, errorConst.MsgInvalidAccessToken))
 			}
 			return
 		}
 
+		if token.TokenType == jwtauth.TokenTypeRefresh {
+			logger.Error("refresh token cannot be used as an access token")
+			abortWithError(c, errorConst.New(errorConst.ErrorUnauthorized, errorConst.MsgInvalidAccessToken))
+			return
+		}
+
 		if token.IsPartial && !allowPartial {
 			logger.Error("cannot mix tokentype partial with full auth scoped token")
-			c.AbortWithStatusJSON(http.StatusForbidden, errResponse.Generate(
-				errorConst.ErrorForbidden,
-				errorConst.ErrorText(errorConst.ErrorForbidden),
-				errorConst.EmptyInterface,
-			))
+			abortWithError(c, errorConst.New(errorConst.ErrorForbidden, errorConst.MsgFullAuthScopedTokenRequired))
 			return
 		}
 
 		if !token.IsPartial && allowPartial {
 			logger.Error("cannot mix tokentype partial with full auth scoped token")
-			c.AbortWithStatusJSON(http.StatusForbidden, errResponse.Generate(
-				errorConst.ErrorForbidden,
-				errorConst.ErrorText(errorConst.ErrorForbidden),
-				errorConst.EmptyInterface,
-			))
+			abortWithError(c, errorConst.New(errorConst.ErrorForbidden, errorConst.MsgPartialAuthScopedTokenRequired))
 			return
 		}
 
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"coinpe/models/modelstest"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/jwtauth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamAccessTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secretKey := []byte("stream-test-secret")
+	tickets := modelstest.NewStreamTickets()
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/stream", StreamAccessTokenMiddleware(secretKey, tickets), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(constants.AuthorizedAccountUUIDContextKey))
+	})
+	stream := func(query string, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/stream"+query, nil)
+		if bearer != "" {
+			req.Header.Set(constants.AuthorizationHeaderName, "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	token, err := jwtauth.NewTokenWithClaims(secretKey, jwtauth.CustomClaims{
+		AccountUUID: "acc_stream",
+		TokenType:   jwtauth.TokenTypeAccess,
+	}, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := stream("", *token)
+	if w.Code != http.StatusOK || w.Body.String() != "acc_stream" {
+		t.Errorf("bearer token got %d %q, want 200 acc_stream", w.Code, w.Body.String())
+	}
+
+	w = stream("?access_token="+*token, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("token in the query got %d, want 401", w.Code)
+	}
+
+	ticket, _, err := tickets.Issue("acc_stream", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = stream("?ticket="+ticket, "")
+	if w.Code != http.StatusOK || w.Body.String() != "acc_stream" {
+		t.Errorf("ticket got %d %q, want 200 acc_stream", w.Code, w.Body.String())
+	}
+	w = stream("?ticket="+ticket, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("redeemed ticket got %d, want 401", w.Code)
+	}
+
+	expired, _, err := tickets.Issue("acc_stream", "", -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = stream("?ticket="+expired, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expired ticket got %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"coinpe/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadYourWrites scopes replica stickiness to the request, see
+// database.WithReadYourWrites. Handlers must use the request context.
+func ReadYourWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(database.WithReadYourWrites(c.Request.Context()))
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"coinpe/pkg/logger"
+
+	errorConst "coinpe/pkg/error"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handle adapts a controller that returns an error into a gin handler. The
+// error is recorded on the context and rendered by ErrorHandler.
+func Handle(handler func(c *gin.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := handler(c)
+		if err != nil {
+			abortWithError(c, err)
+		}
+	}
+}
+
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// ErrorHandler renders the last error recorded on the context as an
+// errorConst.ErrorResponse, unless a response has already been written.
+// Errors that are not a DomainError become a 500 and are logged.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		writeError(c)
+	}
+}
+
+// writeError renders the last error recorded on the context and returns it,
+// or nil when there is none or a response has already been written.
+func writeError(c *gin.Context) *errorConst.DomainError {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return nil
+	}
+
+	err := errorConst.FromError(c.Errors.Last().Err)
+	if err.Code == errorConst.ErrorInternalError && err.Err != nil {
+		logger.Error(c.Request.Method, " ", c.FullPath(), " failed | err: ", err)
+	}
+
+	c.JSON(err.HTTPStatus(), err.Response(c.GetHeader("Accept-Language")))
+	return err
+}
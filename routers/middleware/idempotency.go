@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/logger"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	errorConst "coinpe/pkg/error"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware replays the stored response when a request is retried
+// with the same Idempotency-Key. Keys are scoped to the authorized account and
+// route, so it must run after the access token middleware. Requests without
+// the header are passed through untouched. A key is held for
+// models.IdempotencyKeyLockTTL while its request runs and replays the
+// response for models.IdempotencyKeyRetention. Server errors and retryable
+// errors, like a busy wallet, release the key so a retry runs again.
+func IdempotencyMiddleware(idempotencyKeyRepo models.IIdempotencyKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(constants.IdempotencyKeyHeaderName)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, errorConst.New(errorConst.ErrorBadRequest, errorConst.MsgIdempotencyKeyIsTooLong))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			logger.Error("unable to read request body | err: ", err)
+			abortWithError(c, errorConst.Wrap(err, errorConst.ErrorBindingRequest, errorConst.MsgUnreadableBody))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(body)
+		record := &models.IdempotencyKey{
+			Scope:       c.GetString(constants.AuthorizedAccountUUIDContextKey) + " " + c.Request.Method + " " + c.FullPath(),
+			Key:         key,
+			RequestHash: hex.EncodeToString(hash[:]),
+		}
+
+		reserved, err := idempotencyKeyRepo.Reserve(record)
+		if err != nil {
+			abortWithError(c, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInReservingIdempotencyKey))
+			return
+		}
+
+		if !reserved {
+			existing, err := idempotencyKeyRepo.Get(record.Scope, record.Key)
+			if err != nil {
+				logger.Error("unable to get idempotency key | err: ", err)
+				abortWithError(c, errorConst.Wrap(err, errorConst.ErrorInternalError, errorConst.MsgErrorInGettingIdempotencyKey))
+				return
+			}
+
+			if existing.RequestHash != record.RequestHash {
+				abortWithError(c, errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgIdempotencyKeyReused))
+				return
+			}
+
+			if existing.CompletedAt == nil {
+				abortWithError(c, errorConst.New(errorConst.ErrorConflict, errorConst.MsgIdempotencyKeyInProgress).WithRetryable(true))
+				return
+			}
+
+			c.Header(constants.IdempotentReplayedHeaderName, "true")
+			c.Data(existing.ResponseStatus, "application/json; charset=utf-8", existing.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// render handler errors now rather than in ErrorHandler so they are recorded
+		rendered := writeError(c)
+
+		// server and retryable errors are not final, release the key so the
+		// client can retry
+		if recorder.Status() >= http.StatusInternalServerError || (rendered != nil && rendered.Retryable) {
+			idempotencyKeyRepo.Release(record)
+			return
+		}
+
+		idempotencyKeyRepo.Complete(record, recorder.Status(), recorder.body.Bytes())
+	}
+}
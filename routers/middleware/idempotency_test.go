@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"coinpe/models/modelstest"
+	"coinpe/pkg/constants"
+	errorConst "coinpe/pkg/error"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var (
+		calls int
+		fail  error
+	)
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.POST("/transfers", IdempotencyMiddleware(modelstest.NewIdempotencyKeys()), Handle(func(c *gin.Context) error {
+		calls++
+		if fail != nil {
+			return fail
+		}
+		c.JSON(http.StatusCreated, gin.H{"reference_id": fmt.Sprintf("tr_%d", calls)})
+		return nil
+	}))
+	post := func(key string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(constants.IdempotencyKeyHeaderName, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post("k1", `{"amount_in_cents":500}`)
+	replayed := post("k1", `{"amount_in_cents":500}`)
+	if calls != 1 || replayed.Code != first.Code || replayed.Body.String() != first.Body.String() {
+		t.Errorf("retry ran the handler again (%d calls) or got %d %s, want %d %s", calls, replayed.Code, replayed.Body, first.Code, first.Body)
+	}
+	if replayed.Header().Get(constants.IdempotentReplayedHeaderName) != "true" {
+		t.Error("replayed response is missing the replayed header")
+	}
+
+	w := post("k1", `{"amount_in_cents":600}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("another body under the key got %d, want 422", w.Code)
+	}
+
+	// a busy wallet posted nothing, the retry must run rather than replay the conflict
+	fail = errorConst.New(errorConst.ErrorConflict, errorConst.MsgWalletIsBusyRetryTheRequest).WithRetryable(true)
+	w = post("k2", `{"amount_in_cents":500}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("busy wallet got %d, want 409", w.Code)
+	}
+	fail = nil
+	w = post("k2", `{"amount_in_cents":500}`)
+	if w.Code != http.StatusCreated || calls != 3 {
+		t.Errorf("retry after a retryable conflict got %d after %d calls, want 201 after 3", w.Code, calls)
+	}
+
+	// a final client error is replayed
+	fail = errorConst.New(errorConst.ErrorUnprocessable, errorConst.MsgInsufficientFunds)
+	post("k3", `{"amount_in_cents":500}`)
+	fail = nil
+	w = post("k3", `{"amount_in_cents":500}`)
+	if w.Code != http.StatusUnprocessableEntity || calls != 4 {
+		t.Errorf("retry after a final error got %d after %d calls, want the 422 replayed after 4", w.Code, calls)
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import "testing"
+
+func TestRedactQuery(t *testing.T) {
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"/v1/wallets/wa_1/events", "/v1/wallets/wa_1/events"},
+		{"/v1/wallets/wa_1/events?ticket=st_abc", "/v1/wallets/wa_1/events?ticket=REDACTED"},
+		{"/v1/wallets/wa_1/events?after=4&access_token=eyJ.x.y&ticket=st_abc", "/v1/wallets/wa_1/events?after=4&access_token=REDACTED&ticket=REDACTED"},
+		{"/v1/wallets/wa_1/events?access%5Ftoken=eyJ", "/v1/wallets/wa_1/events?access%5Ftoken=REDACTED"},
+		{"/v1/wallets?tickets=3", "/v1/wallets?tickets=3"},
+	}
+	for _, tc := range cases {
+		got := redactQuery(tc.path)
+		if got != tc.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package routers
+
+import (
+	"coinpe/pkg/config"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/logger"
+	"coinpe/pkg/openapi"
+)
+
+var openAPIConfig = openapi.Config{
+	Info: openapi.Info{
+		Title:       "CoinPe",
+		Description: "Digital wallet ledger for virtual coins",
+		Version:     "v1",
+	},
+	ErrorResponse: errorConst.ErrorResponse{},
+}
+
+// registerOpenAPIRoutes serves the spec of routes and its docs UI. Routes
+// registered without a doc are left out of the spec, TestOpenAPIRoutes
+// catches them.
+func registerOpenAPIRoutes(app config.App, routes []openapi.Route) {
+	doc, err := openapi.Generate(openAPIConfig, app.Router.Routes(), routes)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	handler, err := openapi.JSONHandler(doc)
+	if err != nil {
+		logger.Fatal("unable to encode openapi document ", err)
+	}
+
+	app.Router.GET("/openapi.json", handler)
+	openapi.RegisterUI(app.Router)
+}
@@ -0,0 +1,98 @@
+package routers
+
+import (
+	"coinpe/controllers"
+	"coinpe/pkg/config"
+	"coinpe/pkg/openapi"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestApp() config.App {
+	gin.SetMode(gin.TestMode)
+	return config.App{Router: gin.New()}
+}
+
+// TestOpenAPIRoutes fails when a route is registered without a doc, so the
+// served spec cannot drift from the router.
+func TestOpenAPIRoutes(t *testing.T) {
+	app := newTestApp()
+	routes := apiRoutes(app, controllers.BaseController{})
+
+	doc, err := openapi.Generate(openAPIConfig, app.Router.Routes(), routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		operations   int
+		operationIDs = map[string]string{}
+	)
+	for path, methods := range doc.Paths {
+		for method, operation := range methods {
+			operations++
+			key := strings.ToUpper(method) + " " + path
+			if other, ok := operationIDs[operation.OperationID]; ok {
+				t.Errorf("operation ID %s of %s is also used by %s", operation.OperationID, key, other)
+			}
+			operationIDs[operation.OperationID] = key
+			if operation.Tags == nil {
+				t.Errorf("%s has no tag", key)
+			}
+			if operation.Summary == "" {
+				t.Errorf("%s has no summary", key)
+			}
+		}
+	}
+	if operations != len(app.Router.Routes()) {
+		t.Errorf("spec has %d operations, the router %d routes", operations, len(app.Router.Routes()))
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	app := newTestApp()
+	RegisterRoutes(app, controllers.BaseController{})
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{"/openapi.json", "application/json"},
+		{"/docs", "text/html"},
+		{"/docs/assets/init.js", "javascript"},
+		{"/docs/assets/swagger-ui-bundle.js", "javascript"},
+		{"/docs/assets/swagger-ui.css", "text/css"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d", w.Code)
+			}
+			if contentType := w.Header().Get("Content-Type"); !strings.Contains(contentType, tt.contentType) {
+				t.Errorf("content type %q, want %s", contentType, tt.contentType)
+			}
+			if strings.Contains(w.Body.String(), "unpkg.com") {
+				t.Error("loads assets from a CDN")
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc openapi.Document
+	err := json.Unmarshal(w.Body.Bytes(), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operation := doc.Paths["/v1/transfers"]["post"]
+	if operation == nil || operation.OperationID != "CreateTransfer" || operation.RequestBody == nil || len(operation.Security) == 0 {
+		t.Fatalf("POST /v1/transfers is not documented from its route: %+v", operation)
+	}
+}
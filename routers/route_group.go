@@ -0,0 +1,91 @@
+package routers
+
+import (
+	"coinpe/models"
+	"coinpe/pkg/constants"
+	"coinpe/pkg/openapi"
+	"coinpe/routers/middleware"
+	"path"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// routeGroup registers routes on a gin router group together with their
+// OpenAPI doc. The method, path, operation ID, tag, auth and idempotency
+// header of a route come from how it is registered, the doc only adds its
+// summary, parameters and body types, whose struct tags become the schema.
+type routeGroup struct {
+	router      *gin.RouterGroup
+	tag         string
+	auth        openapi.AuthMode
+	idempotency gin.HandlerFunc
+	docs        *[]openapi.Route
+}
+
+func newRouteGroup(router *gin.Engine, db *gorm.DB) routeGroup {
+	return routeGroup{
+		router:      &router.RouterGroup,
+		idempotency: middleware.IdempotencyMiddleware(models.InitIdempotencyKeyRepo(db)),
+		docs:        &[]openapi.Route{},
+	}
+}
+
+// group returns the routes under relativePath, tagged tag and behind
+// middlewares, which authenticate requests as auth says.
+func (g routeGroup) group(relativePath string, tag string, auth openapi.AuthMode, middlewares ...gin.HandlerFunc) routeGroup {
+	g.router = g.router.Group(relativePath, middlewares...)
+	g.tag = tag
+	g.auth = auth
+	return g
+}
+
+// handle registers handler, wrapped by middleware.Handle, and documents it
+// with doc. The operation ID is the name of handler.
+func (g routeGroup) handle(method string, relativePath string, handler func(c *gin.Context) error, doc openapi.Route, middlewares ...gin.HandlerFunc) {
+	g.router.Handle(method, relativePath, slices.Concat(middlewares, []gin.HandlerFunc{middleware.Handle(handler)})...)
+
+	doc.Method = method
+	doc.Path = joinPaths(g.router.BasePath(), relativePath)
+	if doc.OperationID == "" {
+		doc.OperationID = handlerName(handler)
+	}
+	if doc.Tag == "" {
+		doc.Tag = g.tag
+	}
+	if doc.Auth == openapi.AuthNone {
+		doc.Auth = g.auth
+	}
+	*g.docs = append(*g.docs, doc)
+}
+
+// handleIdempotent is handle behind middleware.IdempotencyMiddleware.
+func (g routeGroup) handleIdempotent(method string, relativePath string, handler func(c *gin.Context) error, doc openapi.Route) {
+	doc.Headers = append([]openapi.Param{
+		{Name: constants.IdempotencyKeyHeaderName, Description: "Retries with the same key replay the first response"},
+	}, doc.Headers...)
+	g.handle(method, relativePath, handler, doc, g.idempotency)
+}
+
+// joinPaths joins paths the way gin does for route groups.
+func joinPaths(absolutePath string, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
+// handlerName returns the method name of a controller method value, e.g.
+// "coinpe/controllers.BaseController.CreateAccount-fm" → "CreateAccount".
+func handlerName(handler func(c *gin.Context) error) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	return strings.TrimSuffix(name[strings.LastIndex(name, ".")+1:], "-fm")
+}
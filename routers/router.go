@@ -3,6 +3,9 @@ package routers
 import (
 	"coinpe/controllers"
 	"coinpe/pkg/config"
+	errorConst "coinpe/pkg/error"
+	"coinpe/pkg/openapi"
+	"coinpe/routers/middleware"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,25 +13,49 @@ import (
 
 // RegisterRoutes add all routing list here automatically get main router
 func RegisterRoutes(app config.App, ctrl controllers.BaseController) {
+	// renders errors returned by handlers, must run before any route is added
+	app.Router.Use(middleware.ErrorHandler())
+	app.Router.Use(middleware.ReadYourWrites())
+
 	app.Router.NoRoute(func(ctx *gin.Context) {
-		ctx.JSON(http.StatusNotFound, gin.H{"status": http.StatusNotFound, "message": "Route Not Found"})
+		ctx.Error(errorConst.New(errorConst.ErrorNoRecordsFound, errorConst.MsgRouteNotFound))
 	})
 
-	app.Router.GET("/health", func(ctx *gin.Context) {
+	// Register All routes
+	routes := apiRoutes(app, ctrl)
+
+	// Must stay last, the spec is generated from the routes registered above
+	registerOpenAPIRoutes(app, routes)
+}
+
+// apiRoutes registers every documented route and returns their docs.
+func apiRoutes(app config.App, ctrl controllers.BaseController) []openapi.Route {
+	root := newRouteGroup(app.Router, app.DB)
+
+	root.handle(http.MethodGet, "/health", func(ctx *gin.Context) error {
 		// Send a ping to make sure the database connection is alive.
 		db, err := app.DB.DB()
 		if err != nil {
 			ctx.JSON(http.StatusServiceUnavailable, gin.H{"live": "not ok"})
-			return
+			return nil
 		}
 		err = db.PingContext(ctx)
 		if err != nil {
 			ctx.JSON(http.StatusServiceUnavailable, gin.H{"live": "not ok"})
-			return
+			return nil
 		}
 		ctx.JSON(http.StatusOK, gin.H{"live": "ok"})
+		return nil
+	}, openapi.Route{
+		OperationID: "Health",
+		Summary:     "Liveness check including the database connection",
+		Tag:         "system",
+		Responses: map[int]interface{}{
+			http.StatusOK:                 map[string]string{},
+			http.StatusServiceUnavailable: map[string]string{},
+		},
 	})
 
-	// Register All routes
-	v1Routes(app, ctrl)
+	v1Routes(app, root, ctrl)
+	return *root.docs
 }
@@ -2,15 +2,559 @@ package routers
 
 import (
 	"coinpe/controllers"
+	"coinpe/models"
 	"coinpe/pkg/config"
+	"coinpe/pkg/openapi"
+	"coinpe/routers/middleware"
+	"net/http"
 )
 
-func v1Routes(app config.App, ctrl controllers.BaseController) {
-	v1 := app.Router.Group("/v1")
+func v1Routes(app config.App, root routeGroup, ctrl controllers.BaseController) {
+	var (
+		secretKey   = []byte(app.Config.JWTConfiguration.SecretKey)
+		accessToken = middleware.AccessTokenMiddleware(secretKey, false, false)
+	)
 
-	accountGroup := v1.Group("/accounts")
-	accountGroup.POST("", ctrl.CreateAccount)
+	v1 := root.group("/v1", "auth", openapi.AuthNone)
 
-	v1.POST("/authenticate", ctrl.Authenticate)
-	v1.POST("/verify", ctrl.VerifyAuthenticate)
+	accountGroup := v1.group("/accounts", "auth", openapi.AuthNone)
+	accountGroup.handle(http.MethodPost, "", ctrl.CreateAccount, openapi.Route{
+		Summary: "Create an account and its wallet, returns a partial auth token",
+		Request: controllers.CreateAccountRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.AuthenticateResponse{},
+		},
+	})
+
+	v1.handle(http.MethodPost, "/authenticate", ctrl.Authenticate, openapi.Route{
+		Summary: "Start a login, returns a partial auth token",
+		Request: controllers.AuthenticateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:       controllers.AuthenticateResponse{},
+			http.StatusNotFound: controllers.AuthenticateResponse{},
+		},
+	})
+	v1.handle(http.MethodPost, "/verify", ctrl.VerifyAuthenticate, openapi.Route{
+		Summary: "Verify the OTP, returns full auth access and refresh tokens",
+		Request: controllers.VerifyAuthRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.AuthenticateResponse{},
+		},
+	})
+	v1.handle(http.MethodPost, "/refresh", ctrl.RefreshToken, openapi.Route{
+		Summary: "Exchange a refresh token for new access and refresh tokens",
+		Request: controllers.RefreshTokenRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.AuthenticateResponse{},
+		},
+	})
+
+	walletGroup := v1.group("/wallets", "wallets", openapi.AuthBearer, accessToken)
+	walletGroup.handle(http.MethodGet, "/me", ctrl.GetMyWallet, openapi.Route{
+		Summary: "Balance of the authenticated account's wallet",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.WalletBalanceResponse{},
+		},
+	})
+	walletGroup.handle(http.MethodGet, "/me/chain", ctrl.GetMyWalletChain, openapi.Route{
+		Summary: "Verify the transaction hash chain of the authenticated account's wallet",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.WalletChainResponse{},
+		},
+	})
+	walletGroup.handle(http.MethodGet, "/me/balance", ctrl.GetMyBalanceAt, openapi.Route{
+		Summary: "Balance of the authenticated account's wallet at a point in time",
+		Query: []openapi.Param{
+			{Name: "at", Description: "RFC 3339 timestamp, transactions created at or before it are included", Required: true},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.BalanceAtResponse{},
+		},
+	})
+	walletGroup.handle(http.MethodGet, "/me/statement", ctrl.GetMyStatement, openapi.Route{
+		Summary: "Statement of the authenticated account's wallet for a period",
+		Query: []openapi.Param{
+			{Name: "from", Description: "RFC 3339 start of the period, inclusive", Required: true},
+			{Name: "to", Description: "RFC 3339 end of the period, exclusive", Required: true},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.StatementResponse{},
+		},
+	})
+	walletGroup.handle(http.MethodGet, "/me/statement.csv", ctrl.ExportMyStatementCSV, openapi.Route{
+		Summary:     "Streamed CSV statement of the authenticated account's wallet, the checksum is the last row and the Statement-Checksum trailer",
+		ContentType: openapi.ContentTypeCSV,
+		Query: []openapi.Param{
+			{Name: "from", Description: "RFC 3339 start of the period, inclusive", Required: true},
+			{Name: "to", Description: "RFC 3339 end of the period, exclusive", Required: true},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: "",
+		},
+	})
+	walletGroup.handle(http.MethodGet, "/me/statement.pdf", ctrl.ExportMyStatementPDF, openapi.Route{
+		Summary:     "PDF statement of the authenticated account's wallet with the checksum printed, also sent as the Statement-Checksum header",
+		ContentType: openapi.ContentTypePDF,
+		Query: []openapi.Param{
+			{Name: "from", Description: "RFC 3339 start of the period, inclusive", Required: true},
+			{Name: "to", Description: "RFC 3339 end of the period, exclusive", Required: true},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: "",
+		},
+	})
+
+	walletGroup.handle(http.MethodPost, "/me/stream-tickets", ctrl.CreateStreamTicket, openapi.Route{
+		Summary: "Single use ticket opening a wallet event stream, for browsers that cannot send the access token in a header",
+		Responses: map[int]interface{}{
+			http.StatusCreated: controllers.StreamTicket{},
+		},
+	})
+
+	walletStreamGroup := v1.group("/wallets", "wallets", openapi.AuthBearerOrTicket, middleware.StreamAccessTokenMiddleware(secretKey, models.InitStreamTicketRepo(app.DB)))
+	walletStreamGroup.handle(http.MethodGet, "/:wallet_uuid/events", ctrl.StreamWalletEvents, openapi.Route{
+		Summary:     "Server-Sent Events stream of wallet balance and transaction events",
+		ContentType: openapi.ContentTypeEventStream,
+		Query: []openapi.Param{
+			{Name: "last_event_id", Description: "Resume after the transaction event with this ID"},
+		},
+		Headers: []openapi.Param{
+			{Name: "Last-Event-ID", Description: "Resume after the transaction event with this ID"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.WalletEvent{},
+		},
+	})
+	walletStreamGroup.handle(http.MethodGet, "/:wallet_uuid/ws", ctrl.StreamWalletEventsWebSocket, openapi.Route{
+		Summary: "WebSocket stream of wallet events as JSON messages",
+		Query: []openapi.Param{
+			{Name: "last_event_id", Description: "Resume after the transaction event with this ID"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusSwitchingProtocols: nil,
+		},
+	})
+
+	transferGroup := v1.group("/transfers", "transfers", openapi.AuthBearer, accessToken)
+	transferGroup.handleIdempotent(http.MethodPost, "", ctrl.CreateTransfer, openapi.Route{
+		Summary: "Transfer coins from the authenticated account's wallet",
+		Request: controllers.CreateTransferRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.TransferResponse{},
+		},
+	})
+	transferGroup.handleIdempotent(http.MethodPost, "/async", ctrl.SubmitTransfer, openapi.Route{
+		Summary: "Queue a transfer from the authenticated account's wallet, a queue worker posts it",
+		Request: controllers.SubmitTransferRequest{},
+		Responses: map[int]interface{}{
+			http.StatusAccepted: controllers.PendingTransaction{},
+		},
+	})
+	transferGroup.handle(http.MethodGet, "/async/:pending_transaction_uuid", ctrl.GetPendingTransaction, openapi.Route{
+		Summary: "Status of a queued transfer, with its transactions once it succeeded",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.PendingTransaction{},
+		},
+	})
+	transferGroup.handleIdempotent(http.MethodPost, "/batches", ctrl.SubmitTransferBatch, openapi.Route{
+		Summary: "Queue up to 1000 transfers from the authenticated account's wallet at once",
+		Request: controllers.SubmitTransferBatchRequest{},
+		Responses: map[int]interface{}{
+			http.StatusAccepted: controllers.TransferBatch{},
+		},
+	})
+	transferGroup.handle(http.MethodGet, "/batches/:batch_uuid", ctrl.GetTransferBatch, openapi.Route{
+		Summary: "Status of every queued transfer of a batch",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.TransferBatch{},
+		},
+	})
+
+	scheduledTransferGroup := v1.group("/scheduled-transfers", "scheduled-transfers", openapi.AuthBearer, accessToken)
+	scheduledTransferGroup.handleIdempotent(http.MethodPost, "", ctrl.CreateScheduledTransfer, openapi.Route{
+		Summary: "Schedule one-off or recurring transfers from the authenticated account's wallet, with a cron expression or an RRULE",
+		Request: controllers.CreateScheduledTransferRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated: controllers.ScheduledTransfer{},
+		},
+	})
+	scheduledTransferGroup.handle(http.MethodGet, "", ctrl.ListScheduledTransfers, openapi.Route{
+		Summary: "Scheduled transfers of the authenticated account, newest first",
+		Query: []openapi.Param{
+			{Name: "status", Description: "ACTIVE, PAUSED, CANCELLED or COMPLETED"},
+			{Name: "before", Description: "UUID of the last scheduled transfer of the previous page"},
+			{Name: "limit", Description: "Page size, 20 by default, at most 100"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ListScheduledTransfersResponse{},
+		},
+	})
+	scheduledTransferGroup.handle(http.MethodGet, "/:scheduled_transfer_uuid", ctrl.GetScheduledTransfer, openapi.Route{
+		Summary: "A scheduled transfer with its latest runs",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ScheduledTransfer{},
+		},
+	})
+	scheduledTransferGroup.handle(http.MethodPost, "/:scheduled_transfer_uuid/pause", ctrl.PauseScheduledTransfer, openapi.Route{
+		Summary: "Pause an active scheduled transfer",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ScheduledTransfer{},
+		},
+	})
+	scheduledTransferGroup.handle(http.MethodPost, "/:scheduled_transfer_uuid/resume", ctrl.ResumeScheduledTransfer, openapi.Route{
+		Summary: "Resume a paused scheduled transfer, skipping occurrences missed while paused",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ScheduledTransfer{},
+		},
+	})
+	scheduledTransferGroup.handle(http.MethodPost, "/:scheduled_transfer_uuid/cancel", ctrl.CancelScheduledTransfer, openapi.Route{
+		Summary: "Cancel a scheduled transfer",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ScheduledTransfer{},
+		},
+	})
+
+	paymentRequestGroup := v1.group("/payment-requests", "payment-requests", openapi.AuthBearer, accessToken)
+	paymentRequestGroup.handleIdempotent(http.MethodPost, "", ctrl.CreatePaymentRequest, openapi.Route{
+		Summary: "Ask the owner of a wallet to pay the authenticated account's wallet",
+		Request: controllers.CreatePaymentRequestRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated: controllers.PaymentRequest{},
+		},
+	})
+	paymentRequestGroup.handle(http.MethodGet, "", ctrl.ListPaymentRequests, openapi.Route{
+		Summary: "Payment requests the authenticated account has to pay, or has made, newest first",
+		Query: []openapi.Param{
+			{Name: "direction", Description: "incoming, the default, or outgoing"},
+			{Name: "status", Description: "PENDING, PAID, DECLINED, CANCELLED or EXPIRED"},
+			{Name: "before", Description: "UUID of the last payment request of the previous page"},
+			{Name: "limit", Description: "Page size, 20 by default, at most 100"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ListPaymentRequestsResponse{},
+		},
+	})
+	paymentRequestGroup.handle(http.MethodGet, "/:payment_request_uuid", ctrl.GetPaymentRequest, openapi.Route{
+		Summary: "A payment request, to its requester and payer",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.PaymentRequest{},
+		},
+	})
+	paymentRequestGroup.handleIdempotent(http.MethodPost, "/:payment_request_uuid/approve", ctrl.ApprovePaymentRequest, openapi.Route{
+		Summary: "Pay a pending payment request, payer only",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ApprovePaymentRequestResponse{},
+		},
+	})
+	paymentRequestGroup.handle(http.MethodPost, "/:payment_request_uuid/decline", ctrl.DeclinePaymentRequest, openapi.Route{
+		Summary: "Decline a pending payment request, payer only, the body with a reason is optional",
+		Request: controllers.DeclinePaymentRequestRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.PaymentRequest{},
+		},
+	})
+	paymentRequestGroup.handle(http.MethodPost, "/:payment_request_uuid/cancel", ctrl.CancelPaymentRequest, openapi.Route{
+		Summary: "Withdraw a pending payment request, requester only",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.PaymentRequest{},
+		},
+	})
+
+	splitBillGroup := v1.group("/split-bills", "payment-requests", openapi.AuthBearer, accessToken)
+	splitBillGroup.handleIdempotent(http.MethodPost, "", ctrl.CreateSplitBill, openapi.Route{
+		Summary: "Split a bill into payment requests to several payers",
+		Request: controllers.CreateSplitBillRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated: controllers.SplitBill{},
+		},
+	})
+	splitBillGroup.handle(http.MethodGet, "/:split_bill_uuid", ctrl.GetSplitBill, openapi.Route{
+		Summary: "A split bill with who has paid, to its requester and payers",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.SplitBill{},
+		},
+	})
+
+	myHandleGroup := v1.group("/wallets/me", "handles", openapi.AuthBearer, accessToken)
+	myHandleGroup.handle(http.MethodGet, "/handle", ctrl.GetMyHandle, openapi.Route{
+		Summary: "Handle of the authenticated account's wallet",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.HandleResponse{},
+		},
+	})
+	myHandleGroup.handle(http.MethodPut, "/handle", ctrl.SetMyHandle, openapi.Route{
+		Summary: "Give the authenticated account's wallet a handle, releasing the one it had",
+		Request: controllers.SetHandleRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.HandleResponse{},
+		},
+	})
+	myHandleGroup.handle(http.MethodDelete, "/handle", ctrl.DeleteMyHandle, openapi.Route{
+		Summary: "Release the handle of the authenticated account's wallet",
+		Responses: map[int]interface{}{
+			http.StatusNoContent: nil,
+		},
+	})
+	myHandleGroup.handle(http.MethodGet, "/qr", ctrl.GetMyPaymentQR, openapi.Route{
+		Summary: "Signed payment QR payload to the authenticated account's handle",
+		Query: []openapi.Param{
+			{Name: "amount_in_cents", Description: "Amount to pay, the payer chooses it when omitted"},
+			{Name: "expires_at", Description: "RFC 3339 expiry, 24 hours away by default"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.PaymentQR{},
+		},
+	})
+	myHandleGroup.handle(http.MethodGet, "/qr.png", ctrl.GetMyPaymentQRPNG, openapi.Route{
+		Summary:     "Payment QR code to the authenticated account's handle as a PNG",
+		ContentType: openapi.ContentTypePNG,
+		Query: []openapi.Param{
+			{Name: "amount_in_cents", Description: "Amount to pay, the payer chooses it when omitted"},
+			{Name: "expires_at", Description: "RFC 3339 expiry, 24 hours away by default"},
+			{Name: "size", Description: "Width and height in pixels, 512 by default, 128 to 1024"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: "",
+		},
+	})
+
+	handleGroup := v1.group("/handles", "handles", openapi.AuthBearer, accessToken)
+	handleGroup.handle(http.MethodGet, "/:handle", ctrl.ResolveHandle, openapi.Route{
+		Summary: "Wallet and display name a handle pays",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ResolvedHandle{},
+		},
+	})
+
+	qrGroup := v1.group("/qr", "handles", openapi.AuthBearer, accessToken)
+	qrGroup.handle(http.MethodPost, "/parse", ctrl.ParsePaymentQR, openapi.Route{
+		Summary: "Check a scanned payment QR payload and return what it asks for",
+		Request: controllers.ParsePaymentQRRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ParsedPaymentQR{},
+		},
+	})
+
+	merchantGroup := v1.group("/merchants", "merchants", openapi.AuthBearer, accessToken)
+	merchantGroup.handle(http.MethodPost, "", ctrl.CreateMerchant, openapi.Route{
+		Summary: "Make the merchant profile of the authenticated MERCHANT account",
+		Request: controllers.CreateMerchantRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated: controllers.Merchant{},
+		},
+	})
+	merchantGroup.handle(http.MethodGet, "/me", ctrl.GetMyMerchant, openapi.Route{
+		Summary: "Merchant profile of the authenticated account, with its webhook secret",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.Merchant{},
+		},
+	})
+	merchantGroup.handle(http.MethodPut, "/me", ctrl.UpdateMyMerchant, openapi.Route{
+		Summary: "Replace the name and webhook URL of the authenticated account's merchant profile",
+		Request: controllers.UpdateMerchantRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.Merchant{},
+		},
+	})
+	merchantGroup.handle(http.MethodPost, "/me/webhook-secret", ctrl.RotateMyMerchantWebhookSecret, openapi.Route{
+		Summary: "Replace the secret signing the authenticated merchant's webhooks",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.Merchant{},
+		},
+	})
+	merchantGroup.handle(http.MethodGet, "/me/settlements", ctrl.ListMySettlementBatches, openapi.Route{
+		Summary: "Settlement batches of the authenticated merchant, newest first",
+		Query: []openapi.Param{
+			{Name: "before", Description: "UUID of the last settlement batch of the previous page"},
+			{Name: "limit", Description: "Page size, 20 by default, at most 100"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ListSettlementBatchesResponse{},
+		},
+	})
+	merchantGroup.handle(http.MethodGet, "/me/settlements/:settlement_batch_uuid", ctrl.GetMySettlementReport, openapi.Route{
+		Summary: "A settlement batch of the authenticated merchant with the payments, refunds and fees it netted",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.SettlementReport{},
+		},
+	})
+	merchantGroup.handle(http.MethodGet, "/me/settlements/:settlement_batch_uuid/report.csv", ctrl.DownloadMySettlementReport, openapi.Route{
+		Summary:     "CSV settlement report of a batch of the authenticated merchant, one row per checkout session and a row of totals",
+		ContentType: openapi.ContentTypeCSV,
+		Responses: map[int]interface{}{
+			http.StatusOK: "",
+		},
+	})
+
+	checkoutSessionGroup := v1.group("/checkout/sessions", "merchants", openapi.AuthBearer, accessToken)
+	checkoutSessionGroup.handleIdempotent(http.MethodPost, "", ctrl.CreateCheckoutSession, openapi.Route{
+		Summary: "Ask a customer to pay the authenticated merchant for an order",
+		Request: controllers.CreateCheckoutSessionRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated: controllers.CheckoutSession{},
+		},
+	})
+	checkoutSessionGroup.handle(http.MethodGet, "", ctrl.ListCheckoutSessions, openapi.Route{
+		Summary: "Checkout sessions of the authenticated merchant, newest first",
+		Query: []openapi.Param{
+			{Name: "status", Description: "OPEN, COMPLETED, REFUNDED, CANCELLED or EXPIRED"},
+			{Name: "before", Description: "UUID of the last checkout session of the previous page"},
+			{Name: "limit", Description: "Page size, 20 by default, at most 100"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ListCheckoutSessionsResponse{},
+		},
+	})
+	checkoutSessionGroup.handle(http.MethodGet, "/:checkout_session_uuid", ctrl.GetCheckoutSession, openapi.Route{
+		Summary: "A checkout session, with its refunds to its merchant",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.CheckoutSession{},
+		},
+	})
+	checkoutSessionGroup.handleIdempotent(http.MethodPost, "/:checkout_session_uuid/pay", ctrl.PayCheckoutSession, openapi.Route{
+		Summary: "Pay an open checkout session from the authenticated account's wallet",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.PayCheckoutSessionResponse{},
+		},
+	})
+	checkoutSessionGroup.handle(http.MethodPost, "/:checkout_session_uuid/cancel", ctrl.CancelCheckoutSession, openapi.Route{
+		Summary: "Close an open checkout session, merchant only",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.CheckoutSession{},
+		},
+	})
+	checkoutSessionGroup.handleIdempotent(http.MethodPost, "/:checkout_session_uuid/refunds", ctrl.RefundCheckoutSession, openapi.Route{
+		Summary: "Refund some or all of a paid checkout session, merchant only, without a body everything left is refunded",
+		Request: controllers.CreateRefundRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated: controllers.CreateRefundResponse{},
+		},
+	})
+
+	feeGroup := v1.group("/fees", "fees", openapi.AuthBearer, accessToken)
+	feeGroup.handle(http.MethodPost, "/quote", ctrl.QuoteFee, openapi.Route{
+		Summary: "Fee a transfer from the authenticated account's wallet would be charged if posted now",
+		Request: controllers.FeeQuoteRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.FeeQuote{},
+		},
+	})
+	feeGroup.handle(http.MethodPost, "/schedules", ctrl.CreateFeeSchedule, openapi.Route{
+		Summary: "Add a flat, percentage or tiered fee keyed by purpose code, role or merchant, internal roles only",
+		Request: controllers.CreateFeeScheduleRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated: controllers.FeeSchedule{},
+		},
+	})
+	feeGroup.handle(http.MethodGet, "/schedules", ctrl.ListFeeSchedules, openapi.Route{
+		Summary: "Fee schedules in force, newest first, internal roles only",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ListFeeSchedulesResponse{},
+		},
+	})
+	feeGroup.handle(http.MethodGet, "/schedules/:fee_schedule_uuid", ctrl.GetFeeSchedule, openapi.Route{
+		Summary: "A fee schedule, internal roles only",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.FeeSchedule{},
+		},
+	})
+	feeGroup.handle(http.MethodDelete, "/schedules/:fee_schedule_uuid", ctrl.DeleteFeeSchedule, openapi.Route{
+		Summary: "Stop a fee schedule applying to new transfers, internal roles only",
+		Responses: map[int]interface{}{
+			http.StatusNoContent: nil,
+		},
+	})
+
+	reconciliationGroup := v1.group("/reconciliation", "reconciliation", openapi.AuthBearer, accessToken)
+	reconciliationGroup.handle(http.MethodPost, "/imports", ctrl.ImportSettlementFile, openapi.Route{
+		Summary:            "Import a CSV settlement file from a bank or gateway and reconcile it, internal roles only",
+		RequestContentType: openapi.ContentTypeCSV,
+		Query: []openapi.Param{
+			{Name: "source", Description: "Bank or gateway the file comes from", Required: true},
+			{Name: "filename", Description: "Name of the uploaded file"},
+			{Name: "timezone", Description: "IANA timezone of settled_at values without a zone, UTC by default"},
+		},
+		Request: "",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.SettlementImportResponse{},
+		},
+	})
+	reconciliationGroup.handle(http.MethodGet, "/imports", ctrl.ListSettlementImports, openapi.Route{
+		Summary: "Settlement imports, newest first, internal roles only",
+		Query: []openapi.Param{
+			{Name: "before", Description: "UUID of the last import of the previous page"},
+			{Name: "limit", Description: "Page size, 50 by default, at most 100"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ListSettlementImportsResponse{},
+		},
+	})
+	reconciliationGroup.handle(http.MethodGet, "/imports/:import_uuid", ctrl.GetReconciliationReport, openapi.Route{
+		Summary: "Matched, mismatched and unmatched records of a settlement import and the unmatched transactions around it, internal roles only",
+		Query: []openapi.Param{
+			{Name: "status", Description: "Only records in this status: UNMATCHED, MATCHED, MISMATCHED or RESOLVED"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ReconciliationReport{},
+		},
+	})
+	reconciliationGroup.handle(http.MethodPost, "/imports/:import_uuid/reconcile", ctrl.ReconcileSettlementImport, openapi.Route{
+		Summary: "Run the matching rules again over the unmatched and mismatched records of an import, internal roles only",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.SettlementImportResponse{},
+		},
+	})
+	reconciliationGroup.handle(http.MethodPost, "/records/:record_uuid/resolve", ctrl.ResolveSettlementRecord, openapi.Route{
+		Summary: "Match, accept, ignore or reopen a settlement record, internal roles only",
+		Request: controllers.ResolveSettlementRecordRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.SettlementRecordResponse{},
+		},
+	})
+
+	payoutGroup := v1.group("/payouts", "payouts", openapi.AuthBearer, accessToken)
+	payoutGroup.handleIdempotent(http.MethodPost, "", ctrl.CreatePayout, openapi.Route{
+		Summary: "Credit up to 100000 wallets from the treasury, validated up front and posted in chunks, internal roles only",
+		Request: controllers.CreatePayoutRequest{},
+		Responses: map[int]interface{}{
+			http.StatusAccepted: controllers.Payout{},
+		},
+	})
+	payoutGroup.handleIdempotent(http.MethodPost, "/csv", ctrl.ImportPayoutFile, openapi.Route{
+		Summary:            "Credit the wallets of a CSV payout file from the treasury, columns wallet_uuid, amount in rupees and description, internal roles only",
+		RequestContentType: openapi.ContentTypeCSV,
+		Query: []openapi.Param{
+			{Name: "purpose_code", Description: "Purpose code of the credits, REWARD by default"},
+			{Name: "description", Description: "Description of rows without their own"},
+			{Name: "filename", Description: "Name of the uploaded file"},
+		},
+		Request: "",
+		Responses: map[int]interface{}{
+			http.StatusAccepted: controllers.Payout{},
+		},
+	})
+	payoutGroup.handle(http.MethodGet, "/:payout_uuid", ctrl.GetPayout, openapi.Route{
+		Summary: "Progress of a payout, internal roles only",
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.Payout{},
+		},
+	})
+	payoutGroup.handle(http.MethodGet, "/:payout_uuid/items", ctrl.ListPayoutItems, openapi.Route{
+		Summary: "Items of a payout in file order, internal roles only",
+		Query: []openapi.Param{
+			{Name: "status", Description: "PENDING, SUCCEEDED or FAILED"},
+			{Name: "after_row", Description: "Row number of the last item of the previous page"},
+			{Name: "limit", Description: "Page size, 100 by default, at most 1000"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controllers.ListPayoutItemsResponse{},
+		},
+	})
+	payoutGroup.handle(http.MethodGet, "/:payout_uuid/result.csv", ctrl.DownloadPayoutResult, openapi.Route{
+		Summary:     "Streamed CSV with the outcome of every item of a payout, internal roles only",
+		ContentType: openapi.ContentTypeCSV,
+		Responses: map[int]interface{}{
+			http.StatusOK: "",
+		},
+	})
 }